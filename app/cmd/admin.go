@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CREATE_ADMIN_TIMEOUT bounds a create-admin run.
+const CREATE_ADMIN_TIMEOUT = 30 * time.Second
+
+// createAdmin implements the create-admin subcommand: it sets the password
+// of the account of an administrator listed in ADMIN_USERS, creating the
+// account if needed. The password is the first line read from stdin, so it
+// stays out of the process list and shell history. Registration refuses
+// the names in ADMIN_USERS, so this is the only way to create their
+// accounts; resetting the password of an account registered before its
+// name was listed takes it over.
+//
+// Usage: create-admin <username> < password-file
+func createAdmin(db *storage.Storage, admins []string, args []string, stdin io.Reader) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: create-admin <username> < password-file")
+	}
+	username := strings.ToLower(strings.TrimSpace(args[0]))
+	if !slices.ContainsFunc(admins, func(admin string) bool { return strings.EqualFold(admin, username) }) {
+		return fmt.Errorf("%q is not listed in ADMIN_USERS", username)
+	}
+
+	password, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("read password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < handlers.MIN_PASSWORD_BYTES || len(password) > handlers.MAX_PASSWORD_BYTES {
+		return fmt.Errorf("password must be %d to %d bytes", handlers.MIN_PASSWORD_BYTES, handlers.MAX_PASSWORD_BYTES)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CREATE_ADMIN_TIMEOUT)
+	defer cancel()
+
+	result, err := db.Users.UpdateOne(ctx, bson.M{"username": username}, bson.M{
+		"$set":         bson.M{"password_hash": string(hash)},
+		"$setOnInsert": bson.M{"_id": db.IDs.New(), "created_at": time.Now()},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("save account: %w", err)
+	}
+	if result.UpsertedCount > 0 {
+		fmt.Printf("created administrator %s\n", username)
+	} else {
+		fmt.Printf("reset the password of administrator %s\n", username)
+	}
+	return nil
+}
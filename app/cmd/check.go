@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/config"
+	"github.com/pedrobertao/challenge-prosi/app/internal/selfcheck"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+)
+
+// CHECK_TIMEOUT bounds a whole --check run.
+const CHECK_TIMEOUT = time.Minute
+
+// runCheck implements the --check flag: it runs the self-check against the
+// configured database without creating indexes or backfilling, logs the
+// report, and returns the process exit code, 1 when a check failed.
+//
+// Usage: --check
+func runCheck(cfg *config.Config) int {
+	ctx, cancel := context.WithTimeout(context.Background(), CHECK_TIMEOUT)
+	defer cancel()
+
+	var db *storage.Storage
+	ids, err := storage.NewIDCodec(cfg.IDFormat)
+	if err == nil {
+		db, err = storage.Open(cfg.MongoURI, cfg.DBName, ids, storage.ReadRouting{
+			Preference:     cfg.MongoReadPreference,
+			TagSets:        cfg.MongoReadTags,
+			Hedged:         cfg.MongoHedgedReads,
+			MaxStaleness:   cfg.MongoMaxStaleness,
+			LocalThreshold: cfg.MongoLocalThreshold,
+		})
+	}
+
+	report := selfcheck.New(cfg, db, err).Run(ctx)
+	report.Log()
+	if db != nil {
+		closeStorage(db, cfg.ShutdownTimeout)
+	}
+	if report.Failed() {
+		return 1
+	}
+	return 0
+}
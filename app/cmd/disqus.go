@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/disqus"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/sanitize"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DISQUS_IMPORT_TIMEOUT bounds a whole Disqus import run.
+const DISQUS_IMPORT_TIMEOUT = 30 * time.Minute
+
+// Ways a Disqus thread was matched to a post, shown in the report.
+const (
+	MATCH_ID    = "id"    // thread identifier or link slug is the post ID
+	MATCH_TITLE = "title" // thread title equals the post title
+)
+
+// importDisqus implements the import-disqus subcommand: it imports the
+// comments of a Disqus export into the posts its threads belong to and
+// prints a mapping report. A thread maps to a post when its identifier or
+// the last segment of its link is the post's ID, or else when its title
+// matches the post title (ignoring case). Unmatched threads are reported
+// and skipped. Running it again skips comments already imported. Comment
+// markup is sanitized in the configured SANITIZE_MODE, like comments
+// posted through the API.
+//
+// Usage: import-disqus [-dry-run] <export.xml>
+func importDisqus(db *storage.Storage, sanitizeMode string, args []string) error {
+	flags := flag.NewFlagSet("import-disqus", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "report the mapping without importing")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: import-disqus [-dry-run] <export.xml>")
+	}
+	sanitizer, err := sanitize.New(sanitizeMode)
+	if err != nil {
+		return fmt.Errorf("SANITIZE_MODE: %w", err)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	export, err := disqus.Parse(file)
+	if err != nil {
+		return fmt.Errorf("parse export: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DISQUS_IMPORT_TIMEOUT)
+	defer cancel()
+
+	posts, err := loadPostIndex(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	grouped := export.ThreadComments()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "THREAD\tTITLE\tPOST\tMATCH\tCOMMENTS\tIMPORTED\tSKIPPED")
+	var totalImported, totalSkipped, unmatched int
+	for _, thread := range export.Threads {
+		comments := grouped[thread.DsqID]
+		postID, match := posts.match(db.IDs, thread)
+		if match == "" {
+			unmatched++
+			fmt.Fprintf(w, "%s\t%s\t-\tunmatched\t%d\t0\t0\n", thread.DsqID, thread.Title, len(comments))
+			continue
+		}
+
+		for i := range comments {
+			comments[i].Content = sanitizer.Sanitize(comments[i].Content)
+		}
+		imported, skipped := 0, 0
+		if !*dryRun && len(comments) > 0 {
+			imported, skipped, err = db.ImportComments(ctx, postID, comments)
+			if err != nil {
+				w.Flush()
+				return fmt.Errorf("import thread %s: %w", thread.DsqID, err)
+			}
+		}
+		totalImported += imported
+		totalSkipped += skipped
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\n", thread.DsqID, thread.Title, postID, match, len(comments), imported, skipped)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d threads, %d unmatched, %d comments imported, %d skipped\n",
+		len(export.Threads), unmatched, totalImported, totalSkipped)
+	if *dryRun {
+		fmt.Println("dry run: nothing was written")
+	}
+	return nil
+}
+
+// postIndex maps post IDs and lowercased titles to posts for thread matching.
+type postIndex struct {
+	ids    map[models.ID]bool
+	titles map[string]models.ID
+}
+
+// loadPostIndex reads the ID and title of every post.
+func loadPostIndex(ctx context.Context, db *storage.Storage) (*postIndex, error) {
+	cursor, err := db.Posts.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"title": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	index := &postIndex{ids: map[models.ID]bool{}, titles: map[string]models.ID{}}
+	for cursor.Next(ctx) {
+		var post models.BlogPost
+		if err := cursor.Decode(&post); err != nil {
+			return nil, err
+		}
+		index.ids[post.ID] = true
+		index.titles[strings.ToLower(strings.TrimSpace(post.Title))] = post.ID
+	}
+	return index, cursor.Err()
+}
+
+// match finds the post a thread belongs to. Returns the post ID and how it
+// matched, or an empty match when no post fits.
+func (p *postIndex) match(ids storage.IDCodec, thread disqus.Thread) (models.ID, string) {
+	for _, candidate := range []string{thread.Identifier, thread.Slug()} {
+		if id, err := ids.Parse(candidate); err == nil && p.ids[id] {
+			return id, MATCH_ID
+		}
+	}
+	if id, ok := p.titles[strings.ToLower(thread.Title)]; ok && thread.Title != "" {
+		return id, MATCH_TITLE
+	}
+	return "", ""
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/site"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GENERATE_TIMEOUT bounds reading the whole blog for a static snapshot.
+const GENERATE_TIMEOUT = 10 * time.Minute
+
+// generateSite implements the generate subcommand: it renders every post,
+// the index, tag pages, and feeds to a static output directory (see the
+// site package for what is published).
+//
+// Usage: generate [-out dir] [-title name] [-base-url url]
+func generateSite(db *storage.Storage, args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	out := flags.String("out", "public", "output directory")
+	title := flags.String("title", "Blog", "blog name shown in pages and feeds")
+	baseURL := flags.String("base-url", "", "absolute URL the output will be hosted at, for feed links")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), GENERATE_TIMEOUT)
+	defer cancel()
+
+	cursor, err := db.Posts.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var posts []models.BlogPost
+	if err := cursor.All(ctx, &posts); err != nil {
+		return err
+	}
+
+	cursor, err = db.Comments.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var all []models.Comment
+	if err := cursor.All(ctx, &all); err != nil {
+		return err
+	}
+	comments := make(map[models.ID][]models.Comment)
+	for _, comment := range all {
+		comments[comment.PostID] = append(comments[comment.PostID], comment)
+	}
+
+	report, err := site.Generate(site.Site{
+		Title:       *title,
+		BaseURL:     *baseURL,
+		GeneratedAt: time.Now(),
+	}, posts, comments, *out)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %d post pages (%d listed), %d tag pages to %s; skipped %d private or protected posts\n",
+		report.Posts, report.Listed, report.Tags, *out, report.Skipped)
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/config"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/selfcheck"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/lifecycle"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+	"go.uber.org/zap"
+)
+
+// BACKFILL_TIMEOUT bounds the one-off backfills run at startup.
+const BACKFILL_TIMEOUT = 10 * time.Second
+
+// newLifecycle registers the hooks of the subsystems the server runs
+// besides the HTTP listener, which the caller appends last so it starts
+// once everything else is ready and is the first to stop. Each stop is
+// bounded by SHUTDOWN_TIMEOUT.
+//
+// Parameters:
+//   - cfg: application configuration
+//   - db: connected storage, disconnected on shutdown
+//   - h: handler owning the background jobs and the webhook dispatcher
+//
+// Returns the manager, not started yet.
+func newLifecycle(cfg *config.Config, db *storage.Storage, h *handlers.Handler) *lifecycle.Manager {
+	manager := lifecycle.New(cfg.ShutdownTimeout)
+	manager.Append(
+		// Spans of the whole shutdown are still exported
+		lifecycle.Hook{Name: "tracing", Stop: tracing.Shutdown},
+		lifecycle.Hook{Name: "database", Stop: db.Close},
+	)
+
+	// Rate limit counters shared through Redis hold pooled connections
+	if closer, ok := h.RateLimits.(io.Closer); ok {
+		manager.Append(lifecycle.Hook{
+			Name: "rate limit cache",
+			Stop: func(context.Context) error { return closer.Close() },
+		})
+	}
+
+	// The ActivityPub actor keeps its signing key in the database
+	if h.Federation != nil {
+		manager.Append(lifecycle.Hook{Name: "federation", Start: h.Federation.Init})
+	}
+
+	manager.Append(
+		// Posts saved before backlinks existed get their post links found once
+		lifecycle.Hook{Name: "backfill", Start: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, BACKFILL_TIMEOUT)
+			defer cancel()
+			if err := db.BackfillLinkedPosts(ctx, h.LinkedPosts); err != nil {
+				logger.Warn("failed to backfill linked posts", zap.Error(err))
+			}
+			return nil
+		}},
+
+		// Background jobs and schedulers run until shutdown, which waits
+		// for their final writes before the database disconnects
+		lifecycle.Workers("jobs",
+			h.Duplicates.Run,
+			h.Changes.Run,
+			h.Analytics.Run,
+			h.Closer.Run,
+			h.Purger.Run,
+			h.Links.Run,
+			selfcheck.New(cfg, db, nil).Log, // One report, without delaying startup
+		),
+
+		// Announcements of posts published just before shutdown still go out
+		lifecycle.Hook{Name: "webhooks", Stop: h.Integrations.Wait},
+	)
+	return manager
+}
@@ -2,13 +2,23 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+	_ "time/tzdata" // Time zone database for the timezone user setting; the runtime image has none
 
 	"github.com/pedrobertao/challenge-prosi/app/internal/config"
 	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
 	"github.com/pedrobertao/challenge-prosi/app/internal/routes"
 	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/lifecycle"
 	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
 	"go.uber.org/zap"
 )
 
@@ -19,16 +29,139 @@ func main() {
 		log.Fatal("failed to init logger", err)
 	}
 
-	db, err := storage.Connect(cfg.MongoURI, cfg.DBName)
+	// Trace requests end to end when an OTLP receiver is configured;
+	// invalid headers are reported but not fatal
+	headers, err := tracing.ParseHeaders(cfg.OTLPHeaders)
+	if err != nil {
+		logger.Warn("some OTLP headers are invalid", zap.Error(err))
+	}
+	tracing.Setup(cfg.OTLPEndpoint, headers, cfg.TraceServiceName, cfg.TraceSampleRatio)
+
+	// "routes" prints the API surface without connecting to the database
+	if len(os.Args) > 1 && os.Args[1] == "routes" {
+		printRoutes(cfg)
+		return
+	}
+
+	// "--check" runs the self-check and exits non-zero if it fails
+	if len(os.Args) > 1 && os.Args[1] == "--check" {
+		code := runCheck(cfg)
+		_ = logger.Sync()
+		os.Exit(code)
+	}
+
+	ids, err := storage.NewIDCodec(cfg.IDFormat)
+	if err != nil {
+		logger.Fatal("invalid ID_FORMAT", zap.Error(err))
+	}
+
+	db, err := storage.Connect(cfg.MongoURI, cfg.DBName, ids, storage.ReadRouting{
+		Preference:     cfg.MongoReadPreference,
+		TagSets:        cfg.MongoReadTags,
+		Hedged:         cfg.MongoHedgedReads,
+		MaxStaleness:   cfg.MongoMaxStaleness,
+		LocalThreshold: cfg.MongoLocalThreshold,
+	})
 	if err != nil {
 		logger.Fatal("failed to connect to database:", zap.Error(err))
 	}
-	defer db.Close(context.Background())
 
-	handler := handlers.New(db)
+	// "import-disqus" imports a Disqus export and exits
+	if len(os.Args) > 1 && os.Args[1] == "import-disqus" {
+		err := importDisqus(db, cfg.SanitizeMode, os.Args[2:])
+		closeStorage(db, cfg.ShutdownTimeout)
+		if err != nil {
+			logger.Fatal("disqus import failed", zap.Error(err))
+		}
+		return
+	}
+
+	// "create-admin" creates or resets an administrator account and exits
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		err := createAdmin(db, cfg.AdminUsers, os.Args[2:], os.Stdin)
+		closeStorage(db, cfg.ShutdownTimeout)
+		if err != nil {
+			logger.Fatal("admin account creation failed", zap.Error(err))
+		}
+		return
+	}
+
+	// "generate" renders a static snapshot of the blog and exits
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		err := generateSite(db, os.Args[2:])
+		closeStorage(db, cfg.ShutdownTimeout)
+		if err != nil {
+			logger.Fatal("static site generation failed", zap.Error(err))
+		}
+		return
+	}
+
+	// Tokens signed with sample or shared secrets could be forged
+	if err := cfg.CheckSecrets(); err != nil {
+		closeStorage(db, cfg.ShutdownTimeout)
+		logger.Fatal("refusing to start with insecure secrets", zap.Error(err))
+	}
+
+	handler := handlers.New(db, cfg)
 	app := routes.Setup(handler)
 
-	if err := app.Listen(":" + cfg.Port); err != nil {
+	// Subsystems start in this order and stop in reverse: requests drain
+	// first, then webhooks and jobs finish their writes, then the database
+	// disconnects, and the remaining spans are exported last
+	listenErr := make(chan error, 1)
+	manager := newLifecycle(cfg, db, handler)
+	manager.Append(lifecycle.Hook{
+		Name: "server",
+		Start: func(context.Context) error {
+			go func() {
+				listenErr <- app.Listen(":" + cfg.Port)
+			}()
+			return nil
+		},
+		Stop: app.ShutdownWithContext,
+	})
+	if err := manager.Start(context.Background()); err != nil {
+		logger.Fatal("failed to start", zap.Error(err))
+	}
+
+	// Serve until the listener fails or SIGINT/SIGTERM arrives
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	select {
+	case err := <-listenErr:
 		logger.Fatal("error on server listener", zap.Error(err))
+	case <-signals.Done():
+		logger.Info("shutting down", zap.Duration("timeout", cfg.ShutdownTimeout))
+	}
+
+	if err := manager.Stop(); err != nil {
+		logger.Warn("shutdown incomplete", zap.Error(err))
+	}
+	logger.Info("shutdown complete")
+	_ = logger.Sync()
+}
+
+// closeStorage disconnects the MongoDB client within timeout.
+func closeStorage(db *storage.Storage, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := db.Close(ctx); err != nil {
+		logger.Warn("failed to disconnect from database", zap.Error(err))
+	}
+}
+
+// printRoutes writes every registered route as a table to stdout.
+func printRoutes(cfg *config.Config) {
+	app := routes.Setup(handlers.New(nil, cfg))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARE\tAUTH")
+	for _, route := range routes.Describe(app) {
+		middleware := strings.Join(route.Middleware, ",")
+		if middleware == "" {
+			middleware = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Handler, middleware, route.Auth)
 	}
+	w.Flush()
 }
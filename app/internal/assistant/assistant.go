@@ -0,0 +1,50 @@
+// Package assistant defines optional AI assistance for authors, such as
+// generating a post summary and suggesting tags. Drivers implement the
+// ContentAssistant interface; an OpenAI-compatible driver is provided.
+package assistant
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// MAX_SUGGESTED_TAGS caps how many tag suggestions are returned.
+const MAX_SUGGESTED_TAGS = 8
+
+// ErrBadResponse is returned when the provider answers with something that
+// cannot be interpreted as a suggestion.
+var ErrBadResponse = errors.New("assistant returned an unusable response")
+
+// Suggestion is generated assistance for a post. Nothing is stored until
+// the author explicitly accepts it.
+type Suggestion struct {
+	Summary string   `json:"summary"` // Short excerpt/summary of the post
+	Tags    []string `json:"tags"`    // Suggested topic tags
+}
+
+// ContentAssistant generates suggestions for a post's content.
+// Implementations must be safe for concurrent use.
+type ContentAssistant interface {
+	// Suggest produces a summary and tag suggestions for the given post.
+	Suggest(ctx context.Context, title, content string) (Suggestion, error)
+}
+
+// CleanTags trims, lowercases, and de-duplicates tags, dropping empty ones
+// and keeping at most MAX_SUGGESTED_TAGS.
+func CleanTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+		if len(cleaned) == MAX_SUGGESTED_TAGS {
+			break
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,115 @@
+package assistant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+)
+
+// DEFAULT_REQUEST_TIMEOUT bounds a single completion request.
+const DEFAULT_REQUEST_TIMEOUT = 30 * time.Second
+
+// systemPrompt instructs the model to answer with a JSON suggestion.
+const systemPrompt = `You help blog authors. Given a post title and content, reply with a JSON object ` +
+	`{"summary": string, "tags": [string]}: a summary of at most two sentences in the post's language ` +
+	`and up to 8 short lowercase topic tags. Reply with JSON only.`
+
+// OpenAI is a ContentAssistant backed by any OpenAI-compatible chat
+// completions API (OpenAI, Azure OpenAI proxies, Ollama, vLLM, ...).
+type OpenAI struct {
+	BaseURL string       // API base URL, e.g. "https://api.openai.com/v1"
+	APIKey  string       // Bearer token sent in the Authorization header
+	Model   string       // Model name, e.g. "gpt-4o-mini"
+	Client  *http.Client // HTTP client used for requests
+}
+
+// NewOpenAI creates an OpenAI-compatible driver.
+//
+// Parameters:
+//   - baseURL: API base URL without the /chat/completions suffix
+//   - apiKey: API key for the provider
+//   - model: model name to request
+//
+// Returns a pointer to a configured OpenAI driver.
+func NewOpenAI(baseURL, apiKey, model string) *OpenAI {
+	return &OpenAI{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		Model:   model,
+		Client:  &http.Client{Timeout: DEFAULT_REQUEST_TIMEOUT, Transport: tracing.Transport(nil)},
+	}
+}
+
+// chatMessage is a single message of a chat completion request or reply.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest is the body of POST /chat/completions.
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	ResponseFormat map[string]string `json:"response_format"`
+}
+
+// chatResponse is the subset of the completion reply that is used.
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Suggest asks the model for a summary and tags and parses its JSON reply.
+func (o *OpenAI) Suggest(ctx context.Context, title, content string) (Suggestion, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: o.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: "Title: " + title + "\n\n" + content},
+		},
+		ResponseFormat: map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return Suggestion{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return Suggestion{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.APIKey)
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Suggestion{}, fmt.Errorf("assistant provider returned status %d", resp.StatusCode)
+	}
+
+	var completion chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return Suggestion{}, err
+	}
+	if len(completion.Choices) == 0 {
+		return Suggestion{}, ErrBadResponse
+	}
+
+	var suggestion Suggestion
+	if err := json.Unmarshal([]byte(completion.Choices[0].Message.Content), &suggestion); err != nil {
+		return Suggestion{}, ErrBadResponse
+	}
+	suggestion.Summary = strings.TrimSpace(suggestion.Summary)
+	suggestion.Tags = CleanTags(suggestion.Tags)
+	return suggestion, nil
+}
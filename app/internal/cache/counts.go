@@ -0,0 +1,97 @@
+// Package cache provides small in-process caches used to take load off
+// the database for hot, cheap-to-recompute values such as comment counts.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// MAX_COUNT_ENTRIES bounds the number of cached counts. When exceeded,
+// expired entries are swept; if the cache is still full it is reset.
+const MAX_COUNT_ENTRIES = 10000
+
+// countEntry is a cached count together with its expiry time.
+type countEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// Counts is a concurrency-safe TTL cache of int64 counts keyed by string
+// (typically a post ID in hex). Entries expire after the configured TTL and
+// can be invalidated explicitly when the underlying data changes.
+type Counts struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]countEntry
+}
+
+// NewCounts creates a count cache whose entries live for ttl.
+// A ttl of zero or less disables caching: Get always misses.
+//
+// Parameters:
+//   - ttl: how long a cached count stays valid
+//
+// Returns a pointer to a new, empty Counts cache.
+func NewCounts(ttl time.Duration) *Counts {
+	return &Counts{
+		ttl:     ttl,
+		entries: make(map[string]countEntry),
+	}
+}
+
+// Get returns the cached count for key and whether it was present and fresh.
+func (c *Counts) Get(key string) (int64, bool) {
+	if c.ttl <= 0 {
+		return 0, false
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.value, true
+}
+
+// Set stores value for key, replacing any previous entry.
+func (c *Counts) Set(key string, value int64) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Keep memory bounded: sweep expired entries, reset if still full
+	if len(c.entries) >= MAX_COUNT_ENTRIES {
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= MAX_COUNT_ENTRIES {
+			c.entries = make(map[string]countEntry)
+		}
+	}
+
+	c.entries[key] = countEntry{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Invalidate drops the cached count for key, forcing the next Get to miss.
+func (c *Counts) Invalidate(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+// Clear drops every cached count, for when invalidations may have been
+// missed and no entry can be trusted.
+func (c *Counts) Clear() {
+	c.mu.Lock()
+	c.entries = make(map[string]countEntry)
+	c.mu.Unlock()
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// FlightStats reports how many calls a Flight served and how many of them
+// took part in a deduplicated execution. Shared minus the number of distinct
+// executions is the number of database queries saved.
+type FlightStats struct {
+	Calls  int64 `json:"calls"`  // Total calls made through the flight
+	Shared int64 `json:"shared"` // Calls whose result went to more than one caller
+}
+
+// Flight collapses identical concurrent reads into one execution, so a burst
+// of requests for the same hot key results in a single database query.
+// Results are shared between callers and must be treated as read-only.
+type Flight struct {
+	group  singleflight.Group
+	calls  atomic.Int64
+	shared atomic.Int64
+}
+
+// NewFlight creates an empty request deduplication group.
+func NewFlight() *Flight {
+	return &Flight{}
+}
+
+// Do runs fn once for all concurrent callers using the same key and returns
+// its result to each of them. shared is true when the result was also
+// delivered to other callers.
+//
+// Parameters:
+//   - key: identity of the read (e.g. "post:<id>")
+//   - fn: the expensive read to perform
+func (f *Flight) Do(key string, fn func() (any, error)) (value any, shared bool, err error) {
+	f.calls.Add(1)
+	value, err, shared = f.group.Do(key, fn)
+	if shared {
+		f.shared.Add(1)
+	}
+	return value, shared, err
+}
+
+// Stats returns a snapshot of the flight counters.
+func (f *Flight) Stats() FlightStats {
+	return FlightStats{Calls: f.calls.Load(), Shared: f.shared.Load()}
+}
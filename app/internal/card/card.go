@@ -0,0 +1,168 @@
+// Package card renders social-card images for posts: the PNG that Open
+// Graph and Twitter Card tags point to, shown when a post link is shared.
+// Cards are drawn from a fixed template, the post title wrapped over up to
+// four lines above the author's name, using the Go fonts, so no image
+// service or font files are needed at runtime.
+package card
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// WIDTH and HEIGHT are the card dimensions, the 1.91:1 ratio social
+// networks crop link previews to.
+const (
+	WIDTH  = 1200
+	HEIGHT = 630
+)
+
+// Template layout, in pixels.
+const (
+	MARGIN            = 80 // Space around the text
+	ACCENT_HEIGHT     = 16 // Height of the accent bar along the top edge
+	TITLE_SIZE        = 64 // Title font size
+	TITLE_LINE_HEIGHT = 80 // Distance between title baselines
+	MAX_TITLE_LINES   = 4  // Longer titles are cut with an ellipsis
+	AUTHOR_SIZE       = 32 // Author font size
+)
+
+// CONTENT_TYPE is the media type of rendered cards.
+const CONTENT_TYPE = "image/png"
+
+// Template colors.
+var (
+	backgroundColor = color.RGBA{R: 0x1f, G: 0x29, B: 0x37, A: 0xff}
+	accentColor     = color.RGBA{R: 0xf5, G: 0x9e, B: 0x0b, A: 0xff}
+	titleColor      = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	authorColor     = color.RGBA{R: 0xd1, G: 0xd5, B: 0xdb, A: 0xff}
+)
+
+// fonts parses the embedded Go fonts once. Parsed fonts are safe for
+// concurrent use; the faces drawn with are not, so Render creates its own.
+var fonts = sync.OnceValues(func() ([2]*sfnt.Font, error) {
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return [2]*sfnt.Font{}, err
+	}
+	regular, err := opentype.Parse(goregular.TTF)
+	return [2]*sfnt.Font{bold, regular}, err
+})
+
+// Render draws the card of a post.
+//
+// Parameters:
+//   - title: post title, wrapped to the card width
+//   - author: byline drawn under the title; omitted when empty
+//
+// Returns the PNG-encoded card.
+func Render(title, author string) ([]byte, error) {
+	parsed, err := fonts()
+	if err != nil {
+		return nil, err
+	}
+	titleFace, err := opentype.NewFace(parsed[0], &opentype.FaceOptions{Size: TITLE_SIZE, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+	authorFace, err := opentype.NewFace(parsed[1], &opentype.FaceOptions{Size: AUTHOR_SIZE, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer authorFace.Close()
+
+	// Background and accent bar
+	img := image.NewRGBA(image.Rect(0, 0, WIDTH, HEIGHT))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, WIDTH, ACCENT_HEIGHT), image.NewUniform(accentColor), image.Point{}, draw.Src)
+
+	// Title lines from the top, author pinned to the bottom margin
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(titleColor), Face: titleFace}
+	baseline := ACCENT_HEIGHT + MARGIN + titleFace.Metrics().Ascent.Ceil()
+	for _, line := range wrap(titleFace, title, WIDTH-2*MARGIN, MAX_TITLE_LINES) {
+		drawer.Dot = fixed.P(MARGIN, baseline)
+		drawer.DrawString(line)
+		baseline += TITLE_LINE_HEIGHT
+	}
+	if author != "" {
+		drawer.Src = image.NewUniform(authorColor)
+		drawer.Face = authorFace
+		drawer.Dot = fixed.P(MARGIN, HEIGHT-MARGIN)
+		drawer.DrawString(fit(authorFace, author, WIDTH-2*MARGIN))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// wrap breaks text into lines at most width pixels wide when drawn with
+// face, breaking between words and inside words longer than a line. Text
+// needing more than maxLines lines is cut, ending the last line with "…".
+func wrap(face font.Face, text string, width, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate).Ceil() <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+
+		// Split words wider than a whole line
+		line = ""
+		for _, r := range word {
+			if line != "" && font.MeasureString(face, line+string(r)).Ceil() > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = fit(face, lines[maxLines-1]+"…", width)
+	}
+	return lines
+}
+
+// fit shortens text to width pixels, ending it with "…" when cut.
+func fit(face font.Face, text string, width int) string {
+	if font.MeasureString(face, text).Ceil() <= width {
+		return text
+	}
+	runes := []rune(strings.TrimSuffix(text, "…"))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimRight(string(runes), " ") + "…"
+		if font.MeasureString(face, candidate).Ceil() <= width {
+			return candidate
+		}
+	}
+	return "…"
+}
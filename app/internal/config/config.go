@@ -4,8 +4,14 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +23,216 @@ type Config struct {
 	MongoURI string // MongoDB connection URI (e.g., "mongodb://localhost:27017")
 	DBName   string // MongoDB database name to use
 	ENV      string // dev, prod ...
+
+	// Read routing for replica sets spanning several regions (see
+	// storage.ReadRouting). MongoReadPreference is a read preference mode,
+	// empty to keep the URI's; MongoReadTags lists tag sets in order of
+	// preference, e.g. "region:eu-west;region:us-east"; MongoHedgedReads
+	// enables hedged reads on sharded clusters. MongoMaxStaleness excludes
+	// lagging secondaries and MongoLocalThreshold widens the latency window
+	// of member selection.
+	MongoReadPreference string
+	MongoReadTags       string
+	MongoHedgedReads    bool
+	MongoMaxStaleness   time.Duration
+	MongoLocalThreshold time.Duration
+
+	// ShutdownTimeout bounds graceful shutdown on SIGINT/SIGTERM: in-flight
+	// requests are drained, then background jobs stop and the database
+	// disconnects, each within this time.
+	ShutdownTimeout time.Duration
+
+	// Database timeouts per operation class (see handlers.DBOperation):
+	// DBReadTimeout bounds plain reads, DBWriteTimeout inserts, updates,
+	// and deletes, DBAggregateTimeout aggregation pipelines, and
+	// DBTransactionTimeout multi-document transactions. DBMaxTimeout caps
+	// every database context of a request, including those extended for
+	// calls to external services. Requests whose context carries a
+	// sooner deadline keep it.
+	DBReadTimeout        time.Duration
+	DBWriteTimeout       time.Duration
+	DBAggregateTimeout   time.Duration
+	DBTransactionTimeout time.Duration
+	DBMaxTimeout         time.Duration
+
+	// IDFormat selects how new primary keys are generated: "objectid"
+	// (default) or "uuidv7". Changing it on a populated database is not
+	// supported, because IDs from the URL are validated in the new format.
+	IDFormat string
+
+	// UseCommentCounter makes post listings read the denormalized
+	// comment_count field instead of counting comments per post.
+	UseCommentCounter bool
+	// CommentCountCacheTTL is how long counted comment totals are cached
+	// in-process when the denormalized counter is disabled (0 disables).
+	CommentCountCacheTTL time.Duration
+	// UseChangeStreams subscribes each instance to a MongoDB change stream
+	// so cached counts are invalidated by writes on other instances.
+	// Requires a replica set or sharded cluster.
+	UseChangeStreams bool
+
+	// Allowed comment content length in characters. A max of 0 means
+	// no upper limit.
+	CommentMinLength int
+	CommentMaxLength int
+	// CommentThreadDepth is how many levels of replies GET /api/posts/:id
+	// nests below a top-level comment; deeper replies are shown at the
+	// last level. 0 lists comments flat.
+	CommentThreadDepth int
+
+	// DuplicateScanInterval is how often the near-duplicate content scan
+	// runs in the background (0 disables the schedule).
+	DuplicateScanInterval time.Duration
+	// DuplicateThreshold is the minimum estimated similarity (0-1) for two
+	// posts to be flagged as near-duplicates.
+	DuplicateThreshold float64
+	// JobLockTTL is the lease duration that keeps scheduled jobs on a
+	// single instance; a crashed holder's lease is taken over after it.
+	JobLockTTL time.Duration
+
+	// CommentsAutoCloseDays locks a post's comments this many days after
+	// publication unless the post sets its own window; 0 never closes them.
+	CommentsAutoCloseDays int
+	// CommentCloseInterval is how often the comment closer job runs.
+	CommentCloseInterval time.Duration
+
+	// TrashRetention is how long deleted posts and comments stay
+	// restorable before the purge job removes them; 0 keeps them forever.
+	TrashRetention time.Duration
+	// TrashPurgeInterval is how often the trash purge job runs (0 disables
+	// the schedule).
+	TrashPurgeInterval time.Duration
+
+	// LinkCheckInterval is how often links in post content are checked
+	// for 404s and timeouts (0 disables the schedule).
+	LinkCheckInterval time.Duration
+
+	// AnalyticsFlushInterval is how often buffered post views are written
+	// to the analytics rollup; 0 disables view analytics.
+	AnalyticsFlushInterval time.Duration
+
+	// CSPReportSampleRate is the fraction (0-1) of received CSP violation
+	// reports that are stored; stored reports are weighted so aggregated
+	// counts still estimate the real totals.
+	CSPReportSampleRate float64
+
+	// TokenSecret is the HMAC key for signed tokens such as preview links.
+	// When empty a random key is generated at startup.
+	TokenSecret string
+	// PreviewTokenTTL is how long a shareable preview link stays valid.
+	PreviewTokenTTL time.Duration
+	// PostAccessTokenTTL is how long the access token issued for a
+	// passphrase-protected post stays valid.
+	PostAccessTokenTTL time.Duration
+
+	// JWTSecret is the HMAC key for the JWTs issued at login. When empty a
+	// random key is generated at startup, so sessions end on restart.
+	JWTSecret string
+	// JWTTTL is how long a login token stays valid.
+	JWTTTL time.Duration
+	// AdminUsers lists the usernames allowed on the admin endpoints (see
+	// middleware.RequireAdmin). Empty leaves them closed to everyone.
+	AdminUsers []string
+
+	// Optional OpenAI-compatible content assistant. Disabled when
+	// AssistantAPIKey is empty.
+	AssistantAPIURL string // Base URL of the chat completions API
+	AssistantAPIKey string // API key for the provider
+	AssistantModel  string // Model name to request
+
+	// Optional ActivityPub federation. Disabled when FederationBaseURL is
+	// empty; otherwise it is the public URL the API is reachable at, which
+	// becomes part of the actor's identity and must not change.
+	FederationBaseURL  string // Public URL of the API, e.g. "https://blog.example.com"
+	FederationUsername string // Actor username, the user part of "@user@host"
+	FederationName     string // Actor display name
+
+	// PublicPostURL is the public link to a post, used in chat integration
+	// messages and search engine pings; "{id}" is replaced by the post ID.
+	// Empty sends messages without links and disables pings.
+	PublicPostURL string
+	// TelegramBotToken and TelegramChatID send the same events to one
+	// Telegram chat through a bot. Disabled unless both are set.
+	TelegramBotToken string
+	TelegramChatID   string
+
+	// Optional IndexNow pings announcing new and edited posts to search
+	// engines. Disabled when IndexNowKey or PublicPostURL is empty.
+	IndexNowEndpoint    string // IndexNow API URL
+	IndexNowKey         string // Key also served as "<key>.txt" on the site
+	IndexNowKeyLocation string // URL of the key file when not at the site root
+
+	// CardAuthor is the byline drawn on post social cards, defaulting to
+	// FederationName since the blog has a single author.
+	CardAuthor string
+
+	// Generated robots.txt and humans.txt; both can be replaced through the
+	// admin site-files endpoints.
+	RobotsDisallow []string // Path prefixes crawlers are asked to skip
+	SitemapURL     string   // Sitemap announced in robots.txt, omitted when empty
+	HumansTeam     []string // humans.txt team entries, e.g. "Jane Doe <jane@example.com>"
+
+	// Plugins lists the registered plugins to enable, in hook order.
+	Plugins []string
+
+	// RequestLogSampling lists "prefix=rate" rules thinning the request
+	// log of high-traffic paths, e.g. "/healthz=0,/api/posts=0.1". Server
+	// errors are always logged; paths without a rule log every request.
+	RequestLogSampling []string
+
+	// TrustedProxies lists CIDRs or IPs of reverse proxies whose
+	// X-Forwarded-For and X-Real-IP headers are honored. Empty trusts none.
+	TrustedProxies []string
+
+	// Per-IP rate limits: requests allowed per RateLimitWindow to create
+	// posts, create comments, and read (GET requests under /api). A limit
+	// of 0 disables it. Counts are kept in memory unless RateLimitRedisURL
+	// is set, e.g. "redis://:password@localhost:6379/0", to share them
+	// between instances.
+	RateLimitWindow   time.Duration
+	RateLimitPosts    int
+	RateLimitComments int
+	RateLimitReads    int
+	RateLimitRedisURL string
+
+	// SanitizeMode selects what happens to markup in posts and comments
+	// that could run script (see sanitize.Policy): "strip" (default)
+	// removes it, "escape" keeps it visible as text.
+	SanitizeMode string
+
+	// AutosaveInterval throttles draft autosaves: a save within this long
+	// of the creation of a post's newest snapshot updates that snapshot
+	// instead of adding one (0 keeps every save). AutosaveKeep is how many
+	// snapshots are kept per post; older ones are dropped.
+	AutosaveInterval time.Duration
+	AutosaveKeep     int
+
+	// SwaggerUIURL is the base URL the API explorer at /api/docs loads the
+	// swagger-ui-dist scripts and styles from; point it at a self-hosted
+	// copy where the CDN is unreachable.
+	SwaggerUIURL string
+
+	// Tracing (see lib/tracing): spans are exported to the OTLP/HTTP
+	// receiver at OTLPEndpoint, e.g. "http://jaeger:4318" (empty disables
+	// export), sending the "name=value" OTLPHeaders with each batch.
+	// TraceServiceName identifies this service in the backend and
+	// TraceSampleRatio is the fraction (0-1) of new traces recorded;
+	// requests carrying a traceparent header follow the caller's decision.
+	OTLPEndpoint     string
+	OTLPHeaders      []string
+	TraceServiceName string
+	TraceSampleRatio float64
+
+	// CORS of the /api endpoints, for browser frontends served from other
+	// origins. AllowedOrigins lists the origins allowed, e.g.
+	// "https://blog.example.com" or "https://*.example.com", or "*" for
+	// any; none (default) keeps the API same-origin. CORSAllowedMethods
+	// and CORSAllowedHeaders are what preflights allow, and CORSMaxAge is
+	// how long browsers may cache a preflight answer.
+	AllowedOrigins     []string
+	CORSAllowedMethods []string
+	CORSAllowedHeaders []string
+	CORSMaxAge         time.Duration
 }
 
 // Load reads configuration from environment variables and .env file.
@@ -36,9 +252,249 @@ func Load() *Config {
 		MongoURI: getEnv("MONGODB_URI", "mongodb://127.0.0.1:27017"), // Default to Docker MongoDB service
 		DBName:   getEnv("MONGODB_NAME", "blog"),
 		ENV:      getEnv("ENV", "PROD"), // Default database name
+
+		MongoReadPreference: getEnv("MONGODB_READ_PREFERENCE", ""),
+		MongoReadTags:       getEnv("MONGODB_READ_TAGS", ""),
+		MongoHedgedReads:    getEnvBool("MONGODB_HEDGED_READS", false),
+		MongoMaxStaleness:   getEnvDuration("MONGODB_MAX_STALENESS", 0),
+		MongoLocalThreshold: getEnvDuration("MONGODB_LOCAL_THRESHOLD", 0),
+
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+		IDFormat:        getEnv("ID_FORMAT", "objectid"),
+
+		DBReadTimeout:        getEnvDuration("DB_READ_TIMEOUT", 10*time.Second),
+		DBWriteTimeout:       getEnvDuration("DB_WRITE_TIMEOUT", 10*time.Second),
+		DBAggregateTimeout:   getEnvDuration("DB_AGGREGATE_TIMEOUT", 30*time.Second),
+		DBTransactionTimeout: getEnvDuration("DB_TRANSACTION_TIMEOUT", 30*time.Second),
+		DBMaxTimeout:         getEnvDuration("DB_MAX_TIMEOUT", time.Minute),
+
+		UseCommentCounter:    getEnvBool("USE_COMMENT_COUNTER", false),
+		CommentCountCacheTTL: getEnvDuration("COMMENT_COUNT_CACHE_TTL", time.Minute),
+		UseChangeStreams:     getEnvBool("USE_CHANGE_STREAMS", false),
+		CommentMinLength:     getEnvInt("COMMENT_MIN_LENGTH", 1),
+		CommentMaxLength:     getEnvInt("COMMENT_MAX_LENGTH", 5000),
+		CommentThreadDepth:   getEnvInt("COMMENT_THREAD_DEPTH", 3),
+
+		DuplicateScanInterval: getEnvDuration("DUPLICATE_SCAN_INTERVAL", time.Hour),
+		DuplicateThreshold:    getEnvFloat("DUPLICATE_THRESHOLD", 0.8),
+		JobLockTTL:            getEnvDuration("JOB_LOCK_TTL", time.Minute),
+
+		CommentsAutoCloseDays: getEnvInt("COMMENTS_AUTO_CLOSE_DAYS", 0),
+		CommentCloseInterval:  getEnvDuration("COMMENT_CLOSE_INTERVAL", time.Hour),
+
+		TrashRetention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+
+		LinkCheckInterval: getEnvDuration("LINK_CHECK_INTERVAL", 24*time.Hour),
+
+		AnalyticsFlushInterval: getEnvDuration("ANALYTICS_FLUSH_INTERVAL", 10*time.Second),
+
+		CSPReportSampleRate: getEnvFloat("CSP_REPORT_SAMPLE_RATE", 1),
+
+		TokenSecret:        getEnv("TOKEN_SECRET", ""),
+		PreviewTokenTTL:    getEnvDuration("PREVIEW_TOKEN_TTL", 24*time.Hour),
+		PostAccessTokenTTL: getEnvDuration("POST_ACCESS_TOKEN_TTL", time.Hour),
+
+		JWTSecret: getEnv("JWT_SECRET", ""),
+		JWTTTL:    getEnvDuration("JWT_TTL", 24*time.Hour),
+
+		AdminUsers: getEnvList("ADMIN_USERS", nil),
+
+		AssistantAPIURL: getEnv("ASSISTANT_API_URL", "https://api.openai.com/v1"),
+		AssistantAPIKey: getEnv("ASSISTANT_API_KEY", ""),
+		AssistantModel:  getEnv("ASSISTANT_MODEL", "gpt-4o-mini"),
+
+		FederationBaseURL:  getEnv("FEDERATION_BASE_URL", ""),
+		FederationUsername: getEnv("FEDERATION_USERNAME", "blog"),
+		FederationName:     getEnv("FEDERATION_NAME", "Blog"),
+
+		PublicPostURL:    getEnv("PUBLIC_POST_URL", ""),
+		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatID:   getEnv("TELEGRAM_CHAT_ID", ""),
+
+		IndexNowEndpoint:    getEnv("INDEXNOW_ENDPOINT", "https://api.indexnow.org/indexnow"),
+		IndexNowKey:         getEnv("INDEXNOW_KEY", ""),
+		IndexNowKeyLocation: getEnv("INDEXNOW_KEY_LOCATION", ""),
+
+		CardAuthor: getEnv("CARD_AUTHOR", getEnv("FEDERATION_NAME", "Blog")),
+
+		RobotsDisallow: getEnvList("ROBOTS_DISALLOW", []string{"/api/", "/ap/"}),
+		SitemapURL:     getEnv("SITEMAP_URL", ""),
+		HumansTeam:     getEnvList("HUMANS_TEAM", nil),
+
+		Plugins: getEnvList("PLUGINS", nil),
+
+		RequestLogSampling: getEnvList("REQUEST_LOG_SAMPLING", nil),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES", nil),
+
+		RateLimitWindow:   getEnvDuration("RATE_LIMIT_WINDOW", time.Minute),
+		RateLimitPosts:    getEnvInt("RATE_LIMIT_POSTS", 5),
+		RateLimitComments: getEnvInt("RATE_LIMIT_COMMENTS", 20),
+		RateLimitReads:    getEnvInt("RATE_LIMIT_READS", 100),
+		RateLimitRedisURL: getEnv("RATE_LIMIT_REDIS_URL", ""),
+
+		SanitizeMode: getEnv("SANITIZE_MODE", "strip"),
+
+		AutosaveInterval: getEnvDuration("AUTOSAVE_INTERVAL", 30*time.Second),
+		AutosaveKeep:     getEnvInt("AUTOSAVE_KEEP", 20),
+
+		SwaggerUIURL: getEnv("SWAGGER_UI_URL", "https://unpkg.com/swagger-ui-dist@5"),
+
+		OTLPEndpoint:     getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		OTLPHeaders:      getEnvList("OTEL_EXPORTER_OTLP_HEADERS", nil),
+		TraceServiceName: getEnv("OTEL_SERVICE_NAME", "blog-api"),
+		TraceSampleRatio: getEnvFloat("OTEL_TRACES_SAMPLER_ARG", 1),
+
+		AllowedOrigins:     getEnvList("ALLOWED_ORIGINS", nil),
+		CORSAllowedMethods: getEnvList("CORS_ALLOWED_METHODS", []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}),
+		CORSAllowedHeaders: getEnvList("CORS_ALLOWED_HEADERS", []string{
+			"Authorization", "Content-Type", "X-API-Key", "X-Request-ID", "If-Modified-Since", "If-None-Match", "traceparent",
+		}),
+		CORSMaxAge: getEnvDuration("CORS_MAX_AGE", 10*time.Minute),
 	}
 }
 
+// MIN_SECRET_BYTES is the shortest TOKEN_SECRET or JWT_SECRET accepted in
+// production.
+const MIN_SECRET_BYTES = 32
+
+// placeholderSecrets are sample secret values, such as the one .env.example
+// shipped, that must never sign tokens in production.
+var placeholderSecrets = []string{"change-me", "changeme", "secret"}
+
+// Production reports whether ENV names a production environment ("prod"
+// or "production", the default).
+func (c *Config) Production() bool {
+	return strings.EqualFold(c.ENV, "prod") || strings.EqualFold(c.ENV, "production")
+}
+
+// CheckSecrets reports TOKEN_SECRET and JWT_SECRET values a production
+// server must not sign with: sample values, values shorter than
+// MIN_SECRET_BYTES, and one value shared by both, which would let a token
+// from one signer be crafted from the key of the other. Empty secrets are
+// accepted, since random keys are then generated at startup. Outside
+// production it always returns nil.
+func (c *Config) CheckSecrets() error {
+	if !c.Production() {
+		return nil
+	}
+	var problems []error
+	for _, s := range []struct{ name, value string }{
+		{"TOKEN_SECRET", c.TokenSecret},
+		{"JWT_SECRET", c.JWTSecret},
+	} {
+		if s.value == "" {
+			continue
+		}
+		for _, placeholder := range placeholderSecrets {
+			if strings.EqualFold(s.value, placeholder) {
+				problems = append(problems, fmt.Errorf("%s: %q is a sample value", s.name, s.value))
+			}
+		}
+		if len(s.value) < MIN_SECRET_BYTES {
+			problems = append(problems, fmt.Errorf("%s: must be at least %d bytes", s.name, MIN_SECRET_BYTES))
+		}
+	}
+	if c.TokenSecret != "" && c.TokenSecret == c.JWTSecret {
+		problems = append(problems, errors.New("TOKEN_SECRET: must differ from JWT_SECRET"))
+	}
+	return errors.Join(problems...)
+}
+
+// invalidValues collects the environment variables Load could not parse and
+// replaced by their defaults, so Validate reports them.
+var invalidValues []error
+
+// Validate reports the configuration values the application cannot run
+// with as intended: unparsable environment variables (Load falls back to
+// their defaults), out-of-range numbers and durations, and malformed URLs.
+// In production, the secrets are checked too (see CheckSecrets).
+// Values checked by the packages consuming them, such as IDFormat,
+// TrustedProxies, or SanitizeMode, are left to those packages.
+//
+// Returns nil when the configuration is valid, otherwise every problem
+// joined into one error.
+func (c *Config) Validate() error {
+	problems := append([]error(nil), invalidValues...)
+	problems = append(problems, c.CheckSecrets())
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Port)
+	check(err == nil && port > 0 && port < 65536, "PORT: %q is not a TCP port", c.Port)
+	check(strings.HasPrefix(c.MongoURI, "mongodb://") || strings.HasPrefix(c.MongoURI, "mongodb+srv://"),
+		"MONGODB_URI: expected a mongodb:// or mongodb+srv:// URI")
+	check(c.DBName != "", "MONGODB_NAME: must not be empty")
+
+	check(c.CommentMinLength >= 0, "COMMENT_MIN_LENGTH: must not be negative")
+	check(c.CommentMaxLength == 0 || c.CommentMaxLength >= c.CommentMinLength,
+		"COMMENT_MAX_LENGTH: %d is below COMMENT_MIN_LENGTH %d", c.CommentMaxLength, c.CommentMinLength)
+	check(c.CommentThreadDepth >= 0, "COMMENT_THREAD_DEPTH: must not be negative")
+	check(c.CommentsAutoCloseDays >= 0, "COMMENTS_AUTO_CLOSE_DAYS: must not be negative")
+	check(c.DuplicateThreshold >= 0 && c.DuplicateThreshold <= 1, "DUPLICATE_THRESHOLD: must be between 0 and 1")
+	check(c.CSPReportSampleRate >= 0 && c.CSPReportSampleRate <= 1, "CSP_REPORT_SAMPLE_RATE: must be between 0 and 1")
+	check(c.TraceSampleRatio >= 0 && c.TraceSampleRatio <= 1, "OTEL_TRACES_SAMPLER_ARG: must be between 0 and 1")
+	check(c.AutosaveKeep > 0, "AUTOSAVE_KEEP: must be positive")
+	check(c.CORSMaxAge >= 0, "CORS_MAX_AGE: must not be negative")
+	for _, n := range []struct {
+		name  string
+		value int
+	}{
+		{"RATE_LIMIT_POSTS", c.RateLimitPosts},
+		{"RATE_LIMIT_COMMENTS", c.RateLimitComments},
+		{"RATE_LIMIT_READS", c.RateLimitReads},
+	} {
+		check(n.value >= 0, "%s: must not be negative", n.name)
+	}
+
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+		{"JOB_LOCK_TTL", c.JobLockTTL},
+		{"PREVIEW_TOKEN_TTL", c.PreviewTokenTTL},
+		{"POST_ACCESS_TOKEN_TTL", c.PostAccessTokenTTL},
+		{"JWT_TTL", c.JWTTTL},
+		{"RATE_LIMIT_WINDOW", c.RateLimitWindow},
+		{"DB_MAX_TIMEOUT", c.DBMaxTimeout},
+	} {
+		check(d.value > 0, "%s: must be positive", d.name)
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"DB_READ_TIMEOUT", c.DBReadTimeout},
+		{"DB_WRITE_TIMEOUT", c.DBWriteTimeout},
+		{"DB_AGGREGATE_TIMEOUT", c.DBAggregateTimeout},
+		{"DB_TRANSACTION_TIMEOUT", c.DBTransactionTimeout},
+	} {
+		check(d.value > 0 && d.value <= c.DBMaxTimeout, "%s: must be positive and at most DB_MAX_TIMEOUT", d.name)
+	}
+
+	for _, u := range []struct{ name, value string }{
+		{"ASSISTANT_API_URL", c.AssistantAPIURL},
+		{"FEDERATION_BASE_URL", c.FederationBaseURL},
+		{"INDEXNOW_ENDPOINT", c.IndexNowEndpoint},
+		{"OTEL_EXPORTER_OTLP_ENDPOINT", c.OTLPEndpoint},
+		{"SITEMAP_URL", c.SitemapURL},
+	} {
+		if u.value == "" {
+			continue
+		}
+		parsed, err := url.Parse(u.value)
+		check(err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "",
+			"%s: %q is not an absolute http(s) URL", u.name, u.value)
+	}
+
+	return errors.Join(problems...)
+}
+
 // getEnv retrieves an environment variable value with a fallback default.
 // If the environment variable exists and is not empty, it returns that value.
 // Otherwise, it returns the provided default value.
@@ -54,3 +510,105 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool retrieves a boolean environment variable with a fallback default.
+// Accepts the values understood by strconv.ParseBool ("true", "1", "false", ...).
+// Unparseable values are logged and the default is used.
+//
+// Parameters:
+//   - key: the environment variable name to look up
+//   - defaultValue: the value to return if the variable is unset or invalid
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean for %s: %q, using default", key, value)
+		invalidValues = append(invalidValues, fmt.Errorf("%s: invalid boolean %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvDuration retrieves a duration environment variable with a fallback default.
+// Values use time.ParseDuration syntax (e.g., "30s", "5m").
+// Unparseable values are logged and the default is used.
+//
+// Parameters:
+//   - key: the environment variable name to look up
+//   - defaultValue: the value to return if the variable is unset or invalid
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s: %q, using default", key, value)
+		invalidValues = append(invalidValues, fmt.Errorf("%s: invalid duration %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvInt retrieves an integer environment variable with a fallback default.
+// Unparseable values are logged and the default is used.
+//
+// Parameters:
+//   - key: the environment variable name to look up
+//   - defaultValue: the value to return if the variable is unset or invalid
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer for %s: %q, using default", key, value)
+		invalidValues = append(invalidValues, fmt.Errorf("%s: invalid integer %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvFloat retrieves a floating-point environment variable with a fallback default.
+// Unparseable values are logged and the default is used.
+//
+// Parameters:
+//   - key: the environment variable name to look up
+//   - defaultValue: the value to return if the variable is unset or invalid
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid number for %s: %q, using default", key, value)
+		invalidValues = append(invalidValues, fmt.Errorf("%s: invalid number %q", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvList retrieves a comma-separated environment variable as a slice.
+// Whitespace around items is trimmed and empty items are dropped.
+//
+// Parameters:
+//   - key: the environment variable name to look up
+//   - defaultValue: the value to return if the variable is unset or empty
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,135 @@
+package content
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Targets of Markdown links and images, "[text](url)", and autolinks, "<url>"
+	markdownTarget   = regexp.MustCompile(`\]\(\s*<?(https?://[^\s)>]+)`)
+	markdownAutolink = regexp.MustCompile(`<(https?://[^\s>]+)>`)
+
+	// Targets of inline HTML links, images, and embeds
+	htmlTarget = regexp.MustCompile(`(?i)\b(?:href|src)\s*=\s*["']?(https?://[^\s"'>]+)`)
+)
+
+// Links returns the absolute http(s) URLs a post body links to or embeds,
+// each once, in order of first appearance. Relative links and URLs inside
+// fenced code blocks are skipped; bare URLs in text are not links.
+//
+// Parameters:
+//   - text: post content in Markdown and/or HTML
+//
+// Returns the unique URLs, empty when the body has none.
+func Links(text string) []string {
+	var links []string
+	seen := make(map[string]bool)
+
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, pattern := range []*regexp.Regexp{markdownTarget, markdownAutolink, htmlTarget} {
+			for _, match := range pattern.FindAllStringSubmatch(line, -1) {
+				link := match[1]
+				if !seen[link] {
+					seen[link] = true
+					links = append(links, link)
+				}
+			}
+		}
+	}
+	return links
+}
+
+var (
+	// Targets of Markdown and inline HTML links, absolute or relative
+	markdownAnyTarget = regexp.MustCompile(`\]\(\s*<?([^\s)>]+)`)
+	htmlAnyTarget     = regexp.MustCompile(`(?i)\bhref\s*=\s*["']?([^\s"'>]+)`)
+
+	// API path of a post, e.g. "/api/posts/<id>"
+	apiPostPath = regexp.MustCompile(`^/api/posts/([^/?#]+)/?$`)
+)
+
+// LinkedPosts returns the IDs of the posts a body links to, each once, in
+// order of first appearance. A link points to a post when it is the post's
+// public link, postURL with "{id}" replaced by the ID, or that link's path
+// alone, or the post's API path "/api/posts/<id>" on any host. Query
+// strings and fragments are ignored. IDs are not validated.
+//
+// Parameters:
+//   - text: post content in Markdown and/or HTML
+//   - postURL: public link template of posts, e.g. "https://blog.example.com/posts/{id}";
+//     empty to only match API paths
+//
+// Returns the unique IDs, empty when the body links to no post.
+func LinkedPosts(text, postURL string) []string {
+	var public, publicPath [2]string
+	if before, after, ok := strings.Cut(postURL, "{id}"); ok {
+		public = [2]string{before, after}
+		if parsed, err := url.Parse(before); err == nil && parsed.Host != "" {
+			publicPath = [2]string{parsed.Path, after}
+		}
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if id != "" && !strings.Contains(id, "/") && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, pattern := range []*regexp.Regexp{markdownAnyTarget, markdownAutolink, htmlAnyTarget} {
+			for _, match := range pattern.FindAllStringSubmatch(line, -1) {
+				link, _, _ := strings.Cut(match[1], "#")
+				link, _, _ = strings.Cut(link, "?")
+				if id, ok := cutAround(link, public); ok {
+					add(id)
+				} else if id, ok := cutAround(link, publicPath); ok && strings.HasPrefix(link, "/") {
+					add(id)
+				} else if parsed, err := url.Parse(link); err == nil {
+					if path := apiPostPath.FindStringSubmatch(parsed.Path); path != nil {
+						add(path[1])
+					}
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// cutAround returns what link holds between affixes[0] and affixes[1], or
+// false if link does not start and end with them. An empty prefix never
+// matches.
+func cutAround(link string, affixes [2]string) (string, bool) {
+	if affixes[0] == "" {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(link, affixes[0])
+	if !ok {
+		return "", false
+	}
+	return strings.CutSuffix(rest, affixes[1])
+}
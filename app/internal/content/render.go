@@ -0,0 +1,780 @@
+package content
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Block syntax, matched on lines whose leading tabs are expanded
+	atxHeading      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	thematicBreak   = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceOpen       = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+	blockquoteLine  = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	tableDelimiter  = regexp.MustCompile(`^ {0,3}\|? *:?-+:? *(?:\| *:?-+:? *)*\|? *$`)
+	linkDefinition  = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?(?:[ \t]+(?:"([^"]*)"|'([^']*)'|\(([^)]*)\)))?[ \t]*$`)
+
+	// HTML blocks: lines starting with a block-level tag or a comment,
+	// passed through up to the next blank line
+	htmlBlockStart = regexp.MustCompile(`(?i)^ {0,3}(?:<!--|</?(?:address|article|aside|blockquote|details|dialog|div|dl|dt|dd|fieldset|figcaption|figure|footer|form|h[1-6]|header|hr|iframe|li|main|nav|ol|p|pre|script|section|style|summary|table|tbody|td|textarea|tfoot|th|thead|tr|ul)(?:[\s/>]|$))`)
+
+	// Inline syntax
+	inlineHTML    = regexp.MustCompile("^(?:<!--[\\s\\S]*?-->|</?[A-Za-z][A-Za-z0-9-]*(?:\\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\\s*=\\s*(?:[^\\s\"'=<>`]+|'[^']*'|\"[^\"]*\"))?)*\\s*/?>)")
+	urlAutolink   = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+	emailAutolink = regexp.MustCompile(`^<([^\s<>@]+@[^\s<>@]+\.[^\s<>@]+)>`)
+	entity        = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	hardBreak     = regexp.MustCompile(`^ {2,}\n`)
+)
+
+// linkReference is the target of a "[id]: url" definition.
+type linkReference struct {
+	url   string
+	title string
+}
+
+// renderer accumulates the HTML of one post body.
+type renderer struct {
+	b    strings.Builder
+	refs map[string]linkReference
+}
+
+// Render converts a post body from Markdown to HTML, so clients can show
+// posts without a Markdown renderer of their own. It covers the CommonMark
+// syntax posts use: headings, paragraphs, block quotes, nested lists,
+// fenced and indented code, thematic breaks, emphasis, code spans, inline
+// and reference links, images, autolinks, and hard line breaks, plus GFM
+// tables and strikethrough. Fenced code keeps its language as a
+// language-* class for syntax highlighting.
+//
+// Inline HTML is passed through as written, so the result must be
+// sanitized before it is served (see sanitize.Policy.SanitizeHTML).
+//
+// Parameters:
+//   - text: post content in Markdown and/or HTML
+//
+// Returns the HTML fragment, one line per block.
+func Render(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	r := &renderer{refs: make(map[string]linkReference)}
+	r.blocks(r.definitions(lines), false)
+	return r.b.String()
+}
+
+// definitions collects the link reference definitions outside code fences
+// and returns the lines without them.
+func (r *renderer) definitions(lines []string) []string {
+	kept := lines[:0:0]
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if m := linkDefinition.FindStringSubmatch(line); m != nil && !inFence {
+			id := referenceID(m[1])
+			if _, ok := r.refs[id]; !ok {
+				r.refs[id] = linkReference{url: m[2], title: m[3] + m[4] + m[5]}
+			}
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept
+}
+
+// blocks renders lines as a sequence of blocks. Tight blocks, the items of
+// lists without blank lines, render paragraphs without <p>.
+func (r *renderer) blocks(lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case isBlank(line):
+			i++
+		case isFence(line):
+			i = r.fence(lines, i)
+		case atxHeading.MatchString(line):
+			m := atxHeading.FindStringSubmatch(line)
+			r.heading(len(m[1]), m[2])
+			i++
+		case thematicBreak.MatchString(line):
+			r.b.WriteString("<hr>\n")
+			i++
+		case blockquoteLine.MatchString(line):
+			i = r.blockquote(lines, i)
+		case isListItem(line):
+			i = r.list(lines, i)
+		case indentation(line) >= 4:
+			i = r.indentedCode(lines, i)
+		case htmlBlockStart.MatchString(line):
+			i = r.htmlBlock(lines, i)
+		case isTable(lines, i):
+			i = r.table(lines, i)
+		default:
+			i = r.paragraph(lines, i, tight)
+		}
+	}
+}
+
+// startsBlock reports whether line starts a block that ends a paragraph.
+func startsBlock(line string) bool {
+	return isFence(line) || atxHeading.MatchString(line) || thematicBreak.MatchString(line) ||
+		blockquoteLine.MatchString(line) || htmlBlockStart.MatchString(line) || isListItem(line)
+}
+
+// heading renders a heading of level 1 to 6.
+func (r *renderer) heading(level int, text string) {
+	tag := "h" + strconv.Itoa(level)
+	r.b.WriteString("<" + tag + ">" + r.inline(strings.TrimSpace(text)) + "</" + tag + ">\n")
+}
+
+// paragraph renders the paragraph starting at lines[i], or the setext
+// heading it turns out to be, and returns the index of the next line.
+func (r *renderer) paragraph(lines []string, i int, tight bool) int {
+	var text []string
+	for ; i < len(lines) && !isBlank(lines[i]); i++ {
+		if len(text) > 0 {
+			if m := setextUnderline.FindStringSubmatch(lines[i]); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				r.heading(level, strings.Join(text, "\n"))
+				return i + 1
+			}
+			if startsBlock(lines[i]) {
+				break
+			}
+		}
+		text = append(text, strings.TrimLeft(lines[i], " "))
+	}
+
+	body := r.inline(strings.TrimRight(strings.Join(text, "\n"), " "))
+	if tight {
+		r.b.WriteString(body)
+	} else {
+		r.b.WriteString("<p>" + body + "</p>\n")
+	}
+	return i
+}
+
+// isFence reports whether line opens a fenced code block.
+func isFence(line string) bool {
+	m := fenceOpen.FindStringSubmatch(line)
+	return m != nil && (m[2][0] != '`' || !strings.Contains(m[3], "`"))
+}
+
+// fence renders the fenced code block starting at lines[i], which runs to
+// a closing fence at least as long as the opening one or to the end.
+func (r *renderer) fence(lines []string, i int) int {
+	m := fenceOpen.FindStringSubmatch(lines[i])
+	indent, marker := len(m[1]), m[2]
+	class := ""
+	if info := strings.Fields(m[3]); len(info) > 0 {
+		class = ` class="language-` + html.EscapeString(info[0]) + `"`
+	}
+
+	r.b.WriteString("<pre><code" + class + ">")
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if trimmed := strings.TrimSpace(line); indentation(line) < 4 && len(trimmed) >= len(marker) &&
+			strings.Trim(trimmed, marker[:1]) == "" {
+			i++
+			break
+		}
+		line = line[min(indent, indentation(line)):]
+		r.b.WriteString(html.EscapeString(line) + "\n")
+	}
+	r.b.WriteString("</code></pre>\n")
+	return i
+}
+
+// indentedCode renders the code block of lines indented by four spaces
+// starting at lines[i].
+func (r *renderer) indentedCode(lines []string, i int) int {
+	var code []string
+	end := i
+	for ; i < len(lines) && (isBlank(lines[i]) || indentation(lines[i]) >= 4); i++ {
+		if isBlank(lines[i]) {
+			code = append(code, "")
+			continue
+		}
+		code = append(code, lines[i][4:])
+		end = i + 1
+	}
+	code = code[:len(code)-(i-end)]
+
+	r.b.WriteString("<pre><code>")
+	for _, line := range code {
+		r.b.WriteString(html.EscapeString(line) + "\n")
+	}
+	r.b.WriteString("</code></pre>\n")
+	return end
+}
+
+// blockquote renders the block quote starting at lines[i]. Lines without
+// ">" continue a quoted paragraph.
+func (r *renderer) blockquote(lines []string, i int) int {
+	var quoted []string
+	for ; i < len(lines); i++ {
+		if m := blockquoteLine.FindStringSubmatch(lines[i]); m != nil {
+			quoted = append(quoted, m[1])
+			continue
+		}
+		if isBlank(lines[i]) || startsBlock(lines[i]) || isBlank(quoted[len(quoted)-1]) {
+			break
+		}
+		quoted = append(quoted, lines[i])
+	}
+
+	r.b.WriteString("<blockquote>\n")
+	r.blocks(quoted, false)
+	r.b.WriteString("</blockquote>\n")
+	return i
+}
+
+// listMarker is the parsed start of a list item.
+type listMarker struct {
+	ordered bool
+	delim   byte   // Bullet character, or "." or ")" after an ordered number
+	start   int    // Number of an ordered item
+	width   int    // Indentation of the item's content
+	text    string // Content on the marker line
+}
+
+// parseListItem reads the list item marker starting line, if it has one.
+func parseListItem(line string) (listMarker, bool) {
+	var m listMarker
+	indent := indentation(line)
+	if indent > 3 {
+		return m, false
+	}
+	rest := line[indent:]
+
+	n := 0
+	if rest != "" && strings.IndexByte("-*+", rest[0]) >= 0 {
+		m.delim, n = rest[0], 1
+	} else {
+		for n < len(rest) && n < 9 && rest[n] >= '0' && rest[n] <= '9' {
+			n++
+		}
+		if n == 0 || n >= len(rest) || (rest[n] != '.' && rest[n] != ')') {
+			return m, false
+		}
+		m.ordered, m.delim = true, rest[n]
+		m.start, _ = strconv.Atoi(rest[:n])
+		n++
+	}
+
+	after := rest[n:]
+	if after != "" && after[0] != ' ' {
+		return m, false
+	}
+	spaces := len(after) - len(strings.TrimLeft(after, " "))
+	m.text = after[spaces:]
+	if m.text == "" || spaces > 4 {
+		// Content indented further is indented code within the item
+		spaces = min(spaces, 1)
+		m.text = strings.TrimPrefix(after, " ")
+	}
+	m.width = indent + n + max(spaces, 1)
+	return m, true
+}
+
+// isListItem reports whether line starts a list item.
+func isListItem(line string) bool {
+	_, ok := parseListItem(line)
+	return ok
+}
+
+// list renders the list starting at lines[i]. It ends at the first line
+// that neither continues an item nor starts an item of the same type.
+// Items separated by blank lines, or holding blocks separated by blank
+// lines, make the list loose: their paragraphs keep <p>.
+func (r *renderer) list(lines []string, i int) int {
+	first, _ := parseListItem(lines[i])
+	var items [][]string
+	loose := false
+	for i < len(lines) {
+		item, ok := parseListItem(lines[i])
+		if !ok || item.ordered != first.ordered || item.delim != first.delim {
+			break
+		}
+		body := []string{item.text}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				body = append(body, "")
+				continue
+			case indentation(line) >= item.width:
+				body = append(body, line[item.width:])
+				continue
+			case isBlank(body[len(body)-1]) || startsBlock(line):
+			default:
+				// A lazy continuation of the item's paragraph
+				body = append(body, line)
+				continue
+			}
+			break
+		}
+
+		trailing := 0
+		for trailing < len(body)-1 && isBlank(body[len(body)-1-trailing]) {
+			trailing++
+		}
+		body = body[:len(body)-trailing]
+		for _, line := range body {
+			loose = loose || isBlank(line)
+		}
+		if trailing > 0 && i < len(lines) {
+			if next, ok := parseListItem(lines[i]); ok && next.ordered == first.ordered && next.delim == first.delim {
+				loose = true
+			}
+		}
+		items = append(items, body)
+	}
+
+	tag := "ul"
+	open := "<ul>"
+	if first.ordered {
+		tag, open = "ol", "<ol>"
+		if first.start != 1 {
+			open = `<ol start="` + strconv.Itoa(first.start) + `">`
+		}
+	}
+	r.b.WriteString(open + "\n")
+	for _, body := range items {
+		r.b.WriteString("<li>")
+		if !loose {
+			r.blocks(body, true)
+		} else {
+			r.b.WriteString("\n")
+			r.blocks(body, false)
+		}
+		r.b.WriteString("</li>\n")
+	}
+	r.b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// htmlBlock passes the HTML block starting at lines[i] through as written.
+func (r *renderer) htmlBlock(lines []string, i int) int {
+	for ; i < len(lines) && !isBlank(lines[i]); i++ {
+		r.b.WriteString(lines[i] + "\n")
+	}
+	return i
+}
+
+// isTable reports whether lines[i] is the header row of a GFM table: a row
+// with pipes followed by a delimiter row with as many cells.
+func isTable(lines []string, i int) bool {
+	return i+1 < len(lines) && strings.Contains(lines[i], "|") && tableDelimiter.MatchString(lines[i+1]) &&
+		len(tableCells(lines[i])) == len(tableCells(lines[i+1]))
+}
+
+// table renders the GFM table starting at lines[i]; its body runs to the
+// next blank line or block.
+func (r *renderer) table(lines []string, i int) int {
+	header := tableCells(lines[i])
+	r.b.WriteString("<table>\n<thead>\n")
+	r.tableRow("th", header, len(header))
+	r.b.WriteString("</thead>\n")
+
+	i += 2
+	if i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]) {
+		r.b.WriteString("<tbody>\n")
+		for ; i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]); i++ {
+			r.tableRow("td", tableCells(lines[i]), len(header))
+		}
+		r.b.WriteString("</tbody>\n")
+	}
+	r.b.WriteString("</table>\n")
+	return i
+}
+
+// tableRow renders a row of exactly columns cells, padding or dropping
+// cells to match the header.
+func (r *renderer) tableRow(tag string, cells []string, columns int) {
+	r.b.WriteString("<tr>\n")
+	for column := 0; column < columns; column++ {
+		cell := ""
+		if column < len(cells) {
+			cell = cells[column]
+		}
+		r.b.WriteString("<" + tag + ">" + r.inline(cell) + "</" + tag + ">\n")
+	}
+	r.b.WriteString("</tr>\n")
+}
+
+// tableCells splits a table row into its trimmed cells. Escaped pipes,
+// "\|", stay in the cell as "|".
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// inline renders the inline syntax of text, escaping characters that are
+// not markup.
+func (r *renderer) inline(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch c {
+		case '\\':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				b.WriteString("<br>\n")
+				i += 2
+				continue
+			}
+			if i+1 < len(text) && isPunct(text[i+1]) {
+				b.WriteString(html.EscapeString(text[i+1 : i+2]))
+				i += 2
+				continue
+			}
+		case '`':
+			n, code := codeSpan(text[i:])
+			if n == 0 {
+				n = runLength(text[i:], c)
+				b.WriteString(text[i : i+n])
+			} else {
+				b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			}
+			i += n
+			continue
+		case '!':
+			if n, link := r.link(text[i+1:], true); n > 0 {
+				b.WriteString(link)
+				i += 1 + n
+				continue
+			}
+		case '[':
+			if n, link := r.link(text[i:], false); n > 0 {
+				b.WriteString(link)
+				i += n
+				continue
+			}
+		case '<':
+			if m := urlAutolink.FindStringSubmatch(text[i:]); m != nil {
+				b.WriteString(`<a href="` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+			if m := emailAutolink.FindStringSubmatch(text[i:]); m != nil {
+				b.WriteString(`<a href="mailto:` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+			if tag := inlineHTML.FindString(text[i:]); tag != "" {
+				b.WriteString(tag)
+				i += len(tag)
+				continue
+			}
+			b.WriteString("&lt;")
+			i++
+			continue
+		case '&':
+			if ref := entity.FindString(text[i:]); ref != "" {
+				b.WriteString(ref)
+				i += len(ref)
+				continue
+			}
+			b.WriteString("&amp;")
+			i++
+			continue
+		case '*', '_', '~':
+			n, emphasized := r.emphasis(text, i)
+			if n == 0 {
+				n = runLength(text[i:], c)
+				b.WriteString(text[i : i+n])
+			} else {
+				b.WriteString(emphasized)
+			}
+			i += n
+			continue
+		case ' ':
+			if brk := hardBreak.FindString(text[i:]); brk != "" {
+				b.WriteString("<br>\n")
+				i += len(brk)
+				continue
+			}
+		case '>':
+			b.WriteString("&gt;")
+			i++
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
+
+// codeSpan reads the code span starting text, closed by a backtick run as
+// long as the opening one. Returns its length and content, or 0 when the
+// run is never closed.
+func codeSpan(text string) (int, string) {
+	run := runLength(text, '`')
+	for j := run; j < len(text); {
+		k := strings.IndexByte(text[j:], '`')
+		if k < 0 {
+			break
+		}
+		j += k
+		if n := runLength(text[j:], '`'); n != run {
+			j += n
+			continue
+		}
+		code := strings.ReplaceAll(text[run:j], "\n", " ")
+		if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+			code = code[1 : len(code)-1]
+		}
+		return j + run, code
+	}
+	return 0, ""
+}
+
+// emphasis renders the emphasis opened by the delimiter run at text[i]:
+// "**" or "__" for <strong>, "*" or "_" for <em>, "~~" for <del>. The
+// closer is the next run of the same character long enough to close it;
+// underscores do not open or close inside words. Returns the length
+// consumed and the HTML, or 0 when the run opens nothing.
+func (r *renderer) emphasis(text string, i int) (int, string) {
+	c := text[i]
+	run := runLength(text[i:], c)
+	if i+run >= len(text) || isSpace(text[i+run]) || (c == '_' && i > 0 && isAlnum(text[i-1])) {
+		return 0, ""
+	}
+
+	sizes := []int{1}
+	switch {
+	case c == '~':
+		if run != 2 {
+			return 0, ""
+		}
+		sizes = []int{2}
+	case run >= 2:
+		sizes = []int{2, 1}
+	}
+	for _, n := range sizes {
+		// Delimiters of the run past a strong opener are part of the
+		// content; those before an emphasis opener are text
+		opener := i
+		if n == 1 {
+			opener = i + run - 1
+		}
+		delim := strings.Repeat(text[i:i+1], n)
+		for j := i + run; j < len(text); {
+			k := strings.Index(text[j:], delim)
+			if k < 0 {
+				break
+			}
+			j += k
+			closer := runLength(text[j:], c)
+			end := j + closer
+			at := end - n
+			if (closer == n || closer >= 3) && at > i+run && !isSpace(text[at-1]) &&
+				(c != '_' || end == len(text) || !isAlnum(text[end])) {
+				tag := "em"
+				switch {
+				case c == '~':
+					tag = "del"
+				case n == 2:
+					tag = "strong"
+				}
+				inner := r.inline(text[opener+n : at])
+				return end - i, text[i:opener] + "<" + tag + ">" + inner + "</" + tag + ">"
+			}
+			j = end
+		}
+	}
+	return 0, ""
+}
+
+// link renders the link or image starting text with "[": an inline link,
+// "[text](url "title")", or a reference, "[text][id]", "[text][]", or
+// "[text]". Returns the length consumed and the HTML, or 0 when text
+// starts no link.
+func (r *renderer) link(text string, image bool) (int, string) {
+	if text == "" || text[0] != '[' {
+		return 0, ""
+	}
+	end := -1
+	depth := 0
+scan:
+	for k := 0; k < len(text); k++ {
+		switch text[k] {
+		case '\\':
+			k++
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				end = k
+				break scan
+			}
+		}
+	}
+	if end < 0 {
+		return 0, ""
+	}
+	label, rest := text[1:end], text[end+1:]
+
+	var target linkReference
+	n := end + 1
+	switch {
+	case strings.HasPrefix(rest, "("):
+		url, title, length, ok := parseDestination(rest[1:])
+		if !ok {
+			return 0, ""
+		}
+		target = linkReference{url: url, title: title}
+		n += 1 + length
+	default:
+		id := label
+		if strings.HasPrefix(rest, "[") {
+			if close := strings.IndexByte(rest, ']'); close > 0 {
+				if close > 1 {
+					id = rest[1:close]
+				}
+				n += close + 1
+			}
+		}
+		ref, ok := r.refs[referenceID(id)]
+		if !ok {
+			return 0, ""
+		}
+		target = ref
+	}
+
+	title := ""
+	if target.title != "" {
+		title = ` title="` + html.EscapeString(target.title) + `"`
+	}
+	if image {
+		return n, `<img src="` + html.EscapeString(target.url) + `" alt="` + html.EscapeString(stripMarkup(label)) + `"` + title + ">"
+	}
+	return n, `<a href="` + html.EscapeString(target.url) + `"` + title + ">" + r.inline(label) + "</a>"
+}
+
+// parseDestination reads the destination and optional title of an inline
+// link, from just after "(" up to and including ")". Destinations may be
+// wrapped in <> or contain balanced parentheses.
+func parseDestination(s string) (url, title string, n int, ok bool) {
+	i := skipSpace(s, 0)
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i+1:], ">\n")
+		if end < 0 || s[i+1+end] != '>' {
+			return "", "", 0, false
+		}
+		url = s[i+1 : i+1+end]
+		i += end + 2
+	} else {
+		start, depth := i, 0
+		for ; i < len(s) && !isSpace(s[i]); i++ {
+			if s[i] == '(' {
+				depth++
+			} else if s[i] == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		url = s[start:i]
+	}
+
+	j := skipSpace(s, i)
+	if j > i && j < len(s) && strings.IndexByte(`"'(`, s[j]) >= 0 {
+		closing := s[j]
+		if closing == '(' {
+			closing = ')'
+		}
+		end := strings.IndexByte(s[j+1:], closing)
+		if end < 0 {
+			return "", "", 0, false
+		}
+		title = s[j+1 : j+1+end]
+		j = skipSpace(s, j+end+2)
+	}
+	if j >= len(s) || s[j] != ')' {
+		return "", "", 0, false
+	}
+	return url, title, j + 1, true
+}
+
+// referenceID normalizes a link reference label for lookups: case and
+// inner whitespace do not matter.
+func referenceID(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// expandTabs replaces the tabs in a line's indentation with four spaces.
+func expandTabs(line string) string {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if !strings.Contains(line[:indent], "\t") {
+		return line
+	}
+	return strings.ReplaceAll(line[:indent], "\t", "    ") + line[indent:]
+}
+
+// indentation returns the number of leading spaces of line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// runLength returns how many times c repeats at the start of text.
+func runLength(text string, c byte) int {
+	n := 0
+	for n < len(text) && text[n] == c {
+		n++
+	}
+	return n
+}
+
+// skipSpace returns the index of the first non-space byte of s from i.
+func skipSpace(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
@@ -0,0 +1,107 @@
+// Package content analyzes post bodies. Posts are written in Markdown with
+// optional inline HTML, so both syntaxes are recognized.
+package content
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+)
+
+var (
+	// Markdown images and links; a link is a [text](url) not preceded by "!"
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink  = regexp.MustCompile(`\[[^\]]*\]\([^)]*\)`)
+
+	// Inline HTML equivalents
+	htmlHeading = regexp.MustCompile(`(?i)<h[1-6][\s>]`)
+	htmlLink    = regexp.MustCompile(`(?i)<a\s`)
+	htmlImage   = regexp.MustCompile(`(?i)<img[\s/>]`)
+
+	// ATX headings: one to six "#" followed by a space
+	markdownHeading = regexp.MustCompile(`^ {0,3}#{1,6}(\s|$)`)
+
+	// Markup removed before counting words
+	htmlTag          = regexp.MustCompile(`<[^>]*>`)
+	markdownLinkText = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+)
+
+// Analyze computes the word, heading, link, and image counts of a post body.
+// Words are counted on the rendered text: HTML tags, images, and link URLs
+// are not words, while link text is. Fenced code blocks are skipped when
+// looking for headings, links, and images, so "# comment" lines in code
+// samples are not reported as headings; their words still count.
+//
+// Parameters:
+//   - text: post content in Markdown and/or HTML
+//
+// Returns the computed statistics.
+func Analyze(text string) models.ContentStats {
+	var stats models.ContentStats
+
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			stats.WordCount += countWords(line)
+			continue
+		}
+		stats.WordCount += countWords(stripMarkup(line))
+
+		if markdownHeading.MatchString(line) {
+			stats.HeadingCount++
+		}
+		stats.HeadingCount += len(htmlHeading.FindAllStringIndex(line, -1))
+
+		images := len(markdownImage.FindAllStringIndex(line, -1))
+		stats.ImageCount += images + len(htmlImage.FindAllStringIndex(line, -1))
+		stats.LinkCount += len(markdownLink.FindAllStringIndex(line, -1)) - images + len(htmlLink.FindAllStringIndex(line, -1))
+	}
+	return stats
+}
+
+// PlainText reduces a post body to its visible text, as counted by
+// Analyze: HTML tags, images, and link URLs are removed, link text is kept,
+// and code fence markers are dropped. Lines are kept.
+func PlainText(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			line = stripMarkup(line)
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// stripMarkup reduces a line to its visible text.
+func stripMarkup(line string) string {
+	line = markdownImage.ReplaceAllString(line, " ")
+	line = markdownLinkText.ReplaceAllString(line, "$1")
+	return htmlTag.ReplaceAllString(line, " ")
+}
+
+// countWords counts whitespace-separated tokens containing at least one
+// letter or digit, so Markdown markers such as "#" or "-" are not words.
+func countWords(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
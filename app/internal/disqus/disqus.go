@@ -0,0 +1,178 @@
+// Package disqus reads Disqus XML exports. An export lists the discussion
+// threads of a site, one per page, followed by every comment ("post" in
+// Disqus terms) with a reference to its thread and, for replies, its parent.
+package disqus
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+)
+
+// INTERNALS_NS is the namespace of the dsq:id attributes that link
+// comments to threads and parents.
+const INTERNALS_NS = "http://disqus.com/disqus-internals"
+
+// Thread is a discussion page of the exported site.
+type Thread struct {
+	DsqID      string // Disqus internal thread ID
+	Identifier string // Site-defined identifier (the <id> element), often a slug or page ID
+	Link       string // URL of the page the thread belongs to
+	Title      string // Page title
+}
+
+// Comment is an exported comment.
+type Comment struct {
+	DsqID     string    // Disqus internal comment ID
+	ThreadID  string    // DsqID of the comment's thread
+	ParentID  string    // DsqID of the comment replied to, empty for top-level comments
+	Author    string    // Author display name
+	Message   string    // Comment body (HTML)
+	CreatedAt time.Time // When the comment was posted
+	Deleted   bool      // Removed by the author or a moderator
+	Spam      bool      // Flagged as spam
+}
+
+// Export is a parsed Disqus export.
+type Export struct {
+	Threads  []Thread
+	Comments []Comment
+}
+
+// ref is an element carrying only a dsq:id attribute, such as <thread> and
+// <parent> inside a comment.
+type ref struct {
+	ID string `xml:"http://disqus.com/disqus-internals id,attr"`
+}
+
+// threadElement is the XML shape of a <thread>.
+type threadElement struct {
+	DsqID      string `xml:"http://disqus.com/disqus-internals id,attr"`
+	Identifier string `xml:"id"`
+	Link       string `xml:"link"`
+	Title      string `xml:"title"`
+}
+
+// postElement is the XML shape of a <post>.
+type postElement struct {
+	DsqID     string `xml:"http://disqus.com/disqus-internals id,attr"`
+	Message   string `xml:"message"`
+	CreatedAt string `xml:"createdAt"`
+	IsDeleted bool   `xml:"isDeleted"`
+	IsSpam    bool   `xml:"isSpam"`
+	Author    struct {
+		Name     string `xml:"name"`
+		Username string `xml:"username"`
+	} `xml:"author"`
+	Thread ref  `xml:"thread"`
+	Parent *ref `xml:"parent"`
+}
+
+// Parse reads a Disqus export. Top-level elements are decoded one at a
+// time, so large exports are never held in memory as a document tree.
+// Comments with an unparseable createdAt are dropped.
+//
+// Parameters:
+//   - r: the export XML
+//
+// Returns the threads and comments in export order.
+func Parse(r io.Reader) (*Export, error) {
+	decoder := xml.NewDecoder(r)
+	export := &Export{}
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return export, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "thread":
+			var el threadElement
+			if err := decoder.DecodeElement(&el, &start); err != nil {
+				return nil, err
+			}
+			export.Threads = append(export.Threads, Thread{
+				DsqID:      el.DsqID,
+				Identifier: strings.TrimSpace(el.Identifier),
+				Link:       strings.TrimSpace(el.Link),
+				Title:      strings.TrimSpace(el.Title),
+			})
+		case "post":
+			var el postElement
+			if err := decoder.DecodeElement(&el, &start); err != nil {
+				return nil, err
+			}
+			createdAt, err := time.Parse(time.RFC3339, strings.TrimSpace(el.CreatedAt))
+			if err != nil {
+				continue
+			}
+			comment := Comment{
+				DsqID:     el.DsqID,
+				ThreadID:  el.Thread.ID,
+				Author:    strings.TrimSpace(el.Author.Name),
+				Message:   strings.TrimSpace(el.Message),
+				CreatedAt: createdAt,
+				Deleted:   el.IsDeleted,
+				Spam:      el.IsSpam,
+			}
+			if comment.Author == "" {
+				comment.Author = strings.TrimSpace(el.Author.Username)
+			}
+			if el.Parent != nil {
+				comment.ParentID = el.Parent.ID
+			}
+			export.Comments = append(export.Comments, comment)
+		}
+	}
+}
+
+// ThreadComments groups the importable comments by thread DsqID, converted
+// to the import format: deleted and spam comments and comments without an
+// author or body are left out, and Disqus IDs become import IDs so replies
+// keep their parent.
+func (e *Export) ThreadComments() map[string][]models.ImportedComment {
+	grouped := make(map[string][]models.ImportedComment)
+	for _, comment := range e.Comments {
+		if comment.Deleted || comment.Spam || comment.Author == "" || comment.Message == "" {
+			continue
+		}
+		grouped[comment.ThreadID] = append(grouped[comment.ThreadID], models.ImportedComment{
+			ImportID:       importID(comment.DsqID),
+			ParentImportID: importID(comment.ParentID),
+			Author:         comment.Author,
+			Content:        comment.Message,
+			CreatedAt:      comment.CreatedAt,
+		})
+	}
+	return grouped
+}
+
+// importID namespaces a Disqus ID so it cannot collide with comments
+// imported from other platforms.
+func importID(dsqID string) string {
+	if dsqID == "" {
+		return ""
+	}
+	return "disqus:" + dsqID
+}
+
+// Slug returns the last path segment of the thread's link, the usual way a
+// blog page maps to a post.
+func (t Thread) Slug() string {
+	link := t.Link
+	if i := strings.IndexAny(link, "?#"); i >= 0 {
+		link = link[:i]
+	}
+	link = strings.TrimRight(link, "/")
+	return link[strings.LastIndex(link, "/")+1:]
+}
@@ -0,0 +1,351 @@
+// Package federation implements a minimal ActivityPub actor for the blog,
+// so Fediverse users (e.g. on Mastodon) can follow it and reply to posts.
+//
+// The blog is a single actor. Public posts are published as Article objects
+// and delivered to followers; replies received in the inbox become comments.
+// Server-to-server requests are authenticated with HTTP Signatures.
+package federation
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// ACTIVITY_CONTENT_TYPE is the media type of ActivityPub documents.
+const ACTIVITY_CONTENT_TYPE = "application/activity+json"
+
+// AS_CONTEXT and SECURITY_CONTEXT are the JSON-LD contexts of documents.
+const (
+	AS_CONTEXT       = "https://www.w3.org/ns/activitystreams"
+	SECURITY_CONTEXT = "https://w3id.org/security/v1"
+)
+
+// PUBLIC_COLLECTION addresses an activity to everyone.
+const PUBLIC_COLLECTION = AS_CONTEXT + "#Public"
+
+// KEY_META_ID identifies the meta document holding the actor's private key,
+// so the actor keeps its identity across restarts and instances.
+const KEY_META_ID = "federation-key"
+
+// MAX_DOCUMENT_BYTES bounds remote documents read when fetching actors.
+const MAX_DOCUMENT_BYTES = 1 << 20
+
+// DELIVERY_TIMEOUT bounds one remote request.
+const DELIVERY_TIMEOUT = 10 * time.Second
+
+// Follower is a remote actor following the blog.
+type Follower struct {
+	Actor      string    `json:"actor" bson:"_id"`               // Follower's actor IRI
+	Inbox      string    `json:"inbox" bson:"inbox"`             // Where activities are delivered
+	FollowedAt time.Time `json:"followed_at" bson:"followed_at"` // When the follow was accepted
+}
+
+// RemoteActor is the subset of a remote actor document the blog uses.
+type RemoteActor struct {
+	ID                string `json:"id"`
+	PreferredUsername string `json:"preferredUsername"`
+	Name              string `json:"name"`
+	Inbox             string `json:"inbox"`
+	Endpoints         struct {
+		SharedInbox string `json:"sharedInbox"`
+	} `json:"endpoints"`
+	PublicKey struct {
+		ID           string `json:"id"`
+		Owner        string `json:"owner"`
+		PublicKeyPem string `json:"publicKeyPem"`
+	} `json:"publicKey"`
+}
+
+// Handle returns the actor's Fediverse handle, "@user@host".
+func (a RemoteActor) Handle() string {
+	host := a.ID
+	if parsed, err := url.Parse(a.ID); err == nil {
+		host = parsed.Host
+	}
+	name := a.PreferredUsername
+	if name == "" {
+		name = a.Name
+	}
+	return "@" + name + "@" + host
+}
+
+// Service holds the blog actor's identity and talks to remote servers.
+type Service struct {
+	DB       *storage.Storage // Database storage instance for MongoDB operations
+	BaseURL  string           // Public URL of the API, without trailing slash
+	Username string           // Actor username, the user part of the handle
+	Name     string           // Actor display name
+
+	key    *rsa.PrivateKey
+	client *http.Client
+}
+
+// New creates the federation service, or returns nil when baseURL is empty
+// (federation disabled). Init must be called before the service is used.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - baseURL: public URL the API is reachable at (e.g. "https://blog.example.com")
+//   - username: actor username
+//   - name: actor display name
+func New(db *storage.Storage, baseURL, username, name string) *Service {
+	if baseURL == "" {
+		return nil
+	}
+	return &Service{
+		DB:       db,
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		Username: username,
+		Name:     name,
+		client:   remoteClient(),
+	}
+}
+
+// Init loads the actor's private key from the meta collection, generating
+// and storing one on first start. Concurrent first starts converge on the
+// key stored first.
+func (s *Service) Init(ctx context.Context) error {
+	var doc struct {
+		PEM string `bson:"pem"`
+	}
+	err := s.DB.Meta.FindOne(ctx, bson.M{"_id": KEY_META_ID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return err
+		}
+		block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+		doc.PEM = string(pem.EncodeToMemory(block))
+		if _, err := s.DB.Meta.InsertOne(ctx, bson.M{"_id": KEY_META_ID, "pem": doc.PEM}); err != nil {
+			if !mongo.IsDuplicateKeyError(err) {
+				return err
+			}
+			return s.Init(ctx)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode([]byte(doc.PEM))
+	if block == nil {
+		return errors.New("stored federation key is not PEM")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	s.key = key
+	return nil
+}
+
+// Domain is the host part of the blog's handle.
+func (s *Service) Domain() string {
+	if parsed, err := url.Parse(s.BaseURL); err == nil {
+		return parsed.Host
+	}
+	return s.BaseURL
+}
+
+// ActorIRI is the ID of the blog actor.
+func (s *Service) ActorIRI() string { return s.BaseURL + "/ap/actor" }
+
+// KeyID is the ID of the actor's public key.
+func (s *Service) KeyID() string { return s.ActorIRI() + "#main-key" }
+
+// ArticleIRI is the ID of the Article object for a post.
+func (s *Service) ArticleIRI(postID models.ID) string {
+	return s.BaseURL + "/ap/posts/" + postID.String()
+}
+
+// PostIDFromIRI returns the post ID in an Article IRI of this blog.
+func (s *Service) PostIDFromIRI(iri string) (string, bool) {
+	return strings.CutPrefix(iri, s.BaseURL+"/ap/posts/")
+}
+
+// Actor returns the blog's actor document.
+func (s *Service) Actor() (map[string]any, error) {
+	publicKey, err := encodePublicKey(&s.key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	actor := s.ActorIRI()
+	return map[string]any{
+		"@context":          []string{AS_CONTEXT, SECURITY_CONTEXT},
+		"id":                actor,
+		"type":              "Person",
+		"preferredUsername": s.Username,
+		"name":              s.Name,
+		"url":               s.BaseURL,
+		"inbox":             s.BaseURL + "/ap/inbox",
+		"outbox":            s.BaseURL + "/ap/outbox",
+		"followers":         s.BaseURL + "/ap/followers",
+		"publicKey": map[string]any{
+			"id":           s.KeyID(),
+			"owner":        actor,
+			"publicKeyPem": publicKey,
+		},
+	}, nil
+}
+
+// Article returns the ActivityPub representation of a post.
+func (s *Service) Article(post models.BlogPost) map[string]any {
+	article := map[string]any{
+		"id":           s.ArticleIRI(post.ID),
+		"type":         "Article",
+		"attributedTo": s.ActorIRI(),
+		"name":         post.Title,
+		"content":      post.Content,
+		"url":          s.ArticleIRI(post.ID),
+		"published":    post.CreatedAt.UTC().Format(time.RFC3339),
+		"to":           []string{PUBLIC_COLLECTION},
+		"cc":           []string{s.BaseURL + "/ap/followers"},
+	}
+	if post.Excerpt != "" {
+		article["summary"] = post.Excerpt
+	}
+	return article
+}
+
+// Create wraps an Article in a Create activity.
+func (s *Service) Create(post models.BlogPost) map[string]any {
+	article := s.Article(post)
+	return map[string]any{
+		"@context":  AS_CONTEXT,
+		"id":        article["id"].(string) + "#create",
+		"type":      "Create",
+		"actor":     s.ActorIRI(),
+		"published": article["published"],
+		"to":        article["to"],
+		"cc":        article["cc"],
+		"object":    article,
+	}
+}
+
+// Publishes reports whether a post is federated: public, listed, published,
+// and not passphrase-protected (visibility.PUBLISHED).
+func Publishes(post models.BlogPost) bool {
+	return visibility.PUBLISHED.Allows(post)
+}
+
+// FetchActor retrieves a remote actor document. keyID may carry a fragment
+// ("#main-key"), which is dropped. The IRI comes from unauthenticated
+// requests, so only https IRIs of public hosts are fetched (see
+// checkRemoteURL); others fail with ErrForbiddenURL.
+func (s *Service) FetchActor(ctx context.Context, iri string) (*RemoteActor, error) {
+	iri, _, _ = strings.Cut(iri, "#")
+	if err := checkRemoteURL(ctx, iri); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", ACTIVITY_CONTENT_TYPE)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch actor %s: status %d", iri, resp.StatusCode)
+	}
+
+	var actor RemoteActor
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MAX_DOCUMENT_BYTES)).Decode(&actor); err != nil {
+		return nil, err
+	}
+	if actor.ID != iri {
+		return nil, fmt.Errorf("fetch actor %s: document is %s", iri, actor.ID)
+	}
+	return &actor, nil
+}
+
+// Deliver POSTs a signed activity to a remote inbox. Inboxes are named by
+// remote actors, so they are checked like actor IRIs (see FetchActor).
+func (s *Service) Deliver(ctx context.Context, inbox string, activity any) error {
+	if err := checkRemoteURL(ctx, inbox); err != nil {
+		return err
+	}
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ACTIVITY_CONTENT_TYPE)
+	if err := Sign(req, body, s.KeyID(), s.key); err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("deliver to %s: status %d", inbox, resp.StatusCode)
+	}
+	return nil
+}
+
+// Publish delivers a Create activity for post to every follower inbox,
+// shared inboxes counted once. Delivery errors are logged per inbox.
+func (s *Service) Publish(ctx context.Context, post models.BlogPost) {
+	cursor, err := s.DB.Followers.Find(ctx, bson.M{})
+	if err != nil {
+		logger.Error("failed to load followers", zap.Error(err))
+		return
+	}
+	var followers []Follower
+	if err := cursor.All(ctx, &followers); err != nil {
+		logger.Error("failed to decode followers", zap.Error(err))
+		return
+	}
+
+	activity := s.Create(post)
+	delivered := make(map[string]bool, len(followers))
+	for _, follower := range followers {
+		if delivered[follower.Inbox] {
+			continue
+		}
+		delivered[follower.Inbox] = true
+		if err := s.Deliver(ctx, follower.Inbox, activity); err != nil {
+			logger.Warn("failed to deliver post", zap.String("inbox", follower.Inbox), zap.Error(err))
+		}
+	}
+}
+
+// Accept builds the Accept activity answering a Follow.
+func (s *Service) Accept(follow map[string]any) map[string]any {
+	id, _ := follow["id"].(string)
+	return map[string]any{
+		"@context": AS_CONTEXT,
+		"id":       s.ActorIRI() + "#accept-" + url.QueryEscape(id),
+		"type":     "Accept",
+		"actor":    s.ActorIRI(),
+		"object":   follow,
+	}
+}
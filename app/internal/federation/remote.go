@@ -0,0 +1,94 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+)
+
+// MAX_REDIRECTS caps the redirects followed by one remote request.
+const MAX_REDIRECTS = 3
+
+// ErrForbiddenURL is returned for remote URLs the server refuses to request:
+// anything but https, and hosts resolving to loopback, private, link-local,
+// or unspecified addresses. Remote servers name the URLs fetched (e.g. the
+// keyId of an unauthenticated inbox request), so without this check they
+// could make the server reach internal services or cloud metadata.
+var ErrForbiddenURL = errors.New("remote URL not allowed")
+
+// remoteClient returns the HTTP client for requests to remote servers. Its
+// dialer refuses non-public addresses when connecting, after DNS
+// resolution, so a host that passed checkRemoteURL and then changes its
+// records (DNS rebinding) is refused too. Redirects are capped at
+// MAX_REDIRECTS and must stay on https. Proxies are not used, since the
+// address check would then only see the proxy.
+func remoteClient() *http.Client {
+	dialer := &net.Dialer{Timeout: DELIVERY_TIMEOUT, Control: refuseNonPublic}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   DELIVERY_TIMEOUT,
+		Transport: tracing.Transport(transport),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= MAX_REDIRECTS {
+				return fmt.Errorf("stopped after %d redirects", MAX_REDIRECTS)
+			}
+			if req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect to %s", ErrForbiddenURL, req.URL.Redacted())
+			}
+			return nil
+		},
+	}
+}
+
+// checkRemoteURL rejects raw unless it is an https URL whose host resolves
+// to public addresses only, before any request is made.
+func checkRemoteURL(ctx context.Context, raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: %s", ErrForbiddenURL, raw)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenURL, parsed.Hostname(), addr.IP)
+		}
+	}
+	return nil
+}
+
+// refuseNonPublic is the net.Dialer Control hook of remoteClient, called
+// with the resolved address of every connection.
+func refuseNonPublic(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: connection to %s", ErrForbiddenURL, host)
+	}
+	return nil
+}
+
+// publicIP reports whether ip may be reached on behalf of remote servers:
+// it is not loopback, private, link-local (including 169.254.169.254, the
+// cloud metadata service), multicast, or unspecified.
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
@@ -0,0 +1,77 @@
+package federation
+
+import (
+	"html"
+	"strings"
+)
+
+// PlainText converts the HTML content of a remote Note to the plain text
+// stored in comments: tags are dropped, paragraph and line breaks become
+// newlines, and entities are decoded.
+func PlainText(content string) string {
+	var b strings.Builder
+	for len(content) > 0 {
+		start := strings.IndexByte(content, '<')
+		if start < 0 {
+			b.WriteString(content)
+			break
+		}
+		b.WriteString(content[:start])
+		end := strings.IndexByte(content[start:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToLower(strings.Trim(content[start+1:start+end], "/ "))
+		name, _, _ := strings.Cut(tag, " ")
+		switch name {
+		case "br":
+			b.WriteString("\n")
+		case "p":
+			if b.Len() > 0 && content[start+1] == 'p' {
+				b.WriteString("\n\n")
+			}
+		}
+		content = content[start+end+1:]
+	}
+	return strings.TrimSpace(html.UnescapeString(b.String()))
+}
+
+// stringValue reads a string property, or the "id" of an embedded object.
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]any:
+		id, _ := v["id"].(string)
+		return id
+	}
+	return ""
+}
+
+// Activity is a received activity with accessors for the properties the
+// inbox uses. Properties may be IRIs or embedded objects.
+type Activity map[string]any
+
+// Type is the activity type, e.g. "Follow".
+func (a Activity) Type() string { return stringValue(a["type"]) }
+
+// ID is the activity IRI.
+func (a Activity) ID() string { return stringValue(a["id"]) }
+
+// Actor is the IRI of the actor performing the activity.
+func (a Activity) Actor() string { return stringValue(a["actor"]) }
+
+// Object is the activity's object; an object given only by IRI is returned
+// as an Activity holding just its "id".
+func (a Activity) Object() Activity {
+	switch v := a["object"].(type) {
+	case map[string]any:
+		return Activity(v)
+	case string:
+		return Activity{"id": v}
+	}
+	return Activity{}
+}
+
+// String reads a string property of the object.
+func (a Activity) String(key string) string { return stringValue(a[key]) }
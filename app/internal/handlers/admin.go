@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/jobs"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// GetDuplicates handles GET /api/admin/duplicates requests.
+// Returns the near-duplicate post pairs found by the latest similarity scan,
+// most similar first. Helps catch accidental re-imports and plagiarism.
+//
+// Response format:
+//   - 200: Success with array of DuplicateMatch objects
+//   - 502: Database query error
+func (h *Handler) GetDuplicates(c *fiber.Ctx) error {
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"similarity": -1})
+	cursor, err := h.DB.Duplicates.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch duplicates", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch duplicates",
+		})
+	}
+	defer cursor.Close(ctx)
+
+	matches := []models.DuplicateMatch{}
+	if err := cursor.All(ctx, &matches); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode duplicates", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch duplicates",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: matches})
+}
+
+// ScanDuplicates handles POST /api/admin/duplicates/scan requests.
+// Runs a near-duplicate scan immediately instead of waiting for the next
+// scheduled pass, and returns its matches. The scan takes the same lease as
+// the scheduled job, so it never overlaps a scan on another instance.
+//
+// Response format:
+//   - 200: Success with array of DuplicateMatch objects
+//   - 409: A scan is already running
+//   - 502: Database error during the scan
+func (h *Handler) ScanDuplicates(c *fiber.Ctx) error {
+	matches, ran, err := h.Duplicates.ScanExclusive(c.Context())
+	if err != nil {
+		logger.Ctx(c.Context()).Error("duplicate scan failed", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to scan for duplicates",
+		})
+	}
+	if !ran {
+		return c.Status(http.StatusConflict).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Scan already running",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: matches})
+}
+
+// GetBrokenLinks handles GET /api/admin/broken-links requests.
+// Returns the posts whose content links to pages that answered 404 or 410
+// or timed out during the latest link check, ordered by post title. Posts
+// without broken links are not listed.
+//
+// Response format:
+//   - 200: Success with array of PostBrokenLinks objects
+//   - 502: Database query error
+func (h *Handler) GetBrokenLinks(c *fiber.Ctx) error {
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"title": 1})
+	cursor, err := h.DB.BrokenLinks.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch broken links", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch broken links",
+		})
+	}
+	defer cursor.Close(ctx)
+
+	reports := []models.PostBrokenLinks{}
+	if err := cursor.All(ctx, &reports); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode broken links", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch broken links",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: reports})
+}
+
+// lockStatus is the response of GetLocks.
+type lockStatus struct {
+	Owner  string         `json:"owner"`  // This instance's lease owner name
+	Stats  jobs.LockStats `json:"stats"`  // This instance's lock counters
+	Leases []jobs.Lease   `json:"leases"` // Leases stored across all instances
+}
+
+// GetLocks handles GET /api/admin/locks requests.
+// Returns the background job leases held across all instances together
+// with this instance's lock counters (acquired, contended, takeovers, lost).
+//
+// Response format:
+//   - 200: Success with owner, stats, and leases
+//   - 502: Database query error
+func (h *Handler) GetLocks(c *fiber.Ctx) error {
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	leases, err := h.Locks.Leases(ctx)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch job leases", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch locks",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: lockStatus{
+		Owner:  h.Locks.Owner,
+		Stats:  h.Locks.Stats(),
+		Leases: leases,
+	}})
+}
+
+// GetReadStats handles GET /api/admin/read-dedup requests.
+// Returns the counters of the read deduplication group, showing how many
+// reads were collapsed into a shared database query.
+//
+// Response format:
+//   - 200: Success with a FlightStats object
+func (h *Handler) GetReadStats(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{Success: true, Data: h.Reads.Stats()})
+}
+
+// GetStorageMetrics handles GET /api/admin/storage-metrics requests.
+// Returns the database commands run since startup per collection and
+// operation type, with their error rates and round-trip times, so the
+// features putting the most load on the database can be found.
+//
+// Response format:
+//   - 200: Success with an array of OperationStats objects, sorted by
+//     collection and operation
+func (h *Handler) GetStorageMetrics(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{Success: true, Data: h.DB.Metrics.Snapshot()})
+}
+
+// siteStats is the body of GET /api/admin/stats.
+type siteStats struct {
+	models.WritingStats
+	CSPViolations []models.CSPViolationCount `json:"csp_violations"` // Most frequent CSP violations
+}
+
+// GetStats handles GET /api/admin/stats requests.
+// Returns site-wide writing statistics aggregated from the content stats
+// stored on every post: total and average word count, and total headings,
+// links, and images. Alongside them, csp_violations lists the most
+// frequent Content-Security-Policy violations reported to
+// POST /api/csp-report over the retention window, with estimated counts.
+//
+// Response format:
+//   - 200: Success with the WritingStats fields (all zero when there are no
+//     posts) and csp_violations
+//   - 502: Database query error
+func (h *Handler) GetStats(c *fiber.Ctx) error {
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_AGGREGATE)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"posts":         bson.M{"$sum": 1},
+			"words":         bson.M{"$sum": "$stats.word_count"},
+			"average_words": bson.M{"$avg": "$stats.word_count"},
+			"headings":      bson.M{"$sum": "$stats.heading_count"},
+			"links":         bson.M{"$sum": "$stats.link_count"},
+			"images":        bson.M{"$sum": "$stats.image_count"},
+		}}},
+	}
+
+	cursor, err := h.DB.Posts.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to aggregate writing stats", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch stats",
+		})
+	}
+	defer cursor.Close(ctx)
+
+	// An empty collection yields no group document
+	var stats models.WritingStats
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&stats); err != nil {
+			logger.Ctx(c.Context()).Error("failed to decode writing stats", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to fetch stats",
+			})
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		logger.Ctx(c.Context()).Error("failed to read writing stats", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch stats",
+		})
+	}
+
+	violations, err := h.cspViolationCounts(ctx)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to aggregate csp reports", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch stats",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: siteStats{WritingStats: stats, CSPViolations: violations}})
+}
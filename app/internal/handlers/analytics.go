@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_ANALYTICS_DAYS and MAX_ANALYTICS_DAYS bound the range of
+// GET /api/admin/posts/:id/analytics.
+const (
+	DEFAULT_ANALYTICS_DAYS = 30
+	MAX_ANALYTICS_DAYS     = 365
+)
+
+// MAX_ANALYTICS_REFERRERS is the number of top referrers returned.
+const MAX_ANALYTICS_REFERRERS = 20
+
+// ANALYTICS_DATE_FORMAT is the layout of days in analytics responses.
+const ANALYTICS_DATE_FORMAT = "2006-01-02"
+
+// GetPostAnalytics handles GET /api/admin/posts/:id/analytics requests.
+// Returns a post's daily views and top referrers over the last days, read
+// from the rollups the view recorder writes. Counts trail live traffic by
+// up to ANALYTICS_FLUSH_INTERVAL.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Query parameters:
+//   - days: int (optional) - days to cover, ending today (UTC); 1 to 365, default 30
+//
+// Response format:
+//   - 200: Success with a PostAnalytics object
+//   - 400: Invalid ID format or invalid days
+//   - 404: Post not found
+//   - 502: Database query error
+func (h *Handler) GetPostAnalytics(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	days := DEFAULT_ANALYTICS_DAYS
+	if raw := c.Query("days"); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > MAX_ANALYTICS_DAYS {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "days must be between 1 and 365",
+			})
+		}
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_AGGREGATE)
+	defer cancel()
+
+	exists, err := h.Posts.Exists(ctx, postID)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to check post", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch analytics",
+		})
+	}
+	if !exists {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, 1-days)
+
+	// One pass over the rollups yields both the series and the referrers
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"post_id": postID, "day": bson.M{"$gte": from}}}},
+		{{Key: "$facet", Value: bson.M{
+			"daily": bson.A{
+				bson.M{"$group": bson.M{"_id": "$day", "views": bson.M{"$sum": "$views"}}},
+			},
+			"referrers": bson.A{
+				bson.M{"$group": bson.M{"_id": "$referrer", "views": bson.M{"$sum": "$views"}}},
+				bson.M{"$sort": bson.D{{Key: "views", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": MAX_ANALYTICS_REFERRERS},
+			},
+		}}},
+	}
+	cursor, err := h.DB.PostViews.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to aggregate post views", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch analytics",
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Daily []struct {
+			Day   time.Time `bson:"_id"`
+			Views int64     `bson:"views"`
+		} `bson:"daily"`
+		Referrers []models.ReferrerViews `bson:"referrers"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil || len(facets) != 1 {
+		logger.Ctx(c.Context()).Error("failed to decode post views", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch analytics",
+		})
+	}
+
+	// Fill in the days without views so the series has no gaps
+	byDay := make(map[string]int64, len(facets[0].Daily))
+	for _, day := range facets[0].Daily {
+		byDay[day.Day.UTC().Format(ANALYTICS_DATE_FORMAT)] = day.Views
+	}
+	analytics := models.PostAnalytics{
+		PostID:    postID,
+		From:      from.Format(ANALYTICS_DATE_FORMAT),
+		To:        to.Format(ANALYTICS_DATE_FORMAT),
+		Daily:     make([]models.DailyViews, 0, days),
+		Referrers: facets[0].Referrers,
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(ANALYTICS_DATE_FORMAT)
+		analytics.Daily = append(analytics.Daily, models.DailyViews{Date: date, Views: byDay[date]})
+		analytics.TotalViews += byDay[date]
+	}
+	if analytics.Referrers == nil {
+		analytics.Referrers = []models.ReferrerViews{}
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: analytics})
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// API_KEY_PREFIX starts every generated key so leaked keys are easy to
+// recognize in logs and secret scanners.
+const API_KEY_PREFIX = "bk_"
+
+// API_KEY_DISPLAY_LENGTH is how much of a key is stored in clear as its
+// prefix, enough to tell keys apart without weakening them.
+const API_KEY_DISPLAY_LENGTH = 10
+
+// hashAPIKey returns the stored form of a raw key. Generated keys carry 256
+// random bits, so a fast hash is as safe as a password hash here.
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// LookupAPIKey resolves a raw X-API-Key value to its active stored key for
+// middleware.APIKey. Returns nil, nil for unknown or revoked keys.
+func (h *Handler) LookupAPIKey(ctx context.Context, raw string) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.dbTimeout(DB_READ))
+	defer cancel()
+
+	var key models.APIKey
+	filter := bson.M{"key_hash": hashAPIKey(raw), "revoked_at": bson.M{"$exists": false}}
+	err := h.DB.APIKeys.FindOne(ctx, filter).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAPIKeys handles GET /api/admin/api-keys requests.
+// Returns every API key, active and revoked, oldest first. Keys themselves
+// are never returned, only their prefixes.
+//
+// Response format:
+//   - 200: Success with array of APIKey objects
+//   - 502: Database query error
+func (h *Handler) GetAPIKeys(c *fiber.Ctx) error {
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := h.DB.APIKeys.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch API keys", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch API keys",
+		})
+	}
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode API keys", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch API keys",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: keys})
+}
+
+// CreateAPIKey handles POST /api/admin/api-keys requests.
+// Generates a key for a bot or integration. The key is only part of this
+// response; the server keeps a hash of it.
+//
+// Request body should contain:
+//   - name: string (required) - what the key is for
+//   - scope: string (optional) - "read" (default) or "write"
+//
+// Response format:
+//   - 201: Success with the APIKey and its key
+//   - 400: Invalid JSON, missing name, or unknown scope
+//   - 502: Database insertion error
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Name required",
+		})
+	}
+	if req.Scope == "" {
+		req.Scope = models.API_KEY_SCOPE_READ
+	}
+	if req.Scope != models.API_KEY_SCOPE_READ && req.Scope != models.API_KEY_SCOPE_WRITE {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Scope must be read or write",
+		})
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		logger.Ctx(c.Context()).Error("failed to generate API key", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to create API key",
+		})
+	}
+	raw := API_KEY_PREFIX + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := models.APIKey{
+		ID:        h.DB.IDs.New(),
+		Name:      req.Name,
+		Prefix:    raw[:API_KEY_DISPLAY_LENGTH],
+		KeyHash:   hashAPIKey(raw),
+		Scope:     req.Scope,
+		CreatedAt: time.Now(),
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	if _, err := h.DB.APIKeys.InsertOne(ctx, key); err != nil {
+		logger.Ctx(c.Context()).Error("failed to insert API key", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to create API key",
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Data:    models.CreatedAPIKey{APIKey: key, Key: raw},
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/admin/api-keys/:id requests.
+// Revokes a key: requests sending it are rejected from then on. Revoked
+// keys stay listed with their revocation time for auditing.
+//
+// URL parameters:
+//   - id: string (required) - ID of the API key
+//
+// Response format:
+//   - 200: Success with the revoked APIKey
+//   - 400: Invalid ID format
+//   - 404: API key not found or already revoked
+//   - 502: Database update error
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid API key ID",
+		})
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	var key models.APIKey
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = h.DB.APIKeys.FindOneAndUpdate(ctx, filter, update, opts).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "API key not found",
+		})
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to revoke API key", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to revoke API key",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: key})
+}
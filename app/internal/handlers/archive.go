@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// ArchivePost handles POST /api/posts/:id/archive requests.
+// Marks a post as archived: it disappears from default listings but remains
+// readable by direct link, where the response carries archived=true.
+// Archiving an archived post keeps its original archived_at.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Response format:
+//   - 200: Success with the updated BlogPost object
+//   - 400: Invalid ID format
+//   - 404: Post not found
+//   - 502: Database update error
+func (h *Handler) ArchivePost(c *fiber.Ctx) error {
+	// $min keeps the first archive time when archiving twice
+	return h.setArchived(c, bson.M{
+		"$set": bson.M{"archived": true},
+		"$min": bson.M{"archived_at": time.Now()},
+	})
+}
+
+// UnarchivePost handles DELETE /api/posts/:id/archive requests.
+// Restores an archived post to the default listings.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Response format:
+//   - 200: Success with the updated BlogPost object
+//   - 400: Invalid ID format
+//   - 404: Post not found
+//   - 502: Database update error
+func (h *Handler) UnarchivePost(c *fiber.Ctx) error {
+	return h.setArchived(c, bson.M{
+		"$unset": bson.M{"archived": "", "archived_at": ""},
+	})
+}
+
+// setArchived applies an archive state update to the post in the URL and
+// responds with the updated post.
+func (h *Handler) setArchived(c *fiber.Ctx, update bson.M) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var post models.BlogPost
+	err = h.DB.Posts.FindOneAndUpdate(ctx, bson.M{"_id": postID}, update, opts).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to update archive state", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update post",
+		})
+	}
+
+	// Archiving changes both the post and the default listing
+	if err := h.DB.TouchPost(ctx, postID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: post})
+}
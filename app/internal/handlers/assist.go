@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/assistant"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// AssistPost handles POST /api/posts/:id/assist requests.
+// Asks the configured content assistant for a summary and tag suggestions
+// for the post. Suggestions are returned to the author and not stored;
+// see AcceptAssist to keep them.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Response format:
+//   - 200: Success with assistant.Suggestion object
+//   - 400: Invalid ID format
+//   - 404: Post not found
+//   - 501: No content assistant configured
+//   - 502: Assistant provider or database error
+func (h *Handler) AssistPost(c *fiber.Ctx) error {
+	if h.Assistant == nil {
+		return c.Status(http.StatusNotImplemented).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Content assistant not configured",
+		})
+	}
+
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// The provider call dominates, so use its timeout rather than the DB one
+	ctx, cancel := h.requestContext(c, assistant.DEFAULT_REQUEST_TIMEOUT+h.dbTimeout(DB_WRITE))
+	defer cancel()
+
+	var post models.BlogPost
+	if err := h.DB.Posts.FindOne(ctx, bson.M{"_id": postID}).Decode(&post); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not found",
+			})
+		}
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+
+	suggestion, err := h.Assistant.Suggest(ctx, post.Title, post.Content)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("content assistant failed", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Content assistant failed",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: suggestion})
+}
+
+// AcceptAssist handles POST /api/posts/:id/assist/accept requests.
+// Stores the suggestions the author accepted (possibly edited) on the post:
+// the summary becomes the post excerpt and the tags replace the post tags.
+// Fields left empty are not touched.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Request body should contain:
+//   - summary: string (optional) - Accepted summary
+//   - tags: []string (optional) - Accepted tags (see cleanPostTags)
+//
+// Response format:
+//   - 200: Success with the updated BlogPost object
+//   - 400: Invalid ID, invalid JSON, too many or too long tags, or nothing to accept
+//   - 404: Post not found
+//   - 502: Database update error
+func (h *Handler) AcceptAssist(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Parse the request body into the expected structure
+	var req models.AcceptAssistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+
+	// Only store what the author actually accepted
+	set := bson.M{}
+	if summary := strings.TrimSpace(req.Summary); summary != "" {
+		set["excerpt"] = summary
+	}
+	tags, err := cleanPostTags(req.Tags)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "At most 10 tags of at most 32 characters",
+		})
+	}
+	if len(tags) > 0 {
+		set["tags"] = tags
+	}
+	if len(set) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Summary or tags required",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var post models.BlogPost
+	err = h.DB.Posts.FindOneAndUpdate(ctx, bson.M{"_id": postID}, bson.M{"$set": set}, opts).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to store accepted suggestions", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update post",
+		})
+	}
+
+	if err := h.DB.TouchPost(ctx, postID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: post})
+}
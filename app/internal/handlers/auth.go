@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/jwt"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MIN_PASSWORD_BYTES and MAX_PASSWORD_BYTES bound account passwords; bcrypt
+// hashes at most 72 bytes.
+const (
+	MIN_PASSWORD_BYTES = 8
+	MAX_PASSWORD_BYTES = 72
+)
+
+// usernamePattern is the accepted form of usernames, after lowercasing.
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_.-]{3,32}$`)
+
+// dummyPasswordHash is compared against when a login names an unknown user,
+// so the response time does not reveal which usernames exist.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Register handles POST /api/auth/register requests.
+// Creates a user account and logs it in. Only a bcrypt hash of the
+// password is stored.
+//
+// Request body should contain:
+//   - username: string (required) - 3 to 32 letters, digits, "_", "." or "-"; case-insensitive
+//   - password: string (required) - 8 to 72 bytes
+//
+// Response format:
+//   - 201: Success with an AuthResponse (token, expiry, and the new user)
+//   - 400: Invalid JSON, username, or password
+//   - 403: Username is listed in ADMIN_USERS (see the create-admin command)
+//   - 409: Username already taken
+//   - 502: Database insertion error
+func (h *Handler) Register(c *fiber.Ctx) error {
+	var req models.AuthRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	username := strings.ToLower(strings.TrimSpace(req.Username))
+	if !usernamePattern.MatchString(username) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Username must be 3 to 32 letters, digits, '_', '.' or '-'",
+		})
+	}
+	if len(req.Password) < MIN_PASSWORD_BYTES || len(req.Password) > MAX_PASSWORD_BYTES {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Password must be 8 to 72 bytes",
+		})
+	}
+
+	// Administrators are named in ADMIN_USERS, so anyone registering one of
+	// those names would gain admin rights; their accounts are made with
+	// the create-admin command instead
+	for _, admin := range h.Config.AdminUsers {
+		if strings.EqualFold(admin, username) {
+			return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Username is reserved",
+			})
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to hash password", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to register user",
+		})
+	}
+
+	user := models.User{
+		ID:           h.DB.IDs.New(),
+		Username:     username,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	// The unique username index settles concurrent registrations
+	if _, err := h.DB.Users.InsertOne(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return c.Status(http.StatusConflict).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Username already taken",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to insert user", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to register user",
+		})
+	}
+
+	return h.issueLogin(c, http.StatusCreated, user)
+}
+
+// Login handles POST /api/auth/login requests.
+// Exchanges a username and password for a JWT to send as
+// "Authorization: Bearer <token>" on protected endpoints.
+//
+// Request body should contain:
+//   - username: string (required)
+//   - password: string (required)
+//
+// Response format:
+//   - 200: Success with an AuthResponse (token, expiry, and the user)
+//   - 400: Invalid JSON
+//   - 401: Unknown username or wrong password (indistinguishable)
+//   - 502: Database query error
+func (h *Handler) Login(c *fiber.Ctx) error {
+	var req models.AuthRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	var user models.User
+	err := h.DB.Users.FindOne(ctx, bson.M{"username": strings.ToLower(strings.TrimSpace(req.Username))}).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		logger.Ctx(c.Context()).Error("failed to find user", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to log in",
+		})
+	}
+
+	// Unknown users cost a bcrypt comparison too
+	hash := []byte(user.PasswordHash)
+	if err == mongo.ErrNoDocuments {
+		hash = dummyPasswordHash()
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(req.Password)) != nil || err == mongo.ErrNoDocuments {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid username or password",
+		})
+	}
+
+	return h.issueLogin(c, http.StatusOK, user)
+}
+
+// issueLogin signs a JWT for user and responds with an AuthResponse.
+func (h *Handler) issueLogin(c *fiber.Ctx, status int, user models.User) error {
+	now := time.Now()
+	expiresAt := now.Add(h.Config.JWTTTL)
+	signed, err := h.Auth.Sign(jwt.Claims{
+		Subject:   user.ID.String(),
+		Name:      user.Username,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to sign login token", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to log in",
+		})
+	}
+
+	return c.Status(status).JSON(models.APIResponse{
+		Success: true,
+		Data:    models.AuthResponse{Token: signed, ExpiresAt: expiresAt, User: user},
+	})
+}
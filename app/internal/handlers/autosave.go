@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/schema"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// autosaveOrder sorts a post's snapshots newest first, matching the
+// autosaves index.
+var autosaveOrder = bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+
+// PutAutosave handles PUT /api/posts/:id/autosave requests.
+// Stores a draft snapshot of the post as it is in the author's editor,
+// separately from the post: readers, the last-modified time, and the
+// content freeze are unaffected. Saves are throttled per post: a save
+// within AUTOSAVE_INTERVAL of the creation of the newest snapshot updates
+// it, so a typing session yields one snapshot per interval. Only the
+// newest AUTOSAVE_KEEP snapshots are kept. Content is sanitized like post
+// content (see sanitize.Policy).
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Request body: AutosaveRequest JSON object
+//
+// Response format:
+//   - 200: Success with the stored Autosave; saves above 1 means it was merged
+//   - 400: Invalid post ID or JSON
+//   - 404: Post not found
+//   - 422: Fields breaking the request schema, each listed in data
+//   - 502: Database error
+func (h *Handler) PutAutosave(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Parse the request body into the expected structure
+	var req models.AutosaveRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	req.Content = h.Sanitizer.Sanitize(req.Content)
+	if problems := schema.Validate(&req); len(problems) > 0 {
+		return validationFailed(c, problems)
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	exists, err := h.Posts.Exists(ctx, postID)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to check post existence", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to save autosave",
+		})
+	}
+	if !exists {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+
+	now := time.Now()
+	actor := h.auditActor(c)
+	var latest models.Autosave
+	err = h.DB.Autosaves.FindOne(ctx, bson.M{"post_id": postID}, options.FindOne().SetSort(autosaveOrder)).Decode(&latest)
+	if err != nil && err != mongo.ErrNoDocuments {
+		logger.Ctx(c.Context()).Error("failed to load latest autosave", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to save autosave",
+		})
+	}
+
+	// Merge into the newest snapshot while it is within the throttle window
+	if err == nil && now.Sub(latest.CreatedAt) < h.Config.AutosaveInterval {
+		update := bson.M{
+			"$set": bson.M{"title": req.Title, "content": req.Content, "saved_by": actor, "saved_at": now},
+			"$inc": bson.M{"saves": 1},
+		}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		var merged models.Autosave
+		if err := h.DB.Autosaves.FindOneAndUpdate(ctx, bson.M{"_id": latest.ID}, update, opts).Decode(&merged); err != nil {
+			logger.Ctx(c.Context()).Error("failed to update autosave", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to save autosave",
+			})
+		}
+		return c.JSON(models.APIResponse{Success: true, Data: merged})
+	}
+
+	snapshot := models.Autosave{
+		ID:        h.DB.IDs.New(),
+		PostID:    postID,
+		Title:     req.Title,
+		Content:   req.Content,
+		SavedBy:   actor,
+		Saves:     1,
+		CreatedAt: now,
+		SavedAt:   now,
+	}
+	if _, err := h.DB.Autosaves.InsertOne(ctx, snapshot); err != nil {
+		logger.Ctx(c.Context()).Error("failed to insert autosave", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to save autosave",
+		})
+	}
+
+	// Extra snapshots are dropped on the next save if this fails
+	if err := h.pruneAutosaves(ctx, postID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to prune autosaves", zap.Error(err))
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: snapshot})
+}
+
+// pruneAutosaves deletes the snapshots of a post beyond the newest
+// AUTOSAVE_KEEP.
+func (h *Handler) pruneAutosaves(ctx context.Context, postID models.ID) error {
+	opts := options.Find().
+		SetSort(autosaveOrder).
+		SetSkip(int64(h.Config.AutosaveKeep)).
+		SetProjection(bson.M{"_id": 1})
+	cursor, err := h.DB.Autosaves.Find(ctx, bson.M{"post_id": postID}, opts)
+	if err != nil {
+		return err
+	}
+	var stale []struct {
+		ID models.ID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &stale); err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	ids := make([]models.ID, len(stale))
+	for i, doc := range stale {
+		ids[i] = doc.ID
+	}
+	_, err = h.DB.Autosaves.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+// GetAutosaves handles GET /api/posts/:id/autosave requests.
+// Lists the kept draft snapshots of a post, newest first, so an editor can
+// offer to restore unsaved work.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Response format:
+//   - 200: Success with array of Autosave objects, empty when there are none
+//   - 400: Invalid post ID
+//   - 502: Database query error
+func (h *Handler) GetAutosaves(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	snapshots := []models.Autosave{}
+	cursor, err := h.DB.Autosaves.Find(ctx, bson.M{"post_id": postID}, options.Find().SetSort(autosaveOrder))
+	if err == nil {
+		err = cursor.All(ctx, &snapshots)
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to list autosaves", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch autosaves",
+		})
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: snapshots})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/content"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// MAX_BACKLINKS caps the posts listed by GET /api/posts/:id/backlinks.
+const MAX_BACKLINKS = 100
+
+// BacklinkProjection reads the fields of a models.Backlink.
+var BacklinkProjection = bson.M{"title": 1, "created_at": 1}
+
+// GetBacklinks handles GET /api/posts/:id/backlinks requests.
+// Lists the posts whose content links to this one, newest first, so the
+// post can show where it is mentioned. Only posts the listing shows are
+// included; links are found when posts are saved (see LinkedPosts).
+//
+// URL parameters:
+//   - id: string (required) - ID of the mentioned post
+//
+// Response format:
+//   - 200: Success with array of Backlink objects, at most MAX_BACKLINKS
+//   - 400: Invalid ID format
+//   - 404: Post is private
+//   - 502: Database query error
+func (h *Handler) GetBacklinks(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_AGGREGATE)
+	defer cancel()
+
+	// Private posts are hidden from public read paths
+	if done, err := h.rejectPrivate(c, ctx, postID); done {
+		return err
+	}
+
+	filter := visibility.LISTING.BSON()
+	filter["linked_posts"] = postID
+	opts := options.Find().
+		SetProjection(BacklinkProjection).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(MAX_BACKLINKS)
+	cursor, err := h.DB.Posts.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch backlinks", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch backlinks",
+		})
+	}
+
+	backlinks := []models.Backlink{}
+	if err := cursor.All(ctx, &backlinks); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode backlinks", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch backlinks",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: backlinks})
+}
+
+// LinkedPosts returns the posts the content of post postID links to,
+// recognizing public links by PUBLIC_POST_URL (see content.LinkedPosts).
+// Links that are not valid IDs and links of the post to itself are
+// dropped. Never nil, so posts without links are stored as such and not
+// backfilled again.
+func (h *Handler) LinkedPosts(postID models.ID, text string) []models.ID {
+	linked := []models.ID{}
+	for _, raw := range content.LinkedPosts(text, h.Config.PublicPostURL) {
+		id, err := h.DB.IDs.Parse(raw)
+		if err == nil && id != postID {
+			linked = append(linked, id)
+		}
+	}
+	return linked
+}
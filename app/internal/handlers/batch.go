@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	pathpkg "path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/valyala/fasthttp"
+)
+
+// MAX_BATCH_REQUESTS caps the sub-requests of one POST /api/batch.
+const MAX_BATCH_REQUESTS = 20
+
+// BATCH_PATH is the batch endpoint itself, which sub-requests may not call.
+const BATCH_PATH = "/api/batch"
+
+// BATCH_LOCALS_KEY is the fiber.Ctx locals key marking sub-requests of a
+// batch. It is set on the dispatched request itself, so clients cannot
+// send or strip it.
+const BATCH_LOCALS_KEY = "batch_sub_request"
+
+// batchForwardedHeaders are copied from the batch request onto every
+// sub-request, so they share its credentials, locale, and client address.
+var batchForwardedHeaders = []string{
+	fiber.HeaderAuthorization,
+	middleware.API_KEY_HEADER,
+	POST_ACCESS_HEADER,
+	fiber.HeaderAcceptLanguage,
+	fiber.HeaderUserAgent,
+	fiber.HeaderXForwardedFor,
+	"X-Real-IP",
+}
+
+// batchMethods are the methods a sub-request may use.
+var batchMethods = map[string]bool{
+	fiber.MethodGet:    true,
+	fiber.MethodPost:   true,
+	fiber.MethodPut:    true,
+	fiber.MethodPatch:  true,
+	fiber.MethodDelete: true,
+}
+
+// Batch handles POST /api/batch requests.
+// Runs up to MAX_BATCH_REQUESTS API requests in one round trip, for
+// clients on slow networks. Sub-requests run one after another in array
+// order, through the same routes and middleware as direct requests, and
+// carry the batch request's Authorization, X-API-Key, X-Post-Access-Token,
+// Accept-Language, and client address headers. A failing sub-request does
+// not stop the others; each reports its own status. Batches cannot be
+// nested, however the path is spelled, so one request never fans out
+// beyond MAX_BATCH_REQUESTS.
+//
+// Request body should contain a JSON array of BatchSubRequest:
+//   - method: string (required) - GET, POST, PUT, PATCH, or DELETE
+//   - path: string (required) - path under /api with query string; /api/batch itself is refused
+//   - body: any (optional) - JSON body of the sub-request
+//
+// Response format:
+//   - 200: Success with array of BatchResponse objects in request order
+//   - 400: Invalid JSON, an empty or too long batch, an invalid sub-request, or a nested batch
+func (h *Handler) Batch(c *fiber.Ctx) error {
+	// Paths the validation missed still reach here as sub-requests
+	if c.Locals(BATCH_LOCALS_KEY) != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Batches cannot be nested",
+		})
+	}
+
+	var requests []models.BatchSubRequest
+	if err := json.Unmarshal(c.Body(), &requests); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON, expected an array of requests",
+		})
+	}
+	if len(requests) == 0 || len(requests) > MAX_BATCH_REQUESTS {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("A batch holds 1 to %d requests", MAX_BATCH_REQUESTS),
+		})
+	}
+	for i := range requests {
+		if message := validateSubRequest(&requests[i]); message != "" {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Request %d: %s", i, message),
+			})
+		}
+	}
+
+	dispatch := c.App().Handler()
+	responses := make([]models.BatchResponse, len(requests))
+	for i, sub := range requests {
+		responses[i] = runSubRequest(c, dispatch, sub)
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: responses})
+}
+
+// validateSubRequest normalizes the method of sub and checks its target.
+//
+// Returns an error message, or an empty string when sub is valid.
+func validateSubRequest(sub *models.BatchSubRequest) string {
+	sub.Method = strings.ToUpper(sub.Method)
+	if !batchMethods[sub.Method] {
+		return "method must be GET, POST, PUT, PATCH, or DELETE"
+	}
+	path, _, _ := strings.Cut(sub.Path, "?")
+	if !strings.HasPrefix(path, "/api/") || strings.Contains(path, "..") {
+		return "path must be under /api/"
+	}
+	// Routing ignores case and repeated or trailing slashes
+	if strings.EqualFold(pathpkg.Clean(path), BATCH_PATH) {
+		return "batches cannot be nested"
+	}
+	if len(sub.Body) > 0 && !json.Valid(sub.Body) {
+		return "body must be JSON"
+	}
+	return ""
+}
+
+// runSubRequest serves sub through dispatch in-process, as if it had been
+// sent on its own with the batch request's forwarded headers.
+func runSubRequest(c *fiber.Ctx, dispatch fasthttp.RequestHandler, sub models.BatchSubRequest) models.BatchResponse {
+	var req fasthttp.Request
+	req.Header.SetMethod(sub.Method)
+	req.SetRequestURI(sub.Path)
+	req.Header.SetHost(string(c.Request().Host()))
+	for _, name := range batchForwardedHeaders {
+		if value := c.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	if len(sub.Body) > 0 {
+		req.Header.SetContentType(fiber.MIMEApplicationJSON)
+		req.SetBody(sub.Body)
+	}
+
+	var ctx fasthttp.RequestCtx
+	ctx.Init(&req, c.Context().RemoteAddr(), nil)
+	ctx.SetUserValue(BATCH_LOCALS_KEY, true)
+	dispatch(&ctx)
+
+	response := models.BatchResponse{Status: ctx.Response.StatusCode()}
+	switch body := ctx.Response.Body(); {
+	case len(body) == 0:
+	case json.Valid(body):
+		response.Body = append(json.RawMessage(nil), body...)
+	default:
+		response.Body, _ = json.Marshal(string(body))
+	}
+	return response
+}
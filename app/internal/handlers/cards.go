@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/card"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// CARD_CACHE_CONTROL lets browsers and social network crawlers keep a card
+// for a day; title edits re-render it under the same URL.
+const CARD_CACHE_CONTROL = "public, max-age=86400"
+
+// GetPostCard handles GET /api/posts/:id/card.png requests.
+// Serves the post's social-card image, the URL to put in og:image and
+// twitter:image tags. Cards are rendered when a post is published or its
+// title changes; posts older than the feature, or whose card is stale,
+// get theirs rendered on first request. Honors If-Modified-Since against
+// the time the card was rendered.
+//
+// URL parameters:
+//   - id: string (required) - ID in the configured ID_FORMAT
+//
+// Response format:
+//   - 200: The 1200x630 PNG image (image/png)
+//   - 304: Card unchanged since If-Modified-Since
+//   - 400: Invalid ID format
+//   - 404: Post not found or private
+//   - 500: Card rendering error
+//   - 502: Database query error
+func (h *Handler) GetPostCard(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	var post models.BlogPost
+	opts := options.FindOne().SetProjection(bson.M{"title": 1, "visibility": 1, "publish_at": 1})
+	err = h.DB.Posts.FindOne(ctx, bson.M{"_id": postID}, opts).Decode(&post)
+	if err == mongo.ErrNoDocuments || post.Visibility == models.VISIBILITY_PRIVATE || post.Scheduled(time.Now()) {
+		// Private and scheduled posts are only reachable through preview links
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch post for card", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+
+	// Serve the stored card unless it shows an outdated title or byline
+	var stored models.PostCard
+	err = h.DB.PostCards.FindOne(ctx, bson.M{"_id": postID}).Decode(&stored)
+	if err != nil && err != mongo.ErrNoDocuments {
+		logger.Ctx(c.Context()).Error("failed to fetch post card", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch card",
+		})
+	}
+	if err == mongo.ErrNoDocuments || stored.Title != post.Title || stored.Author != h.Config.CardAuthor {
+		stored, err = h.renderCard(ctx, post)
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to render post card", zap.String("post_id", postID.String()), zap.Error(err))
+			return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to render card",
+			})
+		}
+	}
+
+	if notModified(c, stored.GeneratedAt) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, card.CONTENT_TYPE)
+	c.Set(fiber.HeaderCacheControl, CARD_CACHE_CONTROL)
+	c.Set(fiber.HeaderLastModified, stored.GeneratedAt.UTC().Format(http.TimeFormat))
+	return c.Status(http.StatusOK).Send(stored.PNG)
+}
+
+// renderCard renders the social card of post and stores it, replacing the
+// previous one. A failed store is logged and the rendered card is still
+// returned, so the request that asked for it can be served.
+func (h *Handler) renderCard(ctx context.Context, post models.BlogPost) (models.PostCard, error) {
+	png, err := card.Render(post.Title, h.Config.CardAuthor)
+	if err != nil {
+		return models.PostCard{}, err
+	}
+	stored := models.PostCard{
+		PostID:      post.ID,
+		Title:       post.Title,
+		Author:      h.Config.CardAuthor,
+		PNG:         png,
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := h.DB.PostCards.ReplaceOne(ctx, bson.M{"_id": post.ID}, stored, opts); err != nil {
+		logger.Warn("failed to store post card", zap.String("post_id", post.ID.String()), zap.Error(err))
+	}
+	return stored, nil
+}
+
+// refreshCard renders and stores the card of post in the background, so
+// the first share of a new or retitled post does not wait for rendering.
+// Failures are logged; GetPostCard renders missing cards on demand.
+func (h *Handler) refreshCard(post models.BlogPost) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.dbTimeout(DB_WRITE))
+	defer cancel()
+
+	if _, err := h.renderCard(ctx, post); err != nil {
+		logger.Warn("failed to render post card", zap.String("post_id", post.ID.String()), zap.Error(err))
+	}
+}
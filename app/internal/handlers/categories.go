@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// MAX_CATEGORY_NAME_LENGTH and MAX_CATEGORY_DESCRIPTION_LENGTH bound a
+// category, in characters, matching models.CategoryRequest's schema.
+const (
+	MAX_CATEGORY_NAME_LENGTH        = 64
+	MAX_CATEGORY_DESCRIPTION_LENGTH = 500
+)
+
+// errUnknownCategory is returned by resolveCategory when the ID is
+// malformed or names no category.
+var errUnknownCategory = errors.New("unknown category")
+
+// categorySlug derives the unique slug of a category name: lowercased,
+// with every run of characters other than letters and digits turned into
+// one hyphen, e.g. "Go & Databases" becomes "go-databases".
+func categorySlug(name string) string {
+	var slug strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return slug.String()
+}
+
+// categoryFromRequest validates a category payload and converts it to its
+// stored form: name and description trimmed, slug derived from the name.
+//
+// Returns the category without ID or timestamps, or an error message.
+func categoryFromRequest(req models.CategoryRequest) (models.Category, string) {
+	category := models.Category{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+	}
+	category.Slug = categorySlug(category.Name)
+	switch {
+	case category.Slug == "":
+		return category, "Name must contain a letter or digit"
+	case utf8.RuneCountInString(category.Name) > MAX_CATEGORY_NAME_LENGTH:
+		return category, "Name must be at most 64 characters"
+	case utf8.RuneCountInString(category.Description) > MAX_CATEGORY_DESCRIPTION_LENGTH:
+		return category, "Description must be at most 500 characters"
+	}
+	return category, ""
+}
+
+// resolveCategory checks that raw is the ID of an existing category, for
+// posts filed under it.
+//
+// Returns the parsed ID, errUnknownCategory, or a database error.
+func (h *Handler) resolveCategory(ctx context.Context, raw string) (models.ID, error) {
+	id, err := h.DB.IDs.Parse(raw)
+	if err != nil {
+		return "", errUnknownCategory
+	}
+	opts := options.FindOne().SetProjection(bson.M{"_id": 1})
+	if err := h.DB.Categories.FindOne(ctx, bson.M{"_id": id}, opts).Err(); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", errUnknownCategory
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+// GetCategories handles GET /api/categories requests.
+// Lists every category by name. Posts of one category are listed by
+// GET /api/posts?category_id=.
+//
+// Response format:
+//   - 200: Success with array of Category objects
+//   - 502: Database query error
+func (h *Handler) GetCategories(c *fiber.Ctx) error {
+	// Create context with timeout to prevent hanging database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "slug", Value: 1}})
+	cursor, err := h.DB.Categories.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch categories", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch categories",
+		})
+	}
+
+	categories := []models.Category{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode categories", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch categories",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: categories})
+}
+
+// GetCategory handles GET /api/categories/:id requests.
+//
+// URL parameters:
+//   - id: string (required) - ID of the category
+//
+// Response format:
+//   - 200: Success with the Category object
+//   - 400: Invalid ID format
+//   - 404: Category not found
+//   - 502: Database query error
+func (h *Handler) GetCategory(c *fiber.Ctx) error {
+	id, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid category ID",
+		})
+	}
+
+	// Create context with timeout to prevent hanging database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	var category models.Category
+	if err := h.DB.Categories.FindOne(ctx, bson.M{"_id": id}).Decode(&category); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Category not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to fetch category", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch category",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: category})
+}
+
+// CreateCategory handles POST /api/categories requests.
+//
+// Request body should contain a CategoryRequest:
+//   - name: string (required) - display name; its slug must be unique
+//   - description: string (optional) - short blurb
+//
+// Response format:
+//   - 200: Success with the created Category
+//   - 400: Invalid JSON, a name without letters or digits, or a too long field
+//   - 409: A category with the same slug exists
+//   - 502: Database insertion error
+func (h *Handler) CreateCategory(c *fiber.Ctx) error {
+	var req models.CategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	category, message := categoryFromRequest(req)
+	if message != "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	category.ID = h.DB.IDs.New()
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = category.CreatedAt
+
+	// The unique slug index settles concurrent creations
+	if _, err := h.DB.Categories.InsertOne(ctx, category); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return c.Status(http.StatusConflict).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Category already exists",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to create category", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to create category",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: category})
+}
+
+// UpdateCategory handles PUT /api/categories/:id requests.
+// Replaces the name and description of a category; posts keep referencing
+// it by ID.
+//
+// URL parameters:
+//   - id: string (required) - ID of the category
+//
+// Request body should contain a CategoryRequest (see CreateCategory).
+//
+// Response format:
+//   - 200: Success with the updated Category
+//   - 400: Invalid ID, invalid JSON, a name without letters or digits, or a too
+//     long field
+//   - 404: Category not found
+//   - 409: Another category has the same slug
+//   - 502: Database update error
+func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
+	id, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid category ID",
+		})
+	}
+
+	var req models.CategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	category, message := categoryFromRequest(req)
+	if message != "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	set := bson.M{"name": category.Name, "slug": category.Slug, "updated_at": time.Now()}
+	update := bson.M{"$set": set}
+	if category.Description != "" {
+		set["description"] = category.Description
+	} else {
+		update["$unset"] = bson.M{"description": ""}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err := h.DB.Categories.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&category); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Category not found",
+			})
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return c.Status(http.StatusConflict).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Category already exists",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to update category", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update category",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: category})
+}
+
+// DeleteCategory handles DELETE /api/categories/:id requests.
+// Categories still referenced by a post are kept: move or clear the
+// category of those posts first, so no post points at a missing category.
+//
+// URL parameters:
+//   - id: string (required) - ID of the category
+//
+// Response format:
+//   - 200: Category deleted
+//   - 400: Invalid ID format
+//   - 404: Category not found
+//   - 409: Posts are filed under the category
+//   - 502: Database error
+func (h *Handler) DeleteCategory(c *fiber.Ctx) error {
+	id, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid category ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	inUse, err := h.DB.Posts.CountDocuments(ctx, bson.M{"category_id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to count category posts", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to delete category",
+		})
+	}
+	if inUse > 0 {
+		return c.Status(http.StatusConflict).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Category has posts",
+		})
+	}
+
+	result, err := h.DB.Categories.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to delete category", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to delete category",
+		})
+	}
+	if result.DeletedCount == 0 {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Category not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true})
+}
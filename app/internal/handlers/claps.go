@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/schema"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_TOP_CLAPPED_LIMIT is the number of posts GET
+// /api/posts/top-clapped lists without a limit parameter.
+const DEFAULT_TOP_CLAPPED_LIMIT = 10
+
+// MAX_TOP_CLAPPED_LIMIT caps the limit parameter of GET
+// /api/posts/top-clapped.
+const MAX_TOP_CLAPPED_LIMIT = 50
+
+// MAX_TOP_CLAPPED_DAYS caps the days parameter of GET
+// /api/posts/top-clapped; longer windows read like all time anyway.
+const MAX_TOP_CLAPPED_DAYS = 365
+
+// ClapPost handles POST /api/posts/:id/clap requests.
+// Adds claps from the requester (see requesterKey). Unlike likes, a
+// requester can clap many times, in one request or several, up to
+// models.MAX_CLAPS_PER_REQUESTER per post; claps beyond the cap are
+// ignored rather than rejected, so eager clients need no special handling.
+// The cap is enforced in a single atomic update of the requester's clap
+// document, so concurrent requests cannot exceed it.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post to clap for
+//
+// Request body: ClapRequest JSON object, optional
+//
+// Response format:
+//   - 200: Success with the post's clap_count, the requester's claps, and the claps added
+//   - 400: Invalid ID format or invalid JSON
+//   - 404: Post not found, private, or scheduled for later
+//   - 422: Fields breaking the request schema, each listed in data
+//   - 502: Database error
+func (h *Handler) ClapPost(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// An empty body claps once
+	var req models.ClapRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Invalid JSON",
+			})
+		}
+	}
+	if problems := schema.Validate(&req); len(problems) > 0 {
+		return validationFailed(c, problems)
+	}
+	if req.Count == 0 {
+		req.Count = 1
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	// Private and scheduled posts answer like missing ones, so neither
+	// their existence nor their clap count leaks
+	if done, err := h.rejectUnpublished(c, ctx, postID); done {
+		return err
+	}
+
+	// Add the claps up to the cap in one update, keeping the previous total
+	// to tell how many were actually added
+	now := time.Now()
+	filter := bson.M{"post_id": postID, "user_key": h.requesterKey(c)}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"_id":        bson.M{"$ifNull": bson.A{"$_id", h.DB.IDs.New()}},
+		"created_at": bson.M{"$ifNull": bson.A{"$created_at", now}},
+		"updated_at": now,
+		"claps": bson.M{"$min": bson.A{
+			models.MAX_CLAPS_PER_REQUESTER,
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$claps", 0}}, req.Count}},
+		}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	var previous models.Clap
+	err = h.DB.Claps.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent first clap won the insert; update it instead
+		err = h.DB.Claps.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	}
+	if err != nil && err != mongo.ErrNoDocuments {
+		logger.Ctx(c.Context()).Error("failed to record claps", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to clap for post",
+		})
+	}
+	claps := min(int64(models.MAX_CLAPS_PER_REQUESTER), previous.Claps+req.Count)
+	added := claps - previous.Claps
+	if added > 0 {
+		if err := h.DB.RecordClaps(ctx, postID, added); err != nil {
+			logger.Ctx(c.Context()).Warn("failed to increment clap counts", zap.Error(err))
+		}
+	}
+
+	var post models.BlogPostHeader
+	headerOpts := options.FindOne().SetProjection(PostHeaderProjection)
+	if err := h.DB.Posts.FindOne(ctx, bson.M{"_id": postID}, headerOpts).Decode(&post); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to read clap count", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: fiber.Map{
+		"post_id":    postID,
+		"clap_count": post.ClapCount,
+		"claps":      claps,
+		"added":      added,
+	}})
+}
+
+// GetTopClapped handles GET /api/posts/top-clapped requests.
+// Lists the posts with the most claps, most first. Without days the
+// ranking is by all-time clap_count, read straight from its index; with
+// days it sums the daily clap counters (see storage.RecordClaps) of the
+// last days UTC days, today included, so the ranking follows recent
+// applause. Only posts GET /api/posts would list are included.
+//
+// Query parameters:
+//   - days: int (optional) - window in days, 1 to MAX_TOP_CLAPPED_DAYS; all time when omitted
+//   - limit: int (optional) - posts to list, default DEFAULT_TOP_CLAPPED_LIMIT,
+//     at most MAX_TOP_CLAPPED_LIMIT
+//
+// Response format:
+//   - 200: Success with array of TopClappedPost objects
+//   - 400: Malformed days or limit
+//   - 502: Database query error
+func (h *Handler) GetTopClapped(c *fiber.Ctx) error {
+	days := 0
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > MAX_TOP_CLAPPED_DAYS {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Invalid days, expected 1 to " + strconv.Itoa(MAX_TOP_CLAPPED_DAYS),
+			})
+		}
+		days = parsed
+	}
+	_, limit, err := parsePageParams(c, "page", "limit", DEFAULT_TOP_CLAPPED_LIMIT, MAX_TOP_CLAPPED_LIMIT)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination",
+		})
+	}
+
+	// Create context with timeout to prevent hanging database operations
+	ctx, cancel := h.dbContext(c, DB_AGGREGATE)
+	defer cancel()
+
+	type ranked struct {
+		Claps int64                 `bson:"claps"`
+		Post  models.BlogPostHeader `bson:"post"`
+	}
+	var posts []ranked
+	if days == 0 {
+		filter := visibility.LISTING.BSON()
+		filter["clap_count"] = bson.M{"$gt": 0}
+		sort := bson.D{{Key: "clap_count", Value: -1}, {Key: "_id", Value: -1}}
+		headers, err := h.Posts.List(ctx, filter, sort, 0, int64(limit))
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to list top-clapped posts", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to fetch posts",
+			})
+		}
+		for _, header := range headers {
+			posts = append(posts, ranked{Claps: header.ClapCount, Post: header})
+		}
+	} else {
+		// Sum the window's counters per post, then join the listable posts
+		// in ranking order until the limit is reached
+		since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+		match := visibility.LISTING.BSON()
+		match["$expr"] = bson.M{"$eq": bson.A{"$_id", "$$post_id"}}
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"day": bson.M{"$gte": since}}}},
+			{{Key: "$group", Value: bson.M{"_id": "$post_id", "claps": bson.M{"$sum": "$claps"}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "claps", Value: -1}, {Key: "_id", Value: -1}}}},
+			{{Key: "$lookup", Value: bson.M{
+				"from": h.DB.Posts.Name(),
+				"let":  bson.M{"post_id": "$_id"},
+				"pipeline": mongo.Pipeline{
+					{{Key: "$match", Value: match}},
+					{{Key: "$project", Value: PostHeaderProjection}},
+				},
+				"as": "post",
+			}}},
+			{{Key: "$unwind", Value: "$post"}},
+			{{Key: "$limit", Value: limit}},
+		}
+		cursor, err := h.DB.ClapCounts.Aggregate(ctx, pipeline)
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to aggregate claps", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to fetch posts",
+			})
+		}
+		defer cursor.Close(ctx)
+		if err := cursor.All(ctx, &posts); err != nil {
+			logger.Ctx(c.Context()).Error("failed to decode top-clapped posts", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to fetch posts",
+			})
+		}
+	}
+
+	summaries := make([]models.BlogPostSummary, len(posts))
+	for i, post := range posts {
+		summaries[i] = h.summarize(ctx, post.Post)
+	}
+	h.markLiked(ctx, h.requesterKey(c), summaries)
+
+	top := make([]models.TopClappedPost, len(posts))
+	for i, post := range posts {
+		top[i] = models.TopClappedPost{BlogPostSummary: summaries[i], Claps: post.Claps}
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: top})
+}
@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// MAX_BATCH_POST_IDS caps how many posts a single batch comment request may
+// ask for, keeping the $in filter and the response size bounded.
+const MAX_BATCH_POST_IDS = 50
+
+// commentGroup is the decoding target of the batch comments aggregation.
+type commentGroup struct {
+	PostID   models.ID        `bson:"_id"`
+	Comments []models.Comment `bson:"comments"`
+}
+
+// GetCommentsBatch handles GET /api/comments?post_ids=a,b,c requests.
+// Returns the comments of several posts grouped by post ID in a single
+// aggregation, so feed pages can render comment previews without one
+// request per post. Each group is sorted oldest first and capped by limit.
+// Private posts, and protected posts the request's access token does not
+// unlock, are reported with no comments, like posts that don't exist.
+// Comments hidden by moderation are left out.
+//
+// Query parameters:
+//   - post_ids: string (required) - comma-separated post IDs, at most MAX_BATCH_POST_IDS
+//   - limit: int (optional) - max comments per post, defaults to DEFAULT_POST_COMMENTS_LIMIT
+//   - truncate: int (optional) - shorten content to this many characters (see truncateComments)
+//
+// Response format:
+//   - 200: Success with an object mapping every requested post ID to its comments
+//   - 400: Missing, invalid, or too many post IDs, or invalid truncate
+//   - 502: Database query error
+func (h *Handler) GetCommentsBatch(c *fiber.Ctx) error {
+	// Parse and validate the requested post IDs
+	rawIDs := strings.Split(c.Query("post_ids"), ",")
+	postIDs := make([]models.ID, 0, len(rawIDs))
+	for _, raw := range rawIDs {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := h.DB.IDs.Parse(raw)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Invalid post ID",
+			})
+		}
+		postIDs = append(postIDs, id)
+	}
+	if len(postIDs) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "post_ids required",
+		})
+	}
+	if len(postIDs) > MAX_BATCH_POST_IDS {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Too many post IDs",
+		})
+	}
+
+	// Clamp the per-post limit to the same cap used by GetPost
+	limit := c.QueryInt("limit", DEFAULT_POST_COMMENTS_LIMIT)
+	if limit <= 0 || limit > DEFAULT_POST_COMMENTS_LIMIT {
+		limit = DEFAULT_POST_COMMENTS_LIMIT
+	}
+	truncate, ok := parseTruncate(c)
+	if !ok {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid truncate",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_AGGREGATE)
+	defer cancel()
+
+	// Leave private posts, and protected ones the request has not unlocked,
+	// out of the query; they still get an empty entry
+	locked, err := h.lockedAmong(c, ctx, postIDs)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to check post visibility", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comments",
+		})
+	}
+	readable := make([]models.ID, 0, len(postIDs))
+	for _, id := range postIDs {
+		if !locked[id] {
+			readable = append(readable, id)
+		}
+	}
+
+	// Fetch and group all requested comments in one query
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: storage.VisibleComments(bson.M{"post_id": bson.M{"$in": readable}})}},
+		{{Key: "$sort", Value: bson.M{"created_at": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$post_id",
+			"comments": bson.M{"$push": "$$ROOT"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"comments": bson.M{"$slice": bson.A{"$comments", limit}},
+		}}},
+	}
+
+	cursor, err := h.DB.Comments.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to aggregate batch comments", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comments",
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var groups []commentGroup
+	if err := cursor.All(ctx, &groups); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode batch comments", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comments",
+		})
+	}
+
+	// Every requested post gets an entry, even when it has no comments
+	grouped := make(map[string][]models.Comment, len(postIDs))
+	for _, id := range postIDs {
+		grouped[id.String()] = []models.Comment{}
+	}
+	for _, group := range groups {
+		grouped[group.PostID.String()] = truncateComments(group.Comments, truncate)
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: grouped})
+}
+
+// GetPostComments handles GET /api/posts/:id/comments requests, and those of
+// the embeddable comments widget.
+// Returns one page of the post's comments, oldest first and capped at
+// DEFAULT_POST_COMMENTS_LIMIT by default, with the post's total comment
+// count in the pagination metadata.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Query parameters (see parseCommentPage):
+//   - page: int (optional) - 1-based page number, default 1
+//   - limit: int (optional) - comments per page, at most DEFAULT_POST_COMMENTS_LIMIT
+//   - sort: string (optional) - oldest (default) or newest
+//   - truncate: int (optional) - shorten content to this many characters (see truncateComments)
+//
+// Response format:
+//   - 200: Success with array of Comment objects and pagination
+//   - 400: Invalid ID format, invalid pagination, or invalid truncate
+//   - 401: Post is protected and no valid access token was sent
+//   - 404: Post is private
+//   - 502: Database query error
+func (h *Handler) GetPostComments(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+	truncate, ok := parseTruncate(c)
+	if !ok {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid truncate",
+		})
+	}
+	commentPage, page, limit, err := parseCommentPage(c, "")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	// Private posts are hidden from public read paths, and protected ones
+	// need their passphrase
+	if done, err := h.rejectLocked(c, ctx, postID); done {
+		return err
+	}
+
+	// Count all comments for the page metadata
+	total, err := h.Comments.CountByPost(ctx, postID)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to count comments", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comments",
+		})
+	}
+
+	comments, err := h.Comments.List(ctx, postID, commentPage)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch comments", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comments",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    truncateComments(comments, truncate),
+		Pagination: &models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	})
+}
+
+// GetCommentSummary handles GET /api/posts/:id/comments/summary requests.
+// Returns statistics of the discussion under a post for "discussion
+// highlights": how many comments and participants it has, how many
+// comments are top-level and how many replies, the clock hour with the most
+// comments, and the comment with the most reactions. Only comments shown to
+// readers are counted. Computed in one aggregation (see
+// storage.MongoCommentRepository.Summarize).
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Query parameters:
+//   - truncate: int (optional) - shorten the most-reacted comment's content to this many characters (see truncateComments)
+//
+// Response format:
+//   - 200: Success with a CommentThreadSummary object
+//   - 400: Invalid ID format or invalid truncate
+//   - 401: Post is protected and no valid access token was sent
+//   - 404: Post is private
+//   - 502: Database query error
+func (h *Handler) GetCommentSummary(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+	truncate, ok := parseTruncate(c)
+	if !ok {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid truncate",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_AGGREGATE)
+	defer cancel()
+
+	// Private posts are hidden from public read paths, and protected ones
+	// need their passphrase
+	if done, err := h.rejectLocked(c, ctx, postID); done {
+		return err
+	}
+
+	summary, err := h.Comments.Summarize(ctx, postID)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to summarize comments", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to summarize comments",
+		})
+	}
+	if summary.MostReacted != nil {
+		summary.MostReacted.Comment = truncateComments([]models.Comment{summary.MostReacted.Comment}, truncate)[0]
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: summary})
+}
+
+// GetComment handles GET /api/comments/:id requests.
+// Returns a single comment with its full content, so clients that listed
+// comments with truncate= can expand the ones flagged has_more.
+//
+// URL parameters:
+//   - id: string (required) - ID of the comment
+//
+// Response format:
+//   - 200: Success with the Comment object
+//   - 400: Invalid ID format
+//   - 401: Comment belongs to a protected post and no valid access token was sent
+//   - 404: Comment not found, hidden by moderation, or it belongs to a private post
+//   - 502: Database query error
+func (h *Handler) GetComment(c *fiber.Ctx) error {
+	// Parse and validate the comment ID from URL parameters
+	commentID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid comment ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	var comment models.Comment
+	err = h.DB.Comments.FindOne(ctx, storage.VisibleComments(bson.M{"_id": commentID})).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Comment not found",
+		})
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch comment", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comment",
+		})
+	}
+
+	// Comments of private posts are as hidden as the post itself
+	private, err := h.isPrivate(ctx, comment.PostID)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to check post visibility", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comment",
+		})
+	}
+	if private {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Comment not found",
+		})
+	}
+	if done, err := h.requirePostPassphrase(c, ctx, comment.PostID); done {
+		return err
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: comment})
+}
+
+// parseTruncate reads the truncate query parameter: the number of characters
+// comment content is shortened to. Zero or absent means no truncation.
+// Returns false when the value is not a non-negative integer.
+func parseTruncate(c *fiber.Ctx) (int, bool) {
+	raw := c.Query("truncate")
+	if raw == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 0 {
+		return 0, false
+	}
+	return limit, true
+}
+
+// truncateComments returns a copy of comments whose content is shortened to
+// at most limit characters, preferring to cut at a word boundary, and marks
+// shortened comments with HasMore, replies included. The input is never
+// modified, because joined comments may be shared between requests (see
+// Handler.Reads). A limit of zero returns comments unchanged.
+func truncateComments(comments []models.Comment, limit int) []models.Comment {
+	if limit <= 0 {
+		return comments
+	}
+
+	truncated := make([]models.Comment, len(comments))
+	for i, comment := range comments {
+		if utf8.RuneCountInString(comment.Content) > limit {
+			comment.Content = truncateText(comment.Content, limit)
+			comment.HasMore = true
+		}
+		if len(comment.Replies) > 0 {
+			comment.Replies = truncateComments(comment.Replies, limit)
+		}
+		truncated[i] = comment
+	}
+	return truncated
+}
+
+// truncateText cuts text to limit characters. When a word boundary exists in
+// the second half of the kept text it cuts there instead, so words are not
+// split mid-way.
+func truncateText(text string, limit int) string {
+	// Find the byte offset of the first rune past the limit
+	cut := len(text)
+	runes := 0
+	for offset := range text {
+		if runes == limit {
+			cut = offset
+			break
+		}
+		runes++
+	}
+	kept := text[:cut]
+
+	if space := strings.LastIndexFunc(kept, unicode.IsSpace); space > len(kept)/2 {
+		kept = kept[:space]
+	}
+	return strings.TrimRightFunc(kept, unicode.IsSpace)
+}
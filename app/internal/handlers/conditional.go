@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// notModified sets the Last-Modified header from lastModified and reports
+// whether the request's If-Modified-Since allows answering 304 Not Modified.
+// HTTP dates have one-second precision, so lastModified is truncated before
+// comparison. A zero lastModified means "unknown" and never matches.
+// Requests with If-None-Match never match either: as per RFC 9110 the
+// entity tag takes precedence, so it is left to etagMatches.
+//
+// Parameters:
+//   - c: Fiber context of the GET request being answered
+//   - lastModified: time the requested representation last changed
+//
+// Returns true if the caller should respond with 304 and no body.
+func notModified(c *fiber.Ctx, lastModified time.Time) bool {
+	if lastModified.IsZero() {
+		return false
+	}
+
+	lastModified = setLastModified(c, lastModified)
+
+	since := c.Get(fiber.HeaderIfModifiedSince)
+	if since == "" || c.Get(fiber.HeaderIfNoneMatch) != "" {
+		return false
+	}
+	sinceTime, err := http.ParseTime(since)
+	if err != nil {
+		// Unparseable dates are ignored as per RFC 9110
+		return false
+	}
+	return !lastModified.After(sinceTime)
+}
+
+// setLastModified sets the Last-Modified header from lastModified, for
+// representations validated by their entity tag alone, and returns
+// lastModified truncated to the one-second precision of HTTP dates.
+func setLastModified(c *fiber.Ctx, lastModified time.Time) time.Time {
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	c.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	return lastModified
+}
+
+// weakETag builds a weak entity tag from the parts identifying a
+// representation, e.g. W/"9f86d081884c7d65". Weak tags fit the API's JSON
+// responses, which are equivalent rather than byte-identical across
+// encodings and plugin versions.
+func weakETag(parts ...string) string {
+	hash := fnv.New64a()
+	for _, part := range parts {
+		// The separator keeps ("ab", "c") and ("a", "bc") apart
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return fmt.Sprintf(`W/"%016x"`, hash.Sum64())
+}
+
+// listingETag builds the weak entity tag of a page of post summaries from
+// the listing's last-modified time, the IDs on the page with the fields
+// shown for them, and extra parts such as the page metadata. The fields are
+// hashed too because view filters and a zero lastModified would otherwise
+// miss counter changes.
+func listingETag(lastModified time.Time, summaries []models.BlogPostSummary, extra ...string) string {
+	parts := append([]string{lastModified.UTC().Format(time.RFC3339Nano)}, extra...)
+	for _, post := range summaries {
+		parts = append(parts, fmt.Sprintf("%s:%s:%d:%d:%d:%d:%t:%s", post.ID, post.Title, post.TitleVariant,
+			post.CommentCount, post.LikeCount, post.ClapCount, post.Liked, post.Language))
+	}
+	return weakETag(parts...)
+}
+
+// etagMatches sets the ETag header to etag and reports whether the
+// request's If-None-Match allows answering 304 Not Modified: it lists etag
+// or is "*". Comparison is weak as per RFC 9110, so the W/ prefix is
+// ignored on both sides.
+//
+// Parameters:
+//   - c: Fiber context of the GET request being answered
+//   - etag: entity tag of the requested representation, see weakETag
+//
+// Returns true if the caller should respond with 304 and no body.
+func etagMatches(c *fiber.Ctx, etag string) bool {
+	c.Set(fiber.HeaderETag, etag)
+
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
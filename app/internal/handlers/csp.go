@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// MAX_CSP_REPORTS is the most violation reports accepted in one request;
+// the Reporting API batches several reports per delivery.
+const MAX_CSP_REPORTS = 100
+
+// MAX_CSP_FIELD_LENGTH caps each stored report field, in bytes.
+const MAX_CSP_FIELD_LENGTH = 1024
+
+// CSP_STATS_LIMIT is the number of violation groups shown in admin stats.
+const CSP_STATS_LIMIT = 50
+
+// legacyCSPReport is the body browsers send to a report-uri endpoint.
+type legacyCSPReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport is one entry of a Reporting API (report-to) delivery.
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+	} `json:"body"`
+}
+
+// parseCSPReports decodes either report format into stored reports.
+// Reporting API entries of other types are ignored.
+func parseCSPReports(body []byte) ([]models.CSPReport, bool) {
+	var batch []reportingAPIReport
+	if err := json.Unmarshal(body, &batch); err == nil {
+		reports := make([]models.CSPReport, 0, len(batch))
+		for _, entry := range batch {
+			if entry.Type != "csp-violation" {
+				continue
+			}
+			reports = append(reports, models.CSPReport{
+				DocumentURI:        entry.Body.DocumentURL,
+				BlockedURI:         entry.Body.BlockedURL,
+				EffectiveDirective: entry.Body.EffectiveDirective,
+				Disposition:        entry.Body.Disposition,
+				SourceFile:         entry.Body.SourceFile,
+				LineNumber:         entry.Body.LineNumber,
+			})
+		}
+		return reports, true
+	}
+
+	var legacy legacyCSPReport
+	if err := json.Unmarshal(body, &legacy); err != nil {
+		return nil, false
+	}
+	directive := legacy.Report.EffectiveDirective
+	if directive == "" {
+		directive = legacy.Report.ViolatedDirective
+	}
+	return []models.CSPReport{{
+		DocumentURI:        legacy.Report.DocumentURI,
+		BlockedURI:         legacy.Report.BlockedURI,
+		EffectiveDirective: directive,
+		Disposition:        legacy.Report.Disposition,
+		SourceFile:         legacy.Report.SourceFile,
+		LineNumber:         legacy.Report.LineNumber,
+	}}, true
+}
+
+// reportURI drops the query and fragment of a reported URL, which can
+// carry tokens such as preview links, and caps its length. Values that are
+// not URLs ("inline", "eval") are kept as they are.
+func reportURI(raw string) string {
+	if parsed, err := url.Parse(raw); err == nil && parsed.Scheme != "" {
+		parsed.RawQuery = ""
+		parsed.Fragment = ""
+		raw = parsed.String()
+	}
+	return capField(raw)
+}
+
+// capField shortens s to MAX_CSP_FIELD_LENGTH bytes.
+func capField(s string) string {
+	if len(s) > MAX_CSP_FIELD_LENGTH {
+		return s[:MAX_CSP_FIELD_LENGTH]
+	}
+	return s
+}
+
+// CSPReport handles POST /api/csp-report requests.
+// Ingests Content-Security-Policy violation reports sent by browsers,
+// either from report-uri (application/csp-report) or from the Reporting
+// API (application/reports+json). A CSP_REPORT_SAMPLE_RATE fraction of
+// reports is stored for CSP_REPORT_RETENTION, weighted so the counts in
+// GET /api/admin/stats estimate the real totals. Query strings and
+// fragments are dropped from reported URLs.
+//
+// Response format:
+//   - 204: Reports received (stored or sampled out)
+//   - 400: Body is not a CSP report, or more than MAX_CSP_REPORTS reports
+//   - 502: Database insertion error
+func (h *Handler) CSPReport(c *fiber.Ctx) error {
+	reports, ok := parseCSPReports(c.Body())
+	if !ok || len(reports) > MAX_CSP_REPORTS {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid CSP report",
+		})
+	}
+
+	// Keep a sample, each stored report standing for 1/rate received ones
+	rate := h.Config.CSPReportSampleRate
+	if rate <= 0 {
+		return c.SendStatus(http.StatusNoContent)
+	}
+	now := time.Now()
+	sampled := make([]any, 0, len(reports))
+	for _, report := range reports {
+		if rate < 1 && rand.Float64() >= rate {
+			continue
+		}
+		report.ID = h.DB.IDs.New()
+		report.DocumentURI = reportURI(report.DocumentURI)
+		report.BlockedURI = reportURI(report.BlockedURI)
+		report.SourceFile = reportURI(report.SourceFile)
+		report.EffectiveDirective = capField(report.EffectiveDirective)
+		report.Disposition = capField(report.Disposition)
+		report.Weight = 1 / min(rate, 1)
+		report.ReceivedAt = now
+		sampled = append(sampled, report)
+	}
+	if len(sampled) == 0 {
+		return c.SendStatus(http.StatusNoContent)
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	if _, err := h.DB.CSPReports.InsertMany(ctx, sampled); err != nil {
+		logger.Ctx(c.Context()).Error("failed to store csp reports", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to store CSP report",
+		})
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// cspViolationCounts aggregates stored CSP reports by directive and blocked
+// resource, most frequent first, capped at CSP_STATS_LIMIT groups.
+func (h *Handler) cspViolationCounts(ctx context.Context) ([]models.CSPViolationCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":       bson.M{"effective_directive": "$effective_directive", "blocked_uri": "$blocked_uri"},
+			"count":     bson.M{"$sum": "$weight"},
+			"last_seen": bson.M{"$max": "$received_at"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "last_seen", Value: -1}}}},
+		{{Key: "$limit", Value: CSP_STATS_LIMIT}},
+		{{Key: "$project", Value: bson.M{
+			"_id":                 0,
+			"effective_directive": "$_id.effective_directive",
+			"blocked_uri":         "$_id.blocked_uri",
+			"count":               bson.M{"$round": bson.A{"$count", 0}},
+			"last_seen":           1,
+		}}},
+	}
+
+	cursor, err := h.DB.CSPReports.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	counts := []models.CSPViolationCount{}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// OPENAPI_PATH is the path of the OpenAPI document the Swagger UI loads.
+const OPENAPI_PATH = "/api/openapi.json"
+
+// swaggerUITemplate renders the API explorer page.
+var swaggerUITemplate = template.Must(template.ParseFS(embedTemplates, "templates/swagger_ui.html"))
+
+// SwaggerUI handles GET /api/docs requests.
+// Returns a Swagger UI page exploring the OpenAPI document at
+// OPENAPI_PATH, so consumers can browse and try the API from a browser.
+// The page loads its scripts and styles from SWAGGER_UI_URL.
+//
+// Response format:
+//   - 200: HTML page
+//   - 500: Template rendering error
+func (h *Handler) SwaggerUI(c *fiber.Ctx) error {
+	var page bytes.Buffer
+	if err := swaggerUITemplate.Execute(&page, fiber.Map{
+		"AssetsURL": strings.TrimSuffix(h.Config.SwaggerUIURL, "/"),
+		"SpecURL":   OPENAPI_PATH,
+	}); err != nil {
+		logger.Ctx(c.Context()).Error("failed to render swagger ui", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to render API docs",
+		})
+	}
+
+	c.Type("html", "utf-8")
+	return c.Send(page.Bytes())
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// EMBED_API_BASE is the path prefix of the JSON endpoints used by the
+// embeddable comments widget. They are served with permissive CORS.
+const EMBED_API_BASE = "/embed/api"
+
+// EMBED_TOKEN_PURPOSE scopes signed tokens to posting comments from the
+// embed widget.
+const EMBED_TOKEN_PURPOSE = "embed-comment"
+
+// EMBED_TOKEN_TTL is how long the comment form of a widget page can be
+// submitted after the page was served.
+const EMBED_TOKEN_TTL = 2 * time.Hour
+
+//go:embed templates/embed_comments.html templates/swagger_ui.html
+var embedTemplates embed.FS
+
+// embedCommentsTemplate renders the iframe-ready comments page.
+var embedCommentsTemplate = template.Must(template.ParseFS(embedTemplates, "templates/embed_comments.html"))
+
+// EmbedComments handles GET /embed/comments/:postId requests.
+// Returns a self-contained HTML page listing a post's comments with a form
+// to add one, meant to be loaded in an iframe by static sites (similar to
+// Disqus). The page talks to the JSON endpoints under EMBED_API_BASE and
+// reports its height to the parent window via postMessage. The page carries
+// an embed token for the post, good for EMBED_TOKEN_TTL, that its comment
+// form must send (see RequireEmbedToken), so it is never cached.
+//
+// URL parameters:
+//   - postId: string (required) - ID of the post
+//
+// Response format:
+//   - 200: HTML page
+//   - 400: Invalid ID format
+//   - 500: Template rendering error
+func (h *Handler) EmbedComments(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("postId"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	var page bytes.Buffer
+	if err := embedCommentsTemplate.Execute(&page, fiber.Map{
+		"PostID":  postID.String(),
+		"APIBase": EMBED_API_BASE,
+		"Token":   h.Tokens.Sign(EMBED_TOKEN_PURPOSE, postID.String(), time.Now().Add(EMBED_TOKEN_TTL)),
+	}); err != nil {
+		logger.Ctx(c.Context()).Error("failed to render embed page", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to render comments",
+		})
+	}
+
+	// Allow any site to frame the widget
+	c.Set(fiber.HeaderContentSecurityPolicy, "frame-ancestors *")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Type("html", "utf-8")
+	return c.Send(page.Bytes())
+}
+
+// RequireEmbedToken guards POST /embed/api/posts/:id/comments, which takes
+// comments without a login. It passes only requests whose token query
+// parameter is an unexpired embed token for the post, as served in the
+// widget page by EmbedComments, so comments cannot be sent to any post
+// without loading its widget first; the route's comments rate limit
+// bounds how many are sent with one page.
+//
+// Response format:
+//   - 400: Invalid ID format
+//   - 401: Missing, invalid, or expired embed token, or a token for another post
+func (h *Handler) RequireEmbedToken(c *fiber.Ctx) error {
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	subject, _, err := h.Tokens.Verify(EMBED_TOKEN_PURPOSE, c.Query("token"))
+	if err != nil || subject != postID.String() {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid or expired embed token, reload the page",
+		})
+	}
+	return c.Next()
+}
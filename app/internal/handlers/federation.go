@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/federation"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// OUTBOX_SIZE is the number of most recent posts listed in the outbox.
+const OUTBOX_SIZE = 20
+
+// WEBFINGER_CONTENT_TYPE is the media type of WebFinger responses.
+const WEBFINGER_CONTENT_TYPE = "application/jrd+json"
+
+// federationDisabled answers 404 when federation is not configured.
+// Returns true when a response was written.
+func (h *Handler) federationDisabled(c *fiber.Ctx) (bool, error) {
+	if h.Federation != nil {
+		return false, nil
+	}
+	return true, c.Status(http.StatusNotFound).JSON(models.APIResponse{
+		Success: false,
+		Error:   "Federation disabled",
+	})
+}
+
+// WebFinger handles GET /.well-known/webfinger requests.
+// Resolves the blog's handle to its ActivityPub actor, so Fediverse users
+// can follow "@username@host".
+//
+// Query parameters:
+//   - resource: string (required) - "acct:username@host"
+//
+// Response format:
+//   - 200: JRD document linking to the actor
+//   - 400: Missing resource
+//   - 404: Unknown account, or federation disabled
+func (h *Handler) WebFinger(c *fiber.Ctx) error {
+	if done, err := h.federationDisabled(c); done {
+		return err
+	}
+
+	resource := c.Query("resource")
+	if resource == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "resource required",
+		})
+	}
+	subject := "acct:" + h.Federation.Username + "@" + h.Federation.Domain()
+	if !strings.EqualFold(resource, subject) && resource != h.Federation.ActorIRI() {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Account not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"subject": subject,
+		"aliases": []string{h.Federation.ActorIRI()},
+		"links": []fiber.Map{{
+			"rel":  "self",
+			"type": federation.ACTIVITY_CONTENT_TYPE,
+			"href": h.Federation.ActorIRI(),
+		}},
+	}, WEBFINGER_CONTENT_TYPE)
+}
+
+// GetActor handles GET /ap/actor requests.
+// Returns the blog's actor document with its inbox, outbox, and public key.
+//
+// Response format:
+//   - 200: ActivityPub Person
+//   - 404: Federation disabled
+//   - 500: Key encoding error
+func (h *Handler) GetActor(c *fiber.Ctx) error {
+	if done, err := h.federationDisabled(c); done {
+		return err
+	}
+
+	actor, err := h.Federation.Actor()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to build actor",
+		})
+	}
+	return c.JSON(actor, federation.ACTIVITY_CONTENT_TYPE)
+}
+
+// GetOutbox handles GET /ap/outbox requests.
+// Returns the OUTBOX_SIZE newest federated posts as Create activities.
+//
+// Response format:
+//   - 200: ActivityPub OrderedCollection
+//   - 404: Federation disabled
+//   - 502: Database query error
+func (h *Handler) GetOutbox(c *fiber.Ctx) error {
+	if done, err := h.federationDisabled(c); done {
+		return err
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	total, err := h.DB.Posts.CountDocuments(ctx, visibility.PUBLISHED.BSON())
+	if err != nil {
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch posts",
+		})
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(OUTBOX_SIZE)
+	cursor, err := h.DB.Posts.Find(ctx, visibility.PUBLISHED.BSON(), opts)
+	if err != nil {
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch posts",
+		})
+	}
+	var posts []models.BlogPost
+	if err := cursor.All(ctx, &posts); err != nil {
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch posts",
+		})
+	}
+
+	items := make([]map[string]any, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, h.Federation.Create(post))
+	}
+	return c.JSON(fiber.Map{
+		"@context":     federation.AS_CONTEXT,
+		"id":           h.Federation.BaseURL + "/ap/outbox",
+		"type":         "OrderedCollection",
+		"totalItems":   total,
+		"orderedItems": items,
+	}, federation.ACTIVITY_CONTENT_TYPE)
+}
+
+// GetArticle handles GET /ap/posts/:id requests.
+// Returns a federated post as an ActivityPub Article, the object replies
+// point at with inReplyTo.
+//
+// URL parameters:
+//   - id: string (required) - ID in the configured ID_FORMAT
+//
+// Response format:
+//   - 200: ActivityPub Article
+//   - 400: Invalid ID format
+//   - 404: Post not found or not federated, or federation disabled
+//   - 500: Database query error
+func (h *Handler) GetArticle(c *fiber.Ctx) error {
+	if done, err := h.federationDisabled(c); done {
+		return err
+	}
+
+	id, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	var post models.BlogPost
+	err = h.DB.Posts.FindOne(ctx, bson.M{"_id": id}).Decode(&post)
+	if err == mongo.ErrNoDocuments || (err == nil && !federation.Publishes(post)) {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+
+	article := h.Federation.Article(post)
+	article["@context"] = federation.AS_CONTEXT
+	return c.JSON(article, federation.ACTIVITY_CONTENT_TYPE)
+}
+
+// GetFollowers handles GET /ap/followers requests.
+// Returns the follower count; like Mastodon, the followers themselves are
+// not listed.
+//
+// Response format:
+//   - 200: ActivityPub OrderedCollection without items
+//   - 404: Federation disabled
+//   - 502: Database query error
+func (h *Handler) GetFollowers(c *fiber.Ctx) error {
+	if done, err := h.federationDisabled(c); done {
+		return err
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	total, err := h.DB.Followers.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to count followers",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"@context":   federation.AS_CONTEXT,
+		"id":         h.Federation.BaseURL + "/ap/followers",
+		"type":       "OrderedCollection",
+		"totalItems": total,
+	}, federation.ACTIVITY_CONTENT_TYPE)
+}
+
+// PostInbox handles POST /ap/inbox requests.
+// Receives activities from remote servers. Requests must carry an HTTP
+// signature by the key of the activity's actor, which is fetched from the
+// actor document.
+//
+// Activities handled:
+//   - Follow of the blog actor: the follower is stored and sent an Accept
+//   - Undo of a Follow: the follower is removed
+//   - Create of a Note replying to a federated post or to a reply already
+//     received: stored as a comment (see storage.ImportComments), authored
+//     "@user@host", threaded under the reply it answers
+//   - Delete of such a Note: the comment is removed; Delete of the actor
+//     itself removes the follower
+//
+// Other activities are accepted and ignored.
+//
+// Response format:
+//   - 202: Activity accepted
+//   - 400: Body is not an activity
+//   - 401: Missing or invalid HTTP signature, or actor does not match the key
+//   - 404: Federation disabled
+//   - 502: Database error
+func (h *Handler) PostInbox(c *fiber.Ctx) error {
+	if done, err := h.federationDisabled(c); done {
+		return err
+	}
+
+	body := c.Body()
+	var activity federation.Activity
+	if err := json.Unmarshal(body, &activity); err != nil || activity.Type() == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid activity",
+		})
+	}
+
+	// Create context with timeout for the key fetch and database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	actor, err := h.verifyInbox(ctx, c, body)
+	if err != nil || actor.ID != activity.Actor() {
+		logger.Ctx(c.Context()).Warn("rejected inbox request", zap.String("actor", activity.Actor()), zap.Error(err))
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid signature",
+		})
+	}
+
+	switch activity.Type() {
+	case "Follow":
+		err = h.acceptFollow(ctx, actor, activity)
+	case "Undo":
+		if object := activity.Object(); object.Type() == "Follow" && object.Actor() == actor.ID {
+			_, err = h.DB.Followers.DeleteOne(ctx, bson.M{"_id": actor.ID})
+		}
+	case "Create":
+		if object := activity.Object(); object.Type() == "Note" {
+			err = h.receiveReply(ctx, actor, object)
+		}
+	case "Delete":
+		err = h.deleteReply(ctx, actor, activity.Object().ID())
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to process activity", zap.String("type", activity.Type()), zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to process activity",
+		})
+	}
+	return c.SendStatus(http.StatusAccepted)
+}
+
+// verifyInbox checks the request's HTTP signature against the public key
+// of the actor owning the signing key, and returns that actor.
+func (h *Handler) verifyInbox(ctx context.Context, c *fiber.Ctx, body []byte) (*federation.RemoteActor, error) {
+	sig, err := federation.ParseSignature(c.Get("Signature"))
+	if err != nil {
+		return nil, err
+	}
+	actor, err := h.Federation.FetchActor(ctx, sig.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.PublicKey.ID != sig.KeyID || actor.PublicKey.Owner != actor.ID {
+		return nil, federation.ErrBadSignature
+	}
+	key, err := federation.ParsePublicKey(actor.PublicKey.PublicKeyPem)
+	if err != nil {
+		return nil, err
+	}
+	get := func(name string) string { return c.Get(name) }
+	if err := federation.Verify(sig, c.Method(), c.OriginalURL(), get, body, key); err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+// acceptFollow stores a follower of the blog actor and delivers an Accept
+// in the background. Follows of anything else are ignored.
+func (h *Handler) acceptFollow(ctx context.Context, actor *federation.RemoteActor, follow federation.Activity) error {
+	if follow.Object().ID() != h.Federation.ActorIRI() || actor.Inbox == "" {
+		return nil
+	}
+
+	// Deliver posts to the shared inbox when the server has one
+	inbox := actor.Endpoints.SharedInbox
+	if inbox == "" {
+		inbox = actor.Inbox
+	}
+	_, err := h.DB.Followers.UpdateOne(ctx,
+		bson.M{"_id": actor.ID},
+		bson.M{
+			"$set":         bson.M{"inbox": inbox},
+			"$setOnInsert": bson.M{"followed_at": time.Now()},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	accept := h.Federation.Accept(follow)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), federation.DELIVERY_TIMEOUT)
+		defer cancel()
+		if err := h.Federation.Deliver(ctx, actor.Inbox, accept); err != nil {
+			logger.Warn("failed to deliver accept", zap.String("actor", actor.ID), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// receiveReply stores a Note replying to a federated post, or to a reply
+// already stored, as a comment. The Note IRI becomes the comment's
+// import_id, so redelivered Notes are skipped. Notes replying to anything
+// else are ignored.
+func (h *Handler) receiveReply(ctx context.Context, actor *federation.RemoteActor, note federation.Activity) error {
+	inReplyTo := note.String("inReplyTo")
+	if note.ID() == "" || inReplyTo == "" {
+		return nil
+	}
+
+	// The reply targets either one of our Articles or a stored reply
+	var postID models.ID
+	parentImportID := ""
+	if raw, ok := h.Federation.PostIDFromIRI(inReplyTo); ok {
+		id, err := h.DB.IDs.Parse(raw)
+		if err != nil {
+			return nil
+		}
+		postID = id
+	} else {
+		var parent models.Comment
+		err := h.DB.Comments.FindOne(ctx, bson.M{"import_id": inReplyTo}).Decode(&parent)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		postID = parent.PostID
+		parentImportID = inReplyTo
+	}
+
+	// Only federated posts with open comments accept federated replies
+	var post models.BlogPost
+	err := h.DB.Posts.FindOne(ctx, bson.M{"_id": postID}).Decode(&post)
+	if err == mongo.ErrNoDocuments || (err == nil && (!federation.Publishes(post) || post.CommentsLocked)) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Decoded character references may spell out markup again
+	text := h.Sanitizer.Sanitize(federation.PlainText(note.String("content")))
+	if text == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(text) > h.Config.CommentMaxLength {
+		text = truncateText(text, h.Config.CommentMaxLength)
+	}
+	published, err := time.Parse(time.RFC3339, note.String("published"))
+	if err != nil {
+		published = time.Now()
+	}
+
+	imported, _, err := h.DB.ImportComments(ctx, postID, []models.ImportedComment{{
+		ImportID:       note.ID(),
+		ParentImportID: parentImportID,
+		Author:         actor.Handle(),
+		Content:        text,
+		CreatedAt:      published,
+	}})
+	if imported > 0 {
+		h.Counts.Invalidate(postID.String())
+	}
+	return err
+}
+
+// deleteReply handles a Delete: the actor itself leaves the followers, and
+// a deleted Note removes the comment it was stored as. Notes are only
+// deleted by actors on the server that hosts them.
+func (h *Handler) deleteReply(ctx context.Context, actor *federation.RemoteActor, objectID string) error {
+	if objectID == actor.ID {
+		_, err := h.DB.Followers.DeleteOne(ctx, bson.M{"_id": actor.ID})
+		return err
+	}
+
+	objectURL, err := url.Parse(objectID)
+	actorURL, _ := url.Parse(actor.ID)
+	if err != nil || objectURL.Host != actorURL.Host {
+		return nil
+	}
+
+	var comment models.Comment
+	err = h.DB.Comments.FindOneAndDelete(ctx, bson.M{"import_id": objectID}).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	h.Counts.Invalidate(comment.PostID.String())
+	return h.DB.RecordCommentChange(ctx, comment.PostID, -1)
+}
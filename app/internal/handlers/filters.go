@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/i18n"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// errInvalidFilter is returned when a listing filter query parameter
+// cannot be parsed.
+var errInvalidFilter = errors.New("invalid filter")
+
+// errHiddenUnauthorized is returned when include_hidden=true is sent by an
+// unauthenticated caller.
+var errHiddenUnauthorized = errors.New("include_hidden requires authentication")
+
+// errHiddenForbidden is returned when include_hidden=true is sent by an
+// authenticated caller who is not a site administrator.
+var errHiddenForbidden = errors.New("include_hidden requires an administrator")
+
+// postListFilter builds the MongoDB filter for GET /api/posts from query
+// parameters. Engagement filters run against the denormalized counters on
+// the post document, which are indexed (see storage.ensureIndexes).
+//
+// Posts are restricted by base (see the visibility package), with archived
+// posts also included when include_archived=true. Site administrators (see
+// middleware.IsAdmin) may send include_hidden=true to include every post,
+// whatever its visibility.
+//
+// Query parameters:
+//   - include_archived: bool (optional) - also list archived posts
+//   - include_hidden: bool (optional) - also list unlisted, private,
+//     archived, protected, and scheduled posts; requires an administrator login
+//   - tag: string (optional) - only posts carrying this tag, matched case-insensitively
+//   - category_id: string (optional) - only posts filed under this category
+//   - lang: string (optional) - only posts detected to be in this language; region
+//     subtags are ignored, so pt-BR lists the posts in pt
+//   - has_comments: bool (optional) - only posts with (true) or without (false) comments
+//   - min_comments: int (optional) - only posts with at least this many comments
+//   - min_views: int (optional) - only posts read at least this many times
+//
+// Returns the filter, errInvalidFilter if a parameter is malformed, or
+// errHiddenUnauthorized or errHiddenForbidden.
+func (h *Handler) postListFilter(c *fiber.Ctx, base visibility.Filter) (bson.M, error) {
+	if raw := c.Query("include_archived"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errInvalidFilter
+		}
+		base.Archived = base.Archived || parsed
+	}
+	if raw := c.Query("include_hidden"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errInvalidFilter
+		}
+		if parsed {
+			if !middleware.IsAdmin(c, h.Auth, h.Config.AdminUsers) {
+				if middleware.Authenticated(c, h.Auth) {
+					return nil, errHiddenForbidden
+				}
+				return nil, errHiddenUnauthorized
+			}
+			base = visibility.HIDDEN
+		}
+	}
+	filter := base.BSON()
+
+	// Tags are stored normalized (see cleanPostTags)
+	if raw := c.Query("tag"); raw != "" {
+		filter["tags"] = strings.ToLower(strings.Join(strings.Fields(raw), " "))
+	}
+	if raw := c.Query("category_id"); raw != "" {
+		categoryID, err := h.DB.IDs.Parse(raw)
+		if err != nil {
+			return nil, errInvalidFilter
+		}
+		filter["category_id"] = categoryID
+	}
+	// Detected languages are primary tags (see i18n.Detect)
+	if raw := c.Query("lang"); raw != "" {
+		if !i18n.Valid(raw) {
+			return nil, errInvalidFilter
+		}
+		primary, _, _ := strings.Cut(i18n.Normalize(raw), "-")
+		filter["language"] = primary
+	}
+
+	commentCount := bson.M{}
+	if raw := c.Query("has_comments"); raw != "" {
+		hasComments, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errInvalidFilter
+		}
+		if hasComments {
+			commentCount["$gt"] = 0
+		} else {
+			// Matches zero as well as posts missing the counter
+			commentCount["$not"] = bson.M{"$gt": 0}
+		}
+	}
+	if raw := c.Query("min_comments"); raw != "" {
+		minComments, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || minComments < 0 {
+			return nil, errInvalidFilter
+		}
+		commentCount["$gte"] = minComments
+	}
+	if len(commentCount) > 0 {
+		filter["comment_count"] = commentCount
+	}
+
+	if raw := c.Query("min_views"); raw != "" {
+		minViews, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || minViews < 0 {
+			return nil, errInvalidFilter
+		}
+		filter["view_count"] = bson.M{"$gte": minViews}
+	}
+
+	return filter, nil
+}
+
+// DEFAULT_POSTS_PAGE_SIZE and MAX_POSTS_PAGE_SIZE bound how many summaries
+// one GET /api/posts page returns.
+const (
+	DEFAULT_POSTS_PAGE_SIZE = 20
+	MAX_POSTS_PAGE_SIZE     = 100
+)
+
+// errInvalidPagination is returned when page or limit cannot be parsed.
+var errInvalidPagination = errors.New("invalid pagination")
+
+// parsePage reads the page and limit query parameters. Page defaults to 1
+// and limit to DEFAULT_POSTS_PAGE_SIZE; limits above MAX_POSTS_PAGE_SIZE
+// are clamped to it.
+//
+// Query parameters:
+//   - page: int (optional) - 1-based page number
+//   - limit: int (optional) - items per page
+//
+// Returns the page and limit, or errInvalidPagination if either is not a
+// positive integer.
+func parsePage(c *fiber.Ctx) (int, int, error) {
+	return parsePageParams(c, "page", "limit", DEFAULT_POSTS_PAGE_SIZE, MAX_POSTS_PAGE_SIZE)
+}
+
+// parsePageParams reads a page number and page size from the named query
+// parameters, for listings other than GET /api/posts that page with their
+// own parameter names and limits (see parsePage).
+func parsePageParams(c *fiber.Ctx, pageKey, limitKey string, defaultLimit, maxLimit int) (int, int, error) {
+	page, limit := 1, defaultLimit
+	if raw := c.Query(pageKey); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			return 0, 0, errInvalidPagination
+		}
+		page = parsed
+	}
+	if raw := c.Query(limitKey); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			return 0, 0, errInvalidPagination
+		}
+		limit = min(parsed, maxLimit)
+	}
+	return page, limit, nil
+}
+
+// Comment orderings accepted by the comments_sort and sort parameters of
+// comment listings.
+const (
+	COMMENTS_SORT_OLDEST = "oldest" // Oldest first, the default
+	COMMENTS_SORT_NEWEST = "newest" // Newest first
+)
+
+// parseCommentPage reads a page of comments from query parameters named
+// prefix+"page", prefix+"limit", and prefix+"sort": "comments_" on GET
+// /api/posts/:id, no prefix on GET /api/posts/:id/comments. Limits default
+// to and are capped at DEFAULT_POST_COMMENTS_LIMIT.
+//
+// Query parameters:
+//   - page: int (optional) - 1-based page number, default 1
+//   - limit: int (optional) - comments per page
+//   - sort: string (optional) - COMMENTS_SORT_OLDEST (default) or COMMENTS_SORT_NEWEST
+//
+// Returns the page with its 1-based number and size, or
+// errInvalidPagination for a malformed page, limit, or sort.
+func parseCommentPage(c *fiber.Ctx, prefix string) (storage.CommentPage, int, int, error) {
+	page, limit, err := parsePageParams(c, prefix+"page", prefix+"limit", DEFAULT_POST_COMMENTS_LIMIT, DEFAULT_POST_COMMENTS_LIMIT)
+	if err != nil {
+		return storage.CommentPage{}, 0, 0, err
+	}
+	comments := storage.CommentPage{Skip: int64((page - 1) * limit), Limit: int64(limit)}
+	switch c.Query(prefix + "sort") {
+	case "", COMMENTS_SORT_OLDEST:
+	case COMMENTS_SORT_NEWEST:
+		comments.NewestFirst = true
+	default:
+		return storage.CommentPage{}, 0, 0, errInvalidPagination
+	}
+	return comments, page, limit, nil
+}
+
+// errInvalidCursor is returned when after cannot be parsed or is combined
+// with offset pagination or a sort.
+var errInvalidCursor = errors.New("invalid cursor")
+
+// parseCursor reads the after query parameter of cursor pagination, for
+// infinite scrolling. Its presence, even empty, selects cursor pagination:
+// posts are ordered by _id, newest first, and each page continues below the
+// ID in after, found by an index range rather than by skipping every
+// earlier post. An empty after starts at the newest post. IDs of every
+// format are time-ordered (see storage.IDCodec), so _id order follows
+// creation order, except for posts imported with an older created_at.
+//
+// Query parameters:
+//   - after: string (optional) - next_cursor of the previous page, or empty for the first page
+//
+// Returns the post ID to continue below (zero for the first page) and
+// whether cursor pagination was requested, or errInvalidCursor if after is
+// not an ID or page, sort, or order is set too.
+func (h *Handler) parseCursor(c *fiber.Ctx) (models.ID, bool, error) {
+	if !c.Context().QueryArgs().Has("after") {
+		return "", false, nil
+	}
+	if c.Query("page") != "" || c.Query("sort") != "" || c.Query("order") != "" {
+		return "", true, errInvalidCursor
+	}
+	raw := c.Query("after")
+	if raw == "" {
+		return "", true, nil
+	}
+	after, err := h.DB.IDs.Parse(raw)
+	if err != nil {
+		return "", true, errInvalidCursor
+	}
+	return after, true, nil
+}
+
+// postSortDefaults maps the sortable fields of GET /api/posts to their
+// default order: newest, most discussed, top-liked, and most-clapped
+// first, titles alphabetically.
+var postSortDefaults = map[string]int{
+	"created_at":    -1,
+	"comment_count": -1,
+	"like_count":    -1,
+	"clap_count":    -1,
+	"title":         1,
+}
+
+// errInvalidSort is returned when sort or order cannot be parsed.
+var errInvalidSort = errors.New("invalid sort")
+
+// parseSort reads the sort and order query parameters into a MongoDB sort
+// spec. _id is appended in the same direction, so posts with equal keys
+// keep a stable order across pages. Every sortable field is indexed
+// together with _id (see storage.ensureIndexes).
+//
+// Query parameters:
+//   - sort: string (optional) - created_at (default), title, comment_count, like_count, or clap_count
+//   - order: string (optional) - asc or desc; defaults to desc, asc for title
+//
+// Returns the sort spec, or errInvalidSort for an unknown field or order.
+func parseSort(c *fiber.Ctx) (bson.D, error) {
+	field := c.Query("sort", "created_at")
+	direction, ok := postSortDefaults[field]
+	if !ok {
+		return nil, errInvalidSort
+	}
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		direction = 1
+	case "desc":
+		direction = -1
+	default:
+		return nil, errInvalidSort
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}, nil
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/schema"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// FREEZE_META_ID is the meta document holding the scheduled content freeze.
+const FREEZE_META_ID = "content-freeze"
+
+// DEFAULT_AUDIT_PAGE_SIZE and MAX_AUDIT_PAGE_SIZE bound the pages of
+// GET /api/admin/audit.
+const (
+	DEFAULT_AUDIT_PAGE_SIZE = 50
+	MAX_AUDIT_PAGE_SIZE     = 200
+)
+
+// contentFreeze returns the scheduled content freeze, in effect or not, or
+// nil when none is scheduled.
+func (h *Handler) contentFreeze(ctx context.Context) (*models.ContentFreeze, error) {
+	var freeze models.ContentFreeze
+	err := h.DB.Meta.FindOne(ctx, bson.M{"_id": FREEZE_META_ID}).Decode(&freeze)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	freeze.Active = freeze.ActiveAt(time.Now())
+	return &freeze, nil
+}
+
+// ActiveFreeze returns the content freeze in effect now, or nil; it is the
+// middleware.FreezeLookup of the routes guarded by middleware.ContentFreeze.
+func (h *Handler) ActiveFreeze(ctx context.Context) (*models.ContentFreeze, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.dbTimeout(DB_READ))
+	defer cancel()
+
+	freeze, err := h.contentFreeze(ctx)
+	if err != nil || freeze == nil || !freeze.Active {
+		return nil, err
+	}
+	return freeze, nil
+}
+
+// AuditFreeze records a request rejected by a content freeze; it is the
+// middleware.FreezeAudit of the guarded routes.
+func (h *Handler) AuditFreeze(c *fiber.Ctx, freeze *models.ContentFreeze, operation string) {
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+	h.audit(c, ctx, models.AUDIT_FREEZE_BLOCKED, operation+" blocked until "+freeze.EndsAt.UTC().Format(time.RFC3339))
+}
+
+// audit appends an entry for the current request to the audit log. The
+// log is a record, not a gate: failures are logged and the request goes on.
+func (h *Handler) audit(c *fiber.Ctx, ctx context.Context, action, detail string) {
+	entry := models.AuditEntry{
+		ID:        h.DB.IDs.New(),
+		Action:    action,
+		Actor:     h.auditActor(c),
+		Method:    c.Method(),
+		Path:      c.Path(),
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+	if _, err := h.DB.AuditLog.InsertOne(ctx, entry); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to write audit entry", zap.String("action", action), zap.Error(err))
+	}
+}
+
+// auditActor identifies who made the request: the API key when there is
+// one, otherwise the requester key, which names the logged-in user.
+func (h *Handler) auditActor(c *fiber.Ctx) string {
+	if key, ok := middleware.CurrentAPIKey(c); ok {
+		return "api-key:" + key.Prefix
+	}
+	return h.requesterKey(c)
+}
+
+// PublishesNewPost reports whether a POST /api/posts request publishes a
+// post rather than saving a draft: its visibility, or the author's default
+// visibility when none is sent, is not private. Bodies that cannot be
+// parsed count as publishing; CreatePost rejects them anyway.
+func (h *Handler) PublishesNewPost(c *fiber.Ctx) bool {
+	var req models.CreatePostRequest
+	if err := c.BodyParser(&req); err != nil {
+		return true
+	}
+	if req.Visibility == "" {
+		ctx, cancel := h.dbContext(c, DB_READ)
+		defer cancel()
+		req.Visibility = h.requesterSettings(ctx, c).DefaultVisibility
+	}
+	return req.Visibility != models.VISIBILITY_PRIVATE
+}
+
+// PublishesVisibility reports whether a PUT /api/posts/:id/visibility
+// request makes a post visible, i.e. sets it anything but private.
+func (h *Handler) PublishesVisibility(c *fiber.Ctx) bool {
+	var req models.SetVisibilityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return true
+	}
+	return req.Visibility != models.VISIBILITY_PRIVATE
+}
+
+// GetContentFreeze handles GET /api/admin/freeze requests.
+// Returns the scheduled content freeze and whether it is in effect.
+//
+// Response format:
+//   - 200: Success with a ContentFreeze object
+//   - 404: No content freeze scheduled
+//   - 502: Database query error
+func (h *Handler) GetContentFreeze(c *fiber.Ctx) error {
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	freeze, err := h.contentFreeze(ctx)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to load content freeze", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch content freeze",
+		})
+	}
+	if freeze == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "No content freeze scheduled",
+		})
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: freeze})
+}
+
+// PutContentFreeze handles PUT /api/admin/freeze requests.
+// Schedules a content freeze, replacing any scheduled one. While it is in
+// effect, publishing and deleting posts is rejected (see
+// middleware.ContentFreeze); drafts and comments are unaffected. The change
+// is recorded in the audit log.
+//
+// Request body: ContentFreezeRequest JSON object
+//
+// Response format:
+//   - 200: Success with the stored ContentFreeze
+//   - 400: Invalid JSON, or an end that is not after the start and in the future
+//   - 422: Fields breaking the request schema, each listed in data
+//   - 502: Database update error
+func (h *Handler) PutContentFreeze(c *fiber.Ctx) error {
+	// Parse the request body into the expected structure
+	var req models.ContentFreezeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	if problems := schema.Validate(&req); len(problems) > 0 {
+		return validationFailed(c, problems)
+	}
+
+	now := time.Now()
+	freeze := models.ContentFreeze{
+		StartsAt:  now,
+		EndsAt:    req.EndsAt,
+		Reason:    req.Reason,
+		UpdatedAt: now,
+	}
+	if req.StartsAt != nil {
+		freeze.StartsAt = *req.StartsAt
+	}
+	if !freeze.EndsAt.After(freeze.StartsAt) || !freeze.EndsAt.After(now) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "ends_at must be after starts_at and in the future",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	if _, err := h.DB.Meta.ReplaceOne(ctx,
+		bson.M{"_id": FREEZE_META_ID},
+		freeze,
+		options.Replace().SetUpsert(true),
+	); err != nil {
+		logger.Ctx(c.Context()).Error("failed to store content freeze", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to schedule content freeze",
+		})
+	}
+	h.audit(c, ctx, models.AUDIT_FREEZE_SCHEDULED,
+		freeze.StartsAt.UTC().Format(time.RFC3339)+" to "+freeze.EndsAt.UTC().Format(time.RFC3339)+": "+freeze.Reason)
+
+	freeze.Active = freeze.ActiveAt(now)
+	return c.JSON(models.APIResponse{Success: true, Data: freeze})
+}
+
+// DeleteContentFreeze handles DELETE /api/admin/freeze requests.
+// Lifts the scheduled content freeze, in effect or not. Lifting when none
+// is scheduled is not an error; only an actual removal is audited.
+//
+// Response format:
+//   - 200: Success
+//   - 502: Database error
+func (h *Handler) DeleteContentFreeze(c *fiber.Ctx) error {
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	result, err := h.DB.Meta.DeleteOne(ctx, bson.M{"_id": FREEZE_META_ID})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to delete content freeze", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to lift content freeze",
+		})
+	}
+	if result.DeletedCount > 0 {
+		h.audit(c, ctx, models.AUDIT_FREEZE_LIFTED, "")
+	}
+	return c.JSON(models.APIResponse{Success: true})
+}
+
+// GetAuditLog handles GET /api/admin/audit requests.
+// Lists audit entries, newest first.
+//
+// Query parameters:
+//   - action: string (optional) - only entries of this action, e.g. freeze.blocked
+//   - page: int (optional) - 1-based page number
+//   - limit: int (optional) - entries per page, default DEFAULT_AUDIT_PAGE_SIZE,
+//     capped at MAX_AUDIT_PAGE_SIZE
+//
+// Response format:
+//   - 200: Success with array of AuditEntry objects and pagination
+//   - 400: Invalid pagination
+//   - 502: Database query error
+func (h *Handler) GetAuditLog(c *fiber.Ctx) error {
+	filter := bson.M{}
+	if action := c.Query("action"); action != "" {
+		filter["action"] = action
+	}
+	page, limit, err := parsePageParams(c, "page", "limit", DEFAULT_AUDIT_PAGE_SIZE, MAX_AUDIT_PAGE_SIZE)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	total, err := h.DB.AuditLog.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to count audit entries", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch audit log",
+		})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := h.DB.AuditLog.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch audit entries", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch audit log",
+		})
+	}
+	entries := []models.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode audit entries", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch audit log",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    entries,
+		Pagination: &models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	})
+}
@@ -5,39 +5,171 @@ package handlers
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/assistant"
+	"github.com/pedrobertao/challenge-prosi/app/internal/cache"
+	"github.com/pedrobertao/challenge-prosi/app/internal/config"
+	"github.com/pedrobertao/challenge-prosi/app/internal/content"
+	"github.com/pedrobertao/challenge-prosi/app/internal/federation"
+	"github.com/pedrobertao/challenge-prosi/app/internal/i18n"
+	"github.com/pedrobertao/challenge-prosi/app/internal/indexnow"
+	"github.com/pedrobertao/challenge-prosi/app/internal/integrations"
+	"github.com/pedrobertao/challenge-prosi/app/internal/jobs"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
 	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/plugins"
+	"github.com/pedrobertao/challenge-prosi/app/internal/proxy"
+	"github.com/pedrobertao/challenge-prosi/app/internal/ratelimit"
+	"github.com/pedrobertao/challenge-prosi/app/internal/sanitize"
+	"github.com/pedrobertao/challenge-prosi/app/internal/schema"
 	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/jwt"
 	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/token"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 )
 
-// DEFAULT_DB_TIMEOUT defines the maximum duration for database operations
-// to prevent hanging requests and ensure responsive API behavior.
-const DEFAULT_DB_TIMEOUT = 10 * time.Second
+// DEFAULT_POST_COMMENTS_LIMIT caps how many comments are joined into a
+// single post response to keep payloads bounded on busy threads.
+const DEFAULT_POST_COMMENTS_LIMIT = 100
 
 // Handler struct holds the database storage instance and provides
 // methods for handling HTTP requests to the blog API endpoints.
 type Handler struct {
-	DB *storage.Storage // Database storage instance for MongoDB operations
+	DB     *storage.Storage // Database storage instance for MongoDB operations
+	Config *config.Config   // Application configuration
+
+	// Posts and Comments back the core post and comment endpoints; tests
+	// replace them with mocks
+	Posts    storage.PostRepository
+	Comments storage.CommentRepository
+
+	Counts *cache.Counts // Cached per-post comment counts
+	Reads  *cache.Flight // Deduplicates identical concurrent reads
+
+	Locks      *jobs.Locker           // Leases keeping scheduled jobs on one instance
+	Duplicates *jobs.DuplicateScanner // Near-duplicate content scan job
+	Changes    *jobs.ChangeWatcher    // Cross-instance cache invalidation
+	Analytics  *jobs.ViewRecorder     // Per-post daily view and referrer rollups
+	Closer     *jobs.CommentCloser    // Scheduled comment-thread auto-close
+	Purger     *jobs.TrashPurger      // Scheduled purge of expired trash
+	Links      *jobs.LinkChecker      // Broken link detection in post content
+	Tokens     *token.Signer          // Signer for preview and access tokens
+	Auth       *jwt.Signer            // Signer for login JWTs
+	HTTP       *http.Client           // Client shared by outbound requests to third-party sites
+
+	// Assistant generates summaries and tag suggestions (nil when disabled)
+	Assistant assistant.ContentAssistant
+	// Plugins are the enabled extension hooks, run in configured order
+	Plugins *plugins.Chain
+	// Proxies resolves client IPs behind trusted reverse proxies
+	Proxies *proxy.Resolver
+	// Federation is the ActivityPub actor of the blog (nil when disabled)
+	Federation *federation.Service
+	// Integrations posts blog events to Slack and Discord channels
+	Integrations *integrations.Dispatcher
+	// IndexNow pings search engines about new and edited posts (nil when disabled)
+	IndexNow *indexnow.Client
+	// RateLimits keeps the per-IP request counts of the rate limits
+	RateLimits ratelimit.Store
+	// Sanitizer strips or escapes unsafe markup in written content
+	Sanitizer *sanitize.Policy
 }
 
+// DEFAULT_HTTP_TIMEOUT bounds outbound requests made with Handler.HTTP.
+const DEFAULT_HTTP_TIMEOUT = 10 * time.Second
+
+// PostHeaderProjection restricts list queries to the fields decoded into
+// models.BlogPostHeader, leaving post content on the server.
+var PostHeaderProjection = storage.PostHeaderProjection
+
 // New creates and returns a new Handler instance with the provided storage.
 // This is the constructor function for the Handler struct.
 //
 // Parameters:
 //   - db: pointer to a Storage instance for database operations
+//   - cfg: application configuration controlling handler behavior
 //
 // Returns a pointer to a new Handler instance.
-func New(db *storage.Storage) *Handler {
-	return &Handler{DB: db}
+func New(db *storage.Storage, cfg *config.Config) *Handler {
+	h := &Handler{
+		DB:     db,
+		Config: cfg,
+		Counts: cache.NewCounts(cfg.CommentCountCacheTTL),
+		Reads:  cache.NewFlight(),
+
+		Posts:    storage.NewPostRepository(db),
+		Comments: storage.NewCommentRepository(db),
+
+		Locks:  jobs.NewLocker(db, cfg.JobLockTTL),
+		Tokens: token.NewSigner(cfg.TokenSecret),
+		Auth:   jwt.NewSigner(cfg.JWTSecret),
+		HTTP:   &http.Client{Timeout: DEFAULT_HTTP_TIMEOUT, Transport: tracing.Transport(nil)},
+	}
+	h.Duplicates = jobs.NewDuplicateScanner(db, h.Locks, cfg.DuplicateThreshold, cfg.DuplicateScanInterval)
+	h.Changes = jobs.NewChangeWatcher(db, h.Counts, cfg.UseChangeStreams)
+	h.Analytics = jobs.NewViewRecorder(db, cfg.AnalyticsFlushInterval)
+	h.Closer = jobs.NewCommentCloser(db, h.Locks, cfg.CommentsAutoCloseDays, cfg.CommentCloseInterval)
+	h.Purger = jobs.NewTrashPurger(db, h.Locks, cfg.TrashRetention, cfg.TrashPurgeInterval)
+	h.Links = jobs.NewLinkChecker(db, h.Locks, h.HTTP, cfg.LinkCheckInterval)
+	h.Federation = federation.New(db, cfg.FederationBaseURL, cfg.FederationUsername, cfg.FederationName)
+	h.Integrations = integrations.NewDispatcher(db, cfg.PublicPostURL)
+	h.Integrations.Client = h.HTTP
+	h.Integrations.Telegram = integrations.NewTelegram(cfg.TelegramBotToken, cfg.TelegramChatID)
+	h.IndexNow = indexnow.New(cfg.IndexNowEndpoint, cfg.IndexNowKey, cfg.IndexNowKeyLocation, cfg.PublicPostURL)
+	if h.IndexNow != nil {
+		h.IndexNow.HTTP = h.HTTP
+	}
+
+	// Enable configured plugins; unknown names are reported but not fatal
+	chain, err := plugins.Enable(cfg.Plugins)
+	if err != nil {
+		logger.Warn("some plugins could not be enabled", zap.Error(err), zap.Strings("available", plugins.Available()))
+	}
+	h.Plugins = chain
+
+	// Invalid proxy entries are skipped; forwarding headers from them are ignored
+	proxies, err := proxy.NewResolver(cfg.TrustedProxies)
+	if err != nil {
+		logger.Warn("some trusted proxies are invalid", zap.Error(err))
+	}
+	h.Proxies = proxies
+
+	// Rate limit counts are shared through Redis when configured; a bad URL
+	// is reported and falls back to counting in memory
+	h.RateLimits = ratelimit.NewMemory()
+	if cfg.RateLimitRedisURL != "" {
+		redis, err := ratelimit.NewRedis(cfg.RateLimitRedisURL)
+		if err != nil {
+			logger.Warn("invalid rate limit Redis URL, counting in memory", zap.Error(err))
+		} else {
+			h.RateLimits = redis
+		}
+	}
+
+	// An unknown sanitize mode is reported and falls back to stripping
+	sanitizer, err := sanitize.New(cfg.SanitizeMode)
+	if err != nil {
+		logger.Warn("invalid sanitize mode, stripping unsafe markup", zap.Error(err))
+	}
+	h.Sanitizer = sanitizer
+
+	// The content assistant is optional and only enabled with an API key
+	if cfg.AssistantAPIKey != "" {
+		h.Assistant = assistant.NewOpenAI(cfg.AssistantAPIURL, cfg.AssistantAPIKey, cfg.AssistantModel)
+	}
+	return h
 }
 
 // GetPosts handles GET /api/posts requests.
@@ -45,56 +177,230 @@ func New(db *storage.Storage) *Handler {
 // post ID, title, comment count, and creation date.
 // This endpoint provides an overview of all posts without full content.
 //
+// Clients sending "Accept: application/x-ndjson" receive the summaries as
+// newline-delimited JSON streamed straight off the cursor (see streamPosts).
+// Honors If-Modified-Since against the collection-level last-modified time,
+// and If-None-Match against a weak ETag of the page (see listingETag), which
+// also covers view filters.
+//
+// The buffered listing is paginated, newest first unless sort says
+// otherwise, with page metadata in the response's pagination field; the
+// NDJSON stream serves every match in the same order.
+// Posts running a title test show each visitor their headline variant and
+// count an impression for it (see applyTitleTests).
+//
+// Query parameters (filters, see postListFilter):
+//   - include_archived, include_hidden, tag, category_id, lang, has_comments,
+//     min_comments, min_views
+//
+// Query parameters (pagination, see parsePage):
+//   - page: int (optional) - 1-based page number, default 1
+//   - limit: int (optional) - posts per page, default DEFAULT_POSTS_PAGE_SIZE,
+//     at most MAX_POSTS_PAGE_SIZE
+//
+// Query parameters (ordering, see parseSort):
+//   - sort: string (optional) - created_at (default), title, comment_count, like_count, or clap_count
+//   - order: string (optional) - asc or desc
+//
+// Query parameters (cursor pagination, see parseCursor):
+//   - after: string (optional) - next_cursor of the previous page, empty for the first;
+//     replaces page, sort, and order, and the response carries cursor instead of pagination
+//
 // Response format:
-//   - 200: Success with array of BlogPostSummary objects
+//   - 200: Success with array of BlogPostSummary objects and pagination or cursor
+//   - 304: Listing unchanged since If-Modified-Since, or page matching If-None-Match
+//   - 400: Malformed filter, pagination, sort, or cursor parameter
+//   - 401: include_hidden=true without authentication
+//   - 403: include_hidden=true from a user who is not an administrator
 //   - 404: No posts found (returns empty array)
 //   - 502: Database connection or query error
 func (h *Handler) GetPosts(c *fiber.Ctx) error {
+	// Build the listing filter from query parameters
+	filter, err := h.postListFilter(c, visibility.LISTING)
+	if err == errHiddenUnauthorized {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Authentication required",
+		})
+	}
+	if err == errHiddenForbidden {
+		return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Admin access required",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid filter",
+		})
+	}
+
+	page, limit, err := parsePage(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination",
+		})
+	}
+	after, cursorMode, err := h.parseCursor(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid cursor",
+		})
+	}
+	var sort bson.D
+	if cursorMode {
+		sort = bson.D{{Key: "_id", Value: -1}}
+		if !after.IsZero() {
+			filter["_id"] = bson.M{"$lt": after}
+		}
+	} else if sort, err = parseSort(c); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid sort",
+		})
+	}
+
 	// Create context with timeout to prevent hanging database operations
-	ctx, cancel := context.WithTimeout(c.Context(), DEFAULT_DB_TIMEOUT)
+	ctx, cancel := h.dbContext(c, DB_READ)
 	defer cancel()
 
-	// Fetch all posts from the database
-	cursor, err := h.DB.Posts.Find(ctx, bson.M{})
-	if err != nil {
-		if err == mongo.ErrEmptySlice {
-			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
-				Success: true,
-				Data:    []models.BlogPostSummary{},
-				Error:   "",
+	// Skip the listing query entirely when the client copy is still fresh.
+	// View counts do not bump last-modified, so view filters opt out.
+	var lastModified time.Time
+	if c.Query("min_views") == "" {
+		lastModified, err = h.Posts.LastModified(ctx)
+		if err != nil {
+			logger.Ctx(c.Context()).Warn("failed to read posts last-modified", zap.Error(err))
+		}
+		if notModified(c, lastModified) {
+			return c.SendStatus(http.StatusNotModified)
+		}
+	}
+
+	// Stream summaries instead of buffering them when NDJSON is requested
+	if wantsNDJSON(c) {
+		return h.streamPosts(c, filter, sort)
+	}
+
+	// Cursor pages skip the count and read one post more than asked for,
+	// to tell whether another page follows
+	if cursorMode {
+		headers, err := h.Posts.List(ctx, filter, sort, 0, int64(limit+1))
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to list posts", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to fetch posts",
 			})
 		}
+		cursor := &models.Cursor{Limit: limit}
+		if len(headers) > limit {
+			headers = headers[:limit]
+			cursor.NextCursor = headers[limit-1].ID
+		}
+		summaries := h.summarizeListing(ctx, c, headers)
+		if etagMatches(c, listingETag(lastModified, summaries, cursor.NextCursor.String())) {
+			return c.SendStatus(http.StatusNotModified)
+		}
+		return c.JSON(models.APIResponse{
+			Success: true,
+			Data:    h.Plugins.PreResponse(c, plugins.RESOURCE_POST_LIST, summaries),
+			Cursor:  cursor,
+		})
+	}
+
+	// Count all matches for the page metadata
+	total, err := h.Posts.Count(ctx, filter)
+	if err != nil {
 		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Failed to fetch posts",
 		})
 	}
-	defer cursor.Close(ctx)
 
+	// Fetch one page of matching posts in the requested order, with only
+	// the summary fields
+	headers, err := h.Posts.List(ctx, filter, sort, int64((page-1)*limit), int64(limit))
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to list posts", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch posts",
+		})
+	}
+
+	summaries := h.summarizeListing(ctx, c, headers)
+	if etagMatches(c, listingETag(lastModified, summaries, strconv.FormatInt(total, 10))) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    h.Plugins.PreResponse(c, plugins.RESOURCE_POST_LIST, summaries),
+		Pagination: &models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	})
+}
+
+// summarizeListing builds the summaries of a page of GET /api/posts, with
+// the requester's likes and the headlines of running title tests.
+func (h *Handler) summarizeListing(ctx context.Context, c *fiber.Ctx, headers []models.BlogPostHeader) []models.BlogPostSummary {
 	// Build summary list with comment counts for each post
 	var summaries []models.BlogPostSummary
-	for cursor.Next(ctx) {
-		var post models.BlogPost
-		if err := cursor.Decode(&post); err != nil {
-			logger.Warn("malformed post", zap.Error(err))
-			// Skip malformed posts and continue processing
-			continue
-		}
+	for _, post := range headers {
+		summaries = append(summaries, h.summarize(ctx, post))
+	}
 
-		// Count comments for this post
-		count, err := h.DB.Comments.CountDocuments(ctx, bson.M{"post_id": post.ID})
-		if err != nil {
-			logger.Error("failed to count objects", zap.Error(err))
-		}
-		summaries = append(summaries, models.BlogPostSummary{
-			ID:           post.ID,
-			Title:        post.Title,
-			CommentCount: count,
-			CreatedAt:    post.CreatedAt,
-		})
+	// Flag the posts the requester already liked
+	h.markLiked(ctx, h.requesterKey(c), summaries)
+
+	// Show each visitor their headline of posts running a title test
+	h.applyTitleTests(ctx, c, headers, summaries)
+	return summaries
+}
+
+// summarize builds the list representation of a post.
+// The comment count comes from the denormalized counter when
+// UseCommentCounter is enabled, otherwise from commentCount.
+func (h *Handler) summarize(ctx context.Context, post models.BlogPostHeader) models.BlogPostSummary {
+	count := post.CommentCount
+	if !h.Config.UseCommentCounter {
+		count = h.commentCount(ctx, post.ID)
+	}
+	return models.BlogPostSummary{
+		ID:           post.ID,
+		Title:        post.Title,
+		CommentCount: count,
+		LikeCount:    post.LikeCount,
+		ClapCount:    post.ClapCount,
+		Language:     post.Language,
+		CreatedAt:    post.CreatedAt,
+	}
+}
+
+// commentCount returns the number of comments on a post, served from the
+// in-process cache when possible and counted in MongoDB otherwise.
+// Count failures are logged and reported as zero (and not cached) so one bad
+// lookup does not fail the whole listing.
+func (h *Handler) commentCount(ctx context.Context, postID models.ID) int64 {
+	key := postID.String()
+	if count, ok := h.Counts.Get(key); ok {
+		return count
 	}
 
-	return c.JSON(models.APIResponse{Success: true, Data: summaries})
+	count, err := h.Comments.CountByPost(ctx, postID)
+	if err != nil {
+		logger.Error("failed to count objects", zap.Error(err))
+		return 0
+	}
+	h.Counts.Set(key, count)
+	return count
 }
 
 // CreatePost handles POST /api/posts requests.
@@ -102,13 +408,31 @@ func (h *Handler) GetPosts(c *fiber.Ctx) error {
 // Validates required fields and returns the created post with its generated ID.
 //
 // Request body should contain:
-//   - title: string (required) - The post title
-//   - content: string (required) - The post content
+//   - title: string (required) - The post title, at most 200 characters
+//   - content: string (required) - The post content, at most 100000 characters
+//   - visibility: string (optional) - public, unlisted, or private; defaults to
+//     the author's default_visibility setting, then public
+//   - publish_at: string (optional) - schedule the post: RFC 3339, or a local
+//     "2006-01-02T15:04" time in timezone (see parsePublishAt)
+//   - timezone: string (optional) - IANA zone of the author, stored and echoed
+//     for display; defaults to the author's timezone setting, then UTC
+//   - title_variants: []string (optional) - up to 4 alternative headlines to A/B test
+//   - tags: []string (optional) - up to MAX_POST_TAGS topic tags, normalized (see cleanPostTags)
+//   - category_id: string (optional) - ID of an existing category
+//   - comments_close_after_days: int (optional) - days after publication comments close,
+//     0 for never; defaults to COMMENTS_AUTO_CLOSE_DAYS
+//
+// Unsafe markup in the content is stripped or escaped (see
+// sanitize.Policy), then title and content are trimmed, and every field is
+// checked against the request's schema tags (see schema.Validate) before
+// anything else.
 //
 // Response format:
 //   - 200: Success with created BlogPost object
-//   - 400: Invalid JSON or missing required fields
-//   - 502: Database insertion error
+//   - 400: Invalid JSON, unknown timezone, invalid publish_at, a too long tag, an unknown
+//     category, or a negative comment window
+//   - 422: Fields breaking the request schema, each listed in data (see validationFailed)
+//   - 502: Database insertion or category lookup error
 func (h *Handler) CreatePost(c *fiber.Ctx) error {
 	// Parse the request body into the expected structure
 	var req models.CreatePostRequest
@@ -119,108 +443,431 @@ func (h *Handler) CreatePost(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate required fields
-	if req.Title == "" || req.Content == "" {
-		return c.Status(400).JSON(models.APIResponse{
+	// Let plugins inspect or reject the request before built-in validation
+	if err := h.Plugins.PreValidate(c, plugins.RESOURCE_POST, &req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
-			Error:   "Title and content required",
+			Error:   err.Error(),
 		})
 	}
 
+	// Remove or escape markup that could run script, then validate the
+	// fields against the request's schema tags
+	req.Content = h.Sanitizer.Sanitize(req.Content)
+	if problems := schema.Validate(&req); len(problems) > 0 {
+		return validationFailed(c, problems)
+	}
+
 	// Create context with timeout for database operation
-	ctx, cancel := context.WithTimeout(c.Context(), DEFAULT_DB_TIMEOUT)
+	ctx, cancel := h.dbContext(c, DB_WRITE)
 	defer cancel()
 
+	// Posts without a visibility take the author's default (see UpdateSettings)
+	if req.Visibility == "" {
+		req.Visibility = h.requesterSettings(ctx, c).DefaultVisibility
+	}
+	if req.Visibility == "" {
+		req.Visibility = models.VISIBILITY_PUBLIC
+	}
+	if !models.ValidVisibility(req.Visibility) {
+		return c.Status(400).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid visibility",
+		})
+	}
+
+	// Local publish times are read in the author's time zone
+	loc, timezone, err := h.postTimezone(ctx, c, req.Timezone)
+	if err != nil {
+		return c.Status(400).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Unknown timezone",
+		})
+	}
+
 	// Create new blog post with current timestamp
+	now := time.Now()
 	post := models.BlogPost{
-		Title:     req.Title,
-		Content:   req.Content,
-		CreatedAt: time.Now(),
+		ID:           h.DB.IDs.New(),
+		Title:        req.Title,
+		Content:      req.Content,
+		Visibility:   req.Visibility,
+		Timezone:     timezone,
+		CreatedAt:    now,
+		LastModified: now,
 	}
+	if req.PublishAt != "" {
+		publishAt, err := parsePublishAt(req.PublishAt, loc)
+		if err != nil {
+			return c.Status(400).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Invalid publish_at",
+			})
+		}
+		post.PublishAt = &publishAt
+	}
+	if variants, err := cleanTitleVariants(req.TitleVariants, req.Title); err != nil {
+		return c.Status(400).JSON(models.APIResponse{
+			Success: false,
+			Error:   "At most 4 title variants",
+		})
+	} else if len(variants) > 0 {
+		post.TitleVariants = variants
+		post.TitleTest = make([]models.TitleVariantStats, len(variants)+1)
+	}
+	if tags, err := cleanPostTags(req.Tags); err != nil {
+		return c.Status(400).JSON(models.APIResponse{
+			Success: false,
+			Error:   "At most 10 tags of at most 32 characters",
+		})
+	} else if len(tags) > 0 {
+		post.Tags = tags
+	}
+	if req.CategoryID != "" {
+		categoryID, err := h.resolveCategory(ctx, req.CategoryID)
+		if err == errUnknownCategory {
+			return c.Status(400).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Unknown category",
+			})
+		}
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to look up category", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to create post",
+			})
+		}
+		post.CategoryID = categoryID
+	}
+	if req.CommentsCloseAfterDays != nil {
+		if *req.CommentsCloseAfterDays < 0 {
+			return c.Status(400).JSON(models.APIResponse{
+				Success: false,
+				Error:   "comments_close_after_days cannot be negative",
+			})
+		}
+		post.CommentsCloseAfterDays = req.CommentsCloseAfterDays
+	}
+	stats := content.Analyze(req.Content)
+	post.Stats = &stats
+	post.LinkedPosts = h.LinkedPosts(post.ID, req.Content)
+	post.Language = i18n.Detect(content.PlainText(req.Content))
 
 	// Insert the post into the database
-	result, err := h.DB.Posts.InsertOne(ctx, post)
-	if err != nil {
+	if err := h.Posts.Insert(ctx, post); err != nil {
 		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Failed to create post",
 		})
 	}
 
-	// Set the generated ID and return the complete post
-	post.ID = result.InsertedID.(primitive.ObjectID)
-	return c.JSON(models.APIResponse{Success: true, Data: post})
+	// A new post changes the listing
+	if err := h.Posts.Touch(ctx); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch posts last-modified", zap.Error(err))
+	}
+
+	// Render the social card for the post's link previews
+	go h.refreshCard(post)
+
+	// Announce public posts to Fediverse followers, chat integrations,
+	// saved searches, and search engines in the background
+	if federation.Publishes(post) {
+		go h.alertSavedSearches(post)
+		if h.Federation != nil {
+			go h.Federation.Publish(context.Background(), post)
+		}
+		h.Integrations.Go(h.Integrations.PostEvent(post))
+		if h.IndexNow != nil {
+			h.IndexNow.PingPost(post)
+		}
+	}
+
+	// Return the complete post with its generated ID
+	h.Plugins.PostCreate(ctx, plugins.RESOURCE_POST, post)
+	return c.JSON(models.APIResponse{Success: true, Data: h.Plugins.PreResponse(c, plugins.RESOURCE_POST, post)})
 }
 
-// GetPost handles GET /api/posts/:id requests.
-// Retrieves a specific blog post by its ID along with all associated comments.
-// Returns 404 if the post doesn't exist, or 400 if the ID format is invalid.
+// UpdatePost handles PUT /api/posts/:id requests.
+// Edits a blog post in place. Only the fields present in the body change;
+// content edits recompute the post's content stats. Sets updated_at and
+// bumps the post's last-modified time.
 //
 // URL parameters:
-//   - id: string (required) - MongoDB ObjectID as hex string
+//   - id: string (required) - ID in the configured ID_FORMAT
+//
+// Request body may contain:
+//   - title: string (optional) - New title, not empty
+//   - content: string (optional) - New content, not empty
+//   - excerpt: string (optional) - New summary; empty removes it
+//   - tags: []string (optional) - New tags (see cleanPostTags); empty removes them
+//   - category_id: string (optional) - ID of an existing category; empty removes it
+//   - title_variants: []string (optional) - New alternative headlines, restarting the
+//     title test; empty ends it
+//   - comments_close_after_days: int (optional) - New comment window, 0 for never,
+//     negative to use COMMENTS_AUTO_CLOSE_DAYS; reopens comments until the
+//     next comment closer pass
+//   - publish_at: string (optional) - New publication time (see CreatePost); empty
+//     publishes the post now
+//   - timezone: string (optional) - New author time zone; empty falls back to the
+//     timezone setting
 //
 // Response format:
-//   - 200: Success with BlogPost object including comments array
-//   - 400: Invalid ObjectID format
+//   - 200: Success with the updated BlogPost object
+//   - 400: Invalid ID, invalid JSON, empty title or content, too many title variants or
+//     tags, a too long tag, unknown category or timezone, invalid publish_at, or no fields
+//     to update
 //   - 404: Post not found
-//   - 500: Database query error
-func (h *Handler) GetPost(c *fiber.Ctx) error {
+//   - 502: Database update or category lookup error
+func (h *Handler) UpdatePost(c *fiber.Ctx) error {
 	// Parse and validate the post ID from URL parameters
-	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(400).JSON(models.APIResponse{
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Invalid post ID",
 		})
 	}
 
+	// Parse the request body into the expected structure
+	var req models.UpdatePostRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+
 	// Create context with timeout for database operations
-	ctx, cancel := context.WithTimeout(c.Context(), DEFAULT_DB_TIMEOUT)
+	ctx, cancel := h.dbContext(c, DB_WRITE)
 	defer cancel()
 
-	// Find the specific post by ID
-	var post models.BlogPost
-	err = h.DB.Posts.FindOne(ctx, bson.M{"_id": id}).Decode(&post)
+	// Build the partial update from the fields that were sent
+	set := bson.M{}
+	unset := bson.M{}
+	if req.Title != nil {
+		if *req.Title == "" {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Title cannot be empty",
+			})
+		}
+		set["title"] = *req.Title
+	}
+	if req.Content != nil {
+		*req.Content = h.Sanitizer.Sanitize(*req.Content)
+		if *req.Content == "" {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Content cannot be empty",
+			})
+		}
+		set["content"] = *req.Content
+		set["stats"] = content.Analyze(*req.Content)
+		set["linked_posts"] = h.LinkedPosts(postID, *req.Content)
+		set["language"] = i18n.Detect(content.PlainText(*req.Content))
+	}
+	if req.Excerpt != nil {
+		if excerpt := strings.TrimSpace(*req.Excerpt); excerpt != "" {
+			set["excerpt"] = excerpt
+		} else {
+			unset["excerpt"] = ""
+		}
+	}
+	if req.Tags != nil {
+		tags, err := cleanPostTags(*req.Tags)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "At most 10 tags of at most 32 characters",
+			})
+		}
+		if len(tags) > 0 {
+			set["tags"] = tags
+		} else {
+			unset["tags"] = ""
+		}
+	}
+	if req.CategoryID != nil && *req.CategoryID == "" {
+		unset["category_id"] = ""
+	} else if req.CategoryID != nil {
+		categoryID, err := h.resolveCategory(ctx, *req.CategoryID)
+		if err == errUnknownCategory {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Unknown category",
+			})
+		}
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to look up category", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to update post",
+			})
+		}
+		set["category_id"] = categoryID
+	}
+	if req.TitleVariants != nil {
+		// Repeats of the current title are only caught when it is sent too
+		title := ""
+		if req.Title != nil {
+			title = *req.Title
+		}
+		variants, err := cleanTitleVariants(*req.TitleVariants, title)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "At most 4 title variants",
+			})
+		}
+		// New variants restart the test from zero
+		if len(variants) > 0 {
+			set["title_variants"] = variants
+			set["title_test"] = make([]models.TitleVariantStats, len(variants)+1)
+		} else {
+			unset["title_variants"] = ""
+			unset["title_test"] = ""
+		}
+	}
+	if req.CommentsCloseAfterDays != nil {
+		if days := *req.CommentsCloseAfterDays; days >= 0 {
+			set["comments_close_after_days"] = days
+		} else {
+			unset["comments_close_after_days"] = ""
+		}
+		// Reopen comments; the closer locks them again if the new window
+		// has passed too
+		unset["comments_locked"] = ""
+		unset["comments_locked_at"] = ""
+	}
+	if req.PublishAt != nil || req.Timezone != nil {
+		// Local publish times are read in the zone stored with them
+		requested := ""
+		if req.Timezone != nil {
+			requested = *req.Timezone
+		}
+		loc, timezone, err := h.postTimezone(ctx, c, requested)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Unknown timezone",
+			})
+		}
+		if timezone != "" {
+			set["timezone"] = timezone
+		} else {
+			unset["timezone"] = ""
+		}
+		if req.PublishAt != nil && *req.PublishAt == "" {
+			unset["publish_at"] = ""
+		} else if req.PublishAt != nil {
+			publishAt, err := parsePublishAt(*req.PublishAt, loc)
+			if err != nil {
+				return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+					Success: false,
+					Error:   "Invalid publish_at",
+				})
+			}
+			set["publish_at"] = publishAt
+		}
+	}
+	if len(set) == 0 && len(unset) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "No fields to update",
+		})
+	}
+	set["updated_at"] = time.Now()
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	post, err := h.Posts.Update(ctx, postID, update)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(404).JSON(models.APIResponse{
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
 				Success: false,
 				Error:   "Post not found",
 			})
 		}
-		return c.Status(500).JSON(models.APIResponse{
+		logger.Ctx(c.Context()).Error("failed to update post", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
 			Success: false,
-			Error:   "Failed to fetch post",
+			Error:   "Failed to update post",
 		})
 	}
 
-	// Fetch all comments for this post and attach them
-	cursor, err := h.DB.Comments.Find(ctx, bson.M{"post_id": id})
-	if err == nil {
-		cursor.All(ctx, &post.Comments)
-		cursor.Close(ctx)
+	// An edited post changes its own last-modified time and the listing
+	if err := h.Posts.TouchPost(ctx, postID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
 	}
 
-	return c.JSON(models.APIResponse{Success: true, Data: post})
+	// A new title needs a new social card
+	if req.Title != nil {
+		go h.refreshCard(post)
+	}
+
+	// Ask search engines to recrawl edited public posts
+	if h.IndexNow != nil && federation.Publishes(post) {
+		h.IndexNow.PingPost(post)
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: h.Plugins.PreResponse(c, plugins.RESOURCE_POST, post)})
 }
 
-// DeletePost handles DELETE /api/posts/:id requests.
-// Deletes a specific blog post and all its associated comments atomically
-// using MongoDB transactions to ensure data consistency.
+// GetPost handles GET /api/posts/:id requests.
+// Retrieves a specific blog post by its ID along with its comments in a single
+// aggregation, joining the comments collection with $lookup. Comments are
+// paged with the comments_* parameters, oldest first and capped at
+// DEFAULT_POST_COMMENTS_LIMIT by default; comment_count gives the total.
+// Replies within the page are nested under the comment they answer, up to
+// COMMENT_THREAD_DEPTH levels (see threadComments).
+// Identical concurrent reads of one post page share a single aggregation.
+// Returns 404 if the post doesn't exist, or is private or scheduled for
+// later and the requester is not an administrator, or 400 if the ID
+// format is invalid. Unlisted posts are served normally. Passphrase-protected
+// posts answer 401 with a WWW-Authenticate challenge unless the request
+// carries an access token from UnlockPost in X-Post-Access-Token. Posts
+// running a title test carry the visitor's headline, and the read counts
+// as a click on it.
 //
 // URL parameters:
-//   - id: string (required) - MongoDB ObjectID as hex string
+//   - id: string (required) - ID in the configured ID_FORMAT
 //
-// Response format:
-//   - 200: Success - post and comments deleted
-//   - 400: Invalid ObjectID format or post not found
-//   - 502: Database transaction or deletion error
+// Query parameters:
+//   - truncate: int (optional) - shorten comment content to this many characters
+//   - comments_page: int (optional) - 1-based page of comments, default 1
+//   - comments_limit: int (optional) - comments per page, at most DEFAULT_POST_COMMENTS_LIMIT
+//   - comments_sort: string (optional) - oldest (default) or newest
+//   - view: string (optional) - full (default) or mobile, a MobilePost with
+//     content cut to MOBILE_CONTENT_LENGTH and comments to MOBILE_COMMENT_LENGTH
+//     characters unless truncate is set
+//   - format: string (optional) - markdown (default) or html, which adds
+//     content_html: the content rendered from Markdown and sanitized (see
+//     renderContent)
 //
-// This operation uses MongoDB transactions to ensure that both the post
-// and all its comments are deleted together, preventing orphaned comments.
-func (h *Handler) DeletePost(c *fiber.Ctx) error {
+// Sends the post's last-modified time, which also moves when comments or
+// translations change, and honors If-None-Match against a weak ETag of the
+// post ID, that time, and the post's counters. If-Modified-Since is not
+// honored, since view, like, and clap counts change without moving
+// last-modified. Title and content are localized according to
+// Accept-Language when a translation exists, so responses vary on it.
+// Private and scheduled posts are served to administrators only, so their
+// responses also vary on Authorization.
+//
+// Response format:
+//   - 200: Success with BlogPost (or MobilePost) object including comments array
+//   - 304: Post matching If-None-Match
+//   - 400: Invalid ID format, invalid truncate, invalid comment pagination, or unknown
+//     view or format
+//   - 401: Post is protected and no valid access token was sent
+//   - 404: Post not found, or private or scheduled and the requester is not
+//     an administrator
+//   - 500: Database query error
+func (h *Handler) GetPost(c *fiber.Ctx) error {
 	// Parse and validate the post ID from URL parameters
-	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	id, err := h.DB.IDs.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(400).JSON(models.APIResponse{
 			Success: false,
@@ -229,88 +876,236 @@ func (h *Handler) DeletePost(c *fiber.Ctx) error {
 	}
 
 	// Create context with timeout for database operations
-	ctx, cancel := context.WithTimeout(c.Context(), DEFAULT_DB_TIMEOUT)
+	ctx, cancel := h.dbContext(c, DB_READ)
 	defer cancel()
 
-	// Start a session for transaction to ensure atomicity
-	session, err := h.DB.Client.StartSession()
+	truncate, ok := parseTruncate(c)
+	if !ok {
+		return c.Status(400).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid truncate",
+		})
+	}
+	comments, _, _, err := parseCommentPage(c, "comments_")
 	if err != nil {
-		logger.Error("failed to start session from db", zap.Error(err))
-		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+		return c.Status(400).JSON(models.APIResponse{
 			Success: false,
-			Error:   "Failed to delete post",
+			Error:   "Invalid comment pagination",
+		})
+	}
+	view, ok := parseView(c)
+	if !ok {
+		return c.Status(400).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid view, expected full or mobile",
+		})
+	}
+	format, ok := parseFormat(c)
+	if !ok {
+		return c.Status(400).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid format, expected markdown or html",
 		})
 	}
-	defer session.EndSession(ctx)
 
-	// Initialize response variables for transaction error handling
-	status := http.StatusOK
-	response := models.APIResponse{
-		Data:    nil,
-		Success: false,
-		Error:   "",
+	// Identical concurrent reads of the same post page share one aggregation
+	key := fmt.Sprintf("post:%s:%d:%d:%t", id, comments.Skip, comments.Limit, comments.NewestFirst)
+	value, _, err := h.Reads.Do(key, func() (any, error) {
+		return h.loadPost(id, comments)
+	})
+	if err == mongo.ErrNoDocuments {
+		return c.Status(404).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
 	}
+	if err != nil {
+		return c.Status(500).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+	result := value.(*models.BlogPost)
 
-	// Execute transaction - both operations must succeed or both will rollback
-	if err := mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
-		// Step 1: Delete all comments associated with this post
-		commentFilter := bson.M{"post_id": postID}
-		_, err := h.DB.Comments.DeleteMany(sc, commentFilter)
-		if err != nil {
-			logger.Error("failed to delete comments from session", zap.Error(err))
-			status = http.StatusBadGateway
-			response.Error = "Failed to delete comments from post"
-			return err
+	// Private and scheduled posts are only reachable through preview links,
+	// and by administrators, who can list them with include_hidden
+	hidden := result.Visibility == models.VISIBILITY_PRIVATE || result.Scheduled(time.Now())
+	if hidden {
+		c.Vary(fiber.HeaderAuthorization)
+	}
+	if hidden && !middleware.IsAdmin(c, h.Auth, h.Config.AdminUsers) {
+		return c.Status(404).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+
+	// Protected posts need an access token from UnlockPost
+	if done, err := h.requirePassphrase(c, result); done {
+		return err
+	}
+
+	// Count the read; a failed counter update must not fail the request
+	if err := h.Posts.RecordView(ctx, id); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to record post view", zap.Error(err))
+	}
+	h.Analytics.Record(id, c.Get(fiber.HeaderReferer))
+
+	// Posts written before last-modified tracking fall back to creation time
+	lastModified := result.LastModified
+	if lastModified.IsZero() {
+		lastModified = result.CreatedAt
+	}
+
+	// The translation served depends on Accept-Language, 304s included.
+	// Counters move without bumping last-modified, so only the entity tag,
+	// which covers them, may answer 304
+	c.Vary(fiber.HeaderAcceptLanguage)
+	setLastModified(c, lastModified)
+	etag := weakETag(id.String(), lastModified.UTC().Format(time.RFC3339Nano), fmt.Sprintf("%d:%d:%d:%d",
+		result.CommentCount, result.ViewCount, result.LikeCount, result.ClapCount))
+	if etagMatches(c, etag) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+
+	// Copy the shared result before shortening its comments for this client
+	post := *result
+	post.Comments = threadComments(truncateComments(result.Comments, truncate), h.Config.CommentThreadDepth)
+
+	// Serve the visitor's headline and count the click
+	h.applyTitleTest(ctx, c, &post)
+
+	// Serve the best translation for the client's Accept-Language
+	h.localize(ctx, c, &post)
+	data := h.Plugins.PreResponse(c, plugins.RESOURCE_POST, post)
+
+	// Shape the mobile view and render HTML last, so plugins see the same
+	// post in every view and format
+	if shaped, ok := data.(models.BlogPost); ok {
+		switch {
+		case view == VIEW_MOBILE:
+			mobile := mobilePost(shaped, truncate)
+			if format == FORMAT_HTML {
+				mobile.ContentHTML = h.renderContent(mobile.Content)
+			}
+			data = mobile
+		case format == FORMAT_HTML:
+			shaped.ContentHTML = h.renderContent(shaped.Content)
+			data = shaped
 		}
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: data})
+}
 
-		// Step 2: Delete the blog post itself
-		postFilter := bson.M{"_id": postID}
-		deletePostResult, err := h.DB.Posts.DeleteOne(sc, postFilter)
-		if err != nil {
-			logger.Error("failed to delete post from session", zap.Error(err))
-			status = http.StatusBadGateway
-			response.Error = "Failed to delete post"
-			return err
+// renderContent renders post content from Markdown to HTML and sanitizes
+// the result with the configured policy, so inline HTML stored before
+// sanitization was enforced cannot run script either.
+func (h *Handler) renderContent(text string) string {
+	return h.Sanitizer.SanitizeHTML(content.Render(text))
+}
+
+// loadPost loads the post matched by id with one page of its comments
+// joined.
+// The result may be shared by concurrent requests (see Handler.Reads), so it
+// runs on its own timeout instead of one request's context and callers must
+// not modify it.
+//
+// Returns mongo.ErrNoDocuments when no post has the given id.
+func (h *Handler) loadPost(id models.ID, comments storage.CommentPage) (*models.BlogPost, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.dbTimeout(DB_AGGREGATE))
+	defer cancel()
+
+	post, err := h.Posts.Get(ctx, id, comments)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			logger.Error("failed to load post", zap.Error(err))
 		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+// DeletePost handles DELETE /api/posts/:id requests.
+// Moves a specific blog post with its comments, likes, and translations to
+// the trash, from where POST /api/posts/:id/restore brings it back until
+// the TRASH_RETENTION window passes and the purge job removes it.
+//
+// URL parameters:
+//   - id: string (required) - ID in the configured ID_FORMAT
+//
+// Response format:
+//   - 200: Success - post and comments moved to the trash
+//   - 400: Invalid ID format or post not found
+//   - 502: Database transaction or deletion error
+//
+// The post and everything attached to it go to one trash entry in one
+// session, preventing orphaned comments.
+func (h *Handler) DeletePost(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
 
-		// Verify that the post actually existed and was deleted
-		if deletePostResult.DeletedCount == 0 {
-			status = http.StatusBadRequest
-			response.Error = "Post not found"
-			return errors.New("no post deleted")
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_TRANSACTION)
+	defer cancel()
+
+	// Trash the post with everything attached to it in one session
+	if err := h.Posts.Delete(ctx, postID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not found",
+			})
 		}
-		return nil
-	}); err != nil {
-		// Transaction failed - return the error details
-		return c.Status(status).JSON(models.APIResponse{
+		logger.Ctx(c.Context()).Error("failed to delete post", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
 			Success: false,
-			Error:   response.Error,
+			Error:   "Failed to delete post",
 		})
 	}
 
-	// Transaction succeeded - post and comments deleted
-	return c.Status(status).JSON(models.APIResponse{Data: postID, Success: true, Error: ""})
+	// Post and attached documents trashed
+	h.Counts.Invalidate(postID.String())
+	if err := h.Posts.Touch(ctx); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch posts last-modified", zap.Error(err))
+	}
+	return c.Status(http.StatusOK).JSON(models.APIResponse{Data: postID, Success: true, Error: ""})
 }
 
 // CreateComment handles POST /api/posts/:id/comments requests.
 // Creates a new comment on a specific blog post.
-// Validates that the post exists and that required comment fields are provided.
+// Strips or escapes unsafe markup in the content (see sanitize.Policy),
+// validates the fields against the request's schema tags (see
+// schema.Validate), then checks that the post exists, that its comments are not
+// closed (see jobs.CommentCloser), and that a reply's parent comment is on
+// the same post.
 //
 // URL parameters:
-//   - id: string (required) - MongoDB ObjectID of the target post
+//   - id: string (required) - ID of the target post
 //
 // Request body should contain:
-//   - author: string (required) - Comment author name
-//   - content: string (required) - Comment content
+//   - author: string (required) - Comment author name, at most 100 characters
+//   - content: string (required) - Comment content, between COMMENT_MIN_LENGTH
+//     and COMMENT_MAX_LENGTH characters once trimmed
+//   - parent_comment_id: string (optional) - ID of the comment replied to
 //
 // Response format:
 //   - 200: Success with created Comment object
-//   - 400: Invalid JSON, missing fields, or invalid post ID
+//   - 400: Invalid JSON, invalid post ID, or a parent comment that is missing or on
+//     another post
+//   - 403: Comments on the post are closed
 //   - 404: Target post not found
+//   - 422: Fields breaking the request schema or the configured content length,
+//     each listed in data (see validationFailed)
 //   - 500: Database insertion error
 func (h *Handler) CreateComment(c *fiber.Ctx) error {
 	// Parse and validate the post ID from URL parameters
-	postID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
@@ -327,65 +1122,213 @@ func (h *Handler) CreateComment(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate required comment fields
-	if req.Author == "" || req.Content == "" {
-		return c.Status(400).JSON(models.APIResponse{
+	// Let plugins inspect or reject the request before built-in validation
+	if err := h.Plugins.PreValidate(c, plugins.RESOURCE_COMMENT, &req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
-			Error:   "Author and content required",
+			Error:   err.Error(),
 		})
 	}
 
+	// Remove or escape markup that could run script, then validate the
+	// fields against the request's schema tags, and the content against
+	// the configured length, counted in characters
+	req.Content = h.Sanitizer.Sanitize(req.Content)
+	problems := schema.Validate(&req)
+	if message := h.commentLengthError(req.Content); message != "" && req.Content != "" {
+		problems = append(problems, schema.FieldError{Field: "content", Message: message})
+	}
+	if len(problems) > 0 {
+		return validationFailed(c, problems)
+	}
+
 	// Create context with timeout for database operations
-	ctx, cancel := context.WithTimeout(c.Context(), DEFAULT_DB_TIMEOUT)
+	ctx, cancel := h.dbContext(c, DB_WRITE)
 	defer cancel()
 
-	// Verify that the target post exists before creating comment
-	count, err := h.DB.Posts.CountDocuments(ctx, bson.M{"_id": postID})
-	if err != nil || count == 0 {
+	// Verify that the target post exists and takes comments
+	locked, err := h.Posts.CommentsLocked(ctx, postID)
+	if err != nil {
 		return c.Status(404).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Post not found",
 		})
 	}
+	if locked {
+		return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Comments are closed",
+		})
+	}
+
+	// Replies must answer an existing comment of the same post
+	var parentID models.ID
+	if req.ParentCommentID != "" {
+		parentID, err = h.DB.IDs.Parse(req.ParentCommentID)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Invalid parent comment ID",
+			})
+		}
+		parent, err := h.Comments.Get(ctx, parentID)
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Parent comment not found",
+			})
+		}
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to fetch parent comment", zap.Error(err))
+			return c.Status(500).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to create comment",
+			})
+		}
+		if parent.PostID != postID {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Parent comment belongs to another post",
+			})
+		}
+	}
 
 	// Create new comment with current timestamp
 	comment := models.Comment{
+		ID:        h.DB.IDs.New(),
 		PostID:    postID,
+		ParentID:  parentID,
 		Author:    req.Author,
 		Content:   req.Content,
 		CreatedAt: time.Now(),
 	}
 
 	// Insert the comment into the database
-	result, err := h.DB.Comments.InsertOne(ctx, comment)
-	if err != nil {
+	if err := h.Comments.Insert(ctx, comment); err != nil {
 		return c.Status(500).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Failed to create comment",
 		})
 	}
 
-	// Set the generated ID and return the complete comment
-	comment.ID = result.InsertedID.(primitive.ObjectID)
-	return c.JSON(models.APIResponse{Success: true, Data: comment})
+	// The new comment changes both the post and its listing comment count
+	h.Counts.Invalidate(postID.String())
+	if err := h.Posts.RecordCommentChange(ctx, postID, 1); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+
+	// Return the complete comment with its generated ID
+	h.Plugins.PostCreate(ctx, plugins.RESOURCE_COMMENT, comment)
+	return c.JSON(models.APIResponse{Success: true, Data: h.Plugins.PreResponse(c, plugins.RESOURCE_COMMENT, comment)})
+}
+
+// commentLengthError checks content against the configured comment length
+// limits, counted in characters. Returns the error message to send, or an
+// empty string when the length is acceptable.
+func (h *Handler) commentLengthError(content string) string {
+	length := utf8.RuneCountInString(content)
+	if length < h.Config.CommentMinLength {
+		return fmt.Sprintf("Comment must be at least %d characters", h.Config.CommentMinLength)
+	}
+	if h.Config.CommentMaxLength > 0 && length > h.Config.CommentMaxLength {
+		return fmt.Sprintf("Comment must be at most %d characters", h.Config.CommentMaxLength)
+	}
+	return ""
+}
+
+// UpdateComment handles PUT /api/comments/:id requests.
+// Replaces the content of a comment and records when it was edited. The
+// author and creation time are kept.
+//
+// URL parameters:
+//   - id: string (required) - ID of the comment to edit
+//
+// Request body should contain:
+//   - content: string (required) - New content, between COMMENT_MIN_LENGTH
+//     and COMMENT_MAX_LENGTH characters
+//
+// Response format:
+//   - 200: Success with the updated Comment object
+//   - 400: Invalid ID format, invalid JSON, missing content, or content length out of range
+//   - 404: Comment not found
+//   - 502: Database update error
+func (h *Handler) UpdateComment(c *fiber.Ctx) error {
+	// Parse and validate the comment ID from URL parameters
+	commentID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid comment ID",
+		})
+	}
+
+	// Parse the request body into the expected structure
+	var req models.UpdateCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	req.Content = h.Sanitizer.Sanitize(req.Content)
+	if req.Content == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Content required",
+		})
+	}
+	if message := h.commentLengthError(req.Content); message != "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	comment, err := h.Comments.UpdateContent(ctx, commentID, req.Content, time.Now())
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Comment not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to update comment", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update comment",
+		})
+	}
+
+	// Comments are served with their post, so the edit changes the post
+	if err := h.Posts.TouchPost(ctx, comment.PostID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: h.Plugins.PreResponse(c, plugins.RESOURCE_COMMENT, comment)})
 }
 
 // DeleteComment handles DELETE /api/comments/:id requests.
-// Deletes a specific comment by its ID.
+// Moves a specific comment with every reply below it to the trash, from
+// where POST /api/comments/:id/restore brings them back until the purge
+// job removes them.
 //
 // URL parameters:
-//   - id: string (required) - MongoDB ObjectID of the comment to delete
+//   - id: string (required) - ID of the comment to delete
 //
 // Response format:
-//   - 200: Success - comment deleted
-//   - 400: Invalid ObjectID format or comment not found
+//   - 200: Success - comment moved to the trash
+//   - 400: Invalid ID format or comment not found
 //   - 502: Database deletion error
 //
-// Note: This operation only deletes the comment itself and does not
-// require transaction handling since it's a single atomic operation.
+// Note: Replies are trashed with the comment they answer, so no reply is
+// left pointing at a missing parent.
 func (h *Handler) DeleteComment(c *fiber.Ctx) error {
 	// Parse and validate the comment ID from URL parameters
-	commentID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	commentID, err := h.DB.IDs.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
@@ -394,31 +1337,33 @@ func (h *Handler) DeleteComment(c *fiber.Ctx) error {
 	}
 
 	// Create context with timeout for database operations
-	ctx, cancel := context.WithTimeout(c.Context(), DEFAULT_DB_TIMEOUT)
+	ctx, cancel := h.dbContext(c, DB_TRANSACTION)
 	defer cancel()
 
-	// Create filter using the comment ID for deletion
-	filter := bson.M{"_id": commentID}
-
-	// Execute the deletion operation
-	result, err := h.DB.Comments.DeleteOne(ctx, filter)
+	// Execute the deletion operation, keeping the document to know its post
+	deleted, removed, err := h.Comments.Delete(ctx, commentID)
 	if err != nil {
-		logger.Error("failed to delete comment", zap.Error(err))
+		// Check if a comment was actually found and deleted
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "No comment found to delete",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to delete comment", zap.Error(err))
 		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Failed to delete comment",
 		})
 	}
 
-	// Check if a comment was actually found and deleted
-	if result.DeletedCount == 0 {
-		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
-			Success: false,
-			Error:   "No comment found to delete",
-		})
+	// The removal changes both the post and its listing comment count
+	h.Counts.Invalidate(deleted.PostID.String())
+	if err := h.Posts.RecordCommentChange(ctx, deleted.PostID, -removed); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
 	}
 
-	// Successfully deleted the comment
+	// Successfully trashed the comment
 	return c.Status(http.StatusOK).JSON(models.APIResponse{
 		Data:    commentID,
 		Success: true,
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// HEALTH_CHECK_TIMEOUT bounds the database round trips of a readiness
+// check, so probes fail fast instead of piling up behind a stuck server.
+const HEALTH_CHECK_TIMEOUT = 2 * time.Second
+
+// HEALTH_OK and HEALTH_UNAVAILABLE are the statuses of a HealthStatus.
+const (
+	HEALTH_OK          = "ok"
+	HEALTH_UNAVAILABLE = "unavailable"
+)
+
+// readinessFind reads at most one _id per collection checked by Readyz.
+var readinessFind = options.Find().SetLimit(1).SetProjection(bson.M{"_id": 1})
+
+// Version is the build version reported by the health endpoints. Set it at
+// build time with -ldflags "-X .../internal/handlers.Version=v1.2.3";
+// otherwise the VCS revision embedded by the Go toolchain is used.
+var Version string
+
+// buildVersion returns Version, falling back to the embedded VCS revision
+// and then to "dev". Computed once, build info does not change.
+var buildVersion = sync.OnceValue(func() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+		if info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version
+		}
+	}
+	return "dev"
+})
+
+// Healthz handles GET /healthz requests.
+// Liveness probe: answers as long as the process serves HTTP, without
+// touching the database, so a MongoDB outage does not get instances
+// restarted.
+//
+// Response format:
+//   - 200: Success with a HealthStatus carrying the build version
+func (h *Handler) Healthz(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Data:    models.HealthStatus{Status: HEALTH_OK, Version: buildVersion()},
+	})
+}
+
+// Readyz handles GET /readyz requests.
+// Readiness probe: pings MongoDB and checks every collection can be
+// queried, all within HEALTH_CHECK_TIMEOUT. Orchestrators should stop
+// routing traffic to the instance while it answers 503.
+//
+// Response format:
+//   - 200: Success with a HealthStatus listing every collection as reachable
+//   - 503: MongoDB or a collection is unreachable; the HealthStatus tells which
+func (h *Handler) Readyz(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), HEALTH_CHECK_TIMEOUT)
+	defer cancel()
+
+	status := models.HealthStatus{
+		Status:      HEALTH_OK,
+		Version:     buildVersion(),
+		Collections: make(map[string]bool),
+	}
+
+	// A failed ping leaves every collection unreachable without querying them
+	err := h.DB.Client.Ping(ctx, nil)
+	if err != nil {
+		logger.Ctx(c.Context()).Warn("readiness ping failed", zap.Error(err))
+	}
+	for _, collection := range h.DB.Collections() {
+		reachable := err == nil
+		if reachable {
+			// A find limited to one _id stays cheap on large collections and
+			// succeeds on collections that do not exist yet
+			cursor, findErr := collection.Find(ctx, bson.M{}, readinessFind)
+			if findErr == nil {
+				findErr = cursor.Close(ctx)
+			}
+			if findErr != nil {
+				logger.Ctx(c.Context()).Warn("readiness check failed", zap.String("collection", collection.Name()), zap.Error(findErr))
+				reachable = false
+			}
+		}
+		status.Collections[collection.Name()] = reachable
+		if !reachable {
+			status.Status = HEALTH_UNAVAILABLE
+		}
+	}
+
+	if status.Status != HEALTH_OK {
+		return c.Status(http.StatusServiceUnavailable).JSON(models.APIResponse{
+			Success: false,
+			Data:    status,
+			Error:   "Service not ready",
+		})
+	}
+	return c.Status(http.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Data:    status,
+	})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+)
+
+// ANON_KEY_PREFIX marks requester keys derived from the client IP rather
+// than an authenticated user ID.
+const ANON_KEY_PREFIX = "anon:"
+
+// USER_KEY_PREFIX marks requester keys of logged-in users.
+const USER_KEY_PREFIX = "user:"
+
+// requesterKey identifies the client making the request for deduplication
+// purposes (e.g., one like per requester, capped claps). Requests with a
+// valid login token are keyed on the user ID, so a user counts once from any
+// device. Other requests are keyed on a SHA-256 hash of the client IP, so no
+// raw IP is stored; headers the client controls, such as User-Agent, are
+// left out so changing them does not make a new requester.
+func (h *Handler) requesterKey(c *fiber.Ctx) string {
+	// Public endpoints do not run RequireAuth; verify the token here
+	if middleware.Authenticated(c, h.Auth) {
+		if claims, ok := middleware.CurrentUser(c); ok {
+			return USER_KEY_PREFIX + claims.Subject
+		}
+	}
+	sum := sha256.Sum256([]byte(h.clientIP(c)))
+	return ANON_KEY_PREFIX + hex.EncodeToString(sum[:])
+}
+
+// clientIP returns the real client IP, honoring X-Forwarded-For and
+// X-Real-IP only when the request arrived through a trusted proxy
+// (TRUSTED_PROXIES). Use it instead of c.IP() wherever the client's
+// address matters, e.g., for rate limits and ban lists.
+func (h *Handler) clientIP(c *fiber.Ctx) string {
+	return h.Proxies.ClientIP(c.Context().RemoteIP().String(), c.Get(fiber.HeaderXForwardedFor), c.Get("X-Real-IP"))
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// MAX_IMPORT_COMMENTS caps the comments accepted by one import request;
+// larger discussions are imported in several batches.
+const MAX_IMPORT_COMMENTS = 1000
+
+// importResult summarizes an import batch.
+type importResult struct {
+	Imported int `json:"imported"` // Comments stored
+	Skipped  int `json:"skipped"`  // Comments whose import_id was already present on the post
+}
+
+// ImportComments handles POST /api/posts/:id/comments/import requests.
+// Stores a batch of historical comments, keeping their original authors and
+// timestamps, for migrating discussions from Disqus or another platform.
+// Comments with an import_id already imported into the post are skipped, so
+// a failed migration can be re-run, and parent_import_id keeps replies
+// threaded (see storage.ImportComments). Imported comments bypass plugins and
+// the configured comment length limits, since they were accepted elsewhere,
+// but unsafe markup is still stripped or escaped (see sanitize.Policy).
+// The route is reserved for site administrators (see middleware.RequireAdmin).
+//
+// URL parameters:
+//   - id: string (required) - ID of the target post
+//
+// Request body should contain:
+//   - comments: array (required) - 1 to MAX_IMPORT_COMMENTS comments, each
+//     with author, content, created_at, and optional import_id and
+//     parent_import_id
+//
+// Response format:
+//   - 200: Success with imported and skipped counts
+//   - 400: Invalid JSON, invalid post ID, empty or oversized batch, or an invalid comment
+//   - 401: No administrator login token
+//   - 403: Not a site administrator
+//   - 404: Target post not found
+//   - 502: Database lookup or insertion error
+func (h *Handler) ImportComments(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	var req models.ImportCommentsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	if len(req.Comments) == 0 || len(req.Comments) > MAX_IMPORT_COMMENTS {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Import 1 to %d comments per request", MAX_IMPORT_COMMENTS),
+		})
+	}
+
+	// Validate the whole batch before writing any of it
+	for i := range req.Comments {
+		imported := &req.Comments[i]
+		imported.Content = h.Sanitizer.Sanitize(imported.Content)
+		if imported.Author == "" || imported.Content == "" || imported.CreatedAt.IsZero() {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Comment %d: author, content, and created_at required", i),
+			})
+		}
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	// Verify that the target post exists before importing
+	count, err := h.DB.Posts.CountDocuments(ctx, bson.M{"_id": postID})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to look up import target post", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to import comments",
+		})
+	}
+	if count == 0 {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+
+	imported, skipped, err := h.DB.ImportComments(ctx, postID, req.Comments)
+	if imported > 0 {
+		h.Counts.Invalidate(postID.String())
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to import comments", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to import comments",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: importResult{
+		Imported: imported,
+		Skipped:  skipped,
+	}})
+}
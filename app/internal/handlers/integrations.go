@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/integrations"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// redactIntegration hides the credentials embedded in the webhook path,
+// keeping the host so admins can tell integrations apart.
+func redactIntegration(integration models.Integration) models.Integration {
+	if parsed, err := url.Parse(integration.WebhookURL); err == nil {
+		integration.WebhookURL = parsed.Scheme + "://" + parsed.Host + "/…"
+	}
+	return integration
+}
+
+// GetIntegrations handles GET /api/admin/integrations requests.
+// Returns the configured chat integrations, oldest first, with webhook
+// URLs redacted.
+//
+// Response format:
+//   - 200: Success with array of Integration objects
+//   - 502: Database query error
+func (h *Handler) GetIntegrations(c *fiber.Ctx) error {
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := h.DB.Integrations.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch integrations", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch integrations",
+		})
+	}
+
+	stored := []models.Integration{}
+	if err := cursor.All(ctx, &stored); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode integrations", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch integrations",
+		})
+	}
+	for i := range stored {
+		stored[i] = redactIntegration(stored[i])
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: stored})
+}
+
+// CreateIntegration handles POST /api/admin/integrations requests.
+// Connects a Slack or Discord channel through its incoming webhook.
+//
+// Request body should contain:
+//   - driver: string (required) - "slack" or "discord"
+//   - webhook_url: string (required) - incoming webhook URL of the channel;
+//     must be a URL of the driver's service
+//   - events: array (optional) - events to send, default ["post.published"]
+//
+// Response format:
+//   - 200: Success with the created Integration (webhook URL redacted)
+//   - 400: Invalid JSON, unknown driver or event, or foreign webhook URL
+//   - 502: Database insertion error
+func (h *Handler) CreateIntegration(c *fiber.Ctx) error {
+	var req models.CreateIntegrationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+
+	if err := integrations.Validate(req.Driver, req.WebhookURL); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+	if len(req.Events) == 0 {
+		req.Events = []string{models.EVENT_POST_PUBLISHED}
+	}
+	for _, event := range req.Events {
+		if !models.ValidEvent(event) {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Unknown event " + event,
+			})
+		}
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	integration := models.Integration{
+		ID:         h.DB.IDs.New(),
+		Driver:     req.Driver,
+		WebhookURL: req.WebhookURL,
+		Events:     req.Events,
+		CreatedAt:  time.Now(),
+	}
+	if _, err := h.DB.Integrations.InsertOne(ctx, integration); err != nil {
+		logger.Ctx(c.Context()).Error("failed to create integration", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to create integration",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: redactIntegration(integration)})
+}
+
+// DeleteIntegration handles DELETE /api/admin/integrations/:id requests.
+// Disconnects a chat channel.
+//
+// URL parameters:
+//   - id: string (required) - ID of the integration
+//
+// Response format:
+//   - 200: Integration deleted
+//   - 400: Invalid ID format
+//   - 404: Integration not found
+//   - 502: Database deletion error
+func (h *Handler) DeleteIntegration(c *fiber.Ctx) error {
+	id, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid integration ID",
+		})
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	result, err := h.DB.Integrations.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to delete integration", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to delete integration",
+		})
+	}
+	if result.DeletedCount == 0 {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Integration not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true})
+}
+
+// TestIntegration handles POST /api/admin/integrations/:id/test requests.
+// Sends a sample message to the channel so admins can check the webhook.
+//
+// URL parameters:
+//   - id: string (required) - ID of the integration
+//
+// Response format:
+//   - 200: Message delivered
+//   - 400: Invalid ID format
+//   - 404: Integration not found
+//   - 502: Database error, or the webhook rejected the message
+func (h *Handler) TestIntegration(c *fiber.Ctx) error {
+	id, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid integration ID",
+		})
+	}
+
+	// The webhook call gets its own budget on top of the lookup
+	ctx, cancel := h.requestContext(c, h.dbTimeout(DB_READ)+integrations.DEFAULT_REQUEST_TIMEOUT)
+	defer cancel()
+
+	var integration models.Integration
+	if err := h.DB.Integrations.FindOne(ctx, bson.M{"_id": id}).Decode(&integration); err != nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Integration not found",
+		})
+	}
+
+	event := integrations.Event{
+		Type:    models.EVENT_POST_PUBLISHED,
+		Title:   "Test message",
+		Summary: "This channel will receive blog updates.",
+	}
+	if err := h.Integrations.Send(ctx, integration, event); err != nil {
+		logger.Ctx(c.Context()).Warn("integration test failed", zap.String("integration", id.String()), zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Webhook call failed: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true})
+}
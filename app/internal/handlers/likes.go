@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// LikePost handles POST /api/posts/:id/like requests.
+// Records a like from the requester (see requesterKey). Liking twice is a
+// no-op thanks to the unique (post_id, user_key) index, so the endpoint is
+// idempotent and the like counter is only incremented once.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post to like
+//
+// Response format:
+//   - 200: Success with the post's like_count and liked=true
+//   - 400: Invalid ID format
+//   - 404: Post not found, private, or scheduled for later
+//   - 502: Database error
+func (h *Handler) LikePost(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	// Private and scheduled posts answer like missing ones, so neither
+	// their existence nor their like count leaks
+	if done, err := h.rejectUnpublished(c, ctx, postID); done {
+		return err
+	}
+
+	// Insert the like; a duplicate means the requester already liked it
+	like := models.Like{
+		ID:        h.DB.IDs.New(),
+		PostID:    postID,
+		UserKey:   h.requesterKey(c),
+		CreatedAt: time.Now(),
+	}
+	if _, err := h.DB.Likes.InsertOne(ctx, like); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			logger.Ctx(c.Context()).Error("failed to insert like", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to like post",
+			})
+		}
+	} else if err := h.DB.RecordLikeChange(ctx, postID, 1); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to increment like count", zap.Error(err))
+	}
+
+	return h.likeState(c, ctx, postID, true)
+}
+
+// UnlikePost handles DELETE /api/posts/:id/like requests.
+// Removes the requester's like if present. Idempotent like LikePost.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post to unlike
+//
+// Response format:
+//   - 200: Success with the post's like_count and liked=false
+//   - 400: Invalid ID format
+//   - 404: Post not found, private, or scheduled for later
+//   - 502: Database error
+func (h *Handler) UnlikePost(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	if done, err := h.rejectUnpublished(c, ctx, postID); done {
+		return err
+	}
+
+	// Remove the like and only decrement when one was actually removed
+	result, err := h.DB.Likes.DeleteOne(ctx, bson.M{"post_id": postID, "user_key": h.requesterKey(c)})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to delete like", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to unlike post",
+		})
+	}
+	if result.DeletedCount > 0 {
+		if err := h.DB.RecordLikeChange(ctx, postID, -1); err != nil {
+			logger.Ctx(c.Context()).Warn("failed to decrement like count", zap.Error(err))
+		}
+	}
+
+	return h.likeState(c, ctx, postID, false)
+}
+
+// DEFAULT_LIKES_PAGE_SIZE and MAX_LIKES_PAGE_SIZE bound the pages of
+// GET /api/posts/:id/likes.
+const (
+	DEFAULT_LIKES_PAGE_SIZE = 50
+	MAX_LIKES_PAGE_SIZE     = 100
+)
+
+// GetPostLikes handles GET /api/posts/:id/likes requests.
+// Lists the individual likes of a post, newest first, so the blog admin can
+// inspect who (by user ID or anonymous hash) liked it. The route is
+// reserved for site administrators (see middleware.RequireAdmin), so private
+// and scheduled posts are listed too.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Query parameters:
+//   - page: int (optional) - 1-based page number
+//   - limit: int (optional) - likes per page, default DEFAULT_LIKES_PAGE_SIZE,
+//     capped at MAX_LIKES_PAGE_SIZE
+//
+// Response format:
+//   - 200: Success with array of Like objects and pagination
+//   - 400: Invalid ID format or invalid pagination
+//   - 401: No administrator login token
+//   - 403: Not a site administrator
+//   - 502: Database query error
+func (h *Handler) GetPostLikes(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+	page, limit, err := parsePageParams(c, "page", "limit", DEFAULT_LIKES_PAGE_SIZE, MAX_LIKES_PAGE_SIZE)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	likes, total, err := h.Posts.Likes(ctx, postID, int64((page-1)*limit), int64(limit))
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch likes", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch likes",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    likes,
+		Pagination: &models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	})
+}
+
+// likeState responds with the post's current like count and the
+// requester's like state after a like or unlike.
+func (h *Handler) likeState(c *fiber.Ctx, ctx context.Context, postID models.ID, liked bool) error {
+	var post models.BlogPostHeader
+	opts := options.FindOne().SetProjection(PostHeaderProjection)
+	if err := h.DB.Posts.FindOne(ctx, bson.M{"_id": postID}, opts).Decode(&post); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to read like count", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: fiber.Map{
+		"post_id":    postID,
+		"like_count": post.LikeCount,
+		"liked":      liked,
+	}})
+}
+
+// markLiked sets Liked on every summary the requester has liked, using a
+// single query over the unique likes index. Failures are logged and leave
+// the flags unset rather than failing the listing.
+func (h *Handler) markLiked(ctx context.Context, userKey string, summaries []models.BlogPostSummary) {
+	if len(summaries) == 0 {
+		return
+	}
+
+	postIDs := make([]models.ID, len(summaries))
+	for i, summary := range summaries {
+		postIDs[i] = summary.ID
+	}
+
+	liked, err := h.Posts.Liked(ctx, userKey, postIDs)
+	if err != nil {
+		logger.Warn("failed to look up requester likes", zap.Error(err))
+		return
+	}
+	for i := range summaries {
+		summaries[i].Liked = liked[summaries[i].ID]
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/schema"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// moderationStatuses maps the status-setting bulk moderation actions to
+// the comment status they set.
+var moderationStatuses = map[string]string{
+	models.MODERATION_APPROVE:   models.COMMENT_APPROVED,
+	models.MODERATION_REJECT:    models.COMMENT_REJECTED,
+	models.MODERATION_MARK_SPAM: models.COMMENT_SPAM,
+}
+
+// moderationResult reports what a bulk moderation action did to one
+// comment.
+type moderationResult struct {
+	ID      string `json:"id"`              // Comment ID as sent
+	Success bool   `json:"success"`         // Whether the action was applied
+	Error   string `json:"error,omitempty"` // Why it was not, e.g. "Comment not found"
+}
+
+// moderationReport summarizes a bulk moderation request.
+type moderationReport struct {
+	Action    string             `json:"action"`    // Action performed
+	Succeeded int                `json:"succeeded"` // Comments the action was applied to
+	Failed    int                `json:"failed"`    // Comments skipped, with the reason in their result
+	Results   []moderationResult `json:"results"`   // One result per ID sent, in request order
+}
+
+// BulkModerateComments handles POST /api/admin/comments/bulk requests.
+// Applies one moderation action to many comments, so moderators can clear
+// large queues in one request. Approving, rejecting, and marking as spam set
+// the comment's status (see models.Comment.Status); rejected and spam
+// comments are hidden from readers until approved. Deleting moves each
+// comment with its replies to the trash, like DELETE /api/comments/:id.
+//
+// The action runs in one transaction: either every comment found is
+// moderated and the comment counts of their posts updated, or nothing is.
+// Invalid, duplicate, and missing IDs do not abort the request; they are
+// reported in their result and skipped. Transactions need MongoDB running
+// as a replica set.
+//
+// Request body: BulkModerationRequest JSON object
+//
+// Response format:
+//   - 200: Success with a moderationReport, whose results follow the order of ids
+//   - 400: Invalid JSON
+//   - 422: Fields breaking the request schema, each listed in data
+//   - 502: Database or transaction error; no comment was changed
+func (h *Handler) BulkModerateComments(c *fiber.Ctx) error {
+	// Parse the request body into the expected structure
+	var req models.BulkModerationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	if problems := schema.Validate(&req); len(problems) > 0 {
+		return validationFailed(c, problems)
+	}
+
+	// Reject malformed and repeated IDs up front; the rest go to the database
+	results := make([]moderationResult, len(req.IDs))
+	ids := make(map[int]models.ID, len(req.IDs))
+	seen := make(map[models.ID]bool, len(req.IDs))
+	for i, raw := range req.IDs {
+		results[i].ID = raw
+		id, err := h.DB.IDs.Parse(raw)
+		switch {
+		case err != nil:
+			results[i].Error = "Invalid comment ID"
+		case seen[id]:
+			results[i].Error = "Duplicate comment ID"
+		default:
+			ids[i], seen[id] = id, true
+		}
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_TRANSACTION)
+	defer cancel()
+
+	actor := h.auditActor(c)
+	var changed map[models.ID]int64
+	err := h.DB.Transaction(ctx, func(tx context.Context) error {
+		// A retried transaction starts over, results included
+		changed = make(map[models.ID]int64)
+		for i := range req.IDs {
+			id, ok := ids[i]
+			if !ok {
+				continue
+			}
+			postID, delta, err := h.moderateComment(tx, id, req.Action, actor)
+			if err == mongo.ErrNoDocuments {
+				results[i].Success, results[i].Error = false, "Comment not found"
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			results[i].Success, results[i].Error = true, ""
+			changed[postID] += delta
+		}
+
+		// Comment counts change with the comments, in the same transaction
+		for postID, delta := range changed {
+			var err error
+			if delta != 0 {
+				err = h.Posts.RecordCommentChange(tx, postID, delta)
+			} else {
+				err = h.Posts.TouchPost(tx, postID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to moderate comments", zap.String("action", req.Action), zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to moderate comments",
+		})
+	}
+
+	report := moderationReport{Action: req.Action, Results: results}
+	for _, result := range results {
+		if result.Success {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+	for postID := range changed {
+		h.Counts.Invalidate(postID.String())
+	}
+	if report.Succeeded > 0 {
+		h.audit(c, ctx, models.AUDIT_COMMENTS_BULK, fmt.Sprintf("%s applied to %d comments", req.Action, report.Succeeded))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: report})
+}
+
+// moderateComment applies a bulk moderation action to one comment and
+// returns its post with the change in the post's visible comment count.
+// Missing comments are mongo.ErrNoDocuments.
+func (h *Handler) moderateComment(ctx context.Context, id models.ID, action, actor string) (models.ID, int64, error) {
+	if action == models.MODERATION_DELETE {
+		deleted, removed, err := h.Comments.Delete(ctx, id)
+		return deleted.PostID, -removed, err
+	}
+
+	status := moderationStatuses[action]
+	before, err := h.Comments.Moderate(ctx, id, status, actor, time.Now())
+	if err != nil {
+		return before.PostID, 0, err
+	}
+	after := models.Comment{Status: status}
+	switch {
+	case before.Hidden() && !after.Hidden():
+		return before.PostID, 1, nil
+	case !before.Hidden() && after.Hidden():
+		return before.PostID, -1, nil
+	}
+	return before.PostID, 0, nil
+}
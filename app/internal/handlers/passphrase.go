@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// POST_ACCESS_TOKEN_PURPOSE scopes signed tokens to unlocking protected posts.
+const POST_ACCESS_TOKEN_PURPOSE = "post-access"
+
+// POST_ACCESS_HEADER carries the access token of a protected post.
+const POST_ACCESS_HEADER = "X-Post-Access-Token"
+
+// MAX_PASSPHRASE_BYTES is the longest passphrase bcrypt can hash.
+const MAX_PASSPHRASE_BYTES = 72
+
+// postAccessResponse is returned when a protected post is unlocked.
+type postAccessResponse struct {
+	Token     string    `json:"token"`      // Token to send in POST_ACCESS_HEADER
+	ExpiresAt time.Time `json:"expires_at"` // When the token stops working
+}
+
+// SetPassphrase handles PUT /api/posts/:id/passphrase requests.
+// Protects a post with a passphrase: GetPost answers 401 until the client
+// unlocks it. Setting a new passphrase revokes previously issued access
+// tokens. Only a bcrypt hash is stored.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Request body should contain:
+//   - passphrase: string (required) - 1 to 72 bytes
+//
+// Response format:
+//   - 200: Success with the updated BlogPost object
+//   - 400: Invalid ID format, invalid JSON, or invalid passphrase length
+//   - 404: Post not found
+//   - 502: Database update error
+func (h *Handler) SetPassphrase(c *fiber.Ctx) error {
+	var req models.PassphraseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	if req.Passphrase == "" || len(req.Passphrase) > MAX_PASSPHRASE_BYTES {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Passphrase must be 1 to 72 bytes",
+		})
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Passphrase), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to hash passphrase", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update post",
+		})
+	}
+
+	return h.setProtection(c, bson.M{
+		"$set": bson.M{"protected": true, "passphrase_hash": string(hash)},
+	})
+}
+
+// RemovePassphrase handles DELETE /api/posts/:id/passphrase requests.
+// Makes a protected post readable without a passphrase again.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Response format:
+//   - 200: Success with the updated BlogPost object
+//   - 400: Invalid ID format
+//   - 404: Post not found
+//   - 502: Database update error
+func (h *Handler) RemovePassphrase(c *fiber.Ctx) error {
+	return h.setProtection(c, bson.M{
+		"$unset": bson.M{"protected": "", "passphrase_hash": ""},
+	})
+}
+
+// setProtection applies a passphrase update to the post in the URL and
+// responds with the updated post.
+func (h *Handler) setProtection(c *fiber.Ctx, update bson.M) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var post models.BlogPost
+	err = h.DB.Posts.FindOneAndUpdate(ctx, bson.M{"_id": postID}, update, opts).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to update passphrase", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update post",
+		})
+	}
+
+	// Cached 304s must not outlive a protection change
+	if err := h.DB.TouchPost(ctx, postID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: post})
+}
+
+// UnlockPost handles POST /api/posts/:id/unlock requests.
+// Checks the passphrase of a protected post and issues a short-lived access
+// token, valid for PostAccessTokenTTL, to send in the X-Post-Access-Token
+// header of GetPost.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Request body should contain:
+//   - passphrase: string (required) - the post's passphrase
+//
+// Response format:
+//   - 200: Success with token and expiry
+//   - 400: Invalid ID format, invalid JSON, or the post is not protected
+//   - 401: Wrong passphrase
+//   - 404: Post not found
+//   - 500: Database query error
+func (h *Handler) UnlockPost(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	var req models.PassphraseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	// Only the hash is needed, and private posts stay indistinguishable
+	// from missing ones
+	var post models.BlogPost
+	opts := options.FindOne().SetProjection(bson.M{"passphrase_hash": 1})
+	filter := bson.M{"_id": postID, "visibility": bson.M{"$ne": models.VISIBILITY_PRIVATE}}
+	err = h.DB.Posts.FindOne(ctx, filter, opts).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to fetch post passphrase", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+	if post.PassphraseHash == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post is not protected",
+		})
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(post.PassphraseHash), []byte(req.Passphrase)) != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid passphrase",
+		})
+	}
+
+	expiresAt := time.Now().Add(h.Config.PostAccessTokenTTL).Truncate(time.Second)
+	signed := h.Tokens.Sign(POST_ACCESS_TOKEN_PURPOSE, postAccessSubject(postID, post.PassphraseHash), expiresAt)
+	return c.JSON(models.APIResponse{Success: true, Data: postAccessResponse{
+		Token:     signed,
+		ExpiresAt: expiresAt,
+	}})
+}
+
+// requirePassphrase responds 401 with a challenge when post is protected
+// and the request carries no valid access token for its current
+// passphrase. Returns true when a response was written.
+func (h *Handler) requirePassphrase(c *fiber.Ctx, post *models.BlogPost) (bool, error) {
+	if h.unlocked(c, post) {
+		return false, nil
+	}
+
+	message := "Passphrase required"
+	if c.Get(POST_ACCESS_HEADER) != "" {
+		message = "Invalid or expired access token"
+	}
+	c.Set(fiber.HeaderWWWAuthenticate, `Passphrase realm="post", unlock="/api/posts/`+post.ID.String()+`/unlock"`)
+	return true, c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// unlocked reports whether post is unprotected, or the request carries a
+// valid access token for its current passphrase.
+func (h *Handler) unlocked(c *fiber.Ctx, post *models.BlogPost) bool {
+	if post.PassphraseHash == "" {
+		return true
+	}
+	subject, _, err := h.Tokens.Verify(POST_ACCESS_TOKEN_PURPOSE, c.Get(POST_ACCESS_HEADER))
+	return err == nil && subject == postAccessSubject(post.ID, post.PassphraseHash)
+}
+
+// requirePostPassphrase is requirePassphrase for read paths serving content
+// attached to the post with the given id, such as its comments or
+// translations, which do not load the post itself. A missing post is not
+// protected, so callers keep their not-found behavior.
+func (h *Handler) requirePostPassphrase(c *fiber.Ctx, ctx context.Context, postID models.ID) (bool, error) {
+	var post models.BlogPost
+	opts := options.FindOne().SetProjection(bson.M{"passphrase_hash": 1})
+	err := h.DB.Posts.FindOne(ctx, bson.M{"_id": postID}, opts).Decode(&post)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to check post protection", zap.Error(err))
+		return true, c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+	return h.requirePassphrase(c, &post)
+}
+
+// rejectLocked responds like rejectPrivate for private posts, then like
+// requirePostPassphrase for protected posts the request has not unlocked.
+// Returns true when a response was written.
+func (h *Handler) rejectLocked(c *fiber.Ctx, ctx context.Context, postID models.ID) (bool, error) {
+	if done, err := h.rejectPrivate(c, ctx, postID); done {
+		return true, err
+	}
+	return h.requirePostPassphrase(c, ctx, postID)
+}
+
+// lockedAmong returns the subset of postIDs that are private (see
+// privateAmong) or protected without an access token in the request, for
+// read paths serving several posts' content at once.
+func (h *Handler) lockedAmong(c *fiber.Ctx, ctx context.Context, postIDs []models.ID) (map[models.ID]bool, error) {
+	locked, err := h.privateAmong(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": postIDs}, "passphrase_hash": bson.M{"$exists": true, "$ne": ""}}
+	cursor, err := h.DB.Posts.Find(ctx, filter, options.Find().SetProjection(bson.M{"passphrase_hash": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var protected []models.BlogPost
+	if err := cursor.All(ctx, &protected); err != nil {
+		return nil, err
+	}
+	for i := range protected {
+		if !h.unlocked(c, &protected[i]) {
+			locked[protected[i].ID] = true
+		}
+	}
+	return locked, nil
+}
+
+// postAccessSubject binds an access token to the post and to a fingerprint
+// of its current passphrase hash, so changing the passphrase revokes tokens.
+func postAccessSubject(postID models.ID, passphraseHash string) string {
+	sum := sha256.Sum256([]byte(passphraseHash))
+	return postID.String() + ":" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/token"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// PREVIEW_TOKEN_PURPOSE scopes signed tokens to the post preview feature.
+const PREVIEW_TOKEN_PURPOSE = "preview"
+
+// previewTokenResponse is returned when a preview link is created.
+type previewTokenResponse struct {
+	Token     string    `json:"token"`      // Signed token to share
+	URL       string    `json:"url"`        // Path that serves the preview
+	ExpiresAt time.Time `json:"expires_at"` // When the link stops working
+}
+
+// CreatePreviewToken handles POST /api/posts/:id/preview-token requests.
+// Generates a signed, expiring token that lets anyone holding the link read
+// the post's current content without an account, so authors can share
+// work-in-progress posts with reviewers. Tokens are stateless and expire
+// after the configured PreviewTokenTTL.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post to preview
+//
+// Response format:
+//   - 200: Success with token, preview URL, and expiry
+//   - 400: Invalid ID format
+//   - 404: Post not found
+func (h *Handler) CreatePreviewToken(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	// Only issue links for posts that exist
+	count, err := h.DB.Posts.CountDocuments(ctx, bson.M{"_id": postID})
+	if err != nil || count == 0 {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+
+	expiresAt := time.Now().Add(h.Config.PreviewTokenTTL).Truncate(time.Second)
+	signed := h.Tokens.Sign(PREVIEW_TOKEN_PURPOSE, postID.String(), expiresAt)
+	return c.JSON(models.APIResponse{Success: true, Data: previewTokenResponse{
+		Token:     signed,
+		URL:       "/api/posts/preview/" + signed,
+		ExpiresAt: expiresAt,
+	}})
+}
+
+// GetPreview handles GET /api/posts/preview/:token requests.
+// Verifies a preview token and returns the post it was issued for. Preview
+// links are the only public read path for private posts.
+//
+// URL parameters:
+//   - token: string (required) - token from CreatePreviewToken
+//
+// Response format:
+//   - 200: Success with BlogPost object
+//   - 401: Token malformed, tampered with, or expired
+//   - 404: Post no longer exists
+//   - 500: Database query error
+func (h *Handler) GetPreview(c *fiber.Ctx) error {
+	// Verify signature, scope, and expiry before touching the database
+	subject, _, err := h.Tokens.Verify(PREVIEW_TOKEN_PURPOSE, c.Params("token"))
+	if err != nil {
+		message := "Invalid preview token"
+		if err == token.ErrExpired {
+			message = "Preview link expired"
+		}
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+	postID, err := h.DB.IDs.Parse(subject)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid preview token",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	var post models.BlogPost
+	if err := h.DB.Posts.FindOne(ctx, bson.M{"_id": postID}).Decode(&post); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to fetch preview post", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+
+	// Previews must not be cached by shared caches
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.JSON(models.APIResponse{Success: true, Data: post})
+}
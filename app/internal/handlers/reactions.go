@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// ReactToComment handles POST /api/comments/:id/react requests.
+// Records the requester's reaction to a comment. Requesters are the
+// logged-in user or else the client IP (see requesterKey), so a different
+// User-Agent does not vote again. The unique (comment_id, user_key) index
+// keeps one reaction per requester: reacting again with the same type is a
+// no-op, with another type it replaces the previous reaction, so counts
+// never double up.
+//
+// URL parameters:
+//   - id: string (required) - ID of the comment to react to
+//
+// Request body: ReactRequest JSON object
+//
+// Response format:
+//   - 200: Success with the comment's reaction counts and the requester's reaction
+//   - 400: Invalid ID format, invalid JSON, or unknown reaction type
+//   - 404: Comment not found, or it belongs to a private post
+//   - 502: Database error
+func (h *Handler) ReactToComment(c *fiber.Ctx) error {
+	// Parse and validate the comment ID from URL parameters
+	commentID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid comment ID",
+		})
+	}
+
+	var req models.ReactRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	if !models.ValidReaction(req.Reaction) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid reaction, expected like, love, laugh, insightful, or sad",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	comment, done, err := h.reactableComment(c, ctx, commentID)
+	if done {
+		return err
+	}
+
+	// Upsert the requester's reaction, keeping the previous one to move its count
+	userKey := h.requesterKey(c)
+	filter := bson.M{"comment_id": commentID, "user_key": userKey}
+	update := bson.M{
+		"$set": bson.M{"type": req.Reaction},
+		"$setOnInsert": bson.M{
+			"_id":        h.DB.IDs.New(),
+			"post_id":    comment.PostID,
+			"created_at": time.Now(),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	var previous models.Reaction
+	err = h.DB.Reactions.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent first reaction won the insert; update it instead
+		err = h.DB.Reactions.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	}
+	if err != nil && err != mongo.ErrNoDocuments {
+		logger.Ctx(c.Context()).Error("failed to upsert reaction", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to react to comment",
+		})
+	}
+	if err := h.DB.RecordReactionChange(ctx, comment, previous.Type, req.Reaction); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to update reaction counts", zap.Error(err))
+	}
+
+	return h.reactionState(c, ctx, commentID, req.Reaction)
+}
+
+// UnreactToComment handles DELETE /api/comments/:id/react requests.
+// Removes the requester's reaction if present. Idempotent like
+// ReactToComment.
+//
+// URL parameters:
+//   - id: string (required) - ID of the comment
+//
+// Response format:
+//   - 200: Success with the comment's reaction counts
+//   - 400: Invalid ID format
+//   - 404: Comment not found, or it belongs to a private post
+//   - 502: Database error
+func (h *Handler) UnreactToComment(c *fiber.Ctx) error {
+	// Parse and validate the comment ID from URL parameters
+	commentID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid comment ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	comment, done, err := h.reactableComment(c, ctx, commentID)
+	if done {
+		return err
+	}
+
+	// Remove the reaction and only decrement when one was actually removed
+	var previous models.Reaction
+	filter := bson.M{"comment_id": commentID, "user_key": h.requesterKey(c)}
+	err = h.DB.Reactions.FindOneAndDelete(ctx, filter).Decode(&previous)
+	if err != nil && err != mongo.ErrNoDocuments {
+		logger.Ctx(c.Context()).Error("failed to delete reaction", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to remove reaction",
+		})
+	}
+	if err := h.DB.RecordReactionChange(ctx, comment, previous.Type, ""); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to update reaction counts", zap.Error(err))
+	}
+
+	return h.reactionState(c, ctx, commentID, "")
+}
+
+// reactableComment loads the comment a reaction targets. Comments of
+// private posts are as hidden as the post itself.
+//
+// Returns done=true when a response was already written.
+func (h *Handler) reactableComment(c *fiber.Ctx, ctx context.Context, commentID models.ID) (models.Comment, bool, error) {
+	comment, err := h.Comments.Get(ctx, commentID)
+	if err == mongo.ErrNoDocuments {
+		return comment, true, c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Comment not found",
+		})
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch comment", zap.Error(err))
+		return comment, true, c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comment",
+		})
+	}
+
+	private, err := h.isPrivate(ctx, comment.PostID)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to check post visibility", zap.Error(err))
+		return comment, true, c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch comment",
+		})
+	}
+	if private {
+		return comment, true, c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Comment not found",
+		})
+	}
+	return comment, false, nil
+}
+
+// reactionState responds with the comment's current reaction counts and
+// the requester's reaction after a reaction or its removal.
+func (h *Handler) reactionState(c *fiber.Ctx, ctx context.Context, commentID models.ID, reaction string) error {
+	comment, err := h.Comments.Get(ctx, commentID)
+	if err != nil {
+		logger.Ctx(c.Context()).Warn("failed to read reaction counts", zap.Error(err))
+	}
+
+	state := fiber.Map{
+		"comment_id": commentID,
+		"reactions":  comment.Reactions,
+	}
+	if reaction != "" {
+		state["reaction"] = reaction
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: state})
+}
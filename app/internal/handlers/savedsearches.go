@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// MAX_SAVED_SEARCHES caps the saved searches of one user, since every
+// published post is checked against all of them.
+const MAX_SAVED_SEARCHES = 20
+
+// DEFAULT_ALERTS_PAGE_SIZE and MAX_ALERTS_PAGE_SIZE bound how many alerts
+// one GET /api/me/alerts page returns.
+const (
+	DEFAULT_ALERTS_PAGE_SIZE = 20
+	MAX_ALERTS_PAGE_SIZE     = 100
+)
+
+// GetSavedSearches handles GET /api/me/searches requests.
+// Returns the saved searches of the user the login JWT belongs to, oldest
+// first, with how many posts each matched.
+//
+// Response format:
+//   - 200: Success with array of SavedSearch objects
+//   - 401: Missing or invalid login JWT
+//   - 502: Database query error
+func (h *Handler) GetSavedSearches(c *fiber.Ctx) error {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Authentication required",
+		})
+	}
+
+	// Create context with timeout to prevent hanging database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := h.DB.SavedSearches.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch saved searches", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch saved searches",
+		})
+	}
+
+	searches := []models.SavedSearch{}
+	if err := cursor.All(ctx, &searches); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode saved searches", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch saved searches",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: searches})
+}
+
+// CreateSavedSearch handles POST /api/me/searches requests.
+// Saves a search for the user the login JWT belongs to. Public posts
+// published afterwards that match it are recorded as alerts, listed by
+// GetSearchAlerts.
+//
+// Request body should contain a SavedSearchRequest:
+//   - query: string (optional) - full-text query, as for GET /api/posts/search, at
+//     most MAX_SEARCH_QUERY_LENGTH characters
+//   - tags: []string (optional) - tags a post must all carry (see cleanPostTags)
+//
+// At least one of query and tags is required. Posts record no author, so
+// searches cannot be restricted to one.
+//
+// Response format:
+//   - 200: Success with the created SavedSearch
+//   - 400: Invalid JSON, neither query nor tags, a too long query, too many
+//     or too long tags, or MAX_SAVED_SEARCHES already saved
+//   - 401: Missing or invalid login JWT
+//   - 502: Database error
+func (h *Handler) CreateSavedSearch(c *fiber.Ctx) error {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Authentication required",
+		})
+	}
+
+	var req models.SavedSearchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	query := strings.TrimSpace(req.Query)
+	if utf8.RuneCountInString(query) > MAX_SEARCH_QUERY_LENGTH {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Search query too long",
+		})
+	}
+	tags, err := cleanPostTags(req.Tags)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "At most 10 tags of at most 32 characters",
+		})
+	}
+	if query == "" && len(tags) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Query or tags required",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	saved, err := h.DB.SavedSearches.CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to count saved searches", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to save search",
+		})
+	}
+	if saved >= MAX_SAVED_SEARCHES {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "At most 20 saved searches",
+		})
+	}
+
+	search := models.SavedSearch{
+		ID:        h.DB.IDs.New(),
+		UserID:    userID,
+		Query:     query,
+		Tags:      tags,
+		CreatedAt: time.Now(),
+	}
+	if _, err := h.DB.SavedSearches.InsertOne(ctx, search); err != nil {
+		logger.Ctx(c.Context()).Error("failed to save search", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to save search",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: search})
+}
+
+// DeleteSavedSearch handles DELETE /api/me/searches/:id requests.
+// Deletes one of the user's saved searches along with its alerts.
+//
+// URL parameters:
+//   - id: string (required) - ID of the saved search
+//
+// Response format:
+//   - 200: Saved search deleted
+//   - 400: Invalid ID format
+//   - 401: Missing or invalid login JWT
+//   - 404: No saved search of the user has this ID
+//   - 502: Database deletion error
+func (h *Handler) DeleteSavedSearch(c *fiber.Ctx) error {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Authentication required",
+		})
+	}
+	id, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid saved search ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	// Other users' searches are reported as missing
+	result, err := h.DB.SavedSearches.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to delete saved search", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to delete saved search",
+		})
+	}
+	if result.DeletedCount == 0 {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Saved search not found",
+		})
+	}
+	if _, err := h.DB.SearchAlerts.DeleteMany(ctx, bson.M{"search_id": id}); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to delete saved search alerts", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true})
+}
+
+// GetSearchAlerts handles GET /api/me/alerts requests.
+// Lists the posts that matched the user's saved searches, newest first.
+// Alerts expire after storage.SEARCH_ALERT_RETENTION.
+//
+// Query parameters:
+//   - page: int (optional) - 1-based page number
+//   - limit: int (optional) - alerts per page, default DEFAULT_ALERTS_PAGE_SIZE,
+//     capped at MAX_ALERTS_PAGE_SIZE
+//
+// Response format:
+//   - 200: Success with array of SearchAlert objects and pagination
+//   - 400: Invalid pagination
+//   - 401: Missing or invalid login JWT
+//   - 502: Database query error
+func (h *Handler) GetSearchAlerts(c *fiber.Ctx) error {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Authentication required",
+		})
+	}
+	page, limit, err := parsePageParams(c, "page", "limit", DEFAULT_ALERTS_PAGE_SIZE, MAX_ALERTS_PAGE_SIZE)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID}
+	total, err := h.DB.SearchAlerts.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to count search alerts", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch alerts",
+		})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := h.DB.SearchAlerts.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch search alerts", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch alerts",
+		})
+	}
+	alerts := []models.SearchAlert{}
+	if err := cursor.All(ctx, &alerts); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode search alerts", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch alerts",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    alerts,
+		Pagination: &models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	})
+}
+
+// alertSavedSearches records a SearchAlert for every saved search post
+// matches, run in the background when a public post is published (the
+// same moment chat integrations are notified). Only searches whose tags
+// the post all carries are considered; their queries are then run against
+// the post through the text index, so matching agrees with search.
+// Failures are logged and skip the affected searches.
+func (h *Handler) alertSavedSearches(post models.BlogPost) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.dbTimeout(DB_WRITE))
+	defer cancel()
+
+	// Searches asking for a tag the post lacks cannot match
+	tags := post.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	filter := bson.M{"tags": bson.M{"$not": bson.M{"$elemMatch": bson.M{"$nin": tags}}}}
+	cursor, err := h.DB.SavedSearches.Find(ctx, filter)
+	if err != nil {
+		logger.Warn("failed to fetch saved searches", zap.String("post_id", post.ID.String()), zap.Error(err))
+		return
+	}
+	var searches []models.SavedSearch
+	if err := cursor.All(ctx, &searches); err != nil {
+		logger.Warn("failed to decode saved searches", zap.String("post_id", post.ID.String()), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, search := range searches {
+		if search.Query != "" {
+			matched, err := h.DB.Posts.CountDocuments(ctx, bson.M{"_id": post.ID, "$text": bson.M{"$search": search.Query}})
+			if err != nil {
+				logger.Warn("failed to match saved search", zap.String("search_id", search.ID.String()), zap.Error(err))
+				continue
+			}
+			if matched == 0 {
+				continue
+			}
+		}
+
+		alert := models.SearchAlert{
+			ID:        h.DB.IDs.New(),
+			UserID:    search.UserID,
+			SearchID:  search.ID,
+			PostID:    post.ID,
+			Title:     post.Title,
+			CreatedAt: now,
+		}
+		if _, err := h.DB.SearchAlerts.InsertOne(ctx, alert); err != nil {
+			logger.Warn("failed to store search alert", zap.String("search_id", search.ID.String()), zap.Error(err))
+			continue
+		}
+		if _, err := h.DB.SavedSearches.UpdateByID(ctx, search.ID, bson.M{"$inc": bson.M{"matches": 1}}); err != nil {
+			logger.Warn("failed to count search match", zap.String("search_id", search.ID.String()), zap.Error(err))
+		}
+	}
+}
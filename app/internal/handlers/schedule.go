@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// publishAtLocalLayouts are the accepted publish_at forms without a UTC
+// offset, read as wall-clock time in the post's timezone.
+var publishAtLocalLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// errInvalidTimezone and errInvalidPublishAt report malformed scheduling
+// fields of post requests.
+var (
+	errInvalidTimezone  = errors.New("invalid timezone")
+	errInvalidPublishAt = errors.New("invalid publish_at")
+)
+
+// postTimezone resolves the time zone a post request is written in: the
+// request's timezone, else the author's timezone setting (see
+// UpdateSettings), else UTC.
+//
+// Returns the zone and its name, empty for UTC by default, or
+// errInvalidTimezone when the requested zone is unknown.
+func (h *Handler) postTimezone(ctx context.Context, c *fiber.Ctx, requested string) (*time.Location, string, error) {
+	name := requested
+	if name == "" {
+		name = h.requesterSettings(ctx, c).Timezone
+	}
+	if name == "" {
+		return time.UTC, "", nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, "", errInvalidTimezone
+	}
+	return loc, name, nil
+}
+
+// parsePublishAt reads a publish_at value. RFC 3339 times carry their own
+// offset; local times such as "2026-03-01T09:00" are read in loc, so an
+// author can schedule "9am" without working out the UTC offset, daylight
+// saving time included.
+//
+// Returns the time in UTC, or errInvalidPublishAt.
+func parsePublishAt(raw string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range publishAtLocalLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errInvalidPublishAt
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/schema"
+)
+
+// SCHEMA_CONTENT_TYPE is the media type of JSON Schema documents.
+const SCHEMA_CONTENT_TYPE = "application/schema+json"
+
+// requestSchemas maps the :type of GET /api/schema/:type to the
+// request DTO whose schema is served.
+var requestSchemas = map[string]any{
+	"post":           models.CreatePostRequest{},
+	"post-update":    models.UpdatePostRequest{},
+	"comment":        models.CreateCommentRequest{},
+	"comment-update": models.UpdateCommentRequest{},
+	"comment-import": models.ImportCommentsRequest{},
+	"reaction":       models.ReactRequest{},
+	"clap":           models.ClapRequest{},
+	"translation":    models.UpsertTranslationRequest{},
+	"assist-accept":  models.AcceptAssistRequest{},
+	"visibility":     models.SetVisibilityRequest{},
+	"passphrase":     models.PassphraseRequest{},
+	"integration":    models.CreateIntegrationRequest{},
+	"category":       models.CategoryRequest{},
+	"site-file":      models.SiteFileRequest{},
+	"auth":           models.AuthRequest{},
+	"settings":       models.UpdateSettingsRequest{},
+	"saved-search":   models.SavedSearchRequest{},
+	"api-key":        models.CreateAPIKeyRequest{},
+	"content-freeze": models.ContentFreezeRequest{},
+	"autosave":       models.AutosaveRequest{},
+	"moderation":     models.BulkModerationRequest{},
+}
+
+// GetSchema handles GET /api/schema/:type requests.
+// Returns the JSON Schema of a request body, generated from the models
+// package, so clients can validate payloads before sending them. Limits
+// that come from configuration, such as comment length, are filled in from
+// the running server's settings.
+//
+// URL parameters:
+//   - type: string (required) - one of post, post-update, comment, comment-update, comment-import,
+//     reaction, clap, translation, assist-accept, visibility, passphrase, integration, category, site-file, auth,
+//     settings, saved-search, api-key, content-freeze, autosave, moderation
+//
+// Response format:
+//   - 200: The JSON Schema document itself (application/schema+json)
+//   - 404: Unknown type, with the available types in the error message
+func (h *Handler) GetSchema(c *fiber.Ctx) error {
+	dto, ok := requestSchemas[c.Params("type")]
+	if !ok {
+		types := make([]string, 0, len(requestSchemas))
+		for name := range requestSchemas {
+			types = append(types, name)
+		}
+		sort.Strings(types)
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Unknown schema type, expected one of " + fmt.Sprint(types),
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(h.RequestSchema(dto), SCHEMA_CONTENT_TYPE)
+}
+
+// RequestSchema returns the JSON Schema of a request DTO (see schema.For),
+// with the limits that come from configuration, such as comment length,
+// filled in from h's settings.
+//
+// Parameters:
+//   - dto: a request DTO value, e.g. models.CreateCommentRequest{}
+func (h *Handler) RequestSchema(dto any) schema.Schema {
+	doc := schema.For(dto)
+
+	// Comment length limits are configurable, so they are not in the tags
+	switch dto.(type) {
+	case models.CreateCommentRequest, models.UpdateCommentRequest:
+		content := doc["properties"].(schema.Schema)["content"].(schema.Schema)
+		content["minLength"] = h.Config.CommentMinLength
+		if h.Config.CommentMaxLength > 0 {
+			content["maxLength"] = h.Config.CommentMaxLength
+		}
+	}
+	return doc
+}
+
+// VALIDATION_FAILED is the error of 422 responses, whose data lists the
+// broken rules per field.
+const VALIDATION_FAILED = "Validation failed"
+
+// validationFailed responds 422 with the rules a request breaks (see
+// schema.Validate).
+func validationFailed(c *fiber.Ctx, problems []schema.FieldError) error {
+	return c.Status(http.StatusUnprocessableEntity).JSON(models.APIResponse{
+		Success: false,
+		Data:    problems,
+		Error:   VALIDATION_FAILED,
+	})
+}
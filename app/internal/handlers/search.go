@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/content"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/plugins"
+	"github.com/pedrobertao/challenge-prosi/app/internal/search"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// MAX_SEARCH_QUERY_LENGTH is the longest accepted search query, in characters.
+const MAX_SEARCH_QUERY_LENGTH = 256
+
+// MAX_SEARCH_SNIPPETS is the number of content excerpts returned per result.
+const MAX_SEARCH_SNIPPETS = 3
+
+// searchHit is the decoding target of search queries: the summary fields,
+// the content snippets are cut from, and the text index score.
+type searchHit struct {
+	models.BlogPostHeader `bson:",inline"`
+	Content               string  `bson:"content"`
+	Score                 float64 `bson:"score"`
+}
+
+// SearchPosts handles GET /api/posts/search requests.
+// Runs a full-text search over post titles and content using the posts
+// text index (see storage.POSTS_TEXT_INDEX) and returns the matching posts
+// most relevant first, with highlighted excerpts. The query uses MongoDB
+// text search syntax: words match any stemmed form, "quoted phrases" must
+// appear as written, and -word excludes posts containing it.
+//
+// Results are filtered like GET /api/posts, except that passphrase-protected
+// posts are left out (visibility.PUBLISHED) so their content cannot be
+// probed through search, unless an administrator sets include_hidden.
+// The response's facets count the matches per language (see languageFacets).
+//
+// Query parameters:
+//   - q: string (required) - search query, at most MAX_SEARCH_QUERY_LENGTH characters
+//   - page, limit: int (optional) - pagination, as for GET /api/posts
+//   - include_archived, include_hidden, tag, category_id, lang, has_comments, min_comments,
+//     min_views (optional) - listing filters
+//
+// Response format:
+//   - 200: Success with []SearchResult, pagination metadata, and language facets
+//   - 400: Missing or too long query, invalid filter, or invalid pagination
+//   - 401: include_hidden=true without authentication
+//   - 403: include_hidden=true from a user who is not an administrator
+//   - 502: Database query error
+func (h *Handler) SearchPosts(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" || utf8.RuneCountInString(query) > MAX_SEARCH_QUERY_LENGTH {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid search query",
+		})
+	}
+
+	// Build the listing filter from query parameters
+	filter, err := h.postListFilter(c, visibility.PUBLISHED)
+	if err == errHiddenUnauthorized {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Authentication required",
+		})
+	}
+	if err == errHiddenForbidden {
+		return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Admin access required",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid filter",
+		})
+	}
+	filter["$text"] = bson.M{"$search": query}
+
+	page, limit, err := parsePage(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination",
+		})
+	}
+
+	// Create context with timeout to prevent hanging database operations
+	ctx, cancel := h.dbContext(c, DB_AGGREGATE)
+	defer cancel()
+
+	// Count all matches for the page metadata
+	total, err := h.DB.Posts.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to count search results", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to search posts",
+		})
+	}
+
+	// Fetch one page, most relevant first; _id breaks ties so pages never
+	// overlap. Content is needed to cut the excerpts.
+	score := bson.M{"$meta": "textScore"}
+	projection := bson.M{"content": 1, "score": score}
+	for field := range PostHeaderProjection {
+		projection[field] = 1
+	}
+	opts := options.Find().
+		SetProjection(projection).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := h.DB.Posts.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to search posts", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to search posts",
+		})
+	}
+	defer cursor.Close(ctx)
+
+	// Build the summaries, keeping the hits for scores and highlights
+	terms := search.Terms(query)
+	var hits []searchHit
+	summaries := []models.BlogPostSummary{}
+	for cursor.Next(ctx) {
+		var hit searchHit
+		if err := cursor.Decode(&hit); err != nil {
+			logger.Ctx(c.Context()).Warn("malformed post", zap.Error(err))
+			// Skip malformed posts and continue processing
+			continue
+		}
+		hits = append(hits, hit)
+		summaries = append(summaries, h.summarize(ctx, hit.BlogPostHeader))
+	}
+
+	// Flag the posts the requester already liked
+	h.markLiked(ctx, h.requesterKey(c), summaries)
+
+	results := make([]models.SearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = models.SearchResult{
+			BlogPostSummary: summaries[i],
+			Score:           hit.Score,
+			Highlights: models.SearchHighlights{
+				Title:   search.Highlight(hit.Title, terms),
+				Content: search.Snippets(content.PlainText(hit.Content), terms, MAX_SEARCH_SNIPPETS),
+			},
+		}
+	}
+
+	// Facets are extras: failing to count them does not fail the search
+	var facets map[string][]models.Facet
+	languages, err := h.languageFacets(ctx, filter)
+	if err != nil {
+		logger.Ctx(c.Context()).Warn("failed to count search language facets", zap.Error(err))
+	} else {
+		facets = map[string][]models.Facet{"language": languages}
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    h.Plugins.PreResponse(c, plugins.RESOURCE_POST_SEARCH, results),
+		Pagination: &models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+		Facets: facets,
+	})
+}
+
+// languageFacets counts the posts matching a search filter per detected
+// language, most posts first. The filter's own lang condition is left out,
+// so the counts show what choosing another language would return. Posts of
+// unknown language are not counted.
+func (h *Handler) languageFacets(ctx context.Context, filter bson.M) ([]models.Facet, error) {
+	match := bson.M{}
+	for key, value := range filter {
+		if key != "language" {
+			match[key] = value
+		}
+	}
+	cursor, err := h.DB.Posts.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": "$language", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Language string `bson:"_id"`
+		Count    int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	facets := []models.Facet{}
+	for _, group := range groups {
+		if group.Language != "" {
+			facets = append(facets, models.Facet{Value: group.Language, Count: group.Count})
+		}
+	}
+	return facets, nil
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/i18n"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// GetSettings handles GET /api/me/settings requests.
+// Returns the preferences of the user the login JWT belongs to.
+//
+// Response format:
+//   - 200: Success with the UserSettings object
+//   - 401: Missing or invalid login JWT
+//   - 404: The token's user no longer exists
+//   - 502: Database query error
+func (h *Handler) GetSettings(c *fiber.Ctx) error {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Authentication required",
+		})
+	}
+
+	// Create context with timeout to prevent hanging database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	settings, err := h.userSettings(ctx, userID)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch settings", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch settings",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: settings})
+}
+
+// UpdateSettings handles PUT /api/me/settings requests.
+// Replaces the preferences of the user the login JWT belongs to. Omitted
+// fields are reset to their defaults.
+//
+// Settings are honored as follows:
+//   - default_visibility: applied by CreatePost when a post sets none
+//   - locale: used to localize posts read without an Accept-Language header
+//   - timezone: stored on the user's new posts and used to read local
+//     publish_at times (see postTimezone)
+//   - moderate_comments, notification_channels: stored for clients; posts
+//     record no author yet, so no handler acts on them
+//
+// Request body should contain an UpdateSettingsRequest:
+//   - default_visibility: string (optional) - public, unlisted, or private
+//   - moderate_comments: bool (optional)
+//   - notification_channels: []string (optional) - integration drivers (slack, discord)
+//   - locale: string (optional) - language tag, e.g. "pt-BR"
+//   - timezone: string (optional) - IANA time zone name, e.g. "Europe/Lisbon"
+//
+// Response format:
+//   - 200: Success with the stored UserSettings object
+//   - 400: Invalid JSON or an invalid setting
+//   - 401: Missing or invalid login JWT
+//   - 404: The token's user no longer exists
+//   - 502: Database update error
+func (h *Handler) UpdateSettings(c *fiber.Ctx) error {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Authentication required",
+		})
+	}
+
+	var req models.UpdateSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	settings, message := normalizeSettings(req)
+	if message != "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Create context with timeout to prevent hanging database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	result, err := h.DB.Users.UpdateByID(ctx, userID, bson.M{"$set": bson.M{"settings": settings}})
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to update settings", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update settings",
+		})
+	}
+	if result.MatchedCount == 0 {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: settings})
+}
+
+// normalizeSettings validates a settings update and converts it to its
+// stored form: tags normalized and channels de-duplicated.
+//
+// Returns the settings, or an error message for the first invalid field.
+func normalizeSettings(req models.UpdateSettingsRequest) (models.UserSettings, string) {
+	settings := models.UserSettings{
+		DefaultVisibility:    req.DefaultVisibility,
+		ModerateComments:     req.ModerateComments,
+		NotificationChannels: []string{},
+		Locale:               i18n.Normalize(req.Locale),
+		Timezone:             req.Timezone,
+	}
+	if settings.DefaultVisibility != "" && !models.ValidVisibility(settings.DefaultVisibility) {
+		return settings, "Unknown default visibility, expected public, unlisted, or private"
+	}
+	seen := make(map[string]bool)
+	for _, channel := range req.NotificationChannels {
+		if !models.ValidIntegrationDriver(channel) {
+			return settings, "Unknown notification channel: " + channel
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			settings.NotificationChannels = append(settings.NotificationChannels, channel)
+		}
+	}
+	if settings.Locale != "" && !i18n.Valid(settings.Locale) {
+		return settings, "Invalid locale"
+	}
+	if settings.Timezone != "" {
+		if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "Local" {
+			return settings, "Unknown timezone"
+		}
+	}
+	return settings, ""
+}
+
+// currentUserID returns the ID of the user whose login JWT authenticated
+// the request. Requests authenticated by an API key have no user.
+func (h *Handler) currentUserID(c *fiber.Ctx) (models.ID, bool) {
+	claims, ok := middleware.CurrentUser(c)
+	if !ok {
+		return "", false
+	}
+	userID, err := h.DB.IDs.Parse(claims.Subject)
+	return userID, err == nil
+}
+
+// userSettings loads the settings of a user.
+//
+// Returns mongo.ErrNoDocuments if the user does not exist.
+func (h *Handler) userSettings(ctx context.Context, userID models.ID) (models.UserSettings, error) {
+	var user models.User
+	opts := options.FindOne().SetProjection(bson.M{"settings": 1})
+	if err := h.DB.Users.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&user); err != nil {
+		return models.UserSettings{}, err
+	}
+	if user.Settings.NotificationChannels == nil {
+		user.Settings.NotificationChannels = []string{}
+	}
+	return user.Settings, nil
+}
+
+// requesterSettings loads the settings of the user behind the request's
+// login JWT, if any, for handlers applying preferences. Any failure, or an
+// anonymous request, yields the defaults: preferences never fail a request.
+func (h *Handler) requesterSettings(ctx context.Context, c *fiber.Ctx) models.UserSettings {
+	// Public endpoints do not run RequireAuth; verify the token here
+	if !middleware.Authenticated(c, h.Auth) {
+		return models.UserSettings{}
+	}
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return models.UserSettings{}
+	}
+	settings, err := h.userSettings(ctx, userID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		logger.Ctx(c.Context()).Warn("failed to load user settings", zap.Error(err))
+	}
+	return settings
+}
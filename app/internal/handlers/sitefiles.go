@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/site"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Site files served at the application root.
+const (
+	ROBOTS_TXT = "robots.txt"
+	HUMANS_TXT = "humans.txt"
+)
+
+// MAX_SITE_FILE_BYTES caps custom site file content.
+const MAX_SITE_FILE_BYTES = 64 << 10
+
+// SITE_FILE_MAX_AGE is how long clients may cache a site file, in seconds.
+const SITE_FILE_MAX_AGE = "3600"
+
+// siteFileMetaID is the meta document holding a site file's custom content.
+func siteFileMetaID(name string) string { return "site-file:" + name }
+
+// validSiteFile reports whether name is one of the served site files.
+func validSiteFile(name string) bool {
+	return name == ROBOTS_TXT || name == HUMANS_TXT
+}
+
+// siteFile returns the custom content stored for name, or the content
+// generated from configuration when there is none.
+func (h *Handler) siteFile(ctx context.Context, name string) (models.SiteFile, error) {
+	file := models.SiteFile{Name: name}
+	err := h.DB.Meta.FindOne(ctx, bson.M{"_id": siteFileMetaID(name)}).Decode(&file)
+	if err == nil {
+		file.Name = name
+		file.Custom = true
+		return file, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return file, err
+	}
+
+	switch name {
+	case ROBOTS_TXT:
+		file.Content = site.Robots(h.Config.RobotsDisallow, h.Config.SitemapURL)
+	case HUMANS_TXT:
+		lastModified, err := h.DB.LastModified(ctx, storage.POSTS_META_KEY)
+		if err != nil {
+			return file, err
+		}
+		file.Content = site.Humans(h.Config.HumansTeam, lastModified)
+	}
+	return file, nil
+}
+
+// serveSiteFile writes the site file name as plain text.
+func (h *Handler) serveSiteFile(c *fiber.Ctx, name string) error {
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	file, err := h.siteFile(ctx, name)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to load site file", zap.String("name", name), zap.Error(err))
+		return c.Status(http.StatusBadGateway).SendString("Failed to load " + name)
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age="+SITE_FILE_MAX_AGE)
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString(file.Content)
+}
+
+// GetRobots handles GET /robots.txt requests.
+// Serves the crawler rules: by default every crawler is asked to skip the
+// ROBOTS_DISALLOW prefixes, and SITEMAP_URL is announced when set.
+//
+// Response format:
+//   - 200: robots.txt as text/plain
+//   - 502: Database query error
+func (h *Handler) GetRobots(c *fiber.Ctx) error {
+	return h.serveSiteFile(c, ROBOTS_TXT)
+}
+
+// GetHumans handles GET /humans.txt requests.
+// Serves the people behind the blog: by default the HUMANS_TEAM entries
+// and the date posts last changed.
+//
+// Response format:
+//   - 200: humans.txt as text/plain
+//   - 502: Database query error
+func (h *Handler) GetHumans(c *fiber.Ctx) error {
+	return h.serveSiteFile(c, HUMANS_TXT)
+}
+
+// GetSiteFile handles GET /api/admin/site-files/:name requests.
+// Returns the content currently served for a site file and whether it is
+// custom or generated.
+//
+// URL parameters:
+//   - name: string (required) - robots.txt or humans.txt
+//
+// Response format:
+//   - 200: Success with a SiteFile object
+//   - 404: Unknown site file
+//   - 502: Database query error
+func (h *Handler) GetSiteFile(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if !validSiteFile(name) {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Unknown site file",
+		})
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	file, err := h.siteFile(ctx, name)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to load site file", zap.String("name", name), zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch site file",
+		})
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: file})
+}
+
+// PutSiteFile handles PUT /api/admin/site-files/:name requests.
+// Replaces the generated content of a site file with custom text, served
+// until it is removed with DeleteSiteFile.
+//
+// URL parameters:
+//   - name: string (required) - robots.txt or humans.txt
+//
+// Request body should contain:
+//   - content: string (required) - full file text, at most MAX_SITE_FILE_BYTES
+//
+// Response format:
+//   - 200: Success with the stored SiteFile
+//   - 400: Invalid JSON, or missing or too long content
+//   - 404: Unknown site file
+//   - 502: Database update error
+func (h *Handler) PutSiteFile(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if !validSiteFile(name) {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Unknown site file",
+		})
+	}
+
+	// Parse the request body into the expected structure
+	var req models.SiteFileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	if req.Content == "" || len(req.Content) > MAX_SITE_FILE_BYTES {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Content required, at most 64 KiB",
+		})
+	}
+
+	// Create context with timeout for database operation
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	now := time.Now()
+	_, err := h.DB.Meta.UpdateOne(ctx,
+		bson.M{"_id": siteFileMetaID(name)},
+		bson.M{"$set": bson.M{"content": req.Content, "updated_at": now}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to store site file", zap.String("name", name), zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to store site file",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: models.SiteFile{
+		Name:      name,
+		Content:   req.Content,
+		Custom:    true,
+		UpdatedAt: &now,
+	}})
+}
+
+// DeleteSiteFile handles DELETE /api/admin/site-files/:name requests.
+// Removes the custom content of a site file, so the generated content is
+// served again. Removing a file that has no custom content is not an error.
+//
+// URL parameters:
+//   - name: string (required) - robots.txt or humans.txt
+//
+// Response format:
+//   - 200: Success with the generated SiteFile now served
+//   - 404: Unknown site file
+//   - 502: Database error
+func (h *Handler) DeleteSiteFile(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if !validSiteFile(name) {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Unknown site file",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	if _, err := h.DB.Meta.DeleteOne(ctx, bson.M{"_id": siteFileMetaID(name)}); err != nil {
+		logger.Ctx(c.Context()).Error("failed to delete site file", zap.String("name", name), zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to delete site file",
+		})
+	}
+
+	file, err := h.siteFile(ctx, name)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to load site file", zap.String("name", name), zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch site file",
+		})
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: file})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// NDJSON_CONTENT_TYPE is the media type for newline-delimited JSON streams.
+const NDJSON_CONTENT_TYPE = "application/x-ndjson"
+
+// DEFAULT_STREAM_TIMEOUT bounds how long a streamed listing or export may
+// keep its cursor open. Streams serve whole collections, so they get a much
+// longer budget than regular request/response database calls.
+const DEFAULT_STREAM_TIMEOUT = 5 * time.Minute
+
+// wantsNDJSON reports whether the client prefers an NDJSON stream over the
+// regular JSON envelope, based on content negotiation of the Accept header.
+func wantsNDJSON(c *fiber.Ctx) bool {
+	return c.Accepts(fiber.MIMEApplicationJSON, NDJSON_CONTENT_TYPE) == NDJSON_CONTENT_TYPE
+}
+
+// streamCursor writes every document of the cursor to the response as one
+// JSON line, flushing after each line so memory stays flat regardless of
+// result size. The stream writer runs after the handler returns, so it owns
+// the context and the cursor and releases both when the stream ends.
+//
+// Parameters:
+//   - c: Fiber context of the request being answered
+//   - ctx: context the cursor was opened with (must outlive the handler)
+//   - cancel: cancel function of ctx, called once streaming finishes
+//   - cursor: open cursor to drain
+//   - next: converts the current cursor document into the value to encode;
+//     returning false skips the document
+func streamCursor(c *fiber.Ctx, ctx context.Context, cancel context.CancelFunc, cursor *mongo.Cursor, next func(ctx context.Context, cursor *mongo.Cursor) (any, bool)) error {
+	// c is released before the writer runs; keep only its logger
+	requestLog := logger.Ctx(c.Context())
+	c.Set(fiber.HeaderContentType, NDJSON_CONTENT_TYPE)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		defer cursor.Close(ctx)
+
+		encoder := json.NewEncoder(w)
+		for cursor.Next(ctx) {
+			value, ok := next(ctx, cursor)
+			if !ok {
+				continue
+			}
+			if err := encoder.Encode(value); err != nil {
+				requestLog.Warn("failed to encode streamed document", zap.Error(err))
+				return
+			}
+			// Flush per line; a write error means the client went away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			requestLog.Error("stream cursor failed", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+// streamPosts answers GET /api/posts with NDJSON, one BlogPostSummary per line.
+// Uses the same filter, sort, and projection as the buffered listing.
+func (h *Handler) streamPosts(c *fiber.Ctx, filter bson.M, sort bson.D) error {
+	// The stream outlives the request handler, so it cannot be bound to c.Context()
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_STREAM_TIMEOUT)
+
+	opts := options.Find().SetProjection(PostHeaderProjection).SetSort(sort)
+	cursor, err := h.Posts.Stream(ctx, filter, opts)
+	if err != nil {
+		cancel()
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch posts",
+		})
+	}
+
+	requestLog := logger.Ctx(c.Context())
+	return streamCursor(c, ctx, cancel, cursor, func(ctx context.Context, cursor *mongo.Cursor) (any, bool) {
+		var post models.BlogPostHeader
+		if err := cursor.Decode(&post); err != nil {
+			requestLog.Warn("malformed post", zap.Error(err))
+			return nil, false
+		}
+		return h.summarize(ctx, post), true
+	})
+}
+
+// ExportPosts handles GET /api/posts/export requests.
+// Streams every blog post, including its full content, as NDJSON with one
+// BlogPost per line. Intended for backups and migrations of large blogs, so
+// documents are never buffered in memory. Unlisted and private posts are
+// included, since an export is a backup rather than a listing, so the route
+// is reserved for administrators (see middleware.RequireAdmin).
+//
+// Response format:
+//   - 200: NDJSON stream of BlogPost objects
+//   - 401: No administrator login token
+//   - 403: Not a site administrator
+//   - 502: Database connection or query error
+func (h *Handler) ExportPosts(c *fiber.Ctx) error {
+	// The stream outlives the request handler, so it cannot be bound to c.Context()
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_STREAM_TIMEOUT)
+
+	cursor, err := h.Posts.Stream(ctx, bson.M{}, options.Find())
+	if err != nil {
+		cancel()
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to export posts",
+		})
+	}
+
+	requestLog := logger.Ctx(c.Context())
+	return streamCursor(c, ctx, cancel, cursor, func(ctx context.Context, cursor *mongo.Cursor) (any, bool) {
+		var post models.BlogPost
+		if err := cursor.Decode(&post); err != nil {
+			requestLog.Warn("malformed post", zap.Error(err))
+			return nil, false
+		}
+		return post, true
+	})
+}
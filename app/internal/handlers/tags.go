@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// MAX_POST_TAGS and MAX_TAG_LENGTH bound the tags of a post; the length is
+// in characters.
+const (
+	MAX_POST_TAGS  = 10
+	MAX_TAG_LENGTH = 32
+)
+
+// errInvalidTags is returned by cleanPostTags.
+var errInvalidTags = errors.New("at most 10 tags of at most 32 characters")
+
+// cleanPostTags normalizes the tags sent for a post: trimmed, lowercased,
+// inner whitespace collapsed, de-duplicated, empty ones dropped. Unlike
+// assistant.CleanTags it rejects too many or too long tags instead of
+// cutting them, so authors never lose a tag silently.
+func cleanPostTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MAX_TAG_LENGTH {
+			return nil, errInvalidTags
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+	if len(cleaned) > MAX_POST_TAGS {
+		return nil, errInvalidTags
+	}
+	return cleaned, nil
+}
+
+// GetTags handles GET /api/tags requests.
+// Lists every tag used by published posts with the number of posts
+// carrying it, most used first and alphabetically among equals. Counts
+// cover the posts of visibility.PUBLISHED, so unlisted, private,
+// archived, protected, and scheduled posts never reveal their tags.
+//
+// Response format:
+//   - 200: Success with array of TagCount objects
+//   - 502: Database query error
+func (h *Handler) GetTags(c *fiber.Ctx) error {
+	// Create context with timeout to prevent hanging database operations
+	ctx, cancel := h.dbContext(c, DB_AGGREGATE)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: visibility.PUBLISHED.BSON()}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "post_count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "post_count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := h.DB.Posts.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to aggregate tags", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch tags",
+		})
+	}
+	defer cursor.Close(ctx)
+
+	tags := []models.TagCount{}
+	if err := cursor.All(ctx, &tags); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode tags", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch tags",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: tags})
+}
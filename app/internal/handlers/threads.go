@@ -0,0 +1,66 @@
+package handlers
+
+import "github.com/pedrobertao/challenge-prosi/app/internal/models"
+
+// threadComments nests each comment of a page under the comment it replies
+// to, keeping the page's order at every level. Replies deeper than
+// maxDepth levels are attached to their ancestor at the last level, so no
+// reply is hidden. Replies whose parent is not in the page (on another
+// page, or deleted before threading existed) stay top-level and keep their
+// parent_comment_id. A maxDepth of 0 returns comments unchanged.
+//
+// The input is never modified, because joined comments may be shared
+// between requests (see Handler.Reads).
+func threadComments(comments []models.Comment, maxDepth int) []models.Comment {
+	if maxDepth <= 0 || len(comments) == 0 {
+		return comments
+	}
+
+	parents := make(map[models.ID]models.ID, len(comments))
+	for _, comment := range comments {
+		parents[comment.ID] = comment.ParentID
+	}
+
+	// Each reply hangs from its nearest ancestor at most maxDepth-1 levels
+	// down; the walk is bounded so malformed imported threads cannot loop
+	attachTo := make(map[models.ID]models.ID, len(comments))
+	for _, comment := range comments {
+		var ancestors []models.ID
+		for id := comment.ParentID; !id.IsZero() && len(ancestors) < len(comments); id = parents[id] {
+			if _, ok := parents[id]; !ok {
+				break
+			}
+			if id == comment.ID {
+				ancestors = nil // A cycle through the comment; show it top-level
+				break
+			}
+			ancestors = append(ancestors, id)
+		}
+		if len(ancestors) > 0 {
+			attachTo[comment.ID] = ancestors[max(len(ancestors)-maxDepth, 0)]
+		}
+	}
+
+	replies := make(map[models.ID][]models.Comment)
+	var roots []models.Comment
+	for _, comment := range comments {
+		if parent, ok := attachTo[comment.ID]; ok {
+			replies[parent] = append(replies[parent], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	var nest func([]models.Comment) []models.Comment
+	nest = func(level []models.Comment) []models.Comment {
+		nested := make([]models.Comment, len(level))
+		for i, comment := range level {
+			if children := replies[comment.ID]; len(children) > 0 {
+				comment.Replies = nest(children)
+			}
+			nested[i] = comment
+		}
+		return nested
+	}
+	return nest(roots)
+}
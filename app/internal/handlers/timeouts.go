@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DBOperation classifies database work by its expected cost, selecting
+// the configured timeout that bounds it.
+type DBOperation int
+
+const (
+	DB_READ        DBOperation = iota // Finds and counts, bounded by DB_READ_TIMEOUT
+	DB_WRITE                          // Inserts, updates, and deletes, bounded by DB_WRITE_TIMEOUT
+	DB_AGGREGATE                      // Aggregation pipelines, bounded by DB_AGGREGATE_TIMEOUT
+	DB_TRANSACTION                    // Multi-document sessions, bounded by DB_TRANSACTION_TIMEOUT
+)
+
+// dbTimeout returns the configured timeout of an operation class.
+func (h *Handler) dbTimeout(op DBOperation) time.Duration {
+	switch op {
+	case DB_WRITE:
+		return h.Config.DBWriteTimeout
+	case DB_AGGREGATE:
+		return h.Config.DBAggregateTimeout
+	case DB_TRANSACTION:
+		return h.Config.DBTransactionTimeout
+	}
+	return h.Config.DBReadTimeout
+}
+
+// dbContext returns the context of a request's database operations of
+// class op, bounded by the class timeout (see requestContext).
+func (h *Handler) dbContext(c *fiber.Ctx, op DBOperation) (context.Context, context.CancelFunc) {
+	return h.requestContext(c, h.dbTimeout(op))
+}
+
+// requestContext returns a context for a request's work expected to take
+// up to timeout, which is capped by DB_MAX_TIMEOUT. A sooner deadline set
+// on the request's user context, e.g. by a timeout middleware, is kept, so
+// the database is not kept busy for a client that stopped waiting.
+func (h *Handler) requestContext(c *fiber.Ctx, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if max := h.Config.DBMaxTimeout; max > 0 && timeout > max {
+		timeout = max
+	}
+	deadline := time.Now().Add(timeout)
+	if client, ok := c.UserContext().Deadline(); ok && client.Before(deadline) {
+		deadline = client
+	}
+	return context.WithDeadline(c.Context(), deadline)
+}
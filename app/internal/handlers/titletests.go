@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// MAX_TITLE_VARIANTS is the number of alternative headlines a post can
+// test against its title.
+const MAX_TITLE_VARIANTS = 4
+
+// errTooManyTitleVariants is returned by cleanTitleVariants.
+var errTooManyTitleVariants = errors.New("at most 4 title variants")
+
+// cleanTitleVariants trims variants and drops empty ones and repeats,
+// including repeats of title.
+func cleanTitleVariants(variants []string, title string) ([]string, error) {
+	seen := map[string]bool{strings.TrimSpace(title): true}
+	cleaned := []string{}
+	for _, variant := range variants {
+		variant = strings.TrimSpace(variant)
+		if variant == "" || seen[variant] {
+			continue
+		}
+		seen[variant] = true
+		cleaned = append(cleaned, variant)
+	}
+	if len(cleaned) > MAX_TITLE_VARIANTS {
+		return nil, errTooManyTitleVariants
+	}
+	return cleaned, nil
+}
+
+// titleVariant picks the headline a visitor sees: 0 for the title, 1 to
+// variants for TitleVariants. The choice hashes the requester key with the
+// post ID, so a visitor keeps seeing the same headline on every request and
+// instance, while different posts split visitors independently.
+func titleVariant(visitorKey string, postID models.ID, variants int) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(visitorKey + "|" + postID.String()))
+	return int(hash.Sum32() % uint32(variants+1))
+}
+
+// variantTitle returns headline number variant of a post.
+func variantTitle(title string, variants []string, variant int) string {
+	if variant == 0 || variant > len(variants) {
+		return title
+	}
+	return variants[variant-1]
+}
+
+// applyTitleTests swaps in each visitor's headline on the listed posts that
+// run a title test and counts the impressions. summaries must be built from
+// headers, in the same order. Counter failures are logged, not returned.
+func (h *Handler) applyTitleTests(ctx context.Context, c *fiber.Ctx, headers []models.BlogPostHeader, summaries []models.BlogPostSummary) {
+	shown := map[models.ID]int{}
+	visitor := h.requesterKey(c)
+	for i, header := range headers {
+		if len(header.TitleVariants) == 0 {
+			continue
+		}
+		variant := titleVariant(visitor, header.ID, len(header.TitleVariants))
+		summaries[i].Title = variantTitle(header.Title, header.TitleVariants, variant)
+		summaries[i].TitleVariant = variant
+		shown[header.ID] = variant
+	}
+	if len(shown) == 0 {
+		return
+	}
+	if err := h.Posts.RecordTitleImpressions(ctx, shown); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to record title impressions", zap.Error(err))
+	}
+}
+
+// applyTitleTest serves a visitor's headline on a post that runs a title
+// test and counts the read as a click on it.
+func (h *Handler) applyTitleTest(ctx context.Context, c *fiber.Ctx, post *models.BlogPost) {
+	if len(post.TitleVariants) == 0 {
+		return
+	}
+	variant := titleVariant(h.requesterKey(c), post.ID, len(post.TitleVariants))
+	post.Title = variantTitle(post.Title, post.TitleVariants, variant)
+	post.TitleVariant = variant
+	if err := h.Posts.RecordTitleClick(ctx, post.ID, variant); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to record title click", zap.Error(err))
+	}
+}
+
+// GetTitleTest handles GET /api/admin/posts/:id/title-test requests.
+// Returns the impressions, clicks, and click rate of every headline of a
+// post's title test, so the author can pick the winner and make it the
+// title with PUT /api/posts/:id.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Response format:
+//   - 200: Success with a TitleTestResults object
+//   - 400: Invalid ID format
+//   - 404: Post not found, or the post has no title variants
+//   - 502: Database query error
+func (h *Handler) GetTitleTest(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	var post models.BlogPost
+	opts := options.FindOne().SetProjection(bson.M{"title": 1, "title_variants": 1, "title_test": 1})
+	err = h.DB.Posts.FindOne(ctx, bson.M{"_id": postID}, opts).Decode(&post)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch title test", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch title test",
+		})
+	}
+	if len(post.TitleVariants) == 0 {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post has no title test",
+		})
+	}
+
+	results := models.TitleTestResults{PostID: postID}
+	bestRate := -1.0
+	for variant := 0; variant <= len(post.TitleVariants); variant++ {
+		result := models.TitleTestResult{
+			Variant: variant,
+			Title:   variantTitle(post.Title, post.TitleVariants, variant),
+		}
+		if variant < len(post.TitleTest) {
+			result.TitleVariantStats = post.TitleTest[variant]
+		}
+		if result.Impressions > 0 {
+			result.ClickRate = float64(result.Clicks) / float64(result.Impressions)
+			if result.ClickRate > bestRate {
+				leader := variant
+				bestRate, results.Leader = result.ClickRate, &leader
+			}
+		}
+		results.Variants = append(results.Variants, result)
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: results})
+}
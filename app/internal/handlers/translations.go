@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/i18n"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// UpsertTranslation handles POST /api/posts/:id/translations/:lang requests.
+// Creates or replaces the localized title and content of a post in one
+// language. Language tags are normalized (e.g., "pt_BR" becomes "pt-br").
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//   - lang: string (required) - language tag of the translation
+//
+// Request body should contain:
+//   - title: string (required) - Localized title
+//   - content: string (required) - Localized content
+//
+// Response format:
+//   - 200: Success with the stored Translation object
+//   - 400: Invalid post ID, language tag, JSON, or missing fields
+//   - 404: Post not found
+//   - 502: Database error
+func (h *Handler) UpsertTranslation(c *fiber.Ctx) error {
+	// Parse and validate the post ID and language from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+	lang := i18n.Normalize(c.Params("lang"))
+	if !i18n.Valid(lang) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid language",
+		})
+	}
+
+	// Parse the request body into the expected structure
+	var req models.UpsertTranslationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+
+	// Validate required fields, after removing or escaping unsafe markup
+	req.Content = h.Sanitizer.Sanitize(req.Content)
+	if req.Title == "" || req.Content == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Title and content required",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	// Verify that the target post exists before translating it
+	count, err := h.DB.Posts.CountDocuments(ctx, bson.M{"_id": postID})
+	if err != nil || count == 0 {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+
+	// Insert or replace the translation for this language
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"title":      req.Title,
+			"content":    req.Content,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{"_id": h.DB.IDs.New(), "created_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var translation models.Translation
+	err = h.DB.Translations.FindOneAndUpdate(ctx, bson.M{"post_id": postID, "lang": lang}, update, opts).Decode(&translation)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to upsert translation", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to save translation",
+		})
+	}
+
+	// Localized responses of the post changed
+	if err := h.DB.TouchPost(ctx, postID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: translation})
+}
+
+// GetTranslation handles GET /api/posts/:id/translations/:lang requests.
+// Returns the stored translation of a post in exactly the requested language.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//   - lang: string (required) - language tag of the translation
+//
+// Response format:
+//   - 200: Success with Translation object
+//   - 400: Invalid post ID or language tag
+//   - 401: Post is protected and no valid access token was sent
+//   - 404: No translation in that language, or the post is private
+//   - 500: Database query error
+//   - 502: Visibility check error
+func (h *Handler) GetTranslation(c *fiber.Ctx) error {
+	// Parse and validate the post ID and language from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+	lang := i18n.Normalize(c.Params("lang"))
+	if !i18n.Valid(lang) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid language",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	// Private posts are hidden from public read paths, and protected ones
+	// need their passphrase
+	if done, err := h.rejectLocked(c, ctx, postID); done {
+		return err
+	}
+
+	var translation models.Translation
+	err = h.DB.Translations.FindOne(ctx, bson.M{"post_id": postID, "lang": lang}).Decode(&translation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Translation not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to fetch translation", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch translation",
+		})
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: translation})
+}
+
+// localize replaces the post's title and content with the translation best
+// matching the request's Accept-Language header, or the requesting user's
+// locale setting when the header is absent. The original is kept when
+// neither is known, when the original language is preferred, or when
+// no acceptable translation exists. Lookup errors are logged and fall back
+// to the original so localization never fails a read.
+func (h *Handler) localize(ctx context.Context, c *fiber.Ctx, post *models.BlogPost) {
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	preferred := i18n.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if len(preferred) == 0 && c.Get(fiber.HeaderAuthorization) != "" {
+		c.Vary(fiber.HeaderAuthorization)
+		if locale := h.requesterSettings(ctx, c).Locale; locale != "" {
+			preferred = i18n.ParseAcceptLanguage(locale)
+		}
+	}
+	if len(preferred) == 0 {
+		return
+	}
+
+	// Load only the translations the client would accept
+	cursor, err := h.DB.Translations.Find(ctx, bson.M{
+		"post_id": post.ID,
+		"lang":    bson.M{"$in": preferred},
+	})
+	if err != nil {
+		logger.Ctx(c.Context()).Warn("failed to look up translations", zap.Error(err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var translations []models.Translation
+	if err := cursor.All(ctx, &translations); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to decode translations", zap.Error(err))
+		return
+	}
+
+	// The original competes with translations when its language is known
+	available := make([]string, 0, len(translations)+1)
+	byLang := make(map[string]models.Translation, len(translations))
+	for _, translation := range translations {
+		available = append(available, translation.Lang)
+		byLang[translation.Lang] = translation
+	}
+	if post.Language != "" {
+		available = append(available, i18n.Normalize(post.Language))
+	}
+
+	best := i18n.Best(preferred, available)
+	translation, ok := byLang[best]
+	if !ok {
+		if post.Language != "" {
+			c.Set(fiber.HeaderContentLanguage, post.Language)
+		}
+		return
+	}
+
+	post.Title = translation.Title
+	post.Content = translation.Content
+	post.Language = translation.Lang
+	c.Set(fiber.HeaderContentLanguage, translation.Lang)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_TRASH_PAGE_SIZE and MAX_TRASH_PAGE_SIZE bound the pages of
+// GET /api/trash.
+const (
+	DEFAULT_TRASH_PAGE_SIZE = 20
+	MAX_TRASH_PAGE_SIZE     = 100
+)
+
+// TrashItemProjection leaves the trashed documents on the server when
+// listing the trash.
+var TrashItemProjection = bson.M{"kind": 1, "post_id": 1, "title": 1, "deleted_at": 1}
+
+// GetTrash handles GET /api/trash requests.
+// Lists the deleted posts and comments that can still be restored, most
+// recently deleted first. Each item reports when the purge job removes it
+// for good (see TRASH_RETENTION); purge_at is omitted when the trash is
+// never purged.
+//
+// Query parameters:
+//   - kind: string (optional) - post or comment; both by default
+//   - page: int (optional) - 1-based page number
+//   - limit: int (optional) - items per page, default DEFAULT_TRASH_PAGE_SIZE,
+//     capped at MAX_TRASH_PAGE_SIZE
+//
+// Response format:
+//   - 200: Success with array of TrashItem objects and pagination
+//   - 400: Unknown kind or invalid pagination
+//   - 502: Database query error
+func (h *Handler) GetTrash(c *fiber.Ctx) error {
+	filter := bson.M{}
+	switch kind := c.Query("kind"); kind {
+	case "":
+	case models.TRASH_POST, models.TRASH_COMMENT:
+		filter["kind"] = kind
+	default:
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid kind, expected post or comment",
+		})
+	}
+	page, limit, err := parsePageParams(c, "page", "limit", DEFAULT_TRASH_PAGE_SIZE, MAX_TRASH_PAGE_SIZE)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_READ)
+	defer cancel()
+
+	total, err := h.DB.Trash.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to count trash", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch trash",
+		})
+	}
+
+	opts := options.Find().
+		SetProjection(TrashItemProjection).
+		SetSort(bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := h.DB.Trash.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to fetch trash", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch trash",
+		})
+	}
+	items := []models.TrashItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		logger.Ctx(c.Context()).Error("failed to decode trash", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch trash",
+		})
+	}
+	for i := range items {
+		items[i].PurgeAt = h.Purger.PurgeAt(items[i].DeletedAt)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    items,
+		Pagination: &models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	})
+}
+
+// RestorePost handles POST /api/posts/:id/restore requests.
+// Moves a trashed post back with the comments, likes, and translations
+// trashed along with it. Comments trashed on their own before the post
+// stay in the trash and are restored separately.
+//
+// URL parameters:
+//   - id: string (required) - ID of the trashed post
+//
+// Response format:
+//   - 200: Success with the restored post's ID
+//   - 400: Invalid ID format
+//   - 404: Post not in the trash, never deleted or already purged
+//   - 502: Database error
+func (h *Handler) RestorePost(c *fiber.Ctx) error {
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_TRANSACTION)
+	defer cancel()
+
+	if err := h.Posts.Restore(ctx, postID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not in trash",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to restore post", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to restore post",
+		})
+	}
+
+	// The post is back in the listing with its comments
+	h.Counts.Invalidate(postID.String())
+	if err := h.Posts.TouchPost(ctx, postID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: postID})
+}
+
+// RestoreComment handles POST /api/comments/:id/restore requests.
+// Moves a trashed comment back onto its post with the replies trashed
+// along with it. A comment whose post or parent comment is trashed too can
+// only be restored once they are.
+//
+// URL parameters:
+//   - id: string (required) - ID of the trashed comment
+//
+// Response format:
+//   - 200: Success with the restored Comment object
+//   - 400: Invalid ID format
+//   - 404: Comment not in the trash, never deleted or already purged
+//   - 409: The comment's post or parent comment is trashed or purged
+//   - 502: Database error
+func (h *Handler) RestoreComment(c *fiber.Ctx) error {
+	commentID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid comment ID",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_TRANSACTION)
+	defer cancel()
+
+	comment, restored, err := h.Comments.Restore(ctx, commentID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Comment not in trash",
+			})
+		}
+		if err == storage.ErrPostMissing {
+			return c.Status(http.StatusConflict).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post of the comment is deleted",
+			})
+		}
+		if err == storage.ErrParentMissing {
+			return c.Status(http.StatusConflict).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Parent comment is deleted",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to restore comment", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to restore comment",
+		})
+	}
+
+	// The comments are back, changing both the post and its listing count
+	h.Counts.Invalidate(comment.PostID.String())
+	if err := h.Posts.RecordCommentChange(ctx, comment.PostID, restored); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+	return c.JSON(models.APIResponse{Success: true, Data: comment})
+}
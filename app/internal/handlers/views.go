@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+)
+
+// Response views of GET /api/posts/:id, chosen with the view query
+// parameter.
+const (
+	VIEW_FULL   = "full"   // The whole BlogPost (default)
+	VIEW_MOBILE = "mobile" // A models.MobilePost, for the mobile apps
+)
+
+// Content formats of GET /api/posts/:id, chosen with the format query
+// parameter. Content is stored and always served as Markdown; the html
+// format adds it rendered.
+const (
+	FORMAT_MARKDOWN = "markdown" // Content only (default)
+	FORMAT_HTML     = "html"     // Content plus sanitized content_html
+)
+
+// MOBILE_CONTENT_LENGTH and MOBILE_COMMENT_LENGTH are the characters of
+// post content and of each comment kept by the mobile view. Requests may
+// still pass truncate to shorten comments further.
+const (
+	MOBILE_CONTENT_LENGTH = 2000
+	MOBILE_COMMENT_LENGTH = 280
+)
+
+// parseView reads the view query parameter.
+//
+// Returns the view, defaulting to VIEW_FULL, and false if it is unknown.
+func parseView(c *fiber.Ctx) (string, bool) {
+	switch view := c.Query("view", VIEW_FULL); view {
+	case VIEW_FULL, VIEW_MOBILE:
+		return view, true
+	default:
+		return "", false
+	}
+}
+
+// parseFormat reads the format query parameter.
+//
+// Returns the format, defaulting to FORMAT_MARKDOWN, and false if it is
+// unknown.
+func parseFormat(c *fiber.Ctx) (string, bool) {
+	switch format := c.Query("format", FORMAT_MARKDOWN); format {
+	case FORMAT_MARKDOWN, FORMAT_HTML:
+		return format, true
+	default:
+		return "", false
+	}
+}
+
+// mobilePost shapes post into its mobile view. Content is cut like comment
+// content (see truncateText); comments are cut to truncate characters, or
+// MOBILE_COMMENT_LENGTH when truncate is zero. post is not modified.
+func mobilePost(post models.BlogPost, truncate int) models.MobilePost {
+	if truncate <= 0 {
+		truncate = MOBILE_COMMENT_LENGTH
+	}
+	mobile := models.MobilePost{
+		ID:           post.ID,
+		Title:        post.Title,
+		Excerpt:      post.Excerpt,
+		Content:      post.Content,
+		Language:     post.Language,
+		Tags:         post.Tags,
+		CommentCount: post.CommentCount,
+		LikeCount:    post.LikeCount,
+		ClapCount:    post.ClapCount,
+		CreatedAt:    post.CreatedAt,
+		UpdatedAt:    post.UpdatedAt,
+		Comments:     truncateComments(post.Comments, truncate),
+	}
+	if utf8.RuneCountInString(mobile.Content) > MOBILE_CONTENT_LENGTH {
+		mobile.Content = truncateText(mobile.Content, MOBILE_CONTENT_LENGTH)
+		mobile.HasMore = true
+	}
+	return mobile
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// SetVisibility handles PUT /api/posts/:id/visibility requests.
+// Changes who can read a post: public posts are listed, unlisted posts are
+// readable by direct link only, and private posts are hidden from every
+// public read path and reachable only through preview links.
+//
+// URL parameters:
+//   - id: string (required) - ID of the post
+//
+// Request body should contain:
+//   - visibility: string (required) - one of public, unlisted, private
+//
+// Response format:
+//   - 200: Success with the updated BlogPost object
+//   - 400: Invalid ID format, invalid JSON, or unknown visibility
+//   - 404: Post not found
+//   - 502: Database update error
+func (h *Handler) SetVisibility(c *fiber.Ctx) error {
+	// Parse and validate the post ID from URL parameters
+	postID, err := h.DB.IDs.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid post ID",
+		})
+	}
+
+	var req models.SetVisibilityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid JSON",
+		})
+	}
+	if !models.ValidVisibility(req.Visibility) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid visibility",
+		})
+	}
+
+	// Create context with timeout for database operations
+	ctx, cancel := h.dbContext(c, DB_WRITE)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$set": bson.M{"visibility": req.Visibility}}
+	var post models.BlogPost
+	err = h.DB.Posts.FindOneAndUpdate(ctx, bson.M{"_id": postID}, update, opts).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Post not found",
+			})
+		}
+		logger.Ctx(c.Context()).Error("failed to update visibility", zap.Error(err))
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update post",
+		})
+	}
+
+	// Visibility changes both the post and the default listing
+	if err := h.DB.TouchPost(ctx, postID); err != nil {
+		logger.Ctx(c.Context()).Warn("failed to touch post last-modified", zap.Error(err))
+	}
+
+	return c.JSON(models.APIResponse{Success: true, Data: post})
+}
+
+// isPrivate reports whether the post with the given id is private, or
+// scheduled and not published yet, which hides it the same way (see
+// visibility.Unpublished). A missing post is not private, so callers keep
+// their not-found behavior.
+func (h *Handler) isPrivate(ctx context.Context, postID models.ID) (bool, error) {
+	filter := visibility.Unpublished(time.Now())
+	filter["_id"] = postID
+	n, err := h.DB.Posts.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+// privateAmong returns the subset of postIDs that are private or not
+// published yet (see isPrivate).
+func (h *Handler) privateAmong(ctx context.Context, postIDs []models.ID) (map[models.ID]bool, error) {
+	filter := visibility.Unpublished(time.Now())
+	filter["_id"] = bson.M{"$in": postIDs}
+	cursor, err := h.DB.Posts.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var headers []struct {
+		ID models.ID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &headers); err != nil {
+		return nil, err
+	}
+	private := make(map[models.ID]bool, len(headers))
+	for _, header := range headers {
+		private[header.ID] = true
+	}
+	return private, nil
+}
+
+// rejectPrivate responds 404 when the post is private, so private posts
+// are indistinguishable from missing ones on public read paths. Returns
+// true when a response was written.
+func (h *Handler) rejectPrivate(c *fiber.Ctx, ctx context.Context, postID models.ID) (bool, error) {
+	private, err := h.isPrivate(ctx, postID)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to check post visibility", zap.Error(err))
+		return true, c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+	if private {
+		return true, c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+	return false, nil
+}
+
+// rejectUnpublished responds 404 when the post is missing, private, or
+// scheduled for later, for endpoints that act on a post and report its
+// counters. Returns true when a response was written.
+func (h *Handler) rejectUnpublished(c *fiber.Ctx, ctx context.Context, postID models.ID) (bool, error) {
+	published, err := h.Posts.Published(ctx, postID)
+	if err != nil {
+		logger.Ctx(c.Context()).Error("failed to check post visibility", zap.Error(err))
+		return true, c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch post",
+		})
+	}
+	if !published {
+		return true, c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Post not found",
+		})
+	}
+	return false, nil
+}
@@ -0,0 +1,146 @@
+package i18n
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Thresholds of Detect. Texts with fewer letters, or Latin-script texts
+// with fewer stopword matches, are too short to tell apart.
+const (
+	MIN_DETECT_LETTERS   = 10
+	MIN_DETECT_STOPWORDS = 3
+)
+
+// stopwords lists frequent function words of the Latin-script languages
+// Detect recognizes. Words shared by several languages count for each.
+var stopwords = map[string][]string{
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "dem", "mit", "von", "sich", "des", "auf", "für", "im", "auch", "sie", "ich", "wir", "aber", "wie", "oder", "wird", "sind", "noch", "kann", "werden"},
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "was", "on", "are", "this", "as", "be", "at", "by", "have", "from", "not", "you", "but", "they", "which", "or", "we", "an", "will", "can"},
+	"es": {"el", "la", "los", "las", "y", "que", "de", "en", "un", "una", "es", "con", "para", "por", "del", "al", "lo", "se", "no", "más", "pero", "como", "su", "está", "son", "también", "esto", "muy", "hay", "fue"},
+	"fr": {"le", "la", "les", "et", "de", "des", "du", "un", "une", "est", "en", "que", "qui", "dans", "pour", "pas", "sur", "au", "avec", "ce", "il", "elle", "sont", "mais", "nous", "vous", "ou", "être", "plus", "cette"},
+	"it": {"il", "la", "di", "che", "e", "è", "un", "una", "per", "non", "con", "del", "della", "le", "gli", "in", "si", "da", "sono", "anche", "come", "ma", "più", "questo", "nel", "alla", "dei", "lo", "ha", "al"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "in", "voor", "met", "zijn", "die", "ook", "aan", "er", "maar", "om", "als", "bij", "dan", "wordt", "nog", "wat", "worden", "kan", "naar", "heeft"},
+	"pt": {"não", "que", "de", "o", "os", "as", "um", "uma", "é", "com", "para", "em", "do", "da", "dos", "das", "no", "na", "se", "por", "mais", "mas", "como", "ao", "ele", "foi", "são", "também", "isso", "você"},
+}
+
+// stopwordLanguages maps each stopword to the languages listing it.
+var stopwordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// Detect guesses the language a text is written in, for storing with
+// posts. Texts in a script used by one language, or by one main language,
+// are told by the script: Cyrillic (ru, or uk with Ukrainian letters),
+// Greek, Hebrew, Arabic (ar, or fa with Persian letters), Devanagari (hi),
+// Thai, Hangul (ko), kana (ja), and Han without kana (zh). Latin-script
+// texts are told apart by their most frequent stopwords among de, en, es,
+// fr, it, nl, and pt.
+//
+// Parameters:
+//   - text: plain text, e.g. content.PlainText of a post body
+//
+// Returns a primary language tag such as "pt", or "" when the text is too
+// short or ambiguous to tell.
+func Detect(text string) string {
+	scripts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			scripts["latin"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["ru"]++
+			if strings.ContainsRune("ґєіїҐЄІЇ", r) {
+				scripts["uk"]++
+			}
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+			if strings.ContainsRune("پچژگ", r) {
+				scripts["fa"]++
+			}
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			scripts["ja"]++
+			scripts["cjk"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["zh"]++
+			scripts["cjk"]++
+		}
+	}
+	if letters < MIN_DETECT_LETTERS {
+		return ""
+	}
+
+	// The script most letters are written in decides, unless it is Latin
+	best, most := "", 0
+	for _, script := range []string{"latin", "ru", "el", "he", "ar", "hi", "th", "ko", "cjk"} {
+		if scripts[script] > most {
+			best, most = script, scripts[script]
+		}
+	}
+	switch best {
+	case "latin":
+		return detectLatin(text)
+	case "ru":
+		if scripts["uk"] > 0 {
+			return "uk"
+		}
+	case "ar":
+		if scripts["fa"] > 0 {
+			return "fa"
+		}
+	case "cjk":
+		if scripts["ja"] > 0 {
+			return "ja"
+		}
+		return "zh"
+	}
+	return best
+}
+
+// detectLatin returns the language whose stopwords appear most often in
+// text, or "" when there are too few or two languages tie.
+func detectLatin(text string) string {
+	scores := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		for _, lang := range stopwordLanguages[word] {
+			scores[lang]++
+		}
+	}
+
+	best, most, tied := "", 0, false
+	for lang, score := range scores {
+		switch {
+		case score > most:
+			best, most, tied = lang, score, false
+		case score == most:
+			tied = true
+		}
+	}
+	if most < MIN_DETECT_STOPWORDS || tied {
+		return ""
+	}
+	return best
+}
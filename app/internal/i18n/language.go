@@ -0,0 +1,103 @@
+// Package i18n provides language tag handling for localized content,
+// including Accept-Language parsing and negotiation against the languages
+// a resource is available in.
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tagPattern accepts BCP 47 style tags such as "en", "pt-BR", or "zh-Hant".
+var tagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// Normalize lowercases a language tag and converts underscores to hyphens
+// ("pt_BR" -> "pt-br") so tags compare consistently.
+func Normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// Valid reports whether tag (after Normalize) is a well-formed language tag.
+func Valid(tag string) bool {
+	return tagPattern.MatchString(Normalize(tag))
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header in
+// preference order (highest q first, header order for ties). Tags are
+// normalized; a region-specific tag is followed by its primary language as
+// an implicit fallback ("pt-br" then "pt"). Wildcards and q=0 entries are
+// dropped.
+//
+// Parameters:
+//   - header: raw Accept-Language value, e.g. "pt-BR,pt;q=0.9,en;q=0.5"
+//
+// Returns the ordered, de-duplicated list of candidate tags.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag   string
+		q     float64
+		index int
+	}
+
+	var entries []weighted
+	for i, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = Normalize(tag)
+		if tag == "" || tag == "*" || !Valid(tag) {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{tag: tag, q: q, index: i})
+	}
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		return entries[a].q > entries[b].q
+	})
+
+	seen := make(map[string]bool)
+	var tags []string
+	add := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, entry := range entries {
+		add(entry.tag)
+		if primary, _, ok := strings.Cut(entry.tag, "-"); ok {
+			add(primary)
+		}
+	}
+	return tags
+}
+
+// Best returns the first preferred tag that is available, or "" if none is.
+//
+// Parameters:
+//   - preferred: candidate tags in preference order (see ParseAcceptLanguage)
+//   - available: tags the resource exists in
+func Best(preferred []string, available []string) string {
+	set := make(map[string]bool, len(available))
+	for _, tag := range available {
+		set[Normalize(tag)] = true
+	}
+	for _, tag := range preferred {
+		if set[tag] {
+			return tag
+		}
+	}
+	return ""
+}
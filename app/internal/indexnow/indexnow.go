@@ -0,0 +1,119 @@
+// Package indexnow notifies search engines of new and changed pages
+// through the IndexNow protocol (https://www.indexnow.org), which Bing,
+// Yandex, Seznam, and other engines share: one ping reaches all of them.
+// Google and Bing retired their sitemap ping endpoints, so IndexNow is the
+// only push channel left.
+package indexnow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_REQUEST_TIMEOUT bounds a single ping.
+const DEFAULT_REQUEST_TIMEOUT = 10 * time.Second
+
+// Client submits post URLs to an IndexNow endpoint.
+type Client struct {
+	Endpoint    string       // IndexNow API URL, e.g. "https://api.indexnow.org/indexnow"
+	Key         string       // Verification key; the site must serve it as "<key>.txt"
+	KeyLocation string       // URL of the key file when not at the site root (optional)
+	PostURL     string       // Public link template for posts; "{id}" is replaced by the post ID
+	HTTP        *http.Client // HTTP client used for pings
+}
+
+// New creates an IndexNow client, or returns nil when key or postURL is
+// empty (pings disabled).
+//
+// Parameters:
+//   - endpoint: IndexNow API URL
+//   - key: verification key
+//   - keyLocation: URL of the key file, empty for "<site>/<key>.txt"
+//   - postURL: public link template for posts, e.g. "https://blog.example.com/posts/{id}"
+func New(endpoint, key, keyLocation, postURL string) *Client {
+	if key == "" || postURL == "" {
+		return nil
+	}
+	return &Client{
+		Endpoint:    endpoint,
+		Key:         key,
+		KeyLocation: keyLocation,
+		PostURL:     postURL,
+		HTTP:        &http.Client{Timeout: DEFAULT_REQUEST_TIMEOUT, Transport: tracing.Transport(nil)},
+	}
+}
+
+// submission is the JSON body of an IndexNow POST.
+type submission struct {
+	Host        string   `json:"host"`
+	Key         string   `json:"key"`
+	KeyLocation string   `json:"keyLocation,omitempty"`
+	URLList     []string `json:"urlList"`
+}
+
+// URL returns the public link of a post.
+func (c *Client) URL(postID models.ID) string {
+	return strings.ReplaceAll(c.PostURL, "{id}", postID.String())
+}
+
+// Submit announces that the pages at urls were added or changed. All urls
+// must be on the same host.
+func (c *Client) Submit(ctx context.Context, urls ...string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	parsed, err := url.Parse(urls[0])
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(submission{
+		Host:        parsed.Host,
+		Key:         c.Key,
+		KeyLocation: c.KeyLocation,
+		URLList:     urls,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 200 and 202 both mean the URLs were received
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("indexnow returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PingPost submits a post's URL in the background. Failures are logged;
+// search engines will still find the page on their next crawl.
+func (c *Client) PingPost(post models.BlogPost) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_REQUEST_TIMEOUT)
+		defer cancel()
+		if err := c.Submit(ctx, c.URL(post.ID)); err != nil {
+			logger.Warn("indexnow ping failed", zap.String("post", post.ID.String()), zap.Error(err))
+		}
+	}()
+}
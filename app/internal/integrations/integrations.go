@@ -0,0 +1,295 @@
+// Package integrations posts blog events to chat services through their
+// incoming webhooks. Each service is a Driver that formats the message
+// payload and knows which webhook hosts belong to it; integrations are
+// configured at runtime through the admin API and stored in MongoDB.
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_REQUEST_TIMEOUT bounds a single webhook call.
+const DEFAULT_REQUEST_TIMEOUT = 10 * time.Second
+
+// MAX_SUMMARY_LENGTH caps the post text quoted in a message.
+const MAX_SUMMARY_LENGTH = 280
+
+// Event is a blog event rendered into a chat message.
+type Event struct {
+	Type    string // Event name, e.g. models.EVENT_POST_PUBLISHED
+	Title   string // Headline, e.g. the post title
+	URL     string // Link to the post (may be empty)
+	Summary string // Short text shown under the headline
+}
+
+// Driver formats events for one chat service.
+type Driver interface {
+	// Accepts reports whether webhook is an incoming webhook URL of the
+	// service, so integrations can only call the service they name.
+	Accepts(webhook *url.URL) bool
+	// Payload returns the JSON body posted to the webhook for event.
+	Payload(event Event) any
+}
+
+// Drivers are the built-in drivers by name, as stored in
+// models.Integration.Driver.
+var Drivers = map[string]Driver{
+	models.INTEGRATION_SLACK:   Slack{},
+	models.INTEGRATION_DISCORD: Discord{},
+}
+
+// Slack posts to Slack incoming webhooks.
+type Slack struct{}
+
+// Accepts allows https://hooks.slack.com URLs.
+func (Slack) Accepts(webhook *url.URL) bool {
+	return webhook.Scheme == "https" && webhook.Host == "hooks.slack.com"
+}
+
+// Payload renders event as Slack mrkdwn text.
+func (Slack) Payload(event Event) any {
+	headline := "*" + slackEscape(event.Title) + "*"
+	if event.URL != "" {
+		headline = "*<" + event.URL + "|" + slackEscape(event.Title) + ">*"
+	}
+	text := headline
+	if event.Summary != "" {
+		text += "\n" + slackEscape(event.Summary)
+	}
+	return map[string]any{"text": text}
+}
+
+// slackEscape escapes the characters Slack treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// Discord posts to Discord channel webhooks.
+type Discord struct{}
+
+// Accepts allows https://discord.com/api/webhooks/ URLs (and the legacy
+// discordapp.com host).
+func (Discord) Accepts(webhook *url.URL) bool {
+	return webhook.Scheme == "https" &&
+		(webhook.Host == "discord.com" || webhook.Host == "discordapp.com") &&
+		strings.HasPrefix(webhook.Path, "/api/webhooks/")
+}
+
+// Payload renders event as a Discord embed. Mentions are disabled so post
+// text can never ping a channel.
+func (Discord) Payload(event Event) any {
+	embed := map[string]any{"title": event.Title}
+	if event.URL != "" {
+		embed["url"] = event.URL
+	}
+	if event.Summary != "" {
+		embed["description"] = event.Summary
+	}
+	return map[string]any{
+		"embeds":           []any{embed},
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+}
+
+// Telegram posts to one chat through a bot. Unlike the webhook drivers it
+// is configured once, through TELEGRAM_BOT_TOKEN and TELEGRAM_CHAT_ID,
+// instead of through the admin API.
+type Telegram struct {
+	Token  string // Bot token from @BotFather
+	ChatID string // Chat, group, or channel ID (or "@channelname")
+}
+
+// NewTelegram returns the Telegram notifier, or nil when token or chatID
+// is empty (Telegram disabled).
+func NewTelegram(token, chatID string) *Telegram {
+	if token == "" || chatID == "" {
+		return nil
+	}
+	return &Telegram{Token: token, ChatID: chatID}
+}
+
+// URL is the Bot API sendMessage endpoint of the bot.
+func (t *Telegram) URL() string {
+	return "https://api.telegram.org/bot" + t.Token + "/sendMessage"
+}
+
+// Payload renders event as a sendMessage request with HTML formatting.
+func (t *Telegram) Payload(event Event) any {
+	text := "<b>" + html.EscapeString(event.Title) + "</b>"
+	if event.URL != "" {
+		text = `<b><a href="` + html.EscapeString(event.URL) + `">` + html.EscapeString(event.Title) + "</a></b>"
+	}
+	if event.Summary != "" {
+		text += "\n" + html.EscapeString(event.Summary)
+	}
+	return map[string]any{
+		"chat_id":    t.ChatID,
+		"text":       text,
+		"parse_mode": "HTML",
+	}
+}
+
+// Validate checks that driver names a built-in driver and that webhook is
+// one of its URLs.
+func Validate(driver, webhook string) error {
+	d, ok := Drivers[driver]
+	if !ok {
+		return fmt.Errorf("unknown driver %q", driver)
+	}
+	parsed, err := url.Parse(webhook)
+	if err != nil || !d.Accepts(parsed) {
+		return fmt.Errorf("webhook_url is not a %s webhook", driver)
+	}
+	return nil
+}
+
+// Dispatcher delivers events to the integrations subscribed to them.
+type Dispatcher struct {
+	DB       *storage.Storage // Database storage instance for MongoDB operations
+	PostURL  string           // Link template for posts; "{id}" is replaced by the post ID
+	Client   *http.Client     // HTTP client used for webhook calls
+	Telegram *Telegram        // Configured Telegram chat, notified of every event (nil when disabled)
+
+	pending sync.WaitGroup // Dispatches started by Go and not finished yet
+}
+
+// NewDispatcher creates a Dispatcher.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - postURL: link template for posts, e.g. "https://blog.example.com/posts/{id}";
+//     empty sends messages without links
+//
+// Returns a pointer to a configured Dispatcher.
+func NewDispatcher(db *storage.Storage, postURL string) *Dispatcher {
+	return &Dispatcher{
+		DB:      db,
+		PostURL: postURL,
+		Client:  &http.Client{Timeout: DEFAULT_REQUEST_TIMEOUT, Transport: tracing.Transport(nil)},
+	}
+}
+
+// PostEvent builds the event announcing post.
+func (d *Dispatcher) PostEvent(post models.BlogPost) Event {
+	event := Event{Type: models.EVENT_POST_PUBLISHED, Title: post.Title, Summary: post.Excerpt}
+	if event.Summary == "" {
+		event.Summary = post.Content
+	}
+	if runes := []rune(event.Summary); len(runes) > MAX_SUMMARY_LENGTH {
+		event.Summary = strings.TrimSpace(string(runes[:MAX_SUMMARY_LENGTH])) + "…"
+	}
+	if d.PostURL != "" {
+		event.URL = strings.ReplaceAll(d.PostURL, "{id}", post.ID.String())
+	}
+	return event
+}
+
+// Go dispatches event in the background, so requests announcing a post do
+// not wait on webhooks. Wait lets shutdown finish the deliveries in flight.
+func (d *Dispatcher) Go(event Event) {
+	d.pending.Add(1)
+	go func() {
+		defer d.pending.Done()
+		d.Dispatch(context.Background(), event)
+	}()
+}
+
+// Wait waits until every dispatch started by Go has finished, or until ctx
+// is done, returning its error then.
+func (d *Dispatcher) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dispatch sends event to the configured Telegram chat and to every
+// integration subscribed to its type. Failures are logged per destination
+// and do not stop the others.
+func (d *Dispatcher) Dispatch(ctx context.Context, event Event) {
+	if d.Telegram != nil {
+		if err := d.post(ctx, d.Telegram.URL(), d.Telegram.Payload(event)); err != nil {
+			logger.Warn("failed to notify telegram", zap.Error(err))
+		}
+	}
+
+	cursor, err := d.DB.Integrations.Find(ctx, bson.M{"events": event.Type})
+	if err != nil {
+		logger.Error("failed to load integrations", zap.Error(err))
+		return
+	}
+	var integrations []models.Integration
+	if err := cursor.All(ctx, &integrations); err != nil {
+		logger.Error("failed to decode integrations", zap.Error(err))
+		return
+	}
+
+	for _, integration := range integrations {
+		if err := d.Send(ctx, integration, event); err != nil {
+			logger.Warn("failed to notify integration",
+				zap.String("integration", integration.ID.String()),
+				zap.String("driver", integration.Driver),
+				zap.Error(err))
+		}
+	}
+}
+
+// Send posts event to a single integration.
+func (d *Dispatcher) Send(ctx context.Context, integration models.Integration, event Event) error {
+	driver, ok := Drivers[integration.Driver]
+	if !ok {
+		return fmt.Errorf("unknown driver %q", integration.Driver)
+	}
+	return d.post(ctx, integration.WebhookURL, driver.Payload(event))
+}
+
+// post sends payload as JSON to a webhook URL.
+func (d *Dispatcher) post(ctx context.Context, webhook string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		// Webhook URLs carry credentials; keep them out of logged errors
+		if urlErr, ok := err.(*url.Error); ok {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_FLUSH_TIMEOUT bounds writing one batch of buffered views.
+const DEFAULT_FLUSH_TIMEOUT = 30 * time.Second
+
+// MAX_PENDING_VIEW_KEYS caps the distinct (post, day, referrer) counters
+// buffered between flushes; views for new keys beyond it are dropped, so a
+// stalled database cannot grow memory without bound.
+const MAX_PENDING_VIEW_KEYS = 10000
+
+// MAX_REFERRER_LENGTH is the longest referrer host stored; longer values
+// are not valid host names.
+const MAX_REFERRER_LENGTH = 253
+
+// viewKey identifies one rollup document.
+type viewKey struct {
+	PostID   models.ID
+	Day      time.Time
+	Referrer string
+}
+
+// ViewRecorder rolls post views up into per-day, per-referrer counters in
+// the post_views collection. Record only increments an in-memory counter,
+// so reads never wait on the write; Run flushes the counters in one bulk
+// upsert per interval. Views buffered when an instance dies are lost, which
+// analytics can tolerate.
+type ViewRecorder struct {
+	DB       *storage.Storage // Database storage instance for MongoDB operations
+	Interval time.Duration    // Time between flushes (0 disables recording)
+
+	mu      sync.Mutex
+	pending map[viewKey]int64
+	dropped int64
+}
+
+// NewViewRecorder creates a recorder flushing every interval when started
+// with Run.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - interval: time between flushes; 0 disables recording
+//
+// Returns a pointer to a new ViewRecorder.
+func NewViewRecorder(db *storage.Storage, interval time.Duration) *ViewRecorder {
+	return &ViewRecorder{DB: db, Interval: interval, pending: make(map[viewKey]int64)}
+}
+
+// Record counts one view of a post arriving from referrer, the raw Referer
+// header (empty for direct traffic). Views are bucketed by UTC day.
+func (r *ViewRecorder) Record(postID models.ID, referrer string) {
+	if r.Interval <= 0 {
+		return
+	}
+	key := viewKey{
+		PostID:   postID,
+		Day:      time.Now().UTC().Truncate(24 * time.Hour),
+		Referrer: ReferrerHost(referrer),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.pending[key]; !ok && len(r.pending) >= MAX_PENDING_VIEW_KEYS {
+		r.dropped++
+		return
+	}
+	r.pending[key]++
+}
+
+// Run flushes buffered views once per Interval until ctx is cancelled, then
+// flushes one last time. Returns immediately if Interval is 0.
+func (r *ViewRecorder) Run(ctx context.Context) {
+	if r.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The jobs context is gone; give the final flush its own
+			if err := r.Flush(context.Background()); err != nil {
+				logger.Error("final view flush failed", zap.Error(err))
+			}
+			return
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil {
+				logger.Error("view flush failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Flush writes the buffered counters with one unordered bulk upsert. On
+// failure the counters are put back to be retried by the next flush.
+func (r *ViewRecorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	batch, dropped := r.pending, r.dropped
+	r.pending, r.dropped = make(map[viewKey]int64), 0
+	r.mu.Unlock()
+
+	if dropped > 0 {
+		logger.Warn("dropped post views, too many pending counters", zap.Int64("views", dropped))
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DEFAULT_FLUSH_TIMEOUT)
+	defer cancel()
+
+	writes := make([]mongo.WriteModel, 0, len(batch))
+	for key, views := range batch {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"post_id": key.PostID, "day": key.Day, "referrer": key.Referrer}).
+			SetUpdate(bson.M{"$inc": bson.M{"views": views}}).
+			SetUpsert(true))
+	}
+	if _, err := r.DB.PostViews.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		r.mu.Lock()
+		for key, views := range batch {
+			r.pending[key] += views
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// ReferrerHost reduces a Referer header to the host it names, lowercased
+// and without a "www." prefix, so all pages of a referring site add up.
+// Returns "" for direct traffic and for values that are not absolute URLs.
+func ReferrerHost(referrer string) string {
+	parsed, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	if len(host) > MAX_REFERRER_LENGTH {
+		return ""
+	}
+	return host
+}
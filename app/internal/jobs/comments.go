@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// COMMENT_CLOSE_LOCK is the lease name that keeps comment closing on one
+// instance.
+const COMMENT_CLOSE_LOCK = "comment-close"
+
+// DAY_MILLISECONDS converts comment windows in days to the milliseconds
+// MongoDB date arithmetic uses.
+const DAY_MILLISECONDS = 24 * 60 * 60 * 1000
+
+// CommentCloser periodically locks the comments of posts older than their
+// comment window: the post's comments_close_after_days, or DefaultDays for
+// posts without one. A window of 0 keeps comments open. Passes run under a
+// lease, so with several replicas only one runs at a time.
+type CommentCloser struct {
+	DB          *storage.Storage // Database storage instance for MongoDB operations
+	Locker      *Locker          // Lease that makes passes singleton across instances
+	DefaultDays int              // Comment window of posts without their own
+	Interval    time.Duration    // Time between passes (0 disables Run)
+}
+
+// NewCommentCloser creates a closer applying defaultDays and running every
+// interval when started with Run.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - locker: lease manager shared by the background jobs
+//   - defaultDays: comment window in days of posts without their own; 0 for never
+//   - interval: time between passes
+//
+// Returns a pointer to a new CommentCloser.
+func NewCommentCloser(db *storage.Storage, locker *Locker, defaultDays int, interval time.Duration) *CommentCloser {
+	return &CommentCloser{DB: db, Locker: locker, DefaultDays: defaultDays, Interval: interval}
+}
+
+// Run closes expired comment threads once per Interval until ctx is
+// cancelled. Ticks where another instance holds the lease are skipped, and
+// errors are logged and retried on the next tick. Returns immediately if
+// Interval is 0.
+func (j *CommentCloser) Run(ctx context.Context) {
+	if j.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var closed int64
+			ran, err := j.Locker.Do(ctx, COMMENT_CLOSE_LOCK, func(ctx context.Context) error {
+				var err error
+				closed, err = j.Close(ctx)
+				return err
+			})
+			if err != nil {
+				logger.Error("comment close pass failed", zap.Error(err))
+				continue
+			}
+			if ran && closed > 0 {
+				logger.Info("closed comment threads", zap.Int64("posts", closed))
+			}
+		}
+	}
+}
+
+// Close locks the comments of every open post whose window has passed, in
+// one update, and bumps their last-modified time so clients refetch the
+// lock state.
+//
+// Returns the number of posts locked.
+func (j *CommentCloser) Close(ctx context.Context) (int64, error) {
+	now := time.Now()
+	filter := bson.M{
+		"comments_locked": bson.M{"$ne": true},
+		"$expr": bson.M{"$let": bson.M{
+			"vars": bson.M{"days": bson.M{"$ifNull": bson.A{"$comments_close_after_days", j.DefaultDays}}},
+			"in": bson.M{"$and": bson.A{
+				bson.M{"$gt": bson.A{"$$days", 0}},
+				bson.M{"$lte": bson.A{
+					bson.M{"$add": bson.A{"$created_at", bson.M{"$multiply": bson.A{"$$days", DAY_MILLISECONDS}}}},
+					now,
+				}},
+			}},
+		}},
+	}
+	update := bson.M{"$set": bson.M{
+		"comments_locked":    true,
+		"comments_locked_at": now,
+		"last_modified":      now,
+	}}
+	result, err := j.DB.Posts.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
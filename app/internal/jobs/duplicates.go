@@ -0,0 +1,175 @@
+// Package jobs contains background jobs that run alongside the HTTP server,
+// such as periodic content analysis. Each job exposes a Run method meant to
+// be started in its own goroutine and a method to trigger one pass on demand.
+package jobs
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/similarity"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_SCAN_TIMEOUT bounds a single near-duplicate scan pass, which has
+// to read the content of every post.
+const DEFAULT_SCAN_TIMEOUT = 10 * time.Minute
+
+// DUPLICATE_SCAN_LOCK is the lease name that keeps scans on one instance.
+const DUPLICATE_SCAN_LOCK = "duplicate-scan"
+
+// DuplicateScanner periodically compares the content of all posts using
+// MinHash signatures and stores highly similar pairs in the duplicates
+// collection, where the admin endpoint reads them. Scans run under a lease,
+// so with several replicas only one scans at a time.
+type DuplicateScanner struct {
+	DB        *storage.Storage // Database storage instance for MongoDB operations
+	Locker    *Locker          // Lease that makes scans singleton across instances
+	Threshold float64          // Minimum estimated similarity to flag a pair
+	Interval  time.Duration    // Time between scheduled scans (0 disables Run)
+
+	hasher *similarity.MinHasher
+}
+
+// NewDuplicateScanner creates a scanner flagging pairs at or above threshold
+// and scanning every interval when started with Run.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - locker: lease manager shared by the background jobs
+//   - threshold: minimum estimated similarity (0-1) to flag a pair
+//   - interval: time between scheduled scans
+//
+// Returns a pointer to a new DuplicateScanner.
+func NewDuplicateScanner(db *storage.Storage, locker *Locker, threshold float64, interval time.Duration) *DuplicateScanner {
+	return &DuplicateScanner{
+		DB:        db,
+		Locker:    locker,
+		Threshold: threshold,
+		Interval:  interval,
+		hasher:    similarity.NewMinHasher(similarity.DEFAULT_NUM_HASHES),
+	}
+}
+
+// Run scans once per Interval until ctx is cancelled. Ticks where another
+// instance holds the scan lease are skipped. Scan errors are logged and
+// retried on the next tick. Returns immediately if Interval is 0.
+func (s *DuplicateScanner) Run(ctx context.Context) {
+	if s.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			matches, ran, err := s.ScanExclusive(ctx)
+			if err != nil {
+				logger.Error("duplicate scan failed", zap.Error(err))
+				continue
+			}
+			if !ran {
+				logger.Debug("duplicate scan running on another instance")
+				continue
+			}
+			logger.Info("duplicate scan finished", zap.Int("matches", len(matches)))
+		}
+	}
+}
+
+// ScanExclusive runs Scan under the DUPLICATE_SCAN_LOCK lease.
+//
+// Returns the matches, whether the scan ran (false when another instance
+// holds the lease), and any error.
+func (s *DuplicateScanner) ScanExclusive(ctx context.Context) ([]models.DuplicateMatch, bool, error) {
+	var matches []models.DuplicateMatch
+	ran, err := s.Locker.Do(ctx, DUPLICATE_SCAN_LOCK, func(ctx context.Context) error {
+		var err error
+		matches, err = s.Scan(ctx)
+		return err
+	})
+	return matches, ran, err
+}
+
+// Scan runs one near-duplicate pass over all posts and replaces the stored
+// matches with the result. Post content is streamed from the cursor and
+// only signatures are kept in memory.
+//
+// Returns the matches found, sorted by similarity (highest first).
+func (s *DuplicateScanner) Scan(ctx context.Context) ([]models.DuplicateMatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, DEFAULT_SCAN_TIMEOUT)
+	defer cancel()
+
+	// Sign every post without keeping its content around
+	opts := options.Find().SetProjection(bson.M{"title": 1, "content": 1})
+	cursor, err := s.DB.Posts.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []similarity.Document
+	titles := make(map[string]string)
+	for cursor.Next(ctx) {
+		var post models.BlogPost
+		if err := cursor.Decode(&post); err != nil {
+			logger.Warn("malformed post", zap.Error(err))
+			continue
+		}
+		shingles := similarity.Shingles(post.Content, similarity.DEFAULT_SHINGLE_SIZE)
+		if len(shingles) == 0 {
+			continue
+		}
+		id := post.ID.String()
+		titles[id] = post.Title
+		docs = append(docs, similarity.Document{ID: id, Signature: s.hasher.Sign(shingles)})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	// Compare signatures and build the stored representation
+	now := time.Now()
+	found := similarity.FindDuplicates(docs, similarity.DEFAULT_BANDS, s.Threshold)
+	matches := make([]models.DuplicateMatch, 0, len(found))
+	for _, match := range found {
+		matches = append(matches, models.DuplicateMatch{
+			PostA:      models.ID(match.A),
+			TitleA:     titles[match.A],
+			PostB:      models.ID(match.B),
+			TitleB:     titles[match.B],
+			Similarity: match.Similarity,
+			DetectedAt: now,
+		})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+
+	// Replace the previous scan's results
+	if _, err := s.DB.Duplicates.DeleteMany(ctx, bson.M{}); err != nil {
+		return nil, err
+	}
+	if len(matches) > 0 {
+		records := make([]any, len(matches))
+		for i := range matches {
+			matches[i].ID = s.DB.IDs.New()
+			records[i] = matches[i]
+		}
+		if _, err := s.DB.Duplicates.InsertMany(ctx, records); err != nil {
+			return nil, err
+		}
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,162 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/cache"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// CHANGE_STREAM_RETRY_DELAY is how long the watcher waits before reopening
+// a change stream that failed.
+const CHANGE_STREAM_RETRY_DELAY = 5 * time.Second
+
+// changeEvent is the subset of a change stream event the watcher needs.
+// The post ID of a deleted comment is only known from its pre-image, which
+// MongoDB includes when the collection has pre-images enabled.
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	Namespace     struct {
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey struct {
+		ID models.ID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *struct {
+		PostID models.ID `bson:"post_id"`
+	} `bson:"fullDocument"`
+	FullDocumentBeforeChange *struct {
+		PostID models.ID `bson:"post_id"`
+	} `bson:"fullDocumentBeforeChange"`
+}
+
+// ChangeWatcher keeps the in-process caches of one instance consistent with
+// writes made by other instances. It subscribes to a MongoDB change stream
+// on the posts and comments collections and invalidates the cached comment
+// counts they affect. Change streams require a replica set or sharded
+// cluster, so the watcher is opt-in.
+type ChangeWatcher struct {
+	DB      *storage.Storage // Database storage instance for MongoDB operations
+	Counts  *cache.Counts    // Comment count cache to invalidate
+	Enabled bool             // Whether Run subscribes at all
+}
+
+// NewChangeWatcher creates a watcher invalidating counts when enabled.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - counts: the comment count cache shared with the handlers
+//   - enabled: whether Run subscribes to the change stream
+//
+// Returns a pointer to a new ChangeWatcher.
+func NewChangeWatcher(db *storage.Storage, counts *cache.Counts, enabled bool) *ChangeWatcher {
+	return &ChangeWatcher{DB: db, Counts: counts, Enabled: enabled}
+}
+
+// Run consumes the change stream until ctx is cancelled. A failed stream is
+// reopened after CHANGE_STREAM_RETRY_DELAY, resuming after the last event
+// seen. When the stream cannot be resumed the whole cache is cleared, since
+// events in the gap are lost. Returns immediately if the watcher is disabled.
+func (w *ChangeWatcher) Run(ctx context.Context) {
+	if !w.Enabled {
+		return
+	}
+
+	var resumeToken bson.Raw
+	for {
+		token, err := w.watch(ctx, resumeToken)
+		if ctx.Err() != nil {
+			return
+		}
+		resumeToken = token
+		logger.Warn("change stream interrupted", zap.Error(err))
+
+		// An unresumable stream means missed events: start over from an empty cache
+		if isHistoryLost(err) {
+			w.Counts.Clear()
+			resumeToken = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(CHANGE_STREAM_RETRY_DELAY):
+		}
+	}
+}
+
+// watch opens one change stream, resuming after resumeToken when set, and
+// applies events until the stream fails. Returns the last resume token and
+// the error that ended the stream.
+func (w *ChangeWatcher) watch(ctx context.Context, resumeToken bson.Raw) (bson.Raw, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"ns.coll":       bson.M{"$in": bson.A{w.DB.Posts.Name(), w.DB.Comments.Name()}},
+			"operationType": bson.M{"$in": bson.A{"insert", "delete", "replace"}},
+		}}},
+	}
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+	if resumeToken != nil {
+		opts.SetResumeAfter(resumeToken)
+	}
+
+	stream, err := w.DB.Posts.Database().Watch(ctx, pipeline, opts)
+	if err != nil {
+		return resumeToken, err
+	}
+	defer stream.Close(context.Background())
+
+	// Writes before the stream opened were never observed
+	if resumeToken == nil {
+		w.Counts.Clear()
+	}
+	logger.Info("change stream opened")
+
+	for stream.Next(ctx) {
+		var event changeEvent
+		if err := stream.Decode(&event); err != nil {
+			logger.Warn("malformed change event", zap.Error(err))
+		} else {
+			w.apply(event)
+		}
+		resumeToken = stream.ResumeToken()
+	}
+	return resumeToken, stream.Err()
+}
+
+// apply invalidates the cached counts affected by event.
+func (w *ChangeWatcher) apply(event changeEvent) {
+	switch event.Namespace.Coll {
+	case w.DB.Posts.Name():
+		// A deleted or replaced post takes its count with it
+		w.Counts.Invalidate(event.DocumentKey.ID.String())
+	case w.DB.Comments.Name():
+		switch {
+		case event.FullDocument != nil:
+			w.Counts.Invalidate(event.FullDocument.PostID.String())
+		case event.FullDocumentBeforeChange != nil:
+			w.Counts.Invalidate(event.FullDocumentBeforeChange.PostID.String())
+		default:
+			// Deleted comment without a pre-image: its post is unknown
+			w.Counts.Clear()
+		}
+	}
+}
+
+// isHistoryLost reports whether err means the resume token fell off the
+// oplog, so the stream cannot continue where it stopped.
+func isHistoryLost(err error) bool {
+	if serverErr, ok := err.(mongo.ServerError); ok {
+		return serverErr.HasErrorCode(286) || serverErr.HasErrorLabel("NonResumableChangeStreamError")
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/content"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_LINK_CHECK_TIMEOUT bounds a single link check pass.
+const DEFAULT_LINK_CHECK_TIMEOUT = 30 * time.Minute
+
+// LINK_REQUEST_TIMEOUT bounds the request to one link; slower links are
+// reported as timeouts.
+const LINK_REQUEST_TIMEOUT = 10 * time.Second
+
+// LINK_CHECK_WORKERS is the number of links checked concurrently.
+const LINK_CHECK_WORKERS = 8
+
+// LINK_CHECK_LOCK is the lease name that keeps link checks on one instance.
+const LINK_CHECK_LOCK = "link-check"
+
+// LINK_CHECK_USER_AGENT identifies the checker to the sites it visits.
+const LINK_CHECK_USER_AGENT = "challenge-prosi-link-checker/1.0"
+
+// LinkChecker periodically requests every external link found in post
+// content and stores, per post, the links that answered 404 or 410 or did
+// not answer in time. Each URL is requested once per pass, however many
+// posts link to it. Passes run under a lease, so with several replicas only
+// one checks at a time.
+type LinkChecker struct {
+	DB       *storage.Storage // Database storage instance for MongoDB operations
+	Locker   *Locker          // Lease that makes checks singleton across instances
+	Client   *http.Client     // HTTP client used for link requests
+	Interval time.Duration    // Time between scheduled checks (0 disables Run)
+}
+
+// NewLinkChecker creates a checker requesting links with client and
+// checking every interval when started with Run.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - locker: lease manager shared by the background jobs
+//   - client: HTTP client shared with the other outbound integrations
+//   - interval: time between scheduled checks
+//
+// Returns a pointer to a new LinkChecker.
+func NewLinkChecker(db *storage.Storage, locker *Locker, client *http.Client, interval time.Duration) *LinkChecker {
+	return &LinkChecker{DB: db, Locker: locker, Client: client, Interval: interval}
+}
+
+// Run checks links once per Interval until ctx is cancelled. Ticks where
+// another instance holds the lease are skipped, and errors are logged and
+// retried on the next tick. Returns immediately if Interval is 0.
+func (j *LinkChecker) Run(ctx context.Context) {
+	if j.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var reports []models.PostBrokenLinks
+			ran, err := j.Locker.Do(ctx, LINK_CHECK_LOCK, func(ctx context.Context) error {
+				var err error
+				reports, err = j.Check(ctx)
+				return err
+			})
+			if err != nil {
+				logger.Error("link check failed", zap.Error(err))
+				continue
+			}
+			if ran {
+				logger.Info("link check finished", zap.Int("posts_with_broken_links", len(reports)))
+			}
+		}
+	}
+}
+
+// Check runs one pass over the links of all posts and replaces the stored
+// results with the posts that have broken links. An interrupted pass
+// stores nothing, so cancellation never reports every link as broken.
+//
+// Returns the posts with broken links, sorted by title.
+func (j *LinkChecker) Check(ctx context.Context) ([]models.PostBrokenLinks, error) {
+	ctx, cancel := context.WithTimeout(ctx, DEFAULT_LINK_CHECK_TIMEOUT)
+	defer cancel()
+
+	// Collect the links of every post, keeping only URLs in memory
+	opts := options.Find().SetProjection(bson.M{"title": 1, "content": 1})
+	cursor, err := j.DB.Posts.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var posts []models.PostBrokenLinks
+	postLinks := make(map[models.ID][]string)
+	unique := make(map[string]bool)
+	for cursor.Next(ctx) {
+		var post models.BlogPost
+		if err := cursor.Decode(&post); err != nil {
+			logger.Warn("malformed post", zap.Error(err))
+			continue
+		}
+		links := content.Links(post.Content)
+		if len(links) == 0 {
+			continue
+		}
+		posts = append(posts, models.PostBrokenLinks{PostID: post.ID, Title: post.Title})
+		postLinks[post.ID] = links
+		for _, link := range links {
+			unique[link] = true
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	broken := j.checkAll(ctx, unique)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Attach the broken links to the posts holding them
+	now := time.Now()
+	reports := make([]models.PostBrokenLinks, 0)
+	for _, post := range posts {
+		for _, link := range postLinks[post.PostID] {
+			if result, ok := broken[link]; ok {
+				post.Links = append(post.Links, result)
+			}
+		}
+		if len(post.Links) > 0 {
+			post.CheckedAt = now
+			reports = append(reports, post)
+		}
+	}
+	sort.Slice(reports, func(a, b int) bool { return reports[a].Title < reports[b].Title })
+
+	// Replace the previous check's results
+	if _, err := j.DB.BrokenLinks.DeleteMany(ctx, bson.M{}); err != nil {
+		return nil, err
+	}
+	if len(reports) > 0 {
+		records := make([]any, len(reports))
+		for i := range reports {
+			records[i] = reports[i]
+		}
+		if _, err := j.DB.BrokenLinks.InsertMany(ctx, records); err != nil {
+			return nil, err
+		}
+	}
+
+	return reports, nil
+}
+
+// checkAll checks links with LINK_CHECK_WORKERS concurrent requests.
+//
+// Returns the broken links keyed by URL.
+func (j *LinkChecker) checkAll(ctx context.Context, links map[string]bool) map[string]models.BrokenLink {
+	queue := make(chan string)
+	broken := make(map[string]models.BrokenLink)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < LINK_CHECK_WORKERS; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range queue {
+				if result, ok := j.CheckLink(ctx, link); !ok {
+					mu.Lock()
+					broken[link] = result
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for link := range links {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- link
+	}
+	close(queue)
+	wg.Wait()
+	return broken
+}
+
+// CheckLink requests link with HEAD, retrying with GET for servers that do
+// not support HEAD. Redirects are followed. A link is broken when it
+// answers 404 or 410, or when the request fails or exceeds
+// LINK_REQUEST_TIMEOUT. Other statuses, including 5xx and 403, count as
+// working: they are usually transient or anti-bot answers.
+//
+// Returns the failure and false when the link is broken.
+func (j *LinkChecker) CheckLink(ctx context.Context, link string) (models.BrokenLink, bool) {
+	status, err := j.request(ctx, http.MethodHead, link)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = j.request(ctx, http.MethodGet, link)
+	}
+
+	result := models.BrokenLink{URL: link, Status: status}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		result.Error = "timeout"
+	case err != nil:
+		result.Error = err.Error()
+	case status != http.StatusNotFound && status != http.StatusGone:
+		return result, true
+	}
+	return result, false
+}
+
+// request sends one request to link and returns the response status. The
+// body is never read.
+func (j *LinkChecker) request(ctx context.Context, method, link string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, LINK_REQUEST_TIMEOUT)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", LINK_CHECK_USER_AGENT)
+
+	resp, err := j.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
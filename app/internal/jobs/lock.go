@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// DEFAULT_LOCK_TTL is the lease duration used when none is configured.
+const DEFAULT_LOCK_TTL = time.Minute
+
+// Lease is a distributed lock document in the locks collection. A lease is
+// held by Owner until ExpiresAt; the holder renews it while working, so a
+// crashed instance's lease expires and another instance takes it over.
+type Lease struct {
+	Name       string    `json:"name" bson:"_id"`                // Lock name, one per job
+	Owner      string    `json:"owner" bson:"owner"`             // Instance holding the lease
+	AcquiredAt time.Time `json:"acquired_at" bson:"acquired_at"` // When the owner took the lease
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`   // When the lease lapses unless renewed
+}
+
+// LockStats are this instance's lock counters since startup.
+type LockStats struct {
+	Acquired  int64 `json:"acquired"`  // Leases obtained
+	Contended int64 `json:"contended"` // Attempts refused because another instance held the lease
+	Takeovers int64 `json:"takeovers"` // Leases taken over after another owner's expired
+	Lost      int64 `json:"lost"`      // Leases lost while working (renewal failed)
+}
+
+// Locker runs jobs under leases stored in MongoDB, so a job scheduled on
+// every replica runs on only one of them at a time.
+type Locker struct {
+	DB    *storage.Storage // Database storage instance for MongoDB operations
+	Owner string           // Identity of this instance in lease documents
+	TTL   time.Duration    // Lease duration; renewed every TTL/3 while held
+
+	acquired  atomic.Int64
+	contended atomic.Int64
+	takeovers atomic.Int64
+	lost      atomic.Int64
+}
+
+// NewLocker creates a Locker whose leases last ttl (DEFAULT_LOCK_TTL when
+// not positive), owned by a name unique to this process (host, pid, and a
+// random suffix).
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - ttl: lease duration
+//
+// Returns a pointer to a new Locker.
+func NewLocker(db *storage.Storage, ttl time.Duration) *Locker {
+	if ttl <= 0 {
+		ttl = DEFAULT_LOCK_TTL
+	}
+	return &Locker{DB: db, Owner: instanceName(), TTL: ttl}
+}
+
+// Do runs fn while holding the lease name. If another instance holds it,
+// fn is not run and Do returns false. The lease is renewed while fn runs;
+// if renewal fails the context passed to fn is cancelled, since another
+// instance may take over. The lease is released when fn returns.
+//
+// Returns whether fn ran, and fn's error or the error acquiring the lease.
+func (l *Locker) Do(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	acquired, err := l.acquire(ctx, name)
+	if err != nil || !acquired {
+		return false, err
+	}
+
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Keep the lease alive for as long as fn runs
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(l.TTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-workCtx.Done():
+				return
+			case <-ticker.C:
+				if ok, err := l.renew(workCtx, name); !ok && workCtx.Err() == nil {
+					l.lost.Add(1)
+					logger.Warn("lost job lease", zap.String("lock", name), zap.Error(err))
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err = fn(workCtx)
+	cancel()
+	<-renewed
+
+	// Release on a fresh context so a cancelled job still frees its lease
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelRelease()
+	if _, releaseErr := l.DB.Locks.DeleteOne(releaseCtx, bson.M{"_id": name, "owner": l.Owner}); releaseErr != nil {
+		logger.Warn("failed to release job lease", zap.String("lock", name), zap.Error(releaseErr))
+	}
+	return true, err
+}
+
+// Stats returns this instance's lock counters.
+func (l *Locker) Stats() LockStats {
+	return LockStats{
+		Acquired:  l.acquired.Load(),
+		Contended: l.contended.Load(),
+		Takeovers: l.takeovers.Load(),
+		Lost:      l.lost.Load(),
+	}
+}
+
+// Leases returns the lease documents currently stored, including expired
+// ones not yet taken over.
+func (l *Locker) Leases(ctx context.Context) ([]Lease, error) {
+	cursor, err := l.DB.Locks.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	leases := []Lease{}
+	if err := cursor.All(ctx, &leases); err != nil {
+		return nil, err
+	}
+	return leases, nil
+}
+
+// acquire takes the lease name if it is free or expired. A lease held by
+// this instance is refused too, so a job never overlaps itself. Concurrent
+// acquirers race on the upsert; the loser gets a duplicate key error, which
+// means the lease is held.
+func (l *Locker) acquire(ctx context.Context, name string) (bool, error) {
+	now := time.Now()
+	filter := bson.M{"_id": name, "expires_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{
+		"owner":       l.Owner,
+		"acquired_at": now,
+		"expires_at":  now.Add(l.TTL),
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous Lease
+	err := l.DB.Locks.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		l.contended.Add(1)
+		return false, nil
+	case err == mongo.ErrNoDocuments:
+		// No lease existed; the upsert created ours
+	case err != nil:
+		return false, err
+	default:
+		l.takeovers.Add(1)
+		logger.Info("took over expired job lease", zap.String("lock", name), zap.String("previous_owner", previous.Owner))
+	}
+
+	l.acquired.Add(1)
+	return true, nil
+}
+
+// renew extends a lease we hold. Returns false when the lease is no
+// longer ours.
+func (l *Locker) renew(ctx context.Context, name string) (bool, error) {
+	result, err := l.DB.Locks.UpdateOne(ctx,
+		bson.M{"_id": name, "owner": l.Owner},
+		bson.M{"$set": bson.M{"expires_at": time.Now().Add(l.TTL)}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// instanceName identifies this process in lease documents.
+func instanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
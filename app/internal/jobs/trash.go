@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// TRASH_PURGE_LOCK is the lease name that keeps trash purges on one
+// instance.
+const TRASH_PURGE_LOCK = "trash-purge"
+
+// TrashPurger periodically removes for good the posts and comments trashed
+// longer than Retention ago. A Retention of 0 keeps the trash forever.
+// Passes run under a lease, so with several replicas only one runs at a
+// time.
+type TrashPurger struct {
+	DB        *storage.Storage // Database storage instance for MongoDB operations
+	Locker    *Locker          // Lease that makes passes singleton across instances
+	Retention time.Duration    // How long trashed items stay restorable
+	Interval  time.Duration    // Time between passes (0 disables Run)
+}
+
+// NewTrashPurger creates a purger applying retention and running every
+// interval when started with Run.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+//   - locker: lease manager shared by the background jobs
+//   - retention: how long trashed items stay restorable; 0 for forever
+//   - interval: time between passes
+//
+// Returns a pointer to a new TrashPurger.
+func NewTrashPurger(db *storage.Storage, locker *Locker, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{DB: db, Locker: locker, Retention: retention, Interval: interval}
+}
+
+// Run purges expired trash once per Interval until ctx is cancelled. Ticks
+// where another instance holds the lease are skipped, and errors are logged
+// and retried on the next tick. Returns immediately if Interval or
+// Retention is 0.
+func (j *TrashPurger) Run(ctx context.Context) {
+	if j.Interval <= 0 || j.Retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var purged int64
+			ran, err := j.Locker.Do(ctx, TRASH_PURGE_LOCK, func(ctx context.Context) error {
+				var err error
+				purged, err = j.Purge(ctx)
+				return err
+			})
+			if err != nil {
+				logger.Error("trash purge pass failed", zap.Error(err))
+				continue
+			}
+			if ran && purged > 0 {
+				logger.Info("purged trash", zap.Int64("items", purged))
+			}
+		}
+	}
+}
+
+// Purge removes every trash item deleted more than Retention ago, with the
+// documents trashed along with it.
+//
+// Returns the number of items removed.
+func (j *TrashPurger) Purge(ctx context.Context) (int64, error) {
+	return j.DB.PurgeTrash(ctx, time.Now().Add(-j.Retention))
+}
+
+// PurgeAt returns when an item deleted at deletedAt is due for purging, or
+// nil if the trash is never purged.
+func (j *TrashPurger) PurgeAt(deletedAt time.Time) *time.Time {
+	if j.Interval <= 0 || j.Retention <= 0 {
+		return nil
+	}
+	purgeAt := deletedAt.Add(j.Retention)
+	return &purgeAt
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// API_KEY_HEADER carries the API key of service-to-service requests.
+const API_KEY_HEADER = "X-API-Key"
+
+// API_KEY_LOCALS_KEY is the fiber.Ctx locals key holding the API key a
+// request authenticated with.
+const API_KEY_LOCALS_KEY = "api_key"
+
+// APIKeyLookup returns the active API key matching a raw key, or nil when
+// the key is unknown or revoked.
+type APIKeyLookup func(ctx context.Context, raw string) (*models.APIKey, error)
+
+// APIKey authenticates requests carrying an X-API-Key header. Requests
+// without the header pass through unchanged, so anonymous and JWT clients
+// are unaffected. Read-scoped keys may only make safe requests (GET, HEAD,
+// OPTIONS); write-scoped keys may also satisfy RequireAuth.
+//
+// Parameters:
+//   - lookup: resolves raw keys to stored API keys
+//
+// Unknown or revoked keys get 401, read keys on mutating requests 403, and
+// lookup failures 502.
+func APIKey(lookup APIKeyLookup) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := c.Get(API_KEY_HEADER)
+		if raw == "" {
+			return c.Next()
+		}
+
+		key, err := lookup(c.Context(), raw)
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to look up API key", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to verify API key",
+			})
+		}
+		if key == nil {
+			return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Invalid API key",
+			})
+		}
+
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		default:
+			if key.Scope != models.API_KEY_SCOPE_WRITE {
+				return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+					Success: false,
+					Error:   "API key is read-only",
+				})
+			}
+		}
+
+		c.Locals(API_KEY_LOCALS_KEY, key)
+		return c.Next()
+	}
+}
+
+// CurrentAPIKey returns the API key APIKey accepted for this request.
+func CurrentAPIKey(c *fiber.Ctx) (*models.APIKey, bool) {
+	key, ok := c.Locals(API_KEY_LOCALS_KEY).(*models.APIKey)
+	return key, ok
+}
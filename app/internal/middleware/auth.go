@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/jwt"
+)
+
+// USER_LOCALS_KEY is the fiber.Ctx locals key holding the authenticated
+// user's claims.
+const USER_LOCALS_KEY = "user"
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer"
+// JWT issued by signer. Accepted claims are stored for CurrentUser.
+// Requests already authenticated by a write-scoped API key (see APIKey),
+// which only administrators can create, are let through without a JWT.
+//
+// Parameters:
+//   - signer: verifies the tokens issued at login
+//
+// Rejected requests get 401 Unauthorized with a WWW-Authenticate challenge.
+func RequireAuth(signer *jwt.Signer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if key, ok := CurrentAPIKey(c); ok && key.Scope == models.API_KEY_SCOPE_WRITE {
+			return c.Next()
+		}
+		if _, message := loginClaims(c, signer); message != "" {
+			return unauthorized(c, message)
+		}
+		return c.Next()
+	}
+}
+
+// RequireAdmin rejects requests that are not from a site administrator: a
+// valid JWT (see RequireAuth) of a user listed in admins. API keys never
+// satisfy it, so a key cannot mint other keys or reach the admin endpoints.
+//
+// Parameters:
+//   - signer: verifies the tokens issued at login
+//   - admins: usernames of the administrators, compared case-insensitively
+//
+// Requests without a valid token get 401 as with RequireAuth; other users
+// get 403 Forbidden.
+func RequireAdmin(signer *jwt.Signer, admins []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, message := loginClaims(c, signer)
+		if message != "" {
+			return unauthorized(c, message)
+		}
+		if !isAdmin(claims, admins) {
+			return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Admin access required",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// IsAdmin reports whether the request carries a valid JWT of a user listed
+// in admins, for public endpoints that reveal more to administrators (see
+// RequireAdmin). Accepted claims are stored for CurrentUser.
+func IsAdmin(c *fiber.Ctx, signer *jwt.Signer, admins []string) bool {
+	claims, message := loginClaims(c, signer)
+	return message == "" && isAdmin(claims, admins)
+}
+
+// isAdmin reports whether claims belong to a user listed in admins.
+func isAdmin(claims jwt.Claims, admins []string) bool {
+	for _, admin := range admins {
+		if strings.EqualFold(admin, claims.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loginClaims returns the claims of the request's login JWT, verified by
+// signer and stored for CurrentUser, or the message to reject it with.
+func loginClaims(c *fiber.Ctx, signer *jwt.Signer) (jwt.Claims, string) {
+	if claims, ok := CurrentUser(c); ok {
+		return claims, ""
+	}
+	claims, err := bearerClaims(c, signer)
+	if err == errNoBearer {
+		return claims, "Authentication required"
+	}
+	if err == jwt.ErrExpired {
+		return claims, "Token expired"
+	}
+	if err != nil {
+		return claims, "Invalid token"
+	}
+	c.Locals(USER_LOCALS_KEY, claims)
+	return claims, ""
+}
+
+// Authenticated reports whether the request carries a valid JWT issued by
+// signer or was authenticated by an API key (see APIKey), for public
+// endpoints that reveal more to authenticated callers. Accepted claims are
+// stored for CurrentUser, as with RequireAuth.
+func Authenticated(c *fiber.Ctx, signer *jwt.Signer) bool {
+	if _, ok := CurrentAPIKey(c); ok {
+		return true
+	}
+	if _, ok := CurrentUser(c); ok {
+		return true
+	}
+	claims, err := bearerClaims(c, signer)
+	if err != nil {
+		return false
+	}
+	c.Locals(USER_LOCALS_KEY, claims)
+	return true
+}
+
+// errNoBearer is returned by bearerClaims for requests without a Bearer
+// Authorization header.
+var errNoBearer = errors.New("no bearer token")
+
+// bearerClaims verifies the request's "Authorization: Bearer" JWT.
+func bearerClaims(c *fiber.Ctx, signer *jwt.Signer) (jwt.Claims, error) {
+	scheme, raw, ok := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return jwt.Claims{}, errNoBearer
+	}
+	return signer.Verify(strings.TrimSpace(raw))
+}
+
+// CurrentUser returns the claims RequireAuth accepted for this request.
+func CurrentUser(c *fiber.Ctx) (jwt.Claims, bool) {
+	claims, ok := c.Locals(USER_LOCALS_KEY).(jwt.Claims)
+	return claims, ok
+}
+
+// unauthorized answers with 401, a Bearer challenge, and the repo's
+// standard error envelope.
+func unauthorized(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="api"`)
+	return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
@@ -0,0 +1,87 @@
+// Package middleware provides Fiber middleware shared by route groups.
+package middleware
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+)
+
+// CSP_REPORT_CONTENT_TYPE is the JSON media type of legacy report-uri
+// Content-Security-Policy violation reports.
+const CSP_REPORT_CONTENT_TYPE = "application/csp-report"
+
+// utf8BOM is the byte order mark some clients prepend to UTF-8 bodies.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// JSONBody enforces JSON request bodies on POST, PUT, and PATCH requests.
+// Requests without a body pass through, since several endpoints (likes,
+// archiving) take none.
+//
+// JSON bodies must be application/json, a +json media type, or the legacy
+// application/csp-report type browsers send CSP reports with, in UTF-8:
+// a charset parameter other than UTF-8 is rejected, while a UTF-8 byte order
+// mark is stripped. The Content-Type is normalized to plain
+// application/json so handlers can rely on BodyParser. With allowForm,
+// application/x-www-form-urlencoded bodies are accepted as well; the embed
+// widget uses them to post without a CORS preflight.
+//
+// Parameters:
+//   - allowForm: also accept form-encoded bodies
+//
+// Rejected requests get 415 Unsupported Media Type.
+func JSONBody(allowForm bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+		if len(c.Body()) == 0 {
+			return c.Next()
+		}
+
+		mediaType, params, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+		if err != nil {
+			return unsupported(c, "Content-Type must be application/json")
+		}
+
+		switch {
+		case mediaType == fiber.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json") || mediaType == CSP_REPORT_CONTENT_TYPE:
+			if charset, ok := params["charset"]; ok && !isUTF8(charset) {
+				return unsupported(c, "Unsupported charset, use UTF-8")
+			}
+			if body := c.Body(); bytes.HasPrefix(body, utf8BOM) {
+				c.Request().SetBody(body[len(utf8BOM):])
+			}
+			c.Request().Header.SetContentType(fiber.MIMEApplicationJSON)
+		case allowForm && mediaType == fiber.MIMEApplicationForm:
+			if charset, ok := params["charset"]; ok && !isUTF8(charset) {
+				return unsupported(c, "Unsupported charset, use UTF-8")
+			}
+		default:
+			if allowForm {
+				return unsupported(c, "Content-Type must be application/json or application/x-www-form-urlencoded")
+			}
+			return unsupported(c, "Content-Type must be application/json")
+		}
+		return c.Next()
+	}
+}
+
+// isUTF8 reports whether a charset parameter names UTF-8.
+func isUTF8(charset string) bool {
+	return strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "utf8")
+}
+
+// unsupported answers with 415 and the repo's standard error envelope.
+func unsupported(c *fiber.Ctx, message string) error {
+	return c.Status(http.StatusUnsupportedMediaType).JSON(models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ANY_ORIGIN allows every origin in ParseOrigins entries.
+const ANY_ORIGIN = "*"
+
+// ParseOrigins parses the origins allowed to call the API cross-origin:
+// http(s) origins such as "https://blog.example.com" or
+// "http://localhost:3000", "https://*.example.com" for every subdomain, or
+// ANY_ORIGIN alone. Origins must not have a path. Invalid entries are
+// skipped and reported in the returned error; the valid ones are still
+// returned, mirroring ParseLogSampling.
+//
+// Parameters:
+//   - entries: allowed origins from configuration
+func ParseOrigins(entries []string) ([]string, error) {
+	var origins, invalid []string
+	for _, entry := range entries {
+		if entry == ANY_ORIGIN && len(entries) == 1 {
+			return []string{ANY_ORIGIN}, nil
+		}
+		parsed, err := url.Parse(strings.Replace(entry, "://*.", "://", 1))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") ||
+			parsed.Hostname() == "" || strings.Contains(parsed.Host, "*") ||
+			parsed.User != nil || parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+			invalid = append(invalid, entry)
+			continue
+		}
+		origins = append(origins, strings.ToLower(entry))
+	}
+	if len(invalid) > 0 {
+		return origins, fmt.Errorf("invalid origins: %v", invalid)
+	}
+	return origins, nil
+}
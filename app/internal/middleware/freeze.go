@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// FreezeLookup returns the content freeze in effect now, or nil when
+// content may change.
+type FreezeLookup func(ctx context.Context) (*models.ContentFreeze, error)
+
+// FreezeAudit records a request a content freeze rejected.
+type FreezeAudit func(c *fiber.Ctx, freeze *models.ContentFreeze, operation string)
+
+// ContentFreeze rejects requests performing operation while a content
+// freeze is in effect, with 403 and the freeze window in the response
+// data. Every rejection is passed to audit. Routes whose requests only
+// sometimes perform the operation, such as creating a post that may be a
+// draft, pass applies to tell them apart; nil applies to every request.
+//
+// Parameters:
+//   - lookup: returns the freeze in effect
+//   - audit: records rejected requests
+//   - operation: models.FREEZE_PUBLISH or models.FREEZE_DELETE
+//   - applies: reports whether a request performs the operation, or nil
+//
+// Lookup failures get 502: content must not change while the freeze
+// cannot be checked.
+func ContentFreeze(lookup FreezeLookup, audit FreezeAudit, operation string, applies func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if applies != nil && !applies(c) {
+			return c.Next()
+		}
+
+		freeze, err := lookup(c.Context())
+		if err != nil {
+			logger.Ctx(c.Context()).Error("failed to look up content freeze", zap.Error(err))
+			return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Failed to check content freeze",
+			})
+		}
+		if freeze == nil {
+			return c.Next()
+		}
+
+		audit(c, freeze, operation)
+		message := "Content is frozen until " + freeze.EndsAt.UTC().Format(time.RFC3339) + ", posts cannot be published or deleted"
+		if freeze.Reason != "" {
+			message += " (" + freeze.Reason + ")"
+		}
+		return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Data:    freeze,
+			Error:   message,
+		})
+	}
+}
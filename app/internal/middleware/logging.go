@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/proxy"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// LogSampling keeps a fraction of the request log lines of paths under
+// Prefix, for high-traffic endpoints such as listings and health probes.
+type LogSampling struct {
+	Prefix string  // Path prefix the rate applies to, e.g. "/api/posts"
+	Rate   float64 // Fraction (0-1) of requests logged; 0 logs none
+}
+
+// ParseLogSampling parses "prefix=rate" entries, e.g. "/healthz=0" or
+// "/api/posts=0.1". Invalid entries are skipped and reported in the
+// returned error; the valid ones are still returned, mirroring
+// proxy.NewResolver.
+//
+// Parameters:
+//   - entries: sampling rules from configuration
+func ParseLogSampling(entries []string) ([]LogSampling, error) {
+	var rules []LogSampling
+	var invalid []string
+	for _, entry := range entries {
+		prefix, raw, ok := strings.Cut(entry, "=")
+		rate, err := strconv.ParseFloat(raw, 64)
+		if !ok || !strings.HasPrefix(prefix, "/") || err != nil || rate < 0 || rate > 1 {
+			invalid = append(invalid, entry)
+			continue
+		}
+		rules = append(rules, LogSampling{Prefix: prefix, Rate: rate})
+	}
+	if len(invalid) > 0 {
+		return rules, fmt.Errorf("invalid request log sampling: %v", invalid)
+	}
+	return rules, nil
+}
+
+// RequestLogger logs one structured line per request once it is handled:
+// method, path, matched route, status, latency, and client IP, plus the
+// request ID through the request-scoped logger (see RequestID).
+// Server errors (5xx) are logged at error level and always kept; other
+// requests are logged at info level, sampled by the longest matching
+// prefix in sampling. Paths without a rule are always logged.
+//
+// Parameters:
+//   - proxies: resolves the client IP behind trusted reverse proxies
+//   - sampling: per-prefix sampling rates (see ParseLogSampling)
+func RequestLogger(proxies *proxy.Resolver, sampling []LogSampling) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+		latency := time.Since(start)
+
+		// Errors returned by handlers are turned into a response only after
+		// the middleware chain unwinds, so derive their status here
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = http.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		path := c.Path()
+		if status < http.StatusInternalServerError && !sampled(path, sampling) {
+			return err
+		}
+
+		fields := []zap.Field{
+			zap.String("method", c.Method()),
+			zap.String("path", path),
+			zap.String("route", c.Route().Path),
+			zap.Int("status", status),
+			zap.Duration("latency", latency),
+			zap.String("client_ip", proxies.ClientIP(c.Context().RemoteIP().String(), c.Get(fiber.HeaderXForwardedFor), c.Get("X-Real-IP"))),
+		}
+		if status >= http.StatusInternalServerError {
+			if err != nil {
+				fields = append(fields, zap.Error(err))
+			}
+			logger.Ctx(c.Context()).Error("request failed", fields...)
+		} else {
+			logger.Ctx(c.Context()).Info("request handled", fields...)
+		}
+		return err
+	}
+}
+
+// sampled decides whether a request to path is logged, using the rule
+// with the longest matching prefix.
+func sampled(path string, sampling []LogSampling) bool {
+	rate, matched := 1.0, -1
+	for _, rule := range sampling {
+		if strings.HasPrefix(path, rule.Prefix) && len(rule.Prefix) > matched {
+			rate, matched = rule.Rate, len(rule.Prefix)
+		}
+	}
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/proxy"
+	"github.com/pedrobertao/challenge-prosi/app/internal/ratelimit"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// Rate limit response headers, sent on every counted request.
+const (
+	RATE_LIMIT_LIMIT_HEADER     = "X-RateLimit-Limit"
+	RATE_LIMIT_REMAINING_HEADER = "X-RateLimit-Remaining"
+)
+
+// RateLimit caps the requests each client IP makes under policy. Requests
+// over the limit get 429 with a Retry-After header giving the seconds
+// until the window resets. Counted requests report the limit and what is
+// left of it in X-RateLimit-Limit and X-RateLimit-Remaining.
+//
+// Requests with methods the policy does not count pass through, as do all
+// requests when the policy is disabled. When the store fails the request
+// is let through: an outage of the counters must not take the API down.
+//
+// Parameters:
+//   - proxies: resolves the client IP behind trusted reverse proxies
+//   - store: keeps the request counts (see ratelimit.Store)
+//   - policy: limit, window, and methods counted
+func RateLimit(proxies *proxy.Resolver, store ratelimit.Store, policy ratelimit.Policy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if policy.Limit <= 0 || !policy.Counts(c.Method()) {
+			return c.Next()
+		}
+
+		ip := proxies.ClientIP(c.Context().RemoteIP().String(), c.Get(fiber.HeaderXForwardedFor), c.Get("X-Real-IP"))
+		count, reset, err := store.Hit(c.Context(), "ratelimit:"+policy.Name+":"+ip, policy.Window)
+		if err != nil {
+			logger.Ctx(c.Context()).Warn("rate limit store failed, request not limited",
+				zap.String("policy", policy.Name), zap.Error(err))
+			return c.Next()
+		}
+
+		limit := int64(policy.Limit)
+		c.Set(RATE_LIMIT_LIMIT_HEADER, strconv.FormatInt(limit, 10))
+		c.Set(RATE_LIMIT_REMAINING_HEADER, strconv.FormatInt(max(limit-count, 0), 10))
+		if count > limit {
+			retryAfter := int64(math.Ceil(reset.Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(max(retryAfter, 1), 10))
+			return c.Status(http.StatusTooManyRequests).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Too many requests, retry later",
+			})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// REQUEST_ID_LOCALS_KEY is the fiber.Ctx locals key holding the ID of the
+// request being served.
+const REQUEST_ID_LOCALS_KEY = "request_id"
+
+// requestIDPattern accepts client-supplied request IDs: up to 128 URL- and
+// log-safe characters, enough for UUIDs and tracing IDs.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// errorBodyPrefix starts every serialized failed models.APIResponse.
+var errorBodyPrefix = []byte(`{"success":false`)
+
+// RequestID gives every request an ID: the client's X-Request-ID when it
+// is well formed, otherwise a random one. The ID is stored in the locals
+// (see CurrentRequestID), echoed in the X-Request-ID response header, and
+// attached to a request-scoped logger reachable with logger.Ctx, so log
+// entries of one request can be found together. Failed APIResponse bodies
+// get a request_id field as well, so users can quote it when reporting an
+// error.
+//
+// It should run first, so every later middleware sees the ID.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(fiber.HeaderXRequestID)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Locals(REQUEST_ID_LOCALS_KEY, id)
+		c.Locals(logger.CONTEXT_KEY, logger.With(zap.String("request_id", id)))
+		c.Set(fiber.HeaderXRequestID, id)
+
+		err := c.Next()
+
+		// Handlers build error responses with models.APIResponse, which
+		// serializes success first; append the ID as its last field.
+		// Streamed bodies are never touched, reading them would drain them.
+		if err != nil || c.Response().StatusCode() < fiber.StatusBadRequest || c.Response().IsBodyStream() {
+			return err
+		}
+		if body := c.Response().Body(); bytes.HasPrefix(body, errorBodyPrefix) && bytes.HasSuffix(body, []byte("}")) {
+			quoted, _ := json.Marshal(id)
+			withID := make([]byte, 0, len(body)+len(quoted)+16)
+			withID = append(withID, body[:len(body)-1]...)
+			withID = append(withID, `,"request_id":`...)
+			withID = append(withID, quoted...)
+			withID = append(withID, '}')
+			c.Response().SetBodyRaw(withID)
+		}
+		return nil
+	}
+}
+
+// CurrentRequestID returns the ID RequestID assigned to the request, or ""
+// when the middleware did not run.
+func CurrentRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(REQUEST_ID_LOCALS_KEY).(string)
+	return id
+}
+
+// newRequestID returns 16 random bytes, hex encoded.
+func newRequestID() string {
+	var raw [16]byte
+	_, _ = rand.Read(raw[:])
+	return hex.EncodeToString(raw[:])
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+	"go.uber.org/zap"
+)
+
+// Tracing records a server span for every request (see lib/tracing). The
+// span continues the trace of the client's traceparent header when it is
+// well formed, so a request can be followed from the caller through this
+// service. The span is stored in the locals under tracing.CONTEXT_KEY, so
+// database commands and outgoing requests made with contexts derived from
+// fiber.Ctx.Context() become its children. The trace ID is added to the
+// request-scoped logger and the span's trace context is echoed in a
+// traceparent response header, linking log lines and bug reports to the
+// trace.
+//
+// It should run after RequestID, whose logger it extends.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		parent, _ := tracing.ParseTraceparent(c.Get(tracing.TRACEPARENT_HEADER))
+		span := tracing.StartSpan(parent, c.Method(), tracing.KIND_SERVER)
+		defer span.End()
+
+		c.Locals(tracing.CONTEXT_KEY, span)
+		c.Locals(logger.CONTEXT_KEY, logger.Ctx(c.Context()).With(zap.String("trace_id", span.Context().TraceID.String())))
+		c.Set(tracing.TRACEPARENT_HEADER, span.Context().Traceparent())
+
+		err := c.Next()
+
+		// The route is only known once a handler matched, and errors are
+		// turned into a response after the chain unwinds, as in RequestLogger
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = http.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		// Fiber reuses the buffers behind request strings once the request is
+		// done, before the span is exported, so keep copies
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(
+			tracing.Attr("http.request.method", c.Method()),
+			tracing.Attr("http.route", route),
+			tracing.Attr("url.path", strings.Clone(c.Path())),
+			tracing.Attr("http.response.status_code", status),
+			tracing.Attr("user_agent.original", strings.Clone(c.Get(fiber.HeaderUserAgent))),
+		)
+		if status >= http.StatusInternalServerError {
+			message := http.StatusText(status)
+			if err != nil {
+				message = err.Error()
+			}
+			span.SetError(message)
+		}
+		return err
+	}
+}
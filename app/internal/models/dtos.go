@@ -1,45 +1,321 @@
 // Package models defines the data structures used throughout the blog API.
 // It contains request/response models for HTTP endpoints and database entities
-// with proper JSON and BSON tags for serialization.
+// with proper JSON and BSON tags for serialization. Request validation rules
+// are declared in `schema` tags and published by GET /api/schema/:type.
 package models
 
 import (
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"encoding/json"
+	"time"
 )
 
 // CreatePostRequest represents the JSON payload for creating a new blog post.
 // Used in POST /api/posts endpoint to capture the required fields for post creation.
 type CreatePostRequest struct {
-	Title   string `json:"title"`   // Post title (required)
-	Content string `json:"content"` // Post content/body (required)
+	Title   string `json:"title" schema:"required,trim,minLength=1,maxLength=200"`      // Post title, trimmed (required)
+	Content string `json:"content" schema:"required,trim,minLength=1,maxLength=100000"` // Post content/body, trimmed (required)
+
+	Visibility string `json:"visibility" schema:"enum=public|unlisted|private"` // Visibility level (optional, defaults to public)
+
+	Tags       []string `json:"tags" schema:"maxItems=10"` // Topic tags, normalized to lowercase (optional)
+	CategoryID string   `json:"category_id"`               // ID of an existing category (optional)
+
+	PublishAt string `json:"publish_at"`                     // When the post goes live, RFC 3339 or local time in Timezone (optional, defaults to now)
+	Timezone  string `json:"timezone" schema:"maxLength=64"` // IANA time zone of the author (optional, defaults to the timezone setting, then UTC)
+
+	TitleVariants []string `json:"title_variants" schema:"maxItems=4"` // Alternative headlines to test against the title (optional)
+
+	CommentsCloseAfterDays *int `json:"comments_close_after_days"` // Days after publication comments close, 0 for never (optional, defaults to COMMENTS_AUTO_CLOSE_DAYS)
+}
+
+// UpdatePostRequest represents the JSON payload for editing a blog post.
+// Used in PUT /api/posts/:id. Every field is optional; only the fields
+// present in the body are changed.
+type UpdatePostRequest struct {
+	Title   *string   `json:"title" schema:"minLength=1"`   // New post title (optional, not empty)
+	Content *string   `json:"content" schema:"minLength=1"` // New post content/body (optional, not empty)
+	Excerpt *string   `json:"excerpt"`                      // New summary, empty to clear (optional)
+	Tags    *[]string `json:"tags" schema:"maxItems=10"`    // New tags, empty to clear (optional)
+
+	CategoryID *string `json:"category_id"` // ID of an existing category, empty to clear (optional)
+
+	TitleVariants *[]string `json:"title_variants" schema:"maxItems=4"` // New alternative headlines, empty to end the test (optional)
+
+	PublishAt *string `json:"publish_at"`                     // New publication time, empty to publish now (optional)
+	Timezone  *string `json:"timezone" schema:"maxLength=64"` // New author time zone, also used to read publish_at (optional)
+
+	CommentsCloseAfterDays *int `json:"comments_close_after_days"` // New comment window in days, 0 for never, negative to use the default (optional)
 }
 
 // CreateCommentRequest represents the JSON payload for creating a new comment.
 // Used in POST /api/posts/:id/comments endpoint to capture comment details.
+// The embed widget endpoint also accepts it form-encoded.
 type CreateCommentRequest struct {
-	Author  string `json:"author"`  // Comment author name (required)
-	Content string `json:"content"` // Comment text content (required)
+	Author          string `json:"author" form:"author" schema:"required,trim,minLength=1,maxLength=100"` // Comment author name, trimmed (required)
+	Content         string `json:"content" form:"content" schema:"required,trim"`                         // Comment text content, trimmed (required, length set by config)
+	ParentCommentID string `json:"parent_comment_id" form:"parent_comment_id"`                            // Comment replied to, on the same post (optional)
+}
+
+// UpdateCommentRequest represents the JSON payload for editing a comment.
+// Used in PUT /api/comments/:id.
+type UpdateCommentRequest struct {
+	Content string `json:"content" schema:"required"` // New comment text (required, length set by config)
+}
+
+// ImportCommentsRequest represents the JSON payload for importing historical
+// comments into a post. Used in POST /api/posts/:id/comments/import.
+type ImportCommentsRequest struct {
+	Comments []ImportedComment `json:"comments" schema:"required,maxItems=1000"` // Comments to import (required)
+}
+
+// ImportedComment is one comment of an import batch. Author and timestamp
+// are kept as they were on the original platform.
+type ImportedComment struct {
+	ImportID       string    `json:"import_id"`                             // ID on the source platform (optional, enables re-runs)
+	ParentImportID string    `json:"parent_import_id"`                      // import_id of the comment replied to (optional)
+	Author         string    `json:"author" schema:"required,minLength=1"`  // Original author name (required)
+	Content        string    `json:"content" schema:"required,minLength=1"` // Comment text content (required)
+	CreatedAt      time.Time `json:"created_at" schema:"required"`          // Original creation time (required)
+}
+
+// UpsertTranslationRequest represents the JSON payload for storing a post
+// translation. Used in POST /api/posts/:id/translations/:lang.
+type UpsertTranslationRequest struct {
+	Title   string `json:"title" schema:"required,minLength=1"`   // Localized title (required)
+	Content string `json:"content" schema:"required,minLength=1"` // Localized content (required)
+}
+
+// AcceptAssistRequest represents the JSON payload for accepting content
+// assistant suggestions. Used in POST /api/posts/:id/assist/accept; only the
+// provided fields are stored on the post.
+type AcceptAssistRequest struct {
+	Summary string   `json:"summary"` // Accepted summary, stored as the post excerpt (optional)
+	Tags    []string `json:"tags"`    // Accepted tags (optional)
+}
+
+// ReactRequest represents the JSON payload for reacting to a comment.
+// Used in POST /api/comments/:id/react.
+type ReactRequest struct {
+	Reaction string `json:"reaction" schema:"required,enum=like|love|laugh|insightful|sad"` // Reaction type (required)
+}
+
+// ClapRequest represents the JSON payload for clapping for a post.
+// Used in POST /api/posts/:id/clap; an empty body claps once.
+type ClapRequest struct {
+	Count int64 `json:"count" schema:"minimum=1,maximum=50"` // Claps to add, default 1, at most MAX_CLAPS_PER_REQUESTER (optional)
+}
+
+// SetVisibilityRequest represents the JSON payload for changing a post's
+// visibility. Used in PUT /api/posts/:id/visibility.
+type SetVisibilityRequest struct {
+	Visibility string `json:"visibility" schema:"required,enum=public|unlisted|private"` // New visibility level (required)
+}
+
+// PassphraseRequest represents the JSON payload carrying a post passphrase.
+// Used to set it in PUT /api/posts/:id/passphrase and to exchange it for an
+// access token in POST /api/posts/:id/unlock.
+type PassphraseRequest struct {
+	Passphrase string `json:"passphrase" schema:"required,minLength=1,maxLength=72"` // Passphrase, at most 72 bytes (required)
 }
 
 // DeletePostRequest represents the request structure for deleting a blog post.
-// Contains the MongoDB ObjectID of the post to be deleted.
+// Contains the ID of the post to be deleted.
 // The bson tag supports both JSON requests and direct MongoDB operations.
 type DeletePostRequest struct {
-	ID primitive.ObjectID `json:"id"` // MongoDB ObjectID of the post
+	ID ID `json:"id"` // ID of the post
 }
 
 // DeleteCommentRequest represents the request structure for deleting a comment.
-// Contains the MongoDB ObjectID of the comment to be deleted.
+// Contains the ID of the comment to be deleted.
 // The bson tag supports both JSON requests and direct MongoDB operations.
 type DeleteCommentRequest struct {
-	ID primitive.ObjectID `json:"id"` // MongoDB ObjectID of the comment
+	ID ID `json:"id"` // ID of the comment
+}
+
+// CreateIntegrationRequest represents the JSON payload for connecting a
+// chat channel.
+type CreateIntegrationRequest struct {
+	Driver     string   `json:"driver" schema:"required,enum=slack|discord"` // Chat service (required)
+	WebhookURL string   `json:"webhook_url" schema:"required,minLength=1"`   // Incoming webhook URL of the channel (required)
+	Events     []string `json:"events"`                                      // Events to send (optional, defaults to post.published)
+}
+
+// CategoryRequest represents the JSON payload for creating or replacing a
+// category. Used in POST /api/categories and PUT /api/categories/:id.
+type CategoryRequest struct {
+	Name        string `json:"name" schema:"required,minLength=1,maxLength=64"` // Display name, unique once slugified (required)
+	Description string `json:"description" schema:"maxLength=500"`              // Short blurb (optional)
+}
+
+// SavedSearchRequest represents the JSON payload for saving a search.
+// Used in POST /api/me/searches.
+type SavedSearchRequest struct {
+	Query string   `json:"query" schema:"maxLength=256"` // Full-text query (optional if tags are set)
+	Tags  []string `json:"tags" schema:"maxItems=10"`    // Tags a post must all carry (optional if query is set)
+}
+
+// SiteFileRequest represents the JSON payload replacing a generated site
+// file. Used in PUT /api/admin/site-files/:name.
+type SiteFileRequest struct {
+	Content string `json:"content" schema:"required,minLength=1"` // Full file text (required)
+}
+
+// CreateAPIKeyRequest represents the JSON payload for creating an API key.
+// Used in POST /api/admin/api-keys.
+type CreateAPIKeyRequest struct {
+	Name  string `json:"name" schema:"required,minLength=1"` // What the key is for (required)
+	Scope string `json:"scope" schema:"enum=read|write"`     // Permissions (optional, defaults to read)
+}
+
+// ContentFreezeRequest represents the JSON payload for scheduling a content
+// freeze. Used in PUT /api/admin/freeze; it replaces any scheduled freeze.
+type ContentFreezeRequest struct {
+	StartsAt *time.Time `json:"starts_at"`                          // When the freeze takes effect (optional, defaults to now)
+	EndsAt   time.Time  `json:"ends_at" schema:"required"`          // When content may change again, in the future (required)
+	Reason   string     `json:"reason" schema:"trim,maxLength=500"` // Why content is frozen (optional)
+}
+
+// AutosaveRequest represents the JSON payload for saving a draft snapshot.
+// Used in PUT /api/posts/:id/autosave; either field may still be empty
+// while the author is writing.
+type AutosaveRequest struct {
+	Title   string `json:"title" schema:"maxLength=200"`      // Title in the editor
+	Content string `json:"content" schema:"maxLength=100000"` // Content in the editor
+}
+
+// Bulk moderation actions of BulkModerationRequest.
+const (
+	MODERATION_APPROVE   = "approve"   // Set the approved status, showing hidden comments again
+	MODERATION_REJECT    = "reject"    // Set the rejected status, hiding the comments
+	MODERATION_DELETE    = "delete"    // Move the comments and their replies to the trash
+	MODERATION_MARK_SPAM = "mark-spam" // Set the spam status, hiding the comments
+)
+
+// BulkModerationRequest represents the JSON payload for moderating many
+// comments at once. Used in POST /api/admin/comments/bulk.
+type BulkModerationRequest struct {
+	IDs    []string `json:"ids" schema:"required,maxItems=500"`                            // IDs of the comments to moderate (required)
+	Action string   `json:"action" schema:"required,enum=approve|reject|delete|mark-spam"` // One of the MODERATION_* actions (required)
+}
+
+// CreatedAPIKey is returned once when an API key is created; the key
+// cannot be retrieved afterwards.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"` // The secret to send in X-API-Key
+}
+
+// AuthRequest represents the JSON payload of POST /api/auth/register and
+// POST /api/auth/login.
+type AuthRequest struct {
+	Username string `json:"username" schema:"required,minLength=3,maxLength=32"` // Login name, letters, digits, "_", "." or "-" (required)
+	Password string `json:"password" schema:"required,minLength=8,maxLength=72"` // Password, at most 72 bytes (required)
+}
+
+// UpdateSettingsRequest represents the JSON payload of PUT
+// /api/me/settings. It replaces every setting; omitted fields are reset to
+// their defaults.
+type UpdateSettingsRequest struct {
+	DefaultVisibility    string   `json:"default_visibility" schema:"enum=public|unlisted|private"` // Visibility of new posts (optional, defaults to public)
+	ModerateComments     bool     `json:"moderate_comments"`                                        // Hold comments on own posts for review (optional)
+	NotificationChannels []string `json:"notification_channels" schema:"maxItems=2"`                // Integration drivers: slack, discord (optional)
+	Locale               string   `json:"locale" schema:"maxLength=35"`                             // Language tag, e.g. "pt-BR" (optional)
+	Timezone             string   `json:"timezone" schema:"maxLength=64"`                           // IANA time zone name, e.g. "Europe/Lisbon" (optional)
+}
+
+// AuthResponse is returned by register and login. Token is sent as
+// "Authorization: Bearer <token>" on protected requests.
+type AuthResponse struct {
+	Token     string    `json:"token"`      // Signed JWT
+	ExpiresAt time.Time `json:"expires_at"` // When the token stops being accepted
+	User      User      `json:"user"`       // The authenticated account
 }
 
 // APIResponse is the standardized response structure for all API endpoints.
 // Provides consistent format for success/error responses with optional data payload.
 // This ensures uniform client-side response handling across the entire API.
 type APIResponse struct {
-	Success bool   `json:"success"`         // Indicates if the operation was successful
-	Data    any    `json:"data,omitempty"`  // Response payload (omitted if nil/empty)
-	Error   string `json:"error,omitempty"` // Error message (omitted if empty)
+	Success    bool        `json:"success"`              // Indicates if the operation was successful
+	Data       any         `json:"data,omitempty"`       // Response payload (omitted if nil/empty)
+	Error      string      `json:"error,omitempty"`      // Error message (omitted if empty)
+	Pagination *Pagination `json:"pagination,omitempty"` // Page metadata for paginated listings
+	Cursor     *Cursor     `json:"cursor,omitempty"`     // Page metadata for listings read with cursor pagination
+
+	// Facets count the matches of a search per value of a field, e.g.
+	// per language, so clients can offer them as filters.
+	Facets map[string][]Facet `json:"facets,omitempty"`
+
+	// RequestID identifies the request in server logs. Handlers leave it
+	// empty: middleware.RequestID adds it to every failed response.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Facet is one value of a facet field and the number of matches having it.
+type Facet struct {
+	Value string `json:"value"` // Field value, e.g. "pt"
+	Count int64  `json:"count"` // Matches with the value
+}
+
+// Pagination describes the page of a listing returned in Data.
+type Pagination struct {
+	Page       int   `json:"page"`        // 1-based page number
+	Limit      int   `json:"limit"`       // Maximum items per page
+	Total      int64 `json:"total"`       // Items matching the listing across all pages
+	TotalPages int   `json:"total_pages"` // Number of pages, zero when nothing matches
+}
+
+// Cursor describes the page of a listing read with cursor pagination, in
+// place of Pagination: totals are not counted, so pages cost the same
+// however deep a client scrolls.
+type Cursor struct {
+	Limit      int `json:"limit"`                 // Maximum items per page
+	NextCursor ID  `json:"next_cursor,omitempty"` // Value of after for the next page, omitted on the last page
+}
+
+// MobilePost is the view=mobile shape of GET /api/posts/:id: the fields a
+// mobile reader screen shows, with content cut short. HasMore tells the
+// client to fetch the full view when the reader asks for the rest.
+type MobilePost struct {
+	ID           ID         `json:"id"`                     // Primary key (format set by storage.IDCodec)
+	Title        string     `json:"title"`                  // Post title, localized and title-tested like the full view
+	Excerpt      string     `json:"excerpt,omitempty"`      // Short summary
+	Content      string     `json:"content"`                // Content, shortened to MOBILE_CONTENT_LENGTH characters
+	ContentHTML  string     `json:"content_html,omitempty"` // Shortened content as sanitized HTML, for format=html
+	HasMore      bool       `json:"has_more,omitempty"`     // Whether Content was shortened
+	Language     string     `json:"language,omitempty"`     // Language of Title and Content
+	Tags         []string   `json:"tags,omitempty"`         // Topic tags
+	CommentCount int64      `json:"comment_count"`          // Number of comments on the post
+	LikeCount    int64      `json:"like_count"`             // Number of distinct likes
+	ClapCount    int64      `json:"clap_count"`             // Number of claps from all requesters
+	CreatedAt    time.Time  `json:"created_at"`             // Creation timestamp
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`   // Last edit, if any
+	Comments     []Comment  `json:"comments,omitempty"`     // Requested comment page, shortened
+}
+
+// BatchSubRequest is one request of a POST /api/batch body, which is a
+// JSON array of them.
+type BatchSubRequest struct {
+	Method string          `json:"method"`         // GET, POST, PUT, PATCH, or DELETE
+	Path   string          `json:"path"`           // Path under /api with its query string, e.g. "/api/posts?page=2"
+	Body   json.RawMessage `json:"body,omitempty"` // JSON request body (optional)
+}
+
+// BatchResponse is the outcome of one BatchSubRequest, in request order.
+type BatchResponse struct {
+	Status int             `json:"status"`         // HTTP status code of the sub-request
+	Body   json.RawMessage `json:"body,omitempty"` // Response body, as a JSON string when it is not JSON
+}
+
+// TagCount is one entry of GET /api/tags.
+type TagCount struct {
+	Tag       string `json:"tag" bson:"_id"`               // Normalized tag
+	PostCount int64  `json:"post_count" bson:"post_count"` // Number of published posts carrying it
+}
+
+// HealthStatus is returned by GET /healthz and GET /readyz.
+// Collections is only reported by the readiness check.
+type HealthStatus struct {
+	Status      string          `json:"status"`                // "ok", or "unavailable" when not ready
+	Version     string          `json:"version"`               // Build version of the running binary
+	Collections map[string]bool `json:"collections,omitempty"` // Reachability per MongoDB collection
 }
@@ -2,36 +2,647 @@ package models
 
 import (
 	"time"
+)
 
-	"go.mongodb.org/mongo-driver/bson/primitive"
+// Post visibility levels. A post without a stored visibility is public.
+const (
+	VISIBILITY_PUBLIC   = "public"   // Listed and readable by anyone
+	VISIBILITY_UNLISTED = "unlisted" // Readable by direct link, left out of listings
+	VISIBILITY_PRIVATE  = "private"  // Hidden from every public read path
 )
 
+// ValidVisibility reports whether v is one of the visibility levels.
+func ValidVisibility(v string) bool {
+	switch v {
+	case VISIBILITY_PUBLIC, VISIBILITY_UNLISTED, VISIBILITY_PRIVATE:
+		return true
+	}
+	return false
+}
+
 // BlogPost represents a blog post entity stored in MongoDB.
 // Contains the full post data including metadata and associated comments.
 type BlogPost struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`      // MongoDB ObjectID
-	Title     string             `json:"title" bson:"title"`           // Post title
-	Content   string             `json:"content" bson:"content"`       // Post content/body
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"` // Creation timestamp
-	Comments  []Comment          `json:"comments,omitempty" bson:"-"`  // Associated comments (not stored in post document)
+	ID        ID        `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	Title     string    `json:"title" bson:"title"`           // Post title
+	Content   string    `json:"content" bson:"content"`       // Post content/body
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // Creation timestamp
+	Comments  []Comment `json:"comments,omitempty" bson:"-"`  // Associated comments (not stored in post document)
+
+	// ContentHTML is Content rendered to sanitized HTML, set by GetPost
+	// for format=html only; content is always stored as Markdown.
+	ContentHTML string `json:"content_html,omitempty" bson:"-"`
+
+	// UpdatedAt is set when the post is edited through UpdatePost; unset
+	// for posts never edited.
+	UpdatedAt *time.Time `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+
+	// Denormalized engagement counters, maintained by the comment and read
+	// handlers so listings can filter on them without counting comments.
+	CommentCount int64 `json:"comment_count" bson:"comment_count"` // Number of comments on this post
+	ViewCount    int64 `json:"view_count" bson:"view_count"`       // Number of times the post was read
+	LikeCount    int64 `json:"like_count" bson:"like_count"`       // Number of distinct likes
+	ClapCount    int64 `json:"clap_count" bson:"clap_count"`       // Number of claps from all requesters
+
+	// Language is the tag of the language Title and Content are in. For the
+	// original post it is detected from Content when the post is saved
+	// (see i18n.Detect), and empty when it could not be told; when a
+	// translation is served it holds the translation's language.
+	Language string `json:"language,omitempty" bson:"language"`
+
+	// Archived posts are hidden from default listings but stay readable by
+	// direct link; clients use the flag to show an "archived" banner.
+	Archived   bool       `json:"archived" bson:"archived,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty" bson:"archived_at,omitempty"`
+
+	// Visibility is one of the VISIBILITY_* levels; empty means public.
+	// Unlisted posts are left out of listings, private posts are only
+	// reachable through signed preview links.
+	Visibility string `json:"visibility,omitempty" bson:"visibility,omitempty"`
+
+	// PublishAt schedules the post: until then it is hidden like a private
+	// post. Stored in UTC. Timezone is the IANA zone of the author when the
+	// post was written or scheduled, so clients can show times as the
+	// author meant them; empty means UTC.
+	PublishAt *time.Time `json:"publish_at,omitempty" bson:"publish_at,omitempty"`
+	Timezone  string     `json:"timezone,omitempty" bson:"timezone,omitempty"`
+
+	// Protected posts require their passphrase (exchanged for an access
+	// token) before GetPost serves them. The bcrypt hash never leaves the
+	// server.
+	Protected      bool   `json:"protected,omitempty" bson:"protected,omitempty"`
+	PassphraseHash string `json:"-" bson:"passphrase_hash,omitempty"`
+
+	// TitleVariants are alternative headlines tested against Title. Each
+	// visitor consistently sees one of them or Title, and TitleTest counts
+	// impressions and clicks per variant, Title first. Both stay on the
+	// server so readers cannot tell a test is running; TitleVariant reports
+	// which headline a response carries (0 for Title).
+	TitleVariants []string            `json:"-" bson:"title_variants,omitempty"`
+	TitleTest     []TitleVariantStats `json:"-" bson:"title_test,omitempty"`
+	TitleVariant  int                 `json:"title_variant,omitempty" bson:"-"`
+
+	// CommentsLocked is set by the comment closer job once the post is
+	// older than its comment window, after which new comments are
+	// rejected. CommentsCloseAfterDays overrides COMMENTS_AUTO_CLOSE_DAYS
+	// for this post; 0 keeps comments open indefinitely.
+	CommentsLocked         bool       `json:"comments_locked" bson:"comments_locked,omitempty"`
+	CommentsLockedAt       *time.Time `json:"comments_locked_at,omitempty" bson:"comments_locked_at,omitempty"`
+	CommentsCloseAfterDays *int       `json:"comments_close_after_days,omitempty" bson:"comments_close_after_days,omitempty"`
+
+	Excerpt    string   `json:"excerpt,omitempty" bson:"excerpt,omitempty"`         // Short summary shown in previews
+	Tags       []string `json:"tags,omitempty" bson:"tags,omitempty"`               // Topic tags
+	CategoryID ID       `json:"category_id,omitempty" bson:"category_id,omitempty"` // Category the post is filed under, see Category
+
+	// Stats are computed from Content when the post is saved.
+	Stats *ContentStats `json:"stats,omitempty" bson:"stats,omitempty"`
+
+	// LinkedPosts are the other posts Content links to, found when the post
+	// is saved (see content.LinkedPosts); their backlinks list this post.
+	LinkedPosts []ID `json:"-" bson:"linked_posts"`
+
+	// LastModified changes whenever the post or its comments change.
+	// Used for Last-Modified/If-Modified-Since handling, not exposed in JSON.
+	LastModified time.Time `json:"-" bson:"last_modified,omitempty"`
+}
+
+// Scheduled reports whether the post's publish_at is after now.
+func (p BlogPost) Scheduled(now time.Time) bool {
+	return p.PublishAt != nil && p.PublishAt.After(now)
+}
+
+// TitleVariantStats counts how one headline of a title test performs.
+type TitleVariantStats struct {
+	Impressions int64 `json:"impressions" bson:"impressions"` // Times it was shown in a listing
+	Clicks      int64 `json:"clicks" bson:"clicks"`           // Times a visitor shown it opened the post
+}
+
+// TitleTestResult is one headline of a title test with its performance.
+type TitleTestResult struct {
+	Variant int    `json:"variant"` // 0 for the post title, 1+ for TitleVariants
+	Title   string `json:"title"`   // The headline
+	TitleVariantStats
+	ClickRate float64 `json:"click_rate"` // Clicks per impression, 0 without impressions
+}
+
+// TitleTestResults is the state of a post's title test.
+type TitleTestResults struct {
+	PostID   ID                `json:"post_id"`  // Post under test
+	Variants []TitleTestResult `json:"variants"` // Every headline, the title first
+	Leader   *int              `json:"leader"`   // Variant with the highest click rate; null before any impression
+}
+
+// ContentStats describes the structure of a post body. Computed by
+// content.Analyze on save and aggregated by the admin stats endpoint.
+type ContentStats struct {
+	WordCount    int `json:"word_count" bson:"word_count"`       // Words in the content
+	HeadingCount int `json:"heading_count" bson:"heading_count"` // Markdown or HTML headings
+	LinkCount    int `json:"link_count" bson:"link_count"`       // Links, excluding images
+	ImageCount   int `json:"image_count" bson:"image_count"`     // Embedded images
+}
+
+// WritingStats are site-wide content statistics over all posts.
+type WritingStats struct {
+	Posts        int64   `json:"posts" bson:"posts"`                 // Number of posts
+	Words        int64   `json:"words" bson:"words"`                 // Total words
+	AverageWords float64 `json:"average_words" bson:"average_words"` // Mean words per post
+	Headings     int64   `json:"headings" bson:"headings"`           // Total headings
+	Links        int64   `json:"links" bson:"links"`                 // Total links
+	Images       int64   `json:"images" bson:"images"`               // Total images
+}
+
+// BlogPostHeader is the slim projection of a blog post used by list queries.
+// It carries only the fields needed to build summaries so the potentially
+// large content field is never transferred from MongoDB or decoded.
+type BlogPostHeader struct {
+	ID           ID        `bson:"_id"`           // Primary key (format set by storage.IDCodec)
+	Title        string    `bson:"title"`         // Post title
+	CreatedAt    time.Time `bson:"created_at"`    // Creation timestamp
+	CommentCount int64     `bson:"comment_count"` // Denormalized comment counter
+	LikeCount    int64     `bson:"like_count"`    // Denormalized like counter
+	ClapCount    int64     `bson:"clap_count"`    // Denormalized clap counter
+	Language     string    `bson:"language"`      // Detected language, "" when unknown
+
+	TitleVariants []string `bson:"title_variants"` // Alternative headlines under test
 }
 
 // BlogPostSummary represents a condensed view of a blog post for list endpoints.
 // Used in GET /api/posts to provide overview information without full content.
 // Optimized for performance by excluding the potentially large content field.
 type BlogPostSummary struct {
-	ID           primitive.ObjectID `json:"id"`            // MongoDB ObjectID
-	Title        string             `json:"title"`         // Post title
-	CommentCount int64              `json:"comment_count"` // Number of comments on this post
-	CreatedAt    time.Time          `json:"created_at"`    // Creation timestamp
+	ID           ID        `json:"id"`                      // Primary key (format set by storage.IDCodec)
+	Title        string    `json:"title"`                   // Post title
+	CommentCount int64     `json:"comment_count"`           // Number of comments on this post
+	LikeCount    int64     `json:"like_count"`              // Number of distinct likes
+	ClapCount    int64     `json:"clap_count"`              // Number of claps from all requesters
+	Language     string    `json:"language,omitempty"`      // Detected language of the post
+	Liked        bool      `json:"liked,omitempty"`         // Whether the requester liked this post
+	TitleVariant int       `json:"title_variant,omitempty"` // Headline shown when a title test runs (0 for the title)
+	CreatedAt    time.Time `json:"created_at"`              // Creation timestamp
+}
+
+// SearchResult is a post matching a GET /api/posts/search query: its
+// summary, the text index relevance score, and highlighted excerpts.
+type SearchResult struct {
+	BlogPostSummary
+	Score      float64          `json:"score"`      // Text index relevance, higher is better
+	Highlights SearchHighlights `json:"highlights"` // Where the query terms appear
+}
+
+// SearchHighlights are HTML-escaped excerpts of a search result with the
+// matched words wrapped in <mark> tags.
+type SearchHighlights struct {
+	Title   string   `json:"title"`             // Full title, highlighted
+	Content []string `json:"content,omitempty"` // Excerpts of the content around matches
 }
 
 // Comment represents a comment entity stored in MongoDB.
 // Comments are stored in a separate collection and linked to posts via PostID.
 type Comment struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`      // MongoDB ObjectID
-	PostID    primitive.ObjectID `json:"post_id" bson:"post_id"`       // Reference to parent blog post
-	Author    string             `json:"author" bson:"author"`         // Comment author name
-	Content   string             `json:"content" bson:"content"`       // Comment text content
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"` // Creation timestamp
+	ID        ID        `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	PostID    ID        `json:"post_id" bson:"post_id"`       // Reference to parent blog post
+	Author    string    `json:"author" bson:"author"`         // Comment author name
+	Content   string    `json:"content" bson:"content"`       // Comment text content
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // Creation timestamp
+
+	// ParentID is the comment this one replies to, on the same post;
+	// empty for top-level comments. Set by replies sent with
+	// parent_comment_id and by imported discussions that were threaded.
+	ParentID ID `json:"parent_comment_id,omitempty" bson:"parent_id,omitempty"`
+
+	// Replies are the replies to the comment within the same page, set
+	// when GET /api/posts/:id threads its comments; never stored.
+	Replies []Comment `json:"replies,omitempty" bson:"-"`
+
+	// EditedAt is set when the content is changed through UpdateComment;
+	// unset for comments never edited.
+	EditedAt *time.Time `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+
+	// ImportID is the comment's ID on the platform it was imported from.
+	// Unique per post, so re-running an import skips comments already present.
+	ImportID string `json:"import_id,omitempty" bson:"import_id,omitempty"`
+
+	// Reactions counts the comment's reactions by type, e.g.
+	// {"like": 3, "laugh": 1}; kept by POST /api/comments/:id/react and
+	// unset until the comment gets one.
+	Reactions map[string]int64 `json:"reactions,omitempty" bson:"reactions,omitempty"`
+
+	// HasMore is set on listings requested with truncate= when Content was
+	// shortened; the full text is served by GET /api/comments/:id.
+	HasMore bool `json:"has_more,omitempty" bson:"-"`
+
+	// Status is the moderation outcome, one of the COMMENT_* statuses, set
+	// by POST /api/admin/comments/bulk; unset until moderated. Rejected and
+	// spam comments are hidden from readers and comment counts.
+	Status      string     `json:"status,omitempty" bson:"status,omitempty"`
+	ModeratedBy string     `json:"moderated_by,omitempty" bson:"moderated_by,omitempty"` // Audit actor of the last moderation
+	ModeratedAt *time.Time `json:"moderated_at,omitempty" bson:"moderated_at,omitempty"` // Time of the last moderation
+}
+
+// Comment moderation statuses. Comments never moderated have none and are
+// shown like approved ones.
+const (
+	COMMENT_APPROVED = "approved" // Reviewed and shown
+	COMMENT_REJECTED = "rejected" // Hidden by a moderator
+	COMMENT_SPAM     = "spam"     // Hidden as spam
+)
+
+// HiddenCommentStatuses lists the moderation statuses of comments hidden
+// from readers.
+var HiddenCommentStatuses = []string{COMMENT_REJECTED, COMMENT_SPAM}
+
+// Hidden reports whether the comment's moderation status hides it from
+// readers.
+func (c Comment) Hidden() bool {
+	return c.Status == COMMENT_REJECTED || c.Status == COMMENT_SPAM
+}
+
+// Backlink is a post linking to another one, listed by GET
+// /api/posts/:id/backlinks as a "mentioned in" reference.
+type Backlink struct {
+	ID        ID        `json:"id" bson:"_id"`                // ID of the linking post
+	Title     string    `json:"title" bson:"title"`           // Title of the linking post
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // Creation timestamp of the linking post
+}
+
+// Like records that a requester liked a post. Likes live in their own
+// collection with a unique (post_id, user_key) index so every requester
+// can like a post at most once.
+type Like struct {
+	ID        ID        `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	PostID    ID        `json:"post_id" bson:"post_id"`       // Reference to the liked blog post
+	UserKey   string    `json:"user_key" bson:"user_key"`     // User ID or anonymous requester hash
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // Creation timestamp
+}
+
+// MAX_CLAPS_PER_REQUESTER caps the claps one requester can give a post
+// over all their POST /api/posts/:id/clap requests.
+const MAX_CLAPS_PER_REQUESTER = 50
+
+// Clap records how many times a requester applauded a post. Unlike likes,
+// a requester can clap repeatedly, up to MAX_CLAPS_PER_REQUESTER; the
+// unique (post_id, user_key) index keeps their claps in one document.
+type Clap struct {
+	ID        ID        `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	PostID    ID        `json:"post_id" bson:"post_id"`       // Reference to the applauded blog post
+	UserKey   string    `json:"user_key" bson:"user_key"`     // User ID or anonymous requester hash
+	Claps     int64     `json:"claps" bson:"claps"`           // Claps given so far, at most MAX_CLAPS_PER_REQUESTER
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // First clap
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"` // Latest clap
+}
+
+// TopClappedPost is a post listed by GET /api/posts/top-clapped: its
+// summary and the claps it received in the requested window.
+type TopClappedPost struct {
+	BlogPostSummary
+	Claps int64 `json:"claps"` // Claps received in the window, clap_count for all time
+}
+
+// Comment reaction types.
+const (
+	REACTION_LIKE       = "like"
+	REACTION_LOVE       = "love"
+	REACTION_LAUGH      = "laugh"
+	REACTION_INSIGHTFUL = "insightful"
+	REACTION_SAD        = "sad"
+)
+
+// ValidReaction reports whether r is one of the reaction types.
+func ValidReaction(r string) bool {
+	switch r {
+	case REACTION_LIKE, REACTION_LOVE, REACTION_LAUGH, REACTION_INSIGHTFUL, REACTION_SAD:
+		return true
+	}
+	return false
+}
+
+// Reaction records how a requester reacted to a comment. Reactions live in
+// their own collection with a unique (comment_id, user_key) index so every
+// requester holds at most one reaction per comment; reacting again changes
+// its type.
+type Reaction struct {
+	ID        ID        `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	CommentID ID        `json:"comment_id" bson:"comment_id"` // Reference to the comment reacted to
+	PostID    ID        `json:"post_id" bson:"post_id"`       // Post of the comment, to trash reactions with it
+	UserKey   string    `json:"user_key" bson:"user_key"`     // User ID or anonymous requester hash
+	Type      string    `json:"type" bson:"type"`             // One of the REACTION_* types
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // Creation timestamp
+}
+
+// DuplicateMatch flags two posts whose content is highly similar, as found
+// by the near-duplicate scan job. Matches are replaced on every scan.
+type DuplicateMatch struct {
+	ID         ID        `json:"id" bson:"_id,omitempty"`        // Primary key (format set by storage.IDCodec)
+	PostA      ID        `json:"post_a" bson:"post_a"`           // First post of the pair
+	TitleA     string    `json:"title_a" bson:"title_a"`         // Title of the first post
+	PostB      ID        `json:"post_b" bson:"post_b"`           // Second post of the pair
+	TitleB     string    `json:"title_b" bson:"title_b"`         // Title of the second post
+	Similarity float64   `json:"similarity" bson:"similarity"`   // Estimated Jaccard similarity (0-1)
+	DetectedAt time.Time `json:"detected_at" bson:"detected_at"` // When the scan found the match
+}
+
+// Translation holds a localized title and content for a post in one
+// language. Stored in the translations collection, unique per (post_id, lang).
+type Translation struct {
+	ID        ID        `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	PostID    ID        `json:"post_id" bson:"post_id"`       // Reference to the translated post
+	Lang      string    `json:"lang" bson:"lang"`             // Normalized language tag (e.g., "pt-br")
+	Title     string    `json:"title" bson:"title"`           // Localized post title
+	Content   string    `json:"content" bson:"content"`       // Localized post content
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // Creation timestamp
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"` // Last update timestamp
+}
+
+// Built-in chat integration drivers (see the integrations package).
+const (
+	INTEGRATION_SLACK   = "slack"   // Slack incoming webhook
+	INTEGRATION_DISCORD = "discord" // Discord channel webhook
+)
+
+// ValidIntegrationDriver reports whether d is a built-in integration driver.
+func ValidIntegrationDriver(d string) bool {
+	return d == INTEGRATION_SLACK || d == INTEGRATION_DISCORD
+}
+
+// Events integrations can subscribe to.
+const (
+	EVENT_POST_PUBLISHED = "post.published" // A public post was created
+)
+
+// ValidEvent reports whether e is an event integrations can subscribe to.
+func ValidEvent(e string) bool {
+	return e == EVENT_POST_PUBLISHED
+}
+
+// Integration sends blog events to a chat channel through the channel's
+// incoming webhook. Webhook URLs embed their credentials, so API responses
+// only show them redacted.
+type Integration struct {
+	ID         ID        `json:"id" bson:"_id,omitempty"`        // Primary key (format set by storage.IDCodec)
+	Driver     string    `json:"driver" bson:"driver"`           // Chat service, e.g. INTEGRATION_SLACK
+	WebhookURL string    `json:"webhook_url" bson:"webhook_url"` // Incoming webhook URL of the channel
+	Events     []string  `json:"events" bson:"events"`           // Subscribed events, e.g. EVENT_POST_PUBLISHED
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`   // Creation timestamp
+}
+
+// Category groups posts under one heading; a post references at most one
+// through BlogPost.CategoryID. Slugs are derived from the name and unique,
+// so two categories cannot differ only in case or punctuation.
+type Category struct {
+	ID          ID        `json:"id" bson:"_id,omitempty"`                            // Primary key (format set by storage.IDCodec)
+	Name        string    `json:"name" bson:"name"`                                   // Display name
+	Slug        string    `json:"slug" bson:"slug"`                                   // Unique, URL-safe lowercase form of Name
+	Description string    `json:"description,omitempty" bson:"description,omitempty"` // Optional blurb shown on category pages
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`                       // Creation timestamp
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`                       // Last update timestamp
+}
+
+// Kinds of trashed items.
+const (
+	TRASH_POST    = "post"    // A post, trashed with its comments, likes, reactions, and translations
+	TRASH_COMMENT = "comment" // A comment, trashed with its replies and their reactions
+)
+
+// TrashItem is a deleted post or comment kept in the trash until it is
+// restored or purged. The deleted documents are stored alongside it and
+// never leave the server.
+type TrashItem struct {
+	ID        ID         `json:"id" bson:"_id"`                              // ID of the deleted post or comment
+	Kind      string     `json:"kind" bson:"kind"`                           // TRASH_POST or TRASH_COMMENT
+	PostID    ID         `json:"post_id,omitempty" bson:"post_id,omitempty"` // Post of a trashed comment
+	Title     string     `json:"title" bson:"title"`                         // Post title or the start of the comment, for listings
+	DeletedAt time.Time  `json:"deleted_at" bson:"deleted_at"`               // Deletion timestamp
+	PurgeAt   *time.Time `json:"purge_at,omitempty" bson:"-"`                // When the purge job removes it; unset if trash is kept forever
+}
+
+// SavedSearch is a query a user asked to be alerted about. Posts published
+// afterwards that match it add a SearchAlert for the user.
+type SavedSearch struct {
+	ID        ID        `json:"id" bson:"_id,omitempty"`          // Primary key (format set by storage.IDCodec)
+	UserID    ID        `json:"-" bson:"user_id"`                 // Owner
+	Query     string    `json:"query,omitempty" bson:"query"`     // Full-text query, as for GET /api/posts/search; empty matches any text
+	Tags      []string  `json:"tags,omitempty" bson:"tags"`       // Normalized tags a post must all carry
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`     // Creation timestamp
+	Matches   int64     `json:"matches" bson:"matches,omitempty"` // Posts matched since creation
+}
+
+// SearchAlert records that a newly published post matched a saved search.
+type SearchAlert struct {
+	ID        ID        `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	UserID    ID        `json:"-" bson:"user_id"`             // Owner of the saved search
+	SearchID  ID        `json:"search_id" bson:"search_id"`   // Saved search that matched
+	PostID    ID        `json:"post_id" bson:"post_id"`       // Matching post
+	Title     string    `json:"title" bson:"title"`           // Post title when it was published
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // When the post matched
+}
+
+// User is a registered account allowed to create and delete posts and
+// comments. The password hash never leaves the server.
+type User struct {
+	ID           ID           `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	Username     string       `json:"username" bson:"username"`     // Unique, lowercase login name
+	PasswordHash string       `json:"-" bson:"password_hash"`       // bcrypt hash of the password
+	Settings     UserSettings `json:"-" bson:"settings"`            // Preferences, served by GET /api/me/settings
+	CreatedAt    time.Time    `json:"created_at" bson:"created_at"` // Registration timestamp
+}
+
+// UserSettings are a user's preferences. The zero value keeps the server
+// defaults: public posts, no comment moderation, no notifications, and
+// the request's Accept-Language.
+type UserSettings struct {
+	DefaultVisibility    string   `json:"default_visibility" bson:"default_visibility"`       // Visibility of new posts that do not set one; empty means public
+	ModerateComments     bool     `json:"moderate_comments" bson:"moderate_comments"`         // Hold comments on the user's posts for review
+	NotificationChannels []string `json:"notification_channels" bson:"notification_channels"` // Integration drivers to be notified through, e.g. INTEGRATION_SLACK
+	Locale               string   `json:"locale" bson:"locale"`                               // Normalized language tag used when a request sends no Accept-Language
+	Timezone             string   `json:"timezone" bson:"timezone"`                           // IANA time zone of new posts and local publish_at times; empty means UTC
+}
+
+// API key scopes. Read keys may only make safe (GET, HEAD, OPTIONS)
+// requests; write keys may also mutate data, including on endpoints that
+// otherwise require a login JWT.
+const (
+	API_KEY_SCOPE_READ  = "read"
+	API_KEY_SCOPE_WRITE = "write"
+)
+
+// APIKey is a credential for bots and integrations, sent in the X-API-Key
+// header. Only a SHA-256 hash of the key is stored; the key itself is shown
+// once, when it is created.
+type APIKey struct {
+	ID        ID         `json:"id" bson:"_id,omitempty"`                          // Primary key (format set by storage.IDCodec)
+	Name      string     `json:"name" bson:"name"`                                 // What the key is for, e.g. "release bot"
+	Prefix    string     `json:"prefix" bson:"prefix"`                             // First characters of the key, to tell keys apart
+	KeyHash   string     `json:"-" bson:"key_hash"`                                // Hex SHA-256 of the key
+	Scope     string     `json:"scope" bson:"scope"`                               // API_KEY_SCOPE_READ or API_KEY_SCOPE_WRITE
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`                     // Creation timestamp
+	RevokedAt *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"` // When the key was revoked; unset while active
+}
+
+// Operations a content freeze blocks.
+const (
+	FREEZE_PUBLISH = "publish" // Making a post visible: creating, restoring, unarchiving, or un-privating it
+	FREEZE_DELETE  = "delete"  // Moving a post to the trash
+)
+
+// ContentFreeze is a scheduled window, e.g. during an audit, in which
+// posts cannot be published or deleted. Drafts (private posts) and
+// comments are unaffected.
+type ContentFreeze struct {
+	StartsAt  time.Time `json:"starts_at" bson:"starts_at"`               // When the freeze takes effect
+	EndsAt    time.Time `json:"ends_at" bson:"ends_at"`                   // When content may change again
+	Reason    string    `json:"reason,omitempty" bson:"reason,omitempty"` // Why content is frozen, shown in rejections
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`             // When the window was scheduled
+	Active    bool      `json:"active" bson:"-"`                          // Whether the freeze is in effect now
+}
+
+// ActiveAt reports whether the freeze is in effect at t.
+func (f ContentFreeze) ActiveAt(t time.Time) bool {
+	return !t.Before(f.StartsAt) && t.Before(f.EndsAt)
+}
+
+// Actions recorded in the audit log.
+const (
+	AUDIT_FREEZE_SCHEDULED = "freeze.scheduled" // A content freeze was scheduled or changed
+	AUDIT_FREEZE_LIFTED    = "freeze.lifted"    // A content freeze was removed
+	AUDIT_FREEZE_BLOCKED   = "freeze.blocked"   // A request was rejected by a content freeze
+	AUDIT_COMMENTS_BULK    = "comments.bulk"    // Comments were moderated in bulk
+)
+
+// AuditEntry is one event of the audit log, kept for administrators to
+// review who changed or attempted what.
+type AuditEntry struct {
+	ID        ID        `json:"id" bson:"_id,omitempty"`                  // Primary key (format set by storage.IDCodec)
+	Action    string    `json:"action" bson:"action"`                     // One of the AUDIT_* actions
+	Actor     string    `json:"actor" bson:"actor"`                       // User ID, or requester key when not logged in
+	Method    string    `json:"method" bson:"method"`                     // HTTP method of the request
+	Path      string    `json:"path" bson:"path"`                         // Request path
+	Detail    string    `json:"detail,omitempty" bson:"detail,omitempty"` // What happened, e.g. the operation blocked
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`             // When it happened
+}
+
+// Autosave is a draft snapshot of a post, saved by an editor while the
+// author types. Snapshots are kept apart from the post, so saving one
+// changes nothing readers see; saves in quick succession are merged into
+// one snapshot (see Handler.PutAutosave).
+type Autosave struct {
+	ID        ID        `json:"id" bson:"_id,omitempty"`      // Primary key (format set by storage.IDCodec)
+	PostID    ID        `json:"post_id" bson:"post_id"`       // Reference to the edited post
+	Title     string    `json:"title" bson:"title"`           // Title in the editor
+	Content   string    `json:"content" bson:"content"`       // Content in the editor
+	SavedBy   string    `json:"saved_by" bson:"saved_by"`     // Who saved last, identified like audit log actors
+	Saves     int       `json:"saves" bson:"saves"`           // Saves merged into the snapshot
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // First save of the snapshot
+	SavedAt   time.Time `json:"saved_at" bson:"saved_at"`     // Last save of the snapshot
+}
+
+// SiteFile is a plain-text file served at the site root, such as
+// robots.txt. Files are generated from configuration unless an
+// administrator stored custom content.
+type SiteFile struct {
+	Name      string     `json:"name" bson:"name"`                                 // File name, e.g. "robots.txt"
+	Content   string     `json:"content" bson:"content"`                           // Text served
+	Custom    bool       `json:"custom" bson:"-"`                                  // Whether Content was stored by an administrator
+	UpdatedAt *time.Time `json:"updated_at,omitempty" bson:"updated_at,omitempty"` // When custom content was stored
+}
+
+// CSPReport is a stored Content-Security-Policy violation report. Reports
+// are sampled on ingestion; Weight is the number of received reports this
+// one stands for, so summing it estimates the real count.
+type CSPReport struct {
+	ID                 ID        `json:"id" bson:"_id,omitempty"`                            // Primary key (format set by storage.IDCodec)
+	DocumentURI        string    `json:"document_uri" bson:"document_uri"`                   // Page where the violation happened
+	BlockedURI         string    `json:"blocked_uri" bson:"blocked_uri"`                     // Resource that was blocked
+	EffectiveDirective string    `json:"effective_directive" bson:"effective_directive"`     // Directive that was violated, e.g. "script-src-elem"
+	Disposition        string    `json:"disposition,omitempty" bson:"disposition,omitempty"` // "enforce" or "report"
+	SourceFile         string    `json:"source_file,omitempty" bson:"source_file,omitempty"` // Script that caused the violation
+	LineNumber         int       `json:"line_number,omitempty" bson:"line_number,omitempty"` // Line in SourceFile
+	Weight             float64   `json:"weight" bson:"weight"`                               // Received reports represented by this one
+	ReceivedAt         time.Time `json:"received_at" bson:"received_at"`                     // Ingestion time
+}
+
+// CSPViolationCount aggregates stored CSP reports by directive and
+// blocked resource.
+type CSPViolationCount struct {
+	EffectiveDirective string    `json:"effective_directive" bson:"effective_directive"` // Violated directive
+	BlockedURI         string    `json:"blocked_uri" bson:"blocked_uri"`                 // Blocked resource
+	Count              int64     `json:"count" bson:"count"`                             // Estimated number of reports
+	LastSeen           time.Time `json:"last_seen" bson:"last_seen"`                     // Most recent report
+}
+
+// CommentThreadSummary describes the discussion under a post, for
+// "discussion highlights" in clients. It counts the comments shown to
+// readers only; comments hidden by moderation are left out.
+type CommentThreadSummary struct {
+	PostID         ID              `json:"post_id"`                    // Post the comments belong to
+	Comments       int64           `json:"comments"`                   // Visible comments
+	TopLevel       int64           `json:"top_level"`                  // Comments not replying to another
+	Replies        int64           `json:"replies"`                    // Comments replying to another
+	Participants   int64           `json:"participants"`               // Distinct author names
+	FirstCommentAt *time.Time      `json:"first_comment_at,omitempty"` // Oldest comment, unset without comments
+	LastCommentAt  *time.Time      `json:"last_comment_at,omitempty"`  // Newest comment, unset without comments
+	BusiestHour    *CommentHour    `json:"busiest_hour,omitempty"`     // Clock hour with the most comments
+	MostReacted    *ReactedComment `json:"most_reacted,omitempty"`     // Comment with the most reactions, unset without reactions
+}
+
+// CommentHour is the number of comments posted within one clock hour.
+type CommentHour struct {
+	Start    time.Time `json:"start" bson:"_id"`         // Start of the hour (UTC)
+	Comments int64     `json:"comments" bson:"comments"` // Comments posted in the hour
+}
+
+// ReactedComment is a comment with the total of its reactions of all
+// types.
+type ReactedComment struct {
+	Comment       `bson:",inline"`
+	ReactionCount int64 `json:"reaction_count" bson:"reaction_count"` // Reactions of all types
+}
+
+// PostAnalytics is the traffic of one post over a range of days, read from
+// the view rollups. Days without views are included with zero views.
+type PostAnalytics struct {
+	PostID     ID              `json:"post_id"`     // Post the traffic belongs to
+	From       string          `json:"from"`        // First day of the range, "2006-01-02" (UTC)
+	To         string          `json:"to"`          // Last day of the range, inclusive
+	TotalViews int64           `json:"total_views"` // Views in the range
+	Daily      []DailyViews    `json:"daily"`       // One entry per day, oldest first
+	Referrers  []ReferrerViews `json:"referrers"`   // Top referring hosts, most views first
+}
+
+// DailyViews is the number of views of a post on one UTC day.
+type DailyViews struct {
+	Date  string `json:"date"`  // Day, "2006-01-02"
+	Views int64  `json:"views"` // Views that day
+}
+
+// ReferrerViews is the number of views arriving from one referring host.
+type ReferrerViews struct {
+	Referrer string `json:"referrer" bson:"_id"` // Referring host; "" for direct traffic
+	Views    int64  `json:"views" bson:"views"`  // Views in the range
+}
+
+// PostBrokenLinks lists the links of one post that failed the latest link
+// check. Stored in the broken_links collection, replaced on every check.
+type PostBrokenLinks struct {
+	PostID    ID           `json:"post_id" bson:"_id"`           // Post whose content holds the links
+	Title     string       `json:"title" bson:"title"`           // Title of the post
+	Links     []BrokenLink `json:"links" bson:"links"`           // Failing links, in content order
+	CheckedAt time.Time    `json:"checked_at" bson:"checked_at"` // When the check ran
+}
+
+// BrokenLink is a link that answered 404 or 410, or did not answer at all.
+type BrokenLink struct {
+	URL    string `json:"url" bson:"url"`                           // Link target as written in the content
+	Status int    `json:"status,omitempty" bson:"status,omitempty"` // HTTP status; 0 when there was no response
+	Error  string `json:"error,omitempty" bson:"error,omitempty"`   // "timeout" or the request error without a response
+}
+
+// PostCard is the rendered social-card image of a post, stored in the
+// post_cards collection. Title is the headline the image shows; a card
+// whose Title no longer matches the post is stale and rendered again.
+type PostCard struct {
+	PostID      ID        `bson:"_id"`          // Post the card belongs to
+	Title       string    `bson:"title"`        // Title drawn on the card
+	Author      string    `bson:"author"`       // Byline drawn on the card
+	PNG         []byte    `bson:"png"`          // PNG-encoded image
+	GeneratedAt time.Time `bson:"generated_at"` // When the image was rendered
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ID is the primary key of every stored entity, kept in its canonical
+// string form: 24 hex characters for ObjectIDs, or a hyphenated UUID.
+// Which format new documents get is decided by the storage IDCodec; ID only
+// takes care of storing both formats with their native BSON representation,
+// so existing ObjectID data keeps working and JSON output is unchanged.
+type ID string
+
+// String returns the canonical string form of the ID.
+func (id ID) String() string {
+	return string(id)
+}
+
+// IsZero reports whether the ID is unset, which omitempty relies on.
+func (id ID) IsZero() bool {
+	return id == ""
+}
+
+// MarshalBSONValue stores ObjectID-formatted IDs as BSON ObjectIDs and any
+// other ID (such as a UUID) as a string.
+func (id ID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if oid, err := primitive.ObjectIDFromHex(string(id)); err == nil {
+		return bson.MarshalValue(oid)
+	}
+	return bson.MarshalValue(string(id))
+}
+
+// UnmarshalBSONValue reads an ID stored as a BSON ObjectID or string.
+func (id *ID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.ObjectID:
+		*id = ID(value.ObjectID().Hex())
+	case bsontype.String:
+		*id = ID(value.StringValue())
+	case bsontype.Null, bsontype.Undefined:
+		*id = ""
+	default:
+		return fmt.Errorf("cannot decode BSON %s into models.ID", t)
+	}
+	return nil
+}
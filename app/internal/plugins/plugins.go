@@ -0,0 +1,185 @@
+// Package plugins provides an extension registry so downstream forks can
+// add behavior (custom validation, analytics, notifications, ...) without
+// modifying handlers. Plugins register themselves by name, usually from an
+// init function, and are enabled in order through configuration.
+//
+// A plugin implements Plugin plus any of the hook interfaces below; the
+// handlers call each hook point for every enabled plugin that implements it:
+//
+//  1. PreValidator - before request validation, may reject the request
+//  2. PostCreator  - after a resource was stored
+//  3. PreResponder - before the response is serialized, may replace the data
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// Resource names passed to hooks to tell which endpoint invoked them.
+const (
+	RESOURCE_POST        = "post"        // A single blog post (models.BlogPost)
+	RESOURCE_POST_LIST   = "post_list"   // The post listing ([]models.BlogPostSummary)
+	RESOURCE_POST_SEARCH = "post_search" // Search results ([]models.SearchResult)
+	RESOURCE_COMMENT     = "comment"     // A single comment (models.Comment)
+)
+
+// Plugin is the base interface every plugin implements.
+type Plugin interface {
+	// Name is the unique identifier used to enable the plugin in config.
+	Name() string
+}
+
+// PreValidator is implemented by plugins that inspect incoming write
+// requests before the built-in validation runs. Returning an error rejects
+// the request with 400 and the error message.
+type PreValidator interface {
+	PreValidate(c *fiber.Ctx, resource string, request any) error
+}
+
+// PostCreator is implemented by plugins that react to newly created
+// resources. It runs synchronously after the insert succeeded; plugins
+// doing slow work should hand it off to their own goroutine.
+type PostCreator interface {
+	PostCreate(ctx context.Context, resource string, created any)
+}
+
+// PreResponder is implemented by plugins that adjust response data.
+// The returned value replaces data for the next plugin and the client.
+type PreResponder interface {
+	PreResponse(c *fiber.Ctx, resource string, data any) any
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Plugin)
+)
+
+// Register makes a plugin available under its Name so it can be enabled
+// through configuration. It panics if the name is already taken, mirroring
+// database/sql driver registration.
+func Register(plugin Plugin) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := plugin.Name()
+	if _, exists := registry[name]; exists {
+		panic("plugins: Register called twice for plugin " + name)
+	}
+	registry[name] = plugin
+}
+
+// Available returns the names of all registered plugins, sorted.
+func Available() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chain is the ordered list of enabled plugins that handlers invoke.
+// The zero value and a nil *Chain are valid and run no hooks.
+type Chain struct {
+	plugins []Plugin
+}
+
+// Enable builds a Chain from registered plugin names, preserving order.
+// Unknown names are skipped and reported in the returned error, so a typo
+// in config never disables the plugins that do exist.
+//
+// Parameters:
+//   - names: plugin names in the order hooks should run
+//
+// Returns the chain and an error listing unknown plugin names, if any.
+func Enable(names []string) (*Chain, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	chain := &Chain{}
+	var unknown []string
+	for _, name := range names {
+		plugin, ok := registry[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		chain.plugins = append(chain.plugins, plugin)
+	}
+	if len(unknown) > 0 {
+		return chain, fmt.Errorf("unknown plugins: %v", unknown)
+	}
+	return chain, nil
+}
+
+// Names returns the names of the enabled plugins in hook order.
+func (ch *Chain) Names() []string {
+	if ch == nil {
+		return nil
+	}
+	names := make([]string, len(ch.plugins))
+	for i, plugin := range ch.plugins {
+		names[i] = plugin.Name()
+	}
+	return names
+}
+
+// PreValidate runs every PreValidator in order and stops at the first error.
+func (ch *Chain) PreValidate(c *fiber.Ctx, resource string, request any) error {
+	if ch == nil {
+		return nil
+	}
+	for _, plugin := range ch.plugins {
+		if hook, ok := plugin.(PreValidator); ok {
+			if err := hook.PreValidate(c, resource, request); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PostCreate runs every PostCreator in order. A panicking plugin is
+// recovered and logged so it cannot fail a write that already succeeded.
+func (ch *Chain) PostCreate(ctx context.Context, resource string, created any) {
+	if ch == nil {
+		return
+	}
+	for _, plugin := range ch.plugins {
+		if hook, ok := plugin.(PostCreator); ok {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						logger.Error("plugin post-create hook panicked",
+							zap.String("plugin", plugin.Name()), zap.Any("panic", r))
+					}
+				}()
+				hook.PostCreate(ctx, resource, created)
+			}()
+		}
+	}
+}
+
+// PreResponse threads data through every PreResponder in order and
+// returns the final value to send.
+func (ch *Chain) PreResponse(c *fiber.Ctx, resource string, data any) any {
+	if ch == nil {
+		return data
+	}
+	for _, plugin := range ch.plugins {
+		if hook, ok := plugin.(PreResponder); ok {
+			data = hook.PreResponse(c, resource, data)
+		}
+	}
+	return data
+}
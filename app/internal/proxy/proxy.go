@@ -0,0 +1,106 @@
+// Package proxy derives the real client IP of requests that pass through
+// reverse proxies. Forwarding headers are only honored when the connection
+// comes from a configured trusted proxy, since any client can send them.
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Resolver resolves client IPs using a set of trusted proxy networks.
+// A nil or empty Resolver trusts no proxy and always returns the peer IP.
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// NewResolver builds a resolver trusting the given proxies. Entries are
+// CIDRs ("10.0.0.0/8") or single addresses ("192.168.1.10"). Invalid
+// entries are skipped and reported in the returned error; the resolver is
+// still usable with the valid ones, mirroring plugins.Enable.
+//
+// Parameters:
+//   - trusted: CIDRs or IPs of the reverse proxies in front of the API
+func NewResolver(trusted []string) (*Resolver, error) {
+	resolver := &Resolver{}
+	var invalid []string
+	for _, entry := range trusted {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				invalid = append(invalid, entry)
+				continue
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			resolver.trusted = append(resolver.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	if len(invalid) > 0 {
+		return resolver, fmt.Errorf("invalid trusted proxies: %v", invalid)
+	}
+	return resolver, nil
+}
+
+// Trusted reports whether ip belongs to a trusted proxy network.
+func (r *Resolver) Trusted(ip net.IP) bool {
+	if r == nil || ip == nil {
+		return false
+	}
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client that originated the request.
+//
+// When the peer is a trusted proxy, X-Forwarded-For is walked from right to
+// left and the first hop that is not itself a trusted proxy is the client;
+// hops further left were supplied by the client and cannot be trusted.
+// Without X-Forwarded-For, X-Real-IP is used. Otherwise, or when the peer
+// is not trusted, the peer IP is returned.
+//
+// Parameters:
+//   - peer: IP of the TCP connection's remote end
+//   - forwardedFor: value of the X-Forwarded-For header (may be empty)
+//   - realIP: value of the X-Real-IP header (may be empty)
+func (r *Resolver) ClientIP(peer, forwardedFor, realIP string) string {
+	if !r.Trusted(net.ParseIP(peer)) {
+		return peer
+	}
+
+	if forwardedFor != "" {
+		hops := strings.Split(forwardedFor, ",")
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				// A malformed hop ends the trustworthy part of the chain
+				break
+			}
+			client = ip.String()
+			if !r.Trusted(ip) {
+				break
+			}
+		}
+		return client
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(realIP)); ip != nil {
+		return ip.String()
+	}
+	return peer
+}
@@ -0,0 +1,88 @@
+// Package ratelimit counts requests per client in fixed time windows, so
+// the API can cap how often one IP creates posts, comments, or reads. A
+// window's count is kept in a Store: in process memory by default, or in
+// Redis so every instance behind a load balancer shares the same counts.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Policy caps the requests one client makes per window.
+type Policy struct {
+	Name    string        // Counter namespace, e.g. "posts"
+	Limit   int           // Requests allowed per window; 0 disables the policy
+	Window  time.Duration // Length of a counting window
+	Methods []string      // HTTP methods counted; empty counts every method
+}
+
+// Counts reports whether requests with method are counted by the policy.
+func (p Policy) Counts(method string) bool {
+	if len(p.Methods) == 0 {
+		return true
+	}
+	for _, counted := range p.Methods {
+		if counted == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps request counts per key and window.
+type Store interface {
+	// Hit counts one request for key and returns the number of requests
+	// counted in the current window, this one included, along with the
+	// time left until the window resets. The first hit opens a window of
+	// the given length.
+	Hit(ctx context.Context, key string, window time.Duration) (count int64, reset time.Duration, err error)
+}
+
+// SWEEP_INTERVAL is how often Memory drops the counters of closed windows.
+const SWEEP_INTERVAL = time.Minute
+
+// Memory is a Store local to the process. Counts are not shared between
+// instances and are lost on restart.
+type Memory struct {
+	mu        sync.Mutex
+	windows   map[string]*memoryWindow
+	nextSweep time.Time
+}
+
+// memoryWindow is the count of one key in the current window.
+type memoryWindow struct {
+	count int64
+	ends  time.Time
+}
+
+// NewMemory creates an empty in-memory Store.
+func NewMemory() *Memory {
+	return &Memory{windows: make(map[string]*memoryWindow)}
+}
+
+// Hit implements Store.
+func (m *Memory) Hit(_ context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.After(m.nextSweep) {
+		// Drop closed windows so one-off clients do not accumulate
+		for k, w := range m.windows {
+			if !now.Before(w.ends) {
+				delete(m.windows, k)
+			}
+		}
+		m.nextSweep = now.Add(SWEEP_INTERVAL)
+	}
+
+	w, ok := m.windows[key]
+	if !ok || !now.Before(w.ends) {
+		w = &memoryWindow{ends: now.Add(window)}
+		m.windows[key] = w
+	}
+	w.count++
+	return w.count, w.ends.Sub(now), nil
+}
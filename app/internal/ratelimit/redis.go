@@ -0,0 +1,261 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// REDIS_TIMEOUT bounds a Redis round trip when the caller's context has no
+// earlier deadline, so a stalled server cannot hold requests.
+const REDIS_TIMEOUT = time.Second
+
+// REDIS_IDLE_CONNS is how many idle connections Redis keeps for reuse.
+const REDIS_IDLE_CONNS = 8
+
+// hitScript increments a counter, opening its window on the first hit, and
+// returns the count with the milliseconds left in the window. A counter
+// left without expiry, e.g. by a failed PEXPIRE, is given one again.
+const hitScript = `local count = redis.call('INCR', KEYS[1])
+if count == 1 then redis.call('PEXPIRE', KEYS[1], ARGV[1]) end
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[1])
+  ttl = tonumber(ARGV[1])
+end
+return {count, ttl}`
+
+// Redis is a Store shared by every instance using the same Redis server.
+// It speaks just enough of the Redis protocol to run one script, so no
+// client library is needed.
+type Redis struct {
+	addr     string
+	username string
+	password string
+	db       int
+	tls      *tls.Config
+	idle     chan *redisConn
+}
+
+// redisConn is one connection to the Redis server.
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedis creates a Redis Store from a URL such as
+// "redis://:password@localhost:6379/0"; "rediss://" connects with TLS.
+// Connections are opened on first use, so an unreachable server surfaces
+// as errors from Hit.
+//
+// Parameters:
+//   - rawURL: Redis server URL with optional credentials and database number
+//
+// Returns the store, or an error when the URL is malformed.
+func NewRedis(rawURL string) (*Redis, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "redis" && parsed.Scheme != "rediss" || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("expected a redis:// or rediss:// URL, got %q", rawURL)
+	}
+
+	r := &Redis{
+		addr: parsed.Host,
+		idle: make(chan *redisConn, REDIS_IDLE_CONNS),
+	}
+	if parsed.Port() == "" {
+		r.addr = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if parsed.User != nil {
+		r.username = parsed.User.Username()
+		r.password, _ = parsed.User.Password()
+	}
+	if path := strings.Trim(parsed.Path, "/"); path != "" {
+		if r.db, err = strconv.Atoi(path); err != nil || r.db < 0 {
+			return nil, fmt.Errorf("invalid Redis database %q", path)
+		}
+	}
+	if parsed.Scheme == "rediss" {
+		r.tls = &tls.Config{ServerName: parsed.Hostname()}
+	}
+	return r, nil
+}
+
+// Hit implements Store.
+func (r *Redis) Hit(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	reply, err := r.do(ctx, "EVAL", hitScript, "1", key, strconv.FormatInt(window.Milliseconds(), 10))
+	if err != nil {
+		return 0, 0, err
+	}
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return 0, 0, fmt.Errorf("unexpected Redis reply %v", reply)
+	}
+	count, ok := values[0].(int64)
+	ttl, ok2 := values[1].(int64)
+	if !ok || !ok2 {
+		return 0, 0, fmt.Errorf("unexpected Redis reply %v", reply)
+	}
+	return count, time.Duration(ttl) * time.Millisecond, nil
+}
+
+// Ping checks that the server answers, authenticating as Hit would.
+func (r *Redis) Ping(ctx context.Context) error {
+	_, err := r.do(ctx, "PING")
+	return err
+}
+
+// Close closes the idle connections.
+func (r *Redis) Close() error {
+	for {
+		select {
+		case conn := <-r.idle:
+			conn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends one command and reads its reply on a pooled connection. The
+// connection is discarded after any error, since the rest of a reply may
+// still be unread.
+func (r *Redis) do(ctx context.Context, args ...string) (any, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, REDIS_TIMEOUT)
+		defer cancel()
+	}
+
+	var conn *redisConn
+	select {
+	case conn = <-r.idle:
+	default:
+		var err error
+		if conn, err = r.dial(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := conn.do(ctx, args...)
+	if err != nil {
+		conn.conn.Close()
+		return nil, err
+	}
+	select {
+	case r.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+	return reply, nil
+}
+
+// dial opens a connection, authenticates, and selects the database.
+func (r *Redis) dial(ctx context.Context) (*redisConn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, err
+	}
+	if r.tls != nil {
+		conn = tls.Client(conn, r.tls)
+	}
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	var setup [][]string
+	switch {
+	case r.username != "":
+		setup = append(setup, []string{"AUTH", r.username, r.password})
+	case r.password != "":
+		setup = append(setup, []string{"AUTH", r.password})
+	}
+	if r.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	for _, args := range setup {
+		if _, err := c.do(ctx, args...); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis %s: %w", args[0], err)
+		}
+	}
+	return c, nil
+}
+
+// redisError is an error reply of the server.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// do writes args as a command and reads the reply.
+func (c *redisConn) do(ctx context.Context, args ...string) (any, error) {
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, cmd.String()); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply reads one reply: a simple string, error, integer, bulk string,
+// or array of those. A nil bulk string or array is returned as nil.
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch kind, rest := line[0], line[1:]; kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, redisError(rest)
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(rest)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(rest)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		values := make([]any, size)
+		for i := range values {
+			if values[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
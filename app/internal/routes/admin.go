@@ -0,0 +1,74 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+)
+
+// adminModule configures operational endpoints for site administrators.
+// Every route requires the login JWT of a user listed in ADMIN_USERS (see
+// middleware.RequireAdmin); API keys are refused.
+//
+// Endpoints configured:
+//   - GET    /api/admin/api-keys               - API keys, active and revoked
+//   - POST   /api/admin/api-keys               - Create a read or write API key
+//   - DELETE /api/admin/api-keys/:id           - Revoke an API key
+//   - GET    /api/admin/audit                  - Audit log, newest first
+//   - GET    /api/admin/broken-links           - Posts linking to missing or unreachable pages
+//   - POST   /api/admin/comments/bulk          - Approve, reject, delete, or mark comments as spam
+//   - GET    /api/admin/duplicates             - Near-duplicate post pairs from the last scan
+//   - POST   /api/admin/duplicates/scan        - Run a near-duplicate scan immediately
+//   - GET    /api/admin/freeze                 - Scheduled content freeze
+//   - PUT    /api/admin/freeze                 - Schedule a content freeze window
+//   - DELETE /api/admin/freeze                 - Lift the content freeze
+//   - GET    /api/admin/integrations           - Slack and Discord integrations
+//   - POST   /api/admin/integrations           - Connect a chat channel
+//   - DELETE /api/admin/integrations/:id       - Disconnect a chat channel
+//   - POST   /api/admin/integrations/:id/test  - Send a test message
+//   - GET    /api/admin/locks                  - Background job leases and lock counters
+//   - GET    /api/admin/posts/:id/analytics    - Daily views and top referrers of a post
+//   - GET    /api/admin/posts/:id/title-test   - Impressions and clicks per headline variant
+//   - GET    /api/admin/read-dedup             - Read deduplication counters
+//   - GET    /api/admin/site-files/:name       - robots.txt or humans.txt content
+//   - PUT    /api/admin/site-files/:name       - Replace a site file with custom content
+//   - DELETE /api/admin/site-files/:name       - Restore the generated site file
+//   - GET    /api/admin/storage-metrics        - Database commands per collection and operation
+//   - GET    /api/admin/stats                  - Site-wide writing statistics and CSP violations
+//   - GET    /api/admin/routes                 - List all registered routes
+var adminModule = Module{
+	Name:     "admin",
+	Prefix:   "/admin",
+	Register: registerAdmin,
+}
+
+// registerAdmin registers the admin module routes on router.
+func registerAdmin(router fiber.Router, h *handlers.Handler) {
+	router.Use(middleware.RequireAdmin(h.Auth, h.Config.AdminUsers))
+
+	router.Get("/api-keys", h.GetAPIKeys)                    // API keys
+	router.Post("/api-keys", h.CreateAPIKey)                 // Create an API key
+	router.Delete("/api-keys/:id", h.RevokeAPIKey)           // Revoke an API key
+	router.Get("/audit", h.GetAuditLog)                      // Audit log
+	router.Get("/broken-links", h.GetBrokenLinks)            // Broken links per post
+	router.Post("/comments/bulk", h.BulkModerateComments)    // Moderate comments in bulk
+	router.Get("/duplicates", h.GetDuplicates)               // Near-duplicate post pairs
+	router.Post("/duplicates/scan", h.ScanDuplicates)        // Run a duplicate scan now
+	router.Get("/freeze", h.GetContentFreeze)                // Scheduled content freeze
+	router.Put("/freeze", h.PutContentFreeze)                // Schedule a freeze window
+	router.Delete("/freeze", h.DeleteContentFreeze)          // Lift the freeze
+	router.Get("/integrations", h.GetIntegrations)           // Chat integrations
+	router.Post("/integrations", h.CreateIntegration)        // Connect a channel
+	router.Delete("/integrations/:id", h.DeleteIntegration)  // Disconnect a channel
+	router.Post("/integrations/:id/test", h.TestIntegration) // Send a test message
+	router.Get("/locks", h.GetLocks)                         // Job leases and lock counters
+	router.Get("/posts/:id/analytics", h.GetPostAnalytics)   // Post traffic time series
+	router.Get("/posts/:id/title-test", h.GetTitleTest)      // Title A/B test results
+	router.Get("/read-dedup", h.GetReadStats)                // Read deduplication counters
+	router.Get("/site-files/:name", h.GetSiteFile)           // Site file content
+	router.Put("/site-files/:name", h.PutSiteFile)           // Custom site file
+	router.Delete("/site-files/:name", h.DeleteSiteFile)     // Restore generated file
+	router.Get("/storage-metrics", h.GetStorageMetrics)      // Database commands per collection
+	router.Get("/stats", h.GetStats)                         // Writing statistics and CSP violations
+	router.Get("/routes", listRoutes)                        // Route introspection
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+)
+
+// authModule configures user accounts. The JWT returned by both endpoints
+// unlocks the write endpoints protected with middleware.RequireAuth.
+//
+// Endpoints configured:
+//   - POST /api/auth/register - Create an account and receive a JWT
+//   - POST /api/auth/login    - Exchange username and password for a JWT
+var authModule = Module{
+	Name:   "auth",
+	Prefix: "/auth",
+	Register: func(router fiber.Router, h *handlers.Handler) {
+		router.Post("/register", h.Register) // Create an account
+		router.Post("/login", h.Login)       // Log in
+	},
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+)
+
+// batchModule configures request batching. Sub-requests carry the batch
+// request's credentials, so each is authorized by its own route.
+//
+// Endpoints configured:
+//   - POST /api/batch - Run several API requests in one round trip
+var batchModule = Module{
+	Name:     "batch",
+	Prefix:   "/batch",
+	Register: registerBatch,
+}
+
+// registerBatch registers the batch module routes on router.
+func registerBatch(router fiber.Router, h *handlers.Handler) {
+	router.Post("", h.Batch) // Run a batch of requests
+}
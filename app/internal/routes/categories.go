@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+)
+
+// categoriesModule configures post categories. Posts reference a category
+// by category_id and are listed per category by GET /api/posts?category_id=.
+//
+// Endpoints configured:
+//   - GET    /api/categories     - List categories by name
+//   - GET    /api/categories/:id - Get a category
+//   - POST   /api/categories     - Create a category (JWT required)
+//   - PUT    /api/categories/:id - Rename or redescribe a category (JWT required)
+//   - DELETE /api/categories/:id - Delete an unused category (JWT required)
+var categoriesModule = Module{
+	Name:     "categories",
+	Prefix:   "/categories",
+	Register: registerCategories,
+}
+
+// registerCategories registers the categories module routes on router.
+func registerCategories(router fiber.Router, h *handlers.Handler) {
+	requireAuth := middleware.RequireAuth(h.Auth)
+
+	router.Get("", h.GetCategories)                      // List categories
+	router.Get("/:id", h.GetCategory)                    // Get a category
+	router.Post("", requireAuth, h.CreateCategory)       // Create a category
+	router.Put("/:id", requireAuth, h.UpdateCategory)    // Replace a category
+	router.Delete("/:id", requireAuth, h.DeleteCategory) // Delete an unused category
+}
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+)
+
+// commentsModule configures comment endpoints. Comments are created under
+// their post's path, so the module is mounted at the API root and declares
+// no middleware of its own (see Module).
+//
+// Endpoints configured:
+//   - POST   /api/posts/:id/comments - Add comment to a specific post (JWT required, rate limited)
+//   - POST   /api/posts/:id/comments/import - Import historical comments into a post (admin only)
+//   - GET    /api/posts/:id/comments - Paged comments of a post with the total count
+//   - GET    /api/posts/:id/comments/summary - Discussion statistics of a post
+//   - GET    /api/comments?post_ids= - Comments of several posts grouped by post
+//   - GET    /api/comments/:id       - Single comment with full content
+//   - PUT    /api/comments/:id       - Edit a comment's content (JWT required)
+//   - DELETE /api/comments/:id       - Move a comment to the trash (JWT required)
+//   - POST   /api/comments/:id/restore - Restore a trashed comment (JWT required)
+//   - POST   /api/comments/:id/react - React to a comment (one reaction per requester)
+//   - DELETE /api/comments/:id/react - Remove the requester's reaction
+var commentsModule = Module{
+	Name:     "comments",
+	Register: registerComments,
+}
+
+// registerComments registers the comments module routes on router.
+func registerComments(router fiber.Router, h *handlers.Handler) {
+	requireAuth := middleware.RequireAuth(h.Auth)
+	requireAdmin := middleware.RequireAdmin(h.Auth, h.Config.AdminUsers)
+	commentLimit := rateLimit(h, "comments", h.Config.RateLimitComments)
+
+	router.Post("/posts/:id/comments", commentLimit, requireAuth, h.CreateComment) // Add comment to post
+	router.Post("/posts/:id/comments/import", requireAdmin, h.ImportComments)      // Import historical comments
+	router.Get("/posts/:id/comments", h.GetPostComments)                           // Paged comments of a post
+	router.Get("/posts/:id/comments/summary", h.GetCommentSummary)                 // Discussion statistics of a post
+	router.Get("/comments", h.GetCommentsBatch)                                    // Comments of several posts, grouped by post
+	router.Get("/comments/:id", h.GetComment)                                      // Single comment with full content
+	router.Put("/comments/:id", requireAuth, h.UpdateComment)                      // Edit a comment
+	router.Delete("/comments/:id", requireAuth, h.DeleteComment)                   // Trash a comment
+	router.Post("/comments/:id/restore", requireAuth, h.RestoreComment)            // Restore a trashed comment
+	router.Post("/comments/:id/react", h.ReactToComment)                           // React to a comment
+	router.Delete("/comments/:id/react", h.UnreactToComment)                       // Remove the requester's reaction
+}
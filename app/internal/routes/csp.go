@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+)
+
+// cspModule receives Content-Security-Policy violation reports.
+//
+// Endpoints configured:
+//   - POST /api/csp-report - Ingest browser CSP violation reports
+var cspModule = Module{
+	Name:   "csp",
+	Prefix: "/csp-report",
+	Register: func(router fiber.Router, h *handlers.Handler) {
+		router.Post("/", h.CSPReport) // CSP violation reports
+	},
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+)
+
+// embedModule configures the embeddable comments widget.
+// The widget page is framed by third-party sites and its JSON endpoints are
+// called cross-origin from that page, so they allow any origin.
+//
+// Endpoints configured:
+//   - GET  /embed/comments/:postId              - iframe-ready comments page
+//   - GET  /embed/api/posts/:id/comments        - Comments of a post (CORS *)
+//   - POST /embed/api/posts/:id/comments        - Add a comment (CORS *, JSON or form body, embed token, rate limited)
+var embedModule = Module{
+	Name:     "embed",
+	Register: registerEmbed,
+}
+
+// registerEmbed registers the embed module routes on router.
+func registerEmbed(router fiber.Router, h *handlers.Handler) {
+	embedGroup := router.Group("/embed")
+	embedGroup.Get("/comments/:postId", h.EmbedComments)
+
+	// Supporting JSON endpoints with permissive CORS for cross-origin widgets
+	// The widget posts form-encoded bodies, which need no CORS preflight
+	embedAPI := router.Group(handlers.EMBED_API_BASE, cors.New(cors.Config{
+		AllowOrigins: "*",
+		AllowMethods: "GET,POST,OPTIONS",
+		AllowHeaders: "Content-Type",
+	}), middleware.JSONBody(true))
+	// Widget comments need no login, so they take the embed token of the
+	// widget page instead, and count against the same limit as API
+	// comments, so the embed endpoint is no way around it. Requests with
+	// bad tokens are counted too
+	commentLimit := rateLimit(h, "comments", h.Config.RateLimitComments)
+	embedAPI.Get("/posts/:id/comments", h.GetPostComments)
+	embedAPI.Post("/posts/:id/comments", commentLimit, h.RequireEmbedToken, h.CreateComment)
+}
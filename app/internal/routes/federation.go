@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+)
+
+// federationModule configures the blog's ActivityPub actor. Every endpoint
+// answers 404 unless FEDERATION_BASE_URL is set. Bodies are ActivityPub
+// JSON, so the module stays outside /api and its JSON body check.
+//
+// Endpoints configured:
+//   - GET  /.well-known/webfinger - Resolve "@username@host" to the actor
+//   - GET  /ap/actor              - Actor document with public key
+//   - GET  /ap/outbox             - Newest federated posts as Create activities
+//   - GET  /ap/followers          - Follower count
+//   - GET  /ap/posts/:id          - A post as an Article
+//   - POST /ap/inbox              - Signed activities from remote servers
+var federationModule = Module{
+	Name:     "federation",
+	Register: registerFederation,
+}
+
+// registerFederation registers the federation module routes on router.
+func registerFederation(router fiber.Router, h *handlers.Handler) {
+	router.Get("/.well-known/webfinger", h.WebFinger)
+
+	ap := router.Group("/ap")
+	ap.Get("/actor", h.GetActor)         // Actor document
+	ap.Get("/outbox", h.GetOutbox)       // Published posts
+	ap.Get("/followers", h.GetFollowers) // Follower count
+	ap.Get("/posts/:id", h.GetArticle)   // Post as an Article
+	ap.Post("/inbox", h.PostInbox)       // Follows and replies
+}
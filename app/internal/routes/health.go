@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+)
+
+// healthModule configures the probes used by orchestrators such as
+// Kubernetes and Docker Compose. They live outside /api so they skip the
+// API middleware and never need credentials.
+//
+// Endpoints configured:
+//   - GET /healthz - Liveness, answers while the process serves HTTP
+//   - GET /readyz  - Readiness, checks MongoDB and every collection
+var healthModule = Module{
+	Name: "health",
+	Register: func(router fiber.Router, h *handlers.Handler) {
+		router.Get("/healthz", h.Healthz) // Liveness probe
+		router.Get("/readyz", h.Readyz)   // Readiness probe
+	},
+}
@@ -0,0 +1,165 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+)
+
+// RouteInfo describes one registered endpoint for API surface audits.
+type RouteInfo struct {
+	Method     string   `json:"method"`     // HTTP method (GET, POST, ...)
+	Path       string   `json:"path"`       // Route pattern, e.g. /api/posts/:id
+	Middleware []string `json:"middleware"` // Middleware run before the handler, in order
+	Handler    string   `json:"handler"`    // Final handler function name
+	Auth       string   `json:"auth"`       // Authentication requirement
+}
+
+// AUTH_PUBLIC is reported for routes without any authentication middleware.
+const AUTH_PUBLIC = "public"
+
+// AUTH_JWT is reported for routes requiring a login JWT.
+const AUTH_JWT = "jwt"
+
+// AUTH_ADMIN is reported for routes requiring the login JWT of a site
+// administrator.
+const AUTH_ADMIN = "admin"
+
+// AUTH_EMBED is reported for routes requiring the embed token served in the
+// comments widget page (see handlers.RequireEmbedToken).
+const AUTH_EMBED = "embed"
+
+// authMiddleware maps the names of the functions returning middleware to
+// the authentication requirement it enforces. Middleware that protects
+// routes registers its name here so introspection reports it.
+var authMiddleware = map[string]string{
+	"middleware.RequireAuth":  AUTH_JWT,
+	"middleware.RequireAdmin": AUTH_ADMIN,
+
+	"handlers.(*Handler).RequireEmbedToken": AUTH_EMBED,
+}
+
+// Describe lists every route registered on app with its middleware chain,
+// generated from the Fiber router itself so it can never drift from the
+// real API surface. Group middleware (registered with Use) is attributed to
+// every route under its prefix. Automatically added HEAD routes are omitted.
+//
+// Parameters:
+//   - app: the configured Fiber application
+//
+// Returns route descriptions in registration order, grouped by method.
+func Describe(app *fiber.App) []RouteInfo {
+	// Use routes only appear in the unfiltered listing
+	endpoints := make(map[string]bool)
+	for _, route := range app.GetRoutes(true) {
+		endpoints[routeKey(route)] = true
+	}
+
+	var infos []RouteInfo
+	var middleware []fiber.Route
+	lastMethod := ""
+	for _, route := range app.GetRoutes() {
+		// Routes are grouped per method stack; middleware is per stack too
+		if route.Method != lastMethod {
+			lastMethod = route.Method
+			middleware = nil
+		}
+		if !endpoints[routeKey(route)] {
+			middleware = append(middleware, route)
+			continue
+		}
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+
+		chain := []string{}
+		for _, use := range middleware {
+			if prefixMatches(use.Path, route.Path) {
+				for _, handler := range use.Handlers {
+					chain = append(chain, funcName(handler))
+				}
+			}
+		}
+		for _, handler := range route.Handlers[:len(route.Handlers)-1] {
+			chain = append(chain, funcName(handler))
+		}
+
+		infos = append(infos, RouteInfo{
+			Method:     route.Method,
+			Path:       route.Path,
+			Middleware: chain,
+			Handler:    funcName(route.Handlers[len(route.Handlers)-1]),
+			Auth:       authRequirement(chain),
+		})
+	}
+	return infos
+}
+
+// listRoutes handles GET /api/admin/routes requests.
+// Returns every route of the running app, as described by Describe.
+//
+// Response format:
+//   - 200: Success with array of RouteInfo objects
+func listRoutes(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(models.APIResponse{Success: true, Data: Describe(c.App())})
+}
+
+// authRequirement returns the strongest known requirement in the chain.
+func authRequirement(chain []string) string {
+	requirement := AUTH_PUBLIC
+	for _, name := range chain {
+		if value, ok := authMiddleware[funcOwner(name)]; ok {
+			requirement = value
+		}
+	}
+	return requirement
+}
+
+// routeKey identifies a route by method, path, and handler addresses.
+func routeKey(route fiber.Route) string {
+	var key strings.Builder
+	key.WriteString(route.Method + " " + route.Path)
+	for _, handler := range route.Handlers {
+		fmt.Fprintf(&key, " %x", reflect.ValueOf(handler).Pointer())
+	}
+	return key.String()
+}
+
+// prefixMatches reports whether middleware mounted at prefix runs for path.
+func prefixMatches(prefix, path string) bool {
+	return prefix == "/" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// funcName returns a readable name for a handler, e.g.
+// "handlers.(*Handler).GetPosts" or "cors.New.func1".
+func funcName(handler fiber.Handler) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	return name
+}
+
+// funcOwner strips the closure suffixes from a function name, e.g.
+// "middleware.RequireAuth.func1" to "middleware.RequireAuth". Closures
+// inlined into other function literals are numbered without the "func"
+// prefix, e.g. "middleware.RequireAuth.1".
+func funcOwner(name string) string {
+	for {
+		dot := strings.LastIndex(name, ".")
+		suffix := strings.TrimPrefix(name[dot+1:], "func")
+		if dot < 0 || suffix == "" || strings.Trim(suffix, "0123456789") != "" {
+			return name
+		}
+		name = name[:dot]
+	}
+}
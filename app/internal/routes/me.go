@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+)
+
+// meModule configures the endpoints of the logged-in user. Every route
+// requires a login JWT; API keys carry no user and are refused.
+//
+// Endpoints configured:
+//   - GET /api/me/settings - Get the user's preferences
+//   - PUT /api/me/settings - Replace the user's preferences
+//   - GET /api/me/searches - List the user's saved searches
+//   - POST /api/me/searches - Save a search to be alerted about
+//   - DELETE /api/me/searches/:id - Delete a saved search and its alerts
+//   - GET /api/me/alerts - Posts that matched the user's saved searches
+var meModule = Module{
+	Name:   "me",
+	Prefix: "/me",
+	Register: func(router fiber.Router, h *handlers.Handler) {
+		requireAuth := middleware.RequireAuth(h.Auth)
+
+		router.Get("/settings", requireAuth, h.GetSettings)    // Get preferences
+		router.Put("/settings", requireAuth, h.UpdateSettings) // Replace preferences
+
+		router.Get("/searches", requireAuth, h.GetSavedSearches)         // List saved searches
+		router.Post("/searches", requireAuth, h.CreateSavedSearch)       // Save a search
+		router.Delete("/searches/:id", requireAuth, h.DeleteSavedSearch) // Delete a saved search
+		router.Get("/alerts", requireAuth, h.GetSearchAlerts)            // Saved search matches
+	},
+}
@@ -0,0 +1,303 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/card"
+	"github.com/pedrobertao/challenge-prosi/app/internal/federation"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/schema"
+)
+
+// OPENAPI_VERSION is the version of generated OpenAPI documents. 3.1
+// schemas are JSON Schema 2020-12, the dialect of the schema package.
+const OPENAPI_VERSION = "3.1.0"
+
+// API_TITLE and API_VERSION describe the API in generated documents.
+const (
+	API_TITLE   = "Blog API"
+	API_VERSION = "1.0.0"
+)
+
+// DOCS_MODULE names the module serving the API description. It is mounted
+// after apiModules by Setup, since OpenAPI reads the other modules.
+const DOCS_MODULE = "docs"
+
+// docsModule publishes a machine-readable description of the API and a
+// browser UI to explore it.
+//
+// Endpoints configured:
+//   - GET /api/openapi.json - OpenAPI 3.1 document of every route
+//   - GET /api/docs         - Swagger UI for the OpenAPI document
+var docsModule = Module{
+	Name: DOCS_MODULE,
+	Register: func(router fiber.Router, h *handlers.Handler) {
+		router.Get("/openapi.json", serveOpenAPI(h)) // OpenAPI document
+		router.Get("/docs", h.SwaggerUI)             // Swagger UI
+	},
+}
+
+// requestBodies maps handler names to the request DTO their body is
+// decoded into. Handlers reading a JSON body register it here so the
+// OpenAPI document describes it.
+var requestBodies = map[string]any{
+	"handlers.(*Handler).AcceptAssist":         models.AcceptAssistRequest{},
+	"handlers.(*Handler).BulkModerateComments": models.BulkModerationRequest{},
+	"handlers.(*Handler).CreateAPIKey":         models.CreateAPIKeyRequest{},
+	"handlers.(*Handler).CreateCategory":       models.CategoryRequest{},
+	"handlers.(*Handler).CreateComment":        models.CreateCommentRequest{},
+	"handlers.(*Handler).CreateIntegration":    models.CreateIntegrationRequest{},
+	"handlers.(*Handler).CreatePost":           models.CreatePostRequest{},
+	"handlers.(*Handler).CreateSavedSearch":    models.SavedSearchRequest{},
+	"handlers.(*Handler).ImportComments":       models.ImportCommentsRequest{},
+	"handlers.(*Handler).Login":                models.AuthRequest{},
+	"handlers.(*Handler).PutAutosave":          models.AutosaveRequest{},
+	"handlers.(*Handler).PutContentFreeze":     models.ContentFreezeRequest{},
+	"handlers.(*Handler).PutSiteFile":          models.SiteFileRequest{},
+	"handlers.(*Handler).ReactToComment":       models.ReactRequest{},
+	"handlers.(*Handler).ClapPost":             models.ClapRequest{},
+	"handlers.(*Handler).Register":             models.AuthRequest{},
+	"handlers.(*Handler).SetPassphrase":        models.PassphraseRequest{},
+	"handlers.(*Handler).SetVisibility":        models.SetVisibilityRequest{},
+	"handlers.(*Handler).UnlockPost":           models.PassphraseRequest{},
+	"handlers.(*Handler).UpdateCategory":       models.CategoryRequest{},
+	"handlers.(*Handler).UpdateComment":        models.UpdateCommentRequest{},
+	"handlers.(*Handler).UpdatePost":           models.UpdatePostRequest{},
+	"handlers.(*Handler).UpdateSettings":       models.UpdateSettingsRequest{},
+	"handlers.(*Handler).UpsertTranslation":    models.UpsertTranslationRequest{},
+}
+
+// rawResponses maps handler names to the media type of their successful
+// responses, for handlers that do not answer with a models.APIResponse.
+var rawResponses = map[string]string{
+	"handlers.(*Handler).EmbedComments": fiber.MIMETextHTMLCharsetUTF8,
+	"handlers.(*Handler).ExportPosts":   handlers.NDJSON_CONTENT_TYPE,
+	"handlers.(*Handler).GetActor":      federation.ACTIVITY_CONTENT_TYPE,
+	"handlers.(*Handler).GetArticle":    federation.ACTIVITY_CONTENT_TYPE,
+	"handlers.(*Handler).GetFollowers":  federation.ACTIVITY_CONTENT_TYPE,
+	"handlers.(*Handler).GetHumans":     fiber.MIMETextPlainCharsetUTF8,
+	"handlers.(*Handler).GetOutbox":     federation.ACTIVITY_CONTENT_TYPE,
+	"handlers.(*Handler).GetPostCard":   card.CONTENT_TYPE,
+	"handlers.(*Handler).GetRobots":     fiber.MIMETextPlainCharsetUTF8,
+	"handlers.(*Handler).GetSchema":     handlers.SCHEMA_CONTENT_TYPE,
+	"handlers.(*Handler).SwaggerUI":     fiber.MIMETextHTMLCharsetUTF8,
+	"handlers.(*Handler).WebFinger":     handlers.WEBFINGER_CONTENT_TYPE,
+	"routes.serveOpenAPI":               fiber.MIMEApplicationJSON,
+}
+
+// serveOpenAPI returns the handler of GET /api/openapi.json requests.
+// Returns the OpenAPI document of the running app, as built by OpenAPI.
+//
+// Response format:
+//   - 200: The OpenAPI document itself, not wrapped in an APIResponse
+func serveOpenAPI(h *handlers.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(http.StatusOK).JSON(OpenAPI(c.App(), h))
+	}
+}
+
+// OpenAPI builds an OpenAPI 3.1 document of every route registered on app,
+// generated from Describe so it can never drift from the real API surface.
+// Operations are named after their handlers and tagged with the module
+// that registers them. Request bodies are the JSON Schemas of the DTOs in
+// requestBodies, with the limits of h's configuration (see
+// handlers.Handler.RequestSchema). Routes behind authentication middleware
+// require a bearer JWT or an X-API-Key.
+//
+// Parameters:
+//   - app: the configured Fiber application
+//   - h: handler whose configuration fills in configurable limits
+//
+// Returns the document, ready to be encoded as JSON.
+func OpenAPI(app *fiber.App, h *handlers.Handler) schema.Schema {
+	tags := moduleTags(h)
+	components := schema.Schema{"APIResponse": componentSchema(models.APIResponse{})}
+	paths := schema.Schema{}
+	operationIDs := make(map[string]bool)
+
+	for _, route := range Describe(app) {
+		path, parameters := openAPIPath(route.Path)
+		tag := tags[route.Method+" "+route.Path]
+		if tag == "" {
+			tag = DOCS_MODULE
+		}
+
+		// Handlers serving several routes, such as the embed widget's,
+		// get the module's name appended from their second route on
+		handler := funcOwner(route.Handler)
+		name := handler[strings.LastIndex(handler, ".")+1:]
+		operationID := name
+		if operationIDs[operationID] {
+			operationID = name + "_" + tag
+		}
+		operationIDs[operationID] = true
+
+		operation := schema.Schema{
+			"operationId": operationID,
+			"summary":     humanize(name),
+			"tags":        []string{tag},
+			"responses":   operationResponses(route),
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if dto, ok := requestBodies[handler]; ok {
+			body := h.RequestSchema(dto)
+			delete(body, "$schema")
+			name := body["title"].(string)
+			components[name] = body
+			operation["requestBody"] = schema.Schema{
+				"required": true,
+				"content": schema.Schema{
+					fiber.MIMEApplicationJSON: schema.Schema{"schema": componentRef(name)},
+				},
+			}
+		}
+		switch route.Auth {
+		case AUTH_JWT:
+			operation["security"] = []schema.Schema{{"bearerAuth": []string{}}, {"apiKey": []string{}}}
+		case AUTH_ADMIN:
+			operation["security"] = []schema.Schema{{"bearerAuth": []string{}}}
+		case AUTH_EMBED:
+			operation["security"] = []schema.Schema{{"embedToken": []string{}}}
+		}
+
+		item, _ := paths[path].(schema.Schema)
+		if item == nil {
+			item = schema.Schema{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+
+	return schema.Schema{
+		"openapi": OPENAPI_VERSION,
+		"info": schema.Schema{
+			"title":   API_TITLE,
+			"version": API_VERSION,
+		},
+		"servers": []schema.Schema{{"url": "/"}},
+		"paths":   paths,
+		"components": schema.Schema{
+			"schemas": components,
+			"securitySchemes": schema.Schema{
+				"bearerAuth": schema.Schema{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"apiKey":     schema.Schema{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"embedToken": schema.Schema{"type": "apiKey", "in": "query", "name": "token"},
+			},
+		},
+	}
+}
+
+// operationResponses describes the responses of a route: the standard
+// envelope on success and failure, or the handler's own media type on
+// success for handlers listed in rawResponses.
+func operationResponses(route RouteInfo) schema.Schema {
+	envelope := schema.Schema{
+		fiber.MIMEApplicationJSON: schema.Schema{"schema": componentRef("APIResponse")},
+	}
+	success := schema.Schema{"description": "Success", "content": envelope}
+	if mediaType, ok := rawResponses[funcOwner(route.Handler)]; ok {
+		success["content"] = schema.Schema{mediaType: schema.Schema{}}
+	}
+
+	responses := schema.Schema{
+		"200":     success,
+		"default": schema.Schema{"description": "Failure, with the error in the envelope", "content": envelope},
+	}
+	if route.Auth == AUTH_JWT || route.Auth == AUTH_ADMIN || route.Auth == AUTH_EMBED {
+		responses["401"] = schema.Schema{"description": "Authentication required, invalid, or expired", "content": envelope}
+	}
+	if route.Auth == AUTH_ADMIN {
+		responses["403"] = schema.Schema{"description": "Not a site administrator", "content": envelope}
+	}
+	return responses
+}
+
+// openAPIPath converts a Fiber route pattern to an OpenAPI path template,
+// e.g. /api/posts/:id to /api/posts/{id}, and returns its path parameters.
+func openAPIPath(pattern string) (string, []schema.Schema) {
+	segments := strings.Split(pattern, "/")
+	var parameters []schema.Schema
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		parameters = append(parameters, schema.Schema{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   schema.Schema{"type": "string"},
+		})
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+// moduleTags maps the method and path of every route to the name of the
+// module registering it, by mounting each module on a scratch app the way
+// Setup does. Routes of the docs module are missing.
+func moduleTags(h *handlers.Handler) map[string]string {
+	tags := make(map[string]string)
+	scratch := fiber.New()
+	tag := func(parent fiber.Router, modules []Module) {
+		for _, module := range modules {
+			mount(parent, h, []Module{module})
+			for _, route := range scratch.GetRoutes() {
+				key := route.Method + " " + route.Path
+				if _, ok := tags[key]; !ok {
+					tags[key] = module.Name
+				}
+			}
+		}
+	}
+	tag(scratch.Group("/api"), apiModules)
+	tag(scratch, rootModules)
+	return tags
+}
+
+// componentSchema returns the schema of v for the components section,
+// which has no $schema of its own.
+func componentSchema(v any) schema.Schema {
+	doc := schema.For(v)
+	delete(doc, "$schema")
+	return doc
+}
+
+// componentRef references a schema of the components section.
+func componentRef(name string) schema.Schema {
+	return schema.Schema{"$ref": "#/components/schemas/" + name}
+}
+
+// humanize turns a handler name into an operation summary, e.g.
+// "GetPostLikes" into "Get post likes" or "listRoutes" into "List
+// routes". Initialisms are kept together,
+// e.g. "CreateAPIKey" becomes "Create API key".
+func humanize(name string) string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		// A word ends before an upper-case letter that follows a lower-case
+		// one, or that starts a word after an initialism
+		if i < len(runes) && !(unicode.IsUpper(runes[i]) && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])))) {
+			continue
+		}
+		word := string(runes[start:i])
+		switch {
+		case len(words) == 0:
+			word = strings.ToUpper(word[:1]) + word[1:]
+		case strings.ToUpper(word) != word:
+			word = strings.ToLower(word)
+		}
+		words = append(words, word)
+		start = i
+	}
+	return strings.Join(words, " ")
+}
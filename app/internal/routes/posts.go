@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+)
+
+// postsModule configures blog posts and everything hanging off a single
+// post: likes, claps, preview links, translations, the content assistant,
+// archiving, visibility, and passphrase protection.
+//
+// Publishing and deleting posts is rejected while a content freeze is in
+// effect (see middleware.ContentFreeze); drafts, which are private posts,
+// can still be created.
+//
+// Endpoints configured:
+//   - GET    /api/posts           - List all blog posts (summary view)
+//   - GET    /api/posts/export    - Stream all posts with content as NDJSON (admin only)
+//   - GET    /api/posts/search    - Full-text search with highlighted excerpts
+//   - GET    /api/posts/top-clapped - Most-clapped posts, all time or over recent days
+//   - GET    /api/posts/preview/:token   - Read a post through a signed preview link
+//   - GET    /api/posts/:id       - Get specific post with comments
+//   - POST   /api/posts           - Create a new blog post (JWT required, rate limited)
+//   - PUT    /api/posts/:id       - Edit a post (partial update, JWT required)
+//   - PUT    /api/posts/:id/autosave - Save a draft snapshot from the editor (JWT required)
+//   - GET    /api/posts/:id/autosave - List a post's draft snapshots (JWT required)
+//   - DELETE /api/posts/:id       - Move a post with its comments to the trash (JWT required)
+//   - POST   /api/posts/:id/restore - Restore a trashed post (JWT required)
+//   - POST   /api/posts/:id/like  - Like a post (deduplicated per requester)
+//   - DELETE /api/posts/:id/like  - Remove the requester's like
+//   - GET    /api/posts/:id/likes - Page through the individual likes of a post (admin only)
+//   - POST   /api/posts/:id/clap  - Clap for a post (capped per requester)
+//   - GET    /api/posts/:id/backlinks - Posts linking to a post
+//   - GET    /api/posts/:id/card.png      - Social-card image for og:image tags
+//   - POST   /api/posts/:id/preview-token - Create a signed, expiring preview link (JWT required)
+//   - POST   /api/posts/:id/translations/:lang - Create or replace a translation (JWT required)
+//   - GET    /api/posts/:id/translations/:lang - Get a translation
+//   - POST   /api/posts/:id/assist        - Generate summary and tag suggestions (JWT required)
+//   - POST   /api/posts/:id/assist/accept - Store accepted suggestions on the post (JWT required)
+//   - POST   /api/posts/:id/archive       - Archive a post, hidden from listings (JWT required)
+//   - DELETE /api/posts/:id/archive       - Unarchive a post (JWT required)
+//   - PUT    /api/posts/:id/visibility    - Set a post public, unlisted, or private (JWT required)
+//   - PUT    /api/posts/:id/passphrase    - Protect a post with a passphrase (JWT required)
+//   - DELETE /api/posts/:id/passphrase    - Remove a post's passphrase (JWT required)
+//   - POST   /api/posts/:id/unlock        - Exchange the passphrase for an access token
+var postsModule = Module{
+	Name:     "posts",
+	Prefix:   "/posts",
+	Register: registerPosts,
+}
+
+// registerPosts registers the posts module routes on router.
+func registerPosts(router fiber.Router, h *handlers.Handler) {
+	requireAuth := middleware.RequireAuth(h.Auth)
+	requireAdmin := middleware.RequireAdmin(h.Auth, h.Config.AdminUsers)
+	postLimit := rateLimit(h, "posts", h.Config.RateLimitPosts)
+	freeze := func(operation string, applies func(*fiber.Ctx) bool) fiber.Handler {
+		return middleware.ContentFreeze(h.ActiveFreeze, h.AuditFreeze, operation, applies)
+	}
+	publishFreeze := freeze(models.FREEZE_PUBLISH, nil)
+	createFreeze := freeze(models.FREEZE_PUBLISH, h.PublishesNewPost)
+	visibilityFreeze := freeze(models.FREEZE_PUBLISH, h.PublishesVisibility)
+	deleteFreeze := freeze(models.FREEZE_DELETE, nil)
+
+	// Blog posts endpoints
+	router.Get("", h.GetPosts)                                          // List all posts with summaries
+	router.Get("/export", requireAdmin, h.ExportPosts)                  // Stream all posts as NDJSON
+	router.Get("/search", h.SearchPosts)                                // Full-text search
+	router.Get("/top-clapped", h.GetTopClapped)                         // Most-clapped posts
+	router.Get("/preview/:token", h.GetPreview)                         // Read a post through a preview link
+	router.Get("/:id", h.GetPost)                                       // Get single post with comments
+	router.Post("", postLimit, requireAuth, createFreeze, h.CreatePost) // Create new blog post
+	router.Put("/:id", requireAuth, h.UpdatePost)                       // Edit a post
+	router.Delete("/:id", requireAuth, deleteFreeze, h.DeletePost)      // Trash a post with its comments
+
+	// Autosave endpoints
+	router.Put("/:id/autosave", requireAuth, h.PutAutosave)  // Save a draft snapshot
+	router.Get("/:id/autosave", requireAuth, h.GetAutosaves) // List draft snapshots
+
+	// Trash endpoints
+	router.Post("/:id/restore", requireAuth, publishFreeze, h.RestorePost) // Restore a trashed post
+
+	// Likes endpoints
+	router.Post("/:id/like", h.LikePost)                   // Like a post
+	router.Delete("/:id/like", h.UnlikePost)               // Remove the requester's like
+	router.Get("/:id/likes", requireAdmin, h.GetPostLikes) // List likes of a post
+
+	// Claps endpoint
+	router.Post("/:id/clap", h.ClapPost) // Clap for a post
+
+	// Backlinks endpoint
+	router.Get("/:id/backlinks", h.GetBacklinks) // Posts linking to a post
+
+	// Social card image
+	router.Get("/:id/card.png", h.GetPostCard) // PNG for link previews
+
+	// Preview links
+	router.Post("/:id/preview-token", requireAuth, h.CreatePreviewToken) // Create a signed preview link
+
+	// Translations endpoints
+	router.Post("/:id/translations/:lang", requireAuth, h.UpsertTranslation) // Create or replace a translation
+	router.Get("/:id/translations/:lang", h.GetTranslation)                  // Get a translation
+
+	// Content assistant endpoints
+	router.Post("/:id/assist", requireAuth, h.AssistPost)          // Generate summary and tag suggestions
+	router.Post("/:id/assist/accept", requireAuth, h.AcceptAssist) // Store accepted suggestions
+
+	// Archive endpoints
+	router.Post("/:id/archive", requireAuth, h.ArchivePost)                    // Archive a post
+	router.Delete("/:id/archive", requireAuth, publishFreeze, h.UnarchivePost) // Unarchive a post
+
+	// Visibility endpoints
+	router.Put("/:id/visibility", requireAuth, visibilityFreeze, h.SetVisibility) // Set public, unlisted, or private
+
+	// Passphrase protection endpoints
+	router.Put("/:id/passphrase", requireAuth, h.SetPassphrase)       // Protect a post
+	router.Delete("/:id/passphrase", requireAuth, h.RemovePassphrase) // Remove protection
+	router.Post("/:id/unlock", h.UnlockPost)                          // Issue an access token
+}
@@ -1,59 +1,173 @@
 // Package routes handles HTTP route configuration and setup for the blog API.
 // It provides functions to create and configure the Fiber application with
 // all necessary endpoints for blog posts and comments management.
+//
+// Routes are organized in modules, one per domain (posts, comments, admin,
+// embed, ...). Each module lives in its own file and declares its mount
+// prefix, its middleware stack, and a Register function, so a new subsystem
+// is added as a new module instead of growing a single route table.
 package routes
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/ratelimit"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+	"go.uber.org/zap"
 )
 
+// Module is the route set of one API domain.
+//
+// Middleware runs for every request under Prefix, so modules that declare
+// middleware must own their prefix: Fiber applies group middleware by path,
+// not by the group a route was registered on.
+type Module struct {
+	Name       string          // Domain name, for documentation and debugging
+	Prefix     string          // Mount point relative to the parent router
+	Middleware []fiber.Handler // Middleware run before the module's handlers
+	Register   func(router fiber.Router, h *handlers.Handler)
+}
+
+// apiModules are mounted under /api, in order. Order matters only where
+// paths overlap: static segments must come before parameters.
+var apiModules = []Module{
+	schemaModule,
+	authModule,
+	meModule,
+	postsModule,
+	commentsModule,
+	tagsModule,
+	categoriesModule,
+	trashModule,
+	batchModule,
+	adminModule,
+	cspModule,
+}
+
+// rootModules are mounted at the application root.
+var rootModules = []Module{
+	healthModule,
+	embedModule,
+	federationModule,
+	siteFilesModule,
+}
+
 // Setup creates and configures a new Fiber application with all API routes.
 // This is the main entry point for setting up the HTTP server with proper
 // route configuration and handler registration.
 //
+// Every request gets an ID (see middleware.RequestID), is traced (see
+// middleware.Tracing), and is logged once handled (see
+// middleware.RequestLogger).
+// Every /api endpoint requires JSON request bodies (see middleware.JSONBody),
+// accepts an X-API-Key header for service-to-service access (see
+// middleware.APIKey), and counts GET requests against the per-IP read
+// limit (see rateLimit). Browsers on the configured ALLOWED_ORIGINS may
+// call it cross-origin (see corsPolicy). The API is described at /api/openapi.json (see
+// OpenAPI).
+//
 // Parameters:
 //   - h: pointer to a Handler instance containing all endpoint handlers
 //
 // Returns a configured Fiber application ready to serve HTTP requests.
-func Setup(handlers *handlers.Handler) *fiber.App {
+func Setup(h *handlers.Handler) *fiber.App {
 	// Create a new Fiber application instance with default configuration
 	fiberApp := fiber.New()
 
-	// Register all API routes with their corresponding handlers
-	registerRoutes(fiberApp, handlers)
+	// Tag every request with an ID and a trace, then log it, thinned on the
+	// configured high-traffic paths; invalid sampling rules are reported but
+	// not fatal
+	sampling, err := middleware.ParseLogSampling(h.Config.RequestLogSampling)
+	if err != nil {
+		logger.Warn("some request log sampling rules are invalid", zap.Error(err))
+	}
+	fiberApp.Use(middleware.RequestID(), middleware.Tracing(), middleware.RequestLogger(h.Proxies, sampling))
+
+	// Create API route group for all endpoints under /api prefix;
+	// request bodies must be JSON, API keys are checked when sent, and
+	// reads are rate limited. CORS runs first, so preflights are answered
+	// and rejections stay readable by the browser
+	readLimit := rateLimit(h, "reads", h.Config.RateLimitReads, fiber.MethodGet, fiber.MethodHead)
+	apiMiddleware := []fiber.Handler{middleware.JSONBody(false), middleware.APIKey(h.LookupAPIKey), readLimit}
+	origins, err := middleware.ParseOrigins(h.Config.AllowedOrigins)
+	if err != nil {
+		logger.Warn("some allowed origins are invalid", zap.Error(err))
+	}
+	if len(origins) > 0 {
+		apiMiddleware = append([]fiber.Handler{corsPolicy(h, origins)}, apiMiddleware...)
+	}
+	apiGroup := fiberApp.Group("/api", apiMiddleware...)
+	mount(apiGroup, h, apiModules)
+	mount(fiberApp, h, rootModules)
+
+	// The API description lists every other module, so it is not one of
+	// apiModules itself
+	mount(apiGroup, h, []Module{docsModule})
 
 	return fiberApp
 }
 
-// registerRoutes configures all API endpoints for the blog application.
-// Sets up RESTful routes for blog posts and comments under the /api prefix.
-// This function organizes all route definitions in one place for maintainability.
-//
-// API Endpoints configured:
-//   - GET    /api/posts           - List all blog posts (summary view)
-//   - GET    /api/posts/:id       - Get specific post with comments
-//   - POST   /api/posts           - Create a new blog post
-//   - POST   /api/posts/:id/comments - Add comment to a specific post
+// mount registers each module on parent under its prefix and middleware.
 //
 // Parameters:
-//   - app: the Fiber application instance to register routes on
+//   - parent: router the modules are mounted on
 //   - h: pointer to Handler instance containing endpoint implementations
-//
-// Returns the API router group for potential additional configuration.
-func registerRoutes(app *fiber.App, h *handlers.Handler) fiber.Router {
-	// Create API route group for all endpoints under /api prefix
-	apiGroup := app.Group("/api")
+//   - modules: modules to register, in order
+func mount(parent fiber.Router, h *handlers.Handler, modules []Module) {
+	for _, module := range modules {
+		module.Register(parent.Group(module.Prefix, module.Middleware...), h)
+	}
+}
 
-	// Blog posts endpoints
-	apiGroup.Get("/posts", h.GetPosts)          // List all posts with summaries
-	apiGroup.Get("/posts/:id", h.GetPost)       // Get single post with comments
-	apiGroup.Post("/posts", h.CreatePost)       // Create new blog post
-	apiGroup.Delete("/posts/:id", h.DeletePost) // Create new blog post
+// corsExposeHeaders lists the response headers of the API that
+// cross-origin scripts may read, beyond those every response exposes.
+var corsExposeHeaders = []string{
+	fiber.HeaderXRequestID,
+	fiber.HeaderRetryAfter,
+	fiber.HeaderETag,
+	fiber.HeaderWWWAuthenticate,
+	middleware.RATE_LIMIT_LIMIT_HEADER,
+	middleware.RATE_LIMIT_REMAINING_HEADER,
+	tracing.TRACEPARENT_HEADER,
+}
 
-	// Comments endpoint
-	apiGroup.Post("/posts/:id/comments", h.CreateComment) // Add comment to post
-	apiGroup.Delete("/comments/:id", h.DeleteComment)     // Create new blog post
+// corsPolicy returns the middleware letting browsers on origins call the
+// API (see cors.New). Preflights are answered with the methods and headers
+// allowed by h's configuration, and may be cached for its CORSMaxAge.
+// Credentials are not allowed: the API authenticates with bearer tokens
+// and API keys, not cookies.
+//
+// Parameters:
+//   - h: handler holding the configuration
+//   - origins: allowed origins, as returned by middleware.ParseOrigins
+func corsPolicy(h *handlers.Handler, origins []string) fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOrigins:  strings.Join(origins, ","),
+		AllowMethods:  strings.Join(h.Config.CORSAllowedMethods, ","),
+		AllowHeaders:  strings.Join(h.Config.CORSAllowedHeaders, ","),
+		ExposeHeaders: strings.Join(corsExposeHeaders, ","),
+		MaxAge:        int(h.Config.CORSMaxAge.Seconds()),
+	})
+}
 
-	return apiGroup
+// rateLimit returns the middleware enforcing one per-IP limit per
+// RateLimitWindow of h's configuration (see middleware.RateLimit).
+//
+// Parameters:
+//   - h: handler holding the configuration and the rate limit store
+//   - name: policy name, keeping its counts apart from other policies
+//   - limit: requests allowed per window, 0 to disable the limit
+//   - methods: HTTP methods counted, none to count every method
+func rateLimit(h *handlers.Handler, name string, limit int, methods ...string) fiber.Handler {
+	return middleware.RateLimit(h.Proxies, h.RateLimits, ratelimit.Policy{
+		Name:    name,
+		Limit:   limit,
+		Window:  h.Config.RateLimitWindow,
+		Methods: methods,
+	})
 }
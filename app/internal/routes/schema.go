@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+)
+
+// schemaModule publishes JSON Schemas of request bodies.
+//
+// Endpoints configured:
+//   - GET /api/schema/:type - JSON Schema of a request body
+var schemaModule = Module{
+	Name:   "schema",
+	Prefix: "/schema",
+	Register: func(router fiber.Router, h *handlers.Handler) {
+		router.Get("/:type", h.GetSchema) // JSON Schema of a request DTO
+	},
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+)
+
+// siteFilesModule serves the plain-text files crawlers and visitors look
+// for at the site root. Their content is managed under /api/admin/site-files.
+//
+// Endpoints configured:
+//   - GET /robots.txt - Crawler rules
+//   - GET /humans.txt - The people behind the blog
+var siteFilesModule = Module{
+	Name: "site-files",
+	Register: func(router fiber.Router, h *handlers.Handler) {
+		router.Get("/"+handlers.ROBOTS_TXT, h.GetRobots) // Crawler rules
+		router.Get("/"+handlers.HUMANS_TXT, h.GetHumans) // Team and site credits
+	},
+}
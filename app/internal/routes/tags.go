@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+)
+
+// tagsModule lists the tags of published posts. Posts of one tag are
+// listed by GET /api/posts?tag=.
+//
+// Endpoints configured:
+//   - GET /api/tags - Distinct tags with post counts
+var tagsModule = Module{
+	Name:   "tags",
+	Prefix: "/tags",
+	Register: func(router fiber.Router, h *handlers.Handler) {
+		router.Get("", h.GetTags) // Tags with post counts
+	},
+}
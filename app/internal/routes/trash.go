@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+)
+
+// trashModule configures the listing of deleted posts and comments. Items
+// are restored under their own paths (POST /api/posts/:id/restore and
+// POST /api/comments/:id/restore) and purged by jobs.TrashPurger.
+//
+// Endpoints configured:
+//   - GET /api/trash - List restorable posts and comments (JWT required)
+var trashModule = Module{
+	Name:     "trash",
+	Prefix:   "/trash",
+	Register: registerTrash,
+}
+
+// registerTrash registers the trash module routes on router.
+func registerTrash(router fiber.Router, h *handlers.Handler) {
+	requireAuth := middleware.RequireAuth(h.Auth)
+
+	router.Get("", requireAuth, h.GetTrash) // List the trash
+}
@@ -0,0 +1,377 @@
+// Package sanitize removes markup that could run script from user content,
+// so posts and comments are safe even in frontends that insert them into
+// pages as raw HTML. Content is Markdown with optional inline HTML: an
+// allowlist policy in the style of bluemonday's UGC policy keeps the
+// formatting elements and attributes Markdown itself renders to, and
+// everything else is stripped or escaped.
+package sanitize
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Modes of a Policy, selected by SANITIZE_MODE.
+const (
+	MODE_STRIP  = "strip"  // Remove disallowed markup, keeping the text around it
+	MODE_ESCAPE = "escape" // Escape disallowed markup, so it shows as text
+)
+
+// allowedElements maps each element kept in content to the attributes it
+// keeps. Event handlers, style, and everything not listed are dropped.
+var allowedElements = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": {"cite"},
+	"br":         nil,
+	"caption":    nil,
+	"code":       {"class"},
+	"dd":         nil,
+	"del":        {"cite", "datetime"},
+	"details":    {"open"},
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        {"cite", "datetime"},
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"q":          {"cite"},
+	"s":          nil,
+	"samp":       nil,
+	"small":      nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"summary":    nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan", "scope"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// rawTextElements hold text that is not markup; stripping them removes
+// their content too, so script source does not end up as post text.
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "noscript": true, "noembed": true,
+	"noframes": true, "template": true, "textarea": true, "title": true, "xmp": true,
+}
+
+var (
+	// URL schemes links and images may use; relative URLs are always allowed
+	safeSchemes = []string{"http", "https", "mailto"}
+
+	// Syntax-highlighting classes, the only class values kept
+	languageClass = regexp.MustCompile(`^language-[A-Za-z0-9_+-]+$`)
+
+	// Markdown link and image targets, "[text](url)", which may contain
+	// balanced parentheses, and reference definitions, "[id]: url"
+	markdownTarget     = regexp.MustCompile(`(\]\(\s*<?)((?:[^\s()<>]|\([^\s()<>]*\))+)`)
+	markdownDefinition = regexp.MustCompile(`(?m)^( {0,3}\[[^\]]+\]:\s*<?)(\S+?)(>?(?:\s|$))`)
+)
+
+// Policy sanitizes content. A nil Policy strips.
+type Policy struct {
+	mode string
+}
+
+// New returns the content policy for a mode.
+//
+// Parameters:
+//   - mode: MODE_STRIP or MODE_ESCAPE
+//
+// Returns an error for unknown modes.
+func New(mode string) (*Policy, error) {
+	if mode != MODE_STRIP && mode != MODE_ESCAPE {
+		return nil, fmt.Errorf("unknown mode %q, expected %s or %s", mode, MODE_STRIP, MODE_ESCAPE)
+	}
+	return &Policy{mode: mode}, nil
+}
+
+// Sanitize returns text with disallowed markup stripped or escaped.
+// Allowed elements are rewritten with their allowed attributes only, and
+// URLs with a scheme other than http, https, or mailto, such as
+// javascript:, are removed from attributes and Markdown links. Text
+// outside tags, Markdown syntax included, is left as written, as are
+// character references, which browsers display as text. Markup in
+// Markdown code is treated like any other.
+//
+// Parameters:
+//   - text: post or comment content
+//
+// Returns the sanitized content; text without markup is returned unchanged.
+func (p *Policy) Sanitize(text string) string {
+	text = markdownTarget.ReplaceAllStringFunc(text, func(match string) string {
+		parts := markdownTarget.FindStringSubmatch(match)
+		if safeURL(parts[2]) {
+			return match
+		}
+		return parts[1] + "#"
+	})
+	text = markdownDefinition.ReplaceAllStringFunc(text, func(match string) string {
+		parts := markdownDefinition.FindStringSubmatch(match)
+		if safeURL(parts[2]) {
+			return match
+		}
+		return parts[1] + "#" + parts[3]
+	})
+	return p.SanitizeHTML(text)
+}
+
+// SanitizeHTML is Sanitize for HTML, such as post content rendered from
+// Markdown (see content.Render): only markup is sanitized, and Markdown
+// link syntax, which may appear in rendered code samples, is left alone.
+//
+// Parameters:
+//   - text: HTML fragment
+//
+// Returns the sanitized fragment.
+func (p *Policy) SanitizeHTML(text string) string {
+	escape := p != nil && p.mode == MODE_ESCAPE
+	var b strings.Builder
+	b.Grow(len(text))
+	for len(text) > 0 {
+		start := strings.IndexByte(text, '<')
+		if start < 0 {
+			b.WriteString(text)
+			break
+		}
+		b.WriteString(text[:start])
+		text = text[start:]
+
+		n, t := parseMarkup(text)
+		if n == 0 {
+			// A "<" that starts no markup, as in "a < b"
+			b.WriteByte('<')
+			text = text[1:]
+			continue
+		}
+		markup := text[:n]
+		text = text[n:]
+
+		switch {
+		case t != nil && allowed(t.name):
+			b.WriteString(t.render())
+		case escape:
+			b.WriteString(html.EscapeString(markup))
+		case t != nil && !t.end && !t.selfClosing && rawTextElements[t.name]:
+			text = skipRawText(text, t.name)
+		}
+	}
+	return b.String()
+}
+
+// allowed reports whether an element is kept, with or without attributes.
+func allowed(name string) bool {
+	_, ok := allowedElements[name]
+	return ok
+}
+
+// tag is a parsed start or end tag.
+type tag struct {
+	name        string
+	end         bool
+	selfClosing bool
+	attrs       [][2]string // name and decoded value, in order of appearance
+}
+
+// render writes the tag back with the allowed attributes only.
+func (t *tag) render() string {
+	if t.end {
+		return "</" + t.name + ">"
+	}
+	var b strings.Builder
+	b.WriteString("<" + t.name)
+	seen := make(map[string]bool)
+	for _, attr := range t.attrs {
+		name, value := attr[0], attr[1]
+		if seen[name] || !slices.Contains(allowedElements[t.name], name) {
+			continue
+		}
+		seen[name] = true
+		switch name {
+		case "href", "src", "cite":
+			if !safeURL(value) {
+				continue
+			}
+		case "class":
+			if !languageClass.MatchString(value) {
+				continue
+			}
+		case "open":
+			b.WriteString(" open")
+			continue
+		}
+		b.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+	if t.selfClosing {
+		b.WriteString("/")
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// parseMarkup reads the markup at the start of text, which begins with
+// "<": a start or end tag, a comment, or a declaration. Returns its length
+// and, for tags, the parsed tag. Unterminated markup runs to the end of
+// text, as browsers never show it. A length of 0 means the "<" is text.
+func parseMarkup(text string) (int, *tag) {
+	if len(text) < 2 {
+		return 0, nil
+	}
+	switch c := text[1]; {
+	case strings.HasPrefix(text, "<!--"):
+		if end := strings.Index(text[4:], "-->"); end >= 0 {
+			return 4 + end + 3, nil
+		}
+		return len(text), nil
+	case c == '!' || c == '?':
+		return markupEnd(text), nil
+	case c == '/':
+		if len(text) > 2 && isLetter(text[2]) {
+			return markupEnd(text), &tag{name: strings.ToLower(text[2:tagNameEnd(text, 2)]), end: true}
+		}
+		return markupEnd(text), nil
+	case isLetter(c):
+		return parseStartTag(text)
+	}
+	return 0, nil
+}
+
+// markupEnd returns the length of markup ending at the next ">".
+func markupEnd(text string) int {
+	if end := strings.IndexByte(text, '>'); end >= 0 {
+		return end + 1
+	}
+	return len(text)
+}
+
+// tagNameEnd returns the index ending the tag name starting at start.
+func tagNameEnd(text string, start int) int {
+	i := start
+	for i < len(text) && !isSpace(text[i]) && text[i] != '/' && text[i] != '>' {
+		i++
+	}
+	return i
+}
+
+// parseStartTag reads a start tag with its attributes. Quoted attribute
+// values may contain ">".
+func parseStartTag(text string) (int, *tag) {
+	i := tagNameEnd(text, 1)
+	t := &tag{name: strings.ToLower(text[1:i])}
+
+	for i < len(text) {
+		for i < len(text) && (isSpace(text[i]) || text[i] == '/') {
+			t.selfClosing = text[i] == '/'
+			i++
+		}
+		if i >= len(text) {
+			break
+		}
+		if text[i] == '>' {
+			return i + 1, t
+		}
+		t.selfClosing = false
+
+		start := i
+		for i < len(text) && !isSpace(text[i]) && text[i] != '/' && text[i] != '>' && (text[i] != '=' || i == start) {
+			i++
+		}
+		name := strings.ToLower(text[start:i])
+
+		for i < len(text) && isSpace(text[i]) {
+			i++
+		}
+		value := ""
+		if i < len(text) && text[i] == '=' {
+			i++
+			for i < len(text) && isSpace(text[i]) {
+				i++
+			}
+			if i < len(text) && (text[i] == '"' || text[i] == '\'') {
+				quote := text[i]
+				end := strings.IndexByte(text[i+1:], quote)
+				if end < 0 {
+					return len(text), nil
+				}
+				value = text[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(text) && !isSpace(text[i]) && text[i] != '>' {
+					i++
+				}
+				value = text[start:i]
+			}
+		}
+		t.attrs = append(t.attrs, [2]string{name, html.UnescapeString(value)})
+	}
+	return len(text), nil
+}
+
+// skipRawText returns what follows the end tag of a raw text element, or
+// nothing when the element is never closed.
+func skipRawText(text, name string) string {
+	for i := 0; ; {
+		end := strings.Index(text[i:], "</")
+		if end < 0 {
+			return ""
+		}
+		i += end
+		if close := text[i+2:]; len(close) >= len(name) && strings.EqualFold(close[:len(name)], name) {
+			return text[i+markupEnd(text[i:]):]
+		}
+		i += 2
+	}
+}
+
+// safeURL reports whether a URL is relative or uses a safe scheme.
+// Browsers ignore whitespace and control characters inside schemes, so
+// they are removed before the scheme is read.
+func safeURL(raw string) bool {
+	url := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(raw))
+	colon := strings.IndexAny(url, ":/?#")
+	if colon < 0 || url[colon] != ':' {
+		return true
+	}
+	return slices.Contains(safeSchemes, strings.ToLower(url[:colon]))
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,118 @@
+// Package schema generates JSON Schema documents from Go request types, so
+// clients can validate payloads with the same rules the handlers apply.
+//
+// Schemas follow the JSON field names from `json` tags. Constraints come
+// from an optional `schema` tag with comma-separated rules:
+//
+//	required       the field must be present
+//	minLength=N    minimum string length
+//	maxLength=N    maximum string length
+//	maxItems=N     maximum array length
+//	minimum=N      minimum integer value
+//	maximum=N      maximum integer value
+//	enum=a|b|c     the value must be one of the listed strings
+//	trim           surrounding whitespace is removed before validation
+//
+// Validate enforces the same rules on decoded requests.
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DRAFT is the JSON Schema dialect of generated documents.
+const DRAFT = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or sub-schema.
+type Schema map[string]any
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// For returns the JSON Schema of v's type, with $schema and title set.
+//
+// Parameters:
+//   - v: a value of the request type, typically its zero value
+func For(v any) Schema {
+	t := reflect.TypeOf(v)
+	schema := ofType(t)
+	schema["$schema"] = DRAFT
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema["title"] = t.Name()
+	return schema
+}
+
+// ofType maps a Go type to its sub-schema.
+func ofType(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": ofType(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": ofType(t.Elem())}
+	case reflect.Struct:
+		return ofStruct(t)
+	default:
+		return Schema{}
+	}
+}
+
+// ofStruct builds an object schema from a struct's JSON-visible fields.
+func ofStruct(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := ofType(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("schema"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			switch key {
+			case "required":
+				required = append(required, name)
+			case "minLength", "maxLength", "maxItems", "minimum", "maximum":
+				if n, err := strconv.Atoi(value); err == nil {
+					property[key] = n
+				}
+			case "enum":
+				property[key] = strings.Split(value, "|")
+			}
+		}
+		properties[name] = property
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError is a rule a request field breaks.
+type FieldError struct {
+	Field   string `json:"field"`   // JSON path of the field, e.g. "title" or "comments[2].author"
+	Message string `json:"message"` // What is wrong with the value
+}
+
+// Validate checks a request against the rules of its `schema` tags, the
+// same rules For publishes, so clients and server agree on what is valid.
+// Fields tagged `trim` first have surrounding whitespace removed in place,
+// which is why v must be a pointer. Every string must be valid UTF-8, and
+// lengths are counted in characters. Optional fields left empty are not
+// checked, unless they are pointers set to an empty value; nested structs
+// and slices of structs are checked too.
+//
+// Parameters:
+//   - v: pointer to the decoded request
+//
+// Returns every broken rule in field order, or nil when v is valid.
+func Validate(v any) []FieldError {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	return validateStruct(value, "")
+}
+
+// validateStruct checks the JSON-visible fields of a struct value; prefix
+// is the JSON path of the struct itself.
+func validateStruct(value reflect.Value, prefix string) []FieldError {
+	var problems []FieldError
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		problems = append(problems, validateField(value.Field(i), prefix+name, field.Tag.Get("schema"))...)
+	}
+	return problems
+}
+
+// validateField applies the rules of one field's tag to its value.
+func validateField(value reflect.Value, path, tag string) []FieldError {
+	rules := make(map[string]string)
+	for _, rule := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if key != "" {
+			rules[key] = arg
+		}
+	}
+
+	// Pointers mark optional fields: nil is absent, anything else is
+	// checked, empty values included
+	present := false
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if _, ok := rules["required"]; ok {
+				return []FieldError{{Field: path, Message: "is required"}}
+			}
+			return nil
+		}
+		value, present = value.Elem(), true
+	}
+
+	if _, ok := rules["trim"]; ok {
+		trimStrings(value)
+	}
+	if value.IsZero() && !present {
+		if _, ok := rules["required"]; ok {
+			return []FieldError{{Field: path, Message: "is required"}}
+		}
+		return nil
+	}
+
+	var problems []FieldError
+	fail := func(format string, args ...any) {
+		problems = append(problems, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		text := value.String()
+		if !utf8.ValidString(text) {
+			fail("must be valid UTF-8")
+			return problems
+		}
+		length := utf8.RuneCountInString(text)
+		if n, ok := intRule(rules, "minLength"); ok && length < n {
+			if length == 0 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters", n)
+			}
+		}
+		if n, ok := intRule(rules, "maxLength"); ok && length > n {
+			fail("must be at most %d characters", n)
+		}
+		if allowed, ok := rules["enum"]; ok && !slices.Contains(strings.Split(allowed, "|"), text) {
+			fail("must be one of %s", strings.ReplaceAll(allowed, "|", ", "))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number := value.Int()
+		if n, ok := intRule(rules, "minimum"); ok && number < int64(n) {
+			fail("must be at least %d", n)
+		}
+		if n, ok := intRule(rules, "maximum"); ok && number > int64(n) {
+			fail("must be at most %d", n)
+		}
+	case reflect.Slice, reflect.Array:
+		if n, ok := intRule(rules, "maxItems"); ok && value.Len() > n {
+			fail("must have at most %d items", n)
+		}
+		for i := 0; i < value.Len(); i++ {
+			item := value.Index(i)
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case item.Kind() == reflect.String && !utf8.ValidString(item.String()):
+				problems = append(problems, FieldError{Field: itemPath, Message: "must be valid UTF-8"})
+			case item.Kind() == reflect.Struct:
+				problems = append(problems, validateStruct(item, itemPath+".")...)
+			}
+		}
+	case reflect.Struct:
+		problems = append(problems, validateStruct(value, path+".")...)
+	}
+	return problems
+}
+
+// trimStrings removes surrounding whitespace from a string, or from each
+// string of a slice, in place.
+func trimStrings(value reflect.Value) {
+	switch {
+	case value.Kind() == reflect.String && value.CanSet():
+		value.SetString(strings.TrimSpace(value.String()))
+	case value.Kind() == reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			trimStrings(value.Index(i))
+		}
+	}
+}
+
+// intRule returns the integer argument of a rule, if the tag has it.
+func intRule(rules map[string]string, key string) (int, bool) {
+	arg, ok := rules[key]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(arg)
+	return n, err == nil
+}
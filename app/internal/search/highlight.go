@@ -0,0 +1,176 @@
+// Package search builds highlighted snippets for full-text search results.
+// Matching is done by MongoDB's text index; this package only finds where
+// the query terms appear in a post so they can be shown to the reader.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Markers wrapped around matched words. Text outside them is HTML-escaped,
+// so highlights can be inserted into a page as-is.
+const (
+	HIGHLIGHT_OPEN  = "<mark>"
+	HIGHLIGHT_CLOSE = "</mark>"
+)
+
+// SNIPPET_CONTEXT is the number of bytes of context kept on each side of a
+// match, widened or narrowed to the nearest word boundary.
+const SNIPPET_CONTEXT = 60
+
+// ELLIPSIS marks text cut from the start or end of a snippet.
+const ELLIPSIS = "…"
+
+// Terms extracts the words to highlight from a MongoDB $text search
+// string: quoted phrases contribute their words, negated terms ("-word")
+// are dropped. Terms are lowercased and deduplicated.
+func Terms(query string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	add := func(text string) {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+			if !seen[word] {
+				seen[word] = true
+				terms = append(terms, word)
+			}
+		}
+	}
+
+	// Even segments are outside quotes, odd segments are phrases
+	for i, segment := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			add(segment)
+			continue
+		}
+		for _, field := range strings.Fields(segment) {
+			if !strings.HasPrefix(field, "-") {
+				add(field)
+			}
+		}
+	}
+	return terms
+}
+
+// span is the byte range of a matched word.
+type span struct{ start, end int }
+
+// matches finds the words of text that start with one of terms, ignoring
+// case. Prefix matching approximates the stemming the text index does, so
+// "posts" and "posting" are highlighted for the term "post".
+func matches(text string, terms []string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text + " " {
+		if !isSeparator(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			word := strings.ToLower(text[start:i])
+			for _, term := range terms {
+				if strings.HasPrefix(word, term) {
+					spans = append(spans, span{start, i})
+					break
+				}
+			}
+			start = -1
+		}
+	}
+	return spans
+}
+
+// Highlight returns text HTML-escaped with every matched word wrapped in
+// HIGHLIGHT_OPEN and HIGHLIGHT_CLOSE.
+func Highlight(text string, terms []string) string {
+	return render(text, matches(text, terms), 0, len(text))
+}
+
+// Snippets returns up to maxSnippets excerpts of text around matched words, each
+// highlighted as by Highlight. Whitespace is collapsed first and matches
+// close enough to share context are merged into one snippet. Returns nil
+// when nothing in text matches.
+func Snippets(text string, terms []string, maxSnippets int) []string {
+	text = strings.Join(strings.Fields(text), " ")
+	spans := matches(text, terms)
+
+	var snippets []string
+	for i := 0; i < len(spans) && len(snippets) < maxSnippets; {
+		lo := wordStart(text, spans[i].start-SNIPPET_CONTEXT, spans[i].start)
+		hi := wordEnd(text, spans[i].end+SNIPPET_CONTEXT, spans[i].end)
+
+		// Take in every later match whose context overlaps this snippet
+		j := i + 1
+		for j < len(spans) && spans[j].start-SNIPPET_CONTEXT < hi {
+			hi = wordEnd(text, spans[j].end+SNIPPET_CONTEXT, spans[j].end)
+			j++
+		}
+
+		snippet := render(text, spans[i:j], lo, hi)
+		if lo > 0 {
+			snippet = ELLIPSIS + snippet
+		}
+		if hi < len(text) {
+			snippet += ELLIPSIS
+		}
+		snippets = append(snippets, snippet)
+		i = j
+	}
+	return snippets
+}
+
+// render escapes text[lo:hi], wrapping each span in the highlight markers.
+func render(text string, spans []span, lo, hi int) string {
+	var b strings.Builder
+	pos := lo
+	for _, s := range spans {
+		if s.start < lo || s.end > hi {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:s.start]))
+		b.WriteString(HIGHLIGHT_OPEN)
+		b.WriteString(html.EscapeString(text[s.start:s.end]))
+		b.WriteString(HIGHLIGHT_CLOSE)
+		pos = s.end
+	}
+	b.WriteString(html.EscapeString(text[pos:hi]))
+	return b.String()
+}
+
+// wordStart moves offset forward to the start of a word, never past limit.
+// Text is whitespace-collapsed, so words are separated by single spaces.
+func wordStart(text string, offset, limit int) int {
+	if offset <= 0 {
+		return 0
+	}
+	if i := strings.IndexByte(text[offset:limit], ' '); i >= 0 {
+		return offset + i + 1
+	}
+	for offset < limit && !utf8.RuneStart(text[offset]) {
+		offset++
+	}
+	return offset
+}
+
+// wordEnd moves offset back to the end of a word, never before limit.
+func wordEnd(text string, offset, limit int) int {
+	if offset >= len(text) {
+		return len(text)
+	}
+	if i := strings.LastIndexByte(text[limit:offset], ' '); i >= 0 {
+		return limit + i
+	}
+	for offset > limit && !utf8.RuneStart(text[offset]) {
+		offset--
+	}
+	return offset
+}
+
+// isSeparator reports whether r separates words.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
@@ -0,0 +1,372 @@
+// Package selfcheck verifies that an instance is fit to serve: its
+// configuration is valid, MongoDB answers, the indexes and backfills the
+// handlers rely on are in place, and the external services it calls are
+// reachable. The report is logged on every boot, and the --check flag runs
+// it alone so deploy pipelines can stop a bad release before it serves.
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/config"
+	"github.com/pedrobertao/challenge-prosi/app/internal/integrations"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/plugins"
+	"github.com/pedrobertao/challenge-prosi/app/internal/proxy"
+	"github.com/pedrobertao/challenge-prosi/app/internal/ratelimit"
+	"github.com/pedrobertao/challenge-prosi/app/internal/sanitize"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// PROBE_TIMEOUT bounds each database query and integration probe, so an
+// unreachable service is reported instead of stalling the check.
+const PROBE_TIMEOUT = 5 * time.Second
+
+// Statuses of a Result. Only STATUS_FAIL fails the report: the instance
+// serves with a warning, e.g. without a chat integration that is down.
+const (
+	STATUS_OK   = "ok"
+	STATUS_WARN = "warn"
+	STATUS_FAIL = "fail"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name     string        // Check name, e.g. "database"
+	Status   string        // STATUS_OK, STATUS_WARN, or STATUS_FAIL
+	Detail   string        // What is wrong, or what was checked
+	Duration time.Duration // Time the check took
+}
+
+// MarshalLogObject encodes the result as a log object.
+func (r Result) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", r.Name)
+	enc.AddString("status", r.Status)
+	if r.Detail != "" {
+		enc.AddString("detail", r.Detail)
+	}
+	enc.AddDuration("duration", r.Duration)
+	return nil
+}
+
+// Report is the outcome of every check, in the order they ran.
+type Report struct {
+	Results []Result
+}
+
+// MarshalLogArray encodes the results as a log array.
+func (r Report) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, result := range r.Results {
+		if err := enc.AppendObject(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Failed reports whether any check failed.
+func (r Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Status == STATUS_FAIL {
+			return true
+		}
+	}
+	return false
+}
+
+// Log writes the report as a single structured log line: at error level
+// when a check failed, warn when one warned, info otherwise.
+func (r Report) Log() {
+	level, status := zap.InfoLevel, STATUS_OK
+	for _, result := range r.Results {
+		switch {
+		case result.Status == STATUS_FAIL:
+			level, status = zap.ErrorLevel, STATUS_FAIL
+		case result.Status == STATUS_WARN && status == STATUS_OK:
+			level, status = zap.WarnLevel, STATUS_WARN
+		}
+	}
+	fields := []zap.Field{zap.String("status", status), zap.Array("checks", r)}
+	switch level {
+	case zap.ErrorLevel:
+		logger.Error("self-check report", fields...)
+	case zap.WarnLevel:
+		logger.Warn("self-check report", fields...)
+	default:
+		logger.Info("self-check report", fields...)
+	}
+}
+
+// Checker runs the self-check of one instance.
+type Checker struct {
+	Config     *config.Config   // Configuration being checked
+	DB         *storage.Storage // Open database, nil when connecting failed
+	ConnectErr error            // Why connecting failed, when DB is nil
+	HTTP       *http.Client     // Client used to probe external integrations
+}
+
+// New creates a Checker of cfg and db; pass the connection error instead
+// of db when the database could not be opened.
+//
+// Parameters:
+//   - cfg: loaded configuration
+//   - db: open database, or nil
+//   - connectErr: error of opening the database, or nil
+//
+// Returns a pointer to a new Checker.
+func New(cfg *config.Config, db *storage.Storage, connectErr error) *Checker {
+	return &Checker{
+		Config:     cfg,
+		DB:         db,
+		ConnectErr: connectErr,
+		HTTP:       &http.Client{Timeout: PROBE_TIMEOUT},
+	}
+}
+
+// Run runs every check and returns the report. The database checks are
+// reported as failed without querying when the database is unreachable.
+func (c *Checker) Run(ctx context.Context) Report {
+	var report Report
+	run := func(name string, check func(ctx context.Context) (string, string)) {
+		ctx, cancel := context.WithTimeout(ctx, PROBE_TIMEOUT)
+		defer cancel()
+		start := time.Now()
+		status, detail := check(ctx)
+		report.Results = append(report.Results, Result{
+			Name:     name,
+			Status:   status,
+			Detail:   detail,
+			Duration: time.Since(start),
+		})
+	}
+
+	run("config", c.checkConfig)
+	run("database", c.checkDatabase)
+	reachable := report.Results[1].Status == STATUS_OK
+	for _, check := range []struct {
+		name string
+		run  func(ctx context.Context) (string, string)
+	}{
+		{"indexes", c.checkIndexes},
+		{"backfills", c.checkBackfills},
+	} {
+		if !reachable {
+			report.Results = append(report.Results, Result{Name: check.name, Status: STATUS_FAIL, Detail: "database unreachable"})
+			continue
+		}
+		run(check.name, check.run)
+	}
+	if c.Config.RateLimitRedisURL != "" {
+		run("ratelimit", c.checkRateLimitStore)
+	}
+	for _, target := range c.integrationTargets(ctx, reachable) {
+		run("integration:"+target.name, func(ctx context.Context) (string, string) {
+			return c.probe(ctx, target.url)
+		})
+	}
+	return report
+}
+
+// Log runs the checks and logs the report, e.g. on boot where only the
+// log matters.
+func (c *Checker) Log(ctx context.Context) {
+	c.Run(ctx).Log()
+}
+
+// checkConfig validates the configuration, including the values parsed by
+// the packages consuming them. Secrets generated at startup only warn:
+// the instance works, but tokens do not survive a restart or another
+// replica.
+func (c *Checker) checkConfig(context.Context) (string, string) {
+	problems := []error{c.Config.Validate()}
+	if _, err := storage.NewIDCodec(c.Config.IDFormat); err != nil {
+		problems = append(problems, fmt.Errorf("ID_FORMAT: %w", err))
+	}
+	if _, err := c.routing().ReadPref(); err != nil {
+		problems = append(problems, fmt.Errorf("MONGODB_READ_PREFERENCE: %w", err))
+	}
+	if _, err := proxy.NewResolver(c.Config.TrustedProxies); err != nil {
+		problems = append(problems, fmt.Errorf("TRUSTED_PROXIES: %w", err))
+	}
+	if _, err := middleware.ParseLogSampling(c.Config.RequestLogSampling); err != nil {
+		problems = append(problems, fmt.Errorf("REQUEST_LOG_SAMPLING: %w", err))
+	}
+	if _, err := middleware.ParseOrigins(c.Config.AllowedOrigins); err != nil {
+		problems = append(problems, fmt.Errorf("ALLOWED_ORIGINS: %w", err))
+	}
+	if _, err := tracing.ParseHeaders(c.Config.OTLPHeaders); err != nil {
+		problems = append(problems, fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS: %w", err))
+	}
+	if _, err := plugins.Enable(c.Config.Plugins); err != nil {
+		problems = append(problems, fmt.Errorf("PLUGINS: %w", err))
+	}
+	if _, err := sanitize.New(c.Config.SanitizeMode); err != nil {
+		problems = append(problems, fmt.Errorf("SANITIZE_MODE: %w", err))
+	}
+	if c.Config.RateLimitRedisURL != "" {
+		if _, err := ratelimit.NewRedis(c.Config.RateLimitRedisURL); err != nil {
+			problems = append(problems, fmt.Errorf("RATE_LIMIT_REDIS_URL: %w", err))
+		}
+	}
+	if err := errors.Join(problems...); err != nil {
+		return STATUS_FAIL, strings.ReplaceAll(err.Error(), "\n", "; ")
+	}
+
+	var generated []string
+	if c.Config.TokenSecret == "" {
+		generated = append(generated, "TOKEN_SECRET")
+	}
+	if c.Config.JWTSecret == "" {
+		generated = append(generated, "JWT_SECRET")
+	}
+	if len(generated) > 0 {
+		return STATUS_WARN, strings.Join(generated, ", ") + " unset, random keys are generated at startup"
+	}
+	return STATUS_OK, ""
+}
+
+// routing returns the read routing configured for MongoDB.
+func (c *Checker) routing() storage.ReadRouting {
+	return storage.ReadRouting{
+		Preference:     c.Config.MongoReadPreference,
+		TagSets:        c.Config.MongoReadTags,
+		Hedged:         c.Config.MongoHedgedReads,
+		MaxStaleness:   c.Config.MongoMaxStaleness,
+		LocalThreshold: c.Config.MongoLocalThreshold,
+	}
+}
+
+// checkDatabase pings MongoDB.
+func (c *Checker) checkDatabase(ctx context.Context) (string, string) {
+	if c.DB == nil {
+		return STATUS_FAIL, fmt.Sprintf("connection failed: %v", c.ConnectErr)
+	}
+	if err := c.DB.Client.Ping(ctx, nil); err != nil {
+		return STATUS_FAIL, err.Error()
+	}
+	return STATUS_OK, ""
+}
+
+// checkIndexes reports the required indexes the database lacks.
+func (c *Checker) checkIndexes(ctx context.Context) (string, string) {
+	missing, err := c.DB.MissingIndexes(ctx)
+	if err != nil {
+		return STATUS_FAIL, err.Error()
+	}
+	if len(missing) > 0 {
+		return STATUS_FAIL, "missing " + strings.Join(missing, ", ")
+	}
+	return STATUS_OK, ""
+}
+
+// checkBackfills reports the posts the startup backfills have yet to
+// update, the schema migrations of this application.
+func (c *Checker) checkBackfills(ctx context.Context) (string, string) {
+	pending, err := c.DB.PendingBackfills(ctx)
+	if err != nil {
+		return STATUS_FAIL, err.Error()
+	}
+	var fields []string
+	for field, count := range pending {
+		if count > 0 {
+			fields = append(fields, fmt.Sprintf("%s on %d posts", field, count))
+		}
+	}
+	if len(fields) > 0 {
+		sort.Strings(fields)
+		return STATUS_FAIL, "pending " + strings.Join(fields, ", ")
+	}
+	return STATUS_OK, ""
+}
+
+// checkRateLimitStore pings the Redis server sharing rate limit counts.
+// An unreachable server only warns: requests are then served unlimited.
+func (c *Checker) checkRateLimitStore(ctx context.Context) (string, string) {
+	redis, err := ratelimit.NewRedis(c.Config.RateLimitRedisURL)
+	if err != nil {
+		return STATUS_FAIL, err.Error()
+	}
+	defer redis.Close()
+	if err := redis.Ping(ctx); err != nil {
+		return STATUS_WARN, "unreachable, requests are not rate limited: " + err.Error()
+	}
+	return STATUS_OK, ""
+}
+
+// integrationTarget is an external service probed by the self-check.
+type integrationTarget struct {
+	name string // Service name, e.g. "assistant" or "slack"
+	url  string // URL probed, without credentials
+}
+
+// integrationTargets lists the enabled external services: the content
+// assistant, IndexNow, Telegram, and the chat services of the stored
+// integrations when the database is reachable.
+func (c *Checker) integrationTargets(ctx context.Context, reachable bool) []integrationTarget {
+	var targets []integrationTarget
+	if c.Config.AssistantAPIKey != "" {
+		targets = append(targets, integrationTarget{"assistant", c.Config.AssistantAPIURL})
+	}
+	if c.Config.IndexNowKey != "" && c.Config.PublicPostURL != "" {
+		targets = append(targets, integrationTarget{"indexnow", c.Config.IndexNowEndpoint})
+	}
+	if integrations.NewTelegram(c.Config.TelegramBotToken, c.Config.TelegramChatID) != nil {
+		// The bot token is part of API URLs; probe the host alone
+		targets = append(targets, integrationTarget{"telegram", "https://api.telegram.org/"})
+	}
+	if !reachable {
+		return targets
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, PROBE_TIMEOUT)
+	defer cancel()
+	var stored []models.Integration
+	cursor, err := c.DB.Integrations.Find(ctx, bson.M{})
+	if err == nil {
+		err = cursor.All(ctx, &stored)
+	}
+	if err != nil {
+		logger.Warn("self-check could not list integrations", zap.Error(err))
+		return targets
+	}
+	// Webhook paths carry secrets too; one probe per service host suffices
+	probed := make(map[string]bool)
+	for _, integration := range stored {
+		webhook, err := url.Parse(integration.WebhookURL)
+		if err != nil || probed[webhook.Host] {
+			continue
+		}
+		probed[webhook.Host] = true
+		targets = append(targets, integrationTarget{integration.Driver, webhook.Scheme + "://" + webhook.Host + "/"})
+	}
+	return targets
+}
+
+// probe checks that target answers HTTP at all: any response, even an
+// error status, shows the service is reachable. Unreachable services only
+// warn, since events to them are dropped without affecting the API.
+func (c *Checker) probe(ctx context.Context, target string) (string, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return STATUS_WARN, err.Error()
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return STATUS_WARN, "unreachable: " + err.Error()
+	}
+	resp.Body.Close()
+	return STATUS_OK, fmt.Sprintf("%s answered %d", target, resp.StatusCode)
+}
@@ -0,0 +1,207 @@
+// Package similarity implements near-duplicate text detection using word
+// shingling and MinHash signatures with locality-sensitive hashing (LSH).
+// It is used to flag posts whose content is highly similar, such as
+// accidental re-imports or plagiarized submissions.
+package similarity
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DEFAULT_SHINGLE_SIZE is the number of consecutive words per shingle.
+const DEFAULT_SHINGLE_SIZE = 5
+
+// DEFAULT_NUM_HASHES is the MinHash signature length. 128 hashes give a
+// Jaccard estimate with a standard error of about 0.09.
+const DEFAULT_NUM_HASHES = 128
+
+// DEFAULT_BANDS is the number of LSH bands the signature is split into when
+// looking for candidate pairs. With 128 hashes this is 4 rows per band,
+// which reliably surfaces pairs above ~0.5 similarity for verification.
+const DEFAULT_BANDS = 32
+
+// Signature is the MinHash signature of a document.
+type Signature []uint64
+
+// Document is an item to compare, identified by an opaque ID.
+type Document struct {
+	ID        string    // Caller-defined identifier (e.g., post ID)
+	Signature Signature // MinHash signature from MinHasher.Sign
+}
+
+// Match is a pair of documents whose estimated similarity reached the
+// requested threshold. A is always the document that appeared first.
+type Match struct {
+	A          string  // ID of the first document
+	B          string  // ID of the second document
+	Similarity float64 // Estimated Jaccard similarity in [0, 1]
+}
+
+// MinHasher computes MinHash signatures with a fixed set of hash seeds.
+// Signatures are only comparable when produced by MinHashers built with
+// the same number of hashes.
+type MinHasher struct {
+	seeds []uint64
+}
+
+// NewMinHasher creates a MinHasher producing signatures of numHashes values.
+// Seeds are derived deterministically, so signatures are stable across runs.
+//
+// Parameters:
+//   - numHashes: signature length (e.g., DEFAULT_NUM_HASHES)
+//
+// Returns a pointer to a ready-to-use MinHasher.
+func NewMinHasher(numHashes int) *MinHasher {
+	seeds := make([]uint64, numHashes)
+	state := uint64(0x9E3779B97F4A7C15)
+	for i := range seeds {
+		state += 0x9E3779B97F4A7C15
+		seeds[i] = mix(state)
+	}
+	return &MinHasher{seeds: seeds}
+}
+
+// Shingles splits text into lowercase words and hashes every run of k
+// consecutive words. Texts shorter than k words yield a single shingle of
+// all their words; empty texts yield no shingles.
+//
+// Parameters:
+//   - text: the document content
+//   - k: words per shingle (e.g., DEFAULT_SHINGLE_SIZE)
+//
+// Returns the set of shingle hashes (duplicates removed).
+func Shingles(text string, k int) []uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < k {
+		k = len(words)
+	}
+
+	seen := make(map[uint64]struct{})
+	shingles := make([]uint64, 0, len(words)-k+1)
+	for i := 0; i+k <= len(words); i++ {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(strings.Join(words[i:i+k], " ")))
+		sum := hasher.Sum64()
+		if _, ok := seen[sum]; ok {
+			continue
+		}
+		seen[sum] = struct{}{}
+		shingles = append(shingles, sum)
+	}
+	return shingles
+}
+
+// Sign computes the MinHash signature of a shingle set.
+// An empty set produces a signature of math.MaxUint64 values, which never
+// matches anything else.
+func (m *MinHasher) Sign(shingles []uint64) Signature {
+	signature := make(Signature, len(m.seeds))
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+	for _, shingle := range shingles {
+		for i, seed := range m.seeds {
+			if h := mix(shingle ^ seed); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// Similarity estimates the Jaccard similarity of the documents behind two
+// signatures as the fraction of equal positions.
+// Returns 0 when the signatures have different lengths or are empty.
+func Similarity(a, b Signature) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] && a[i] != math.MaxUint64 {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// FindDuplicates returns every pair of documents whose estimated similarity
+// is at least threshold. Candidate pairs are found with LSH banding so the
+// cost grows with the number of similar pairs rather than quadratically.
+//
+// Parameters:
+//   - docs: documents with signatures of equal length
+//   - bands: number of LSH bands; must divide the signature length
+//   - threshold: minimum estimated similarity to report, in [0, 1]
+//
+// Returns the matches in no particular order.
+func FindDuplicates(docs []Document, bands int, threshold float64) []Match {
+	if len(docs) < 2 {
+		return nil
+	}
+	rows := len(docs[0].Signature) / bands
+	if rows == 0 {
+		return nil
+	}
+
+	type pair struct{ a, b int }
+	seen := make(map[pair]bool)
+	var matches []Match
+
+	for band := 0; band < bands; band++ {
+		// Bucket documents by the hash of this band of their signature
+		buckets := make(map[uint64][]int)
+		for i, doc := range docs {
+			if len(doc.Signature) != len(docs[0].Signature) {
+				continue
+			}
+			hasher := fnv.New64a()
+			for _, value := range doc.Signature[band*rows : (band+1)*rows] {
+				var buf [8]byte
+				for j := range buf {
+					buf[j] = byte(value >> (8 * j))
+				}
+				hasher.Write(buf[:])
+			}
+			key := hasher.Sum64()
+			buckets[key] = append(buckets[key], i)
+		}
+
+		// Verify every candidate pair sharing a bucket exactly once
+		for _, members := range buckets {
+			for x := 0; x < len(members); x++ {
+				for y := x + 1; y < len(members); y++ {
+					p := pair{members[x], members[y]}
+					if seen[p] {
+						continue
+					}
+					seen[p] = true
+
+					score := Similarity(docs[p.a].Signature, docs[p.b].Signature)
+					if score >= threshold {
+						matches = append(matches, Match{A: docs[p.a].ID, B: docs[p.b].ID, Similarity: score})
+					}
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// mix is the splitmix64 finalizer, used to derive independent hash values.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,55 @@
+package site
+
+import (
+	"strings"
+	"time"
+)
+
+// HUMANS_SOFTWARE is the software line of generated humans.txt files.
+const HUMANS_SOFTWARE = "Go, Fiber, MongoDB"
+
+// Robots builds a robots.txt asking every crawler to skip the disallowed
+// path prefixes, announcing sitemap when it is not empty.
+//
+// Parameters:
+//   - disallow: path prefixes to exclude, e.g. "/api/"
+//   - sitemap: absolute sitemap URL, or empty
+func Robots(disallow []string, sitemap string) string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	for _, path := range disallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	if len(disallow) == 0 {
+		// An empty Disallow allows everything; a group needs one rule
+		b.WriteString("Disallow:\n")
+	}
+	if sitemap != "" {
+		b.WriteString("\nSitemap: " + sitemap + "\n")
+	}
+	return b.String()
+}
+
+// Humans builds a humans.txt (see humanstxt.org) listing the team and
+// when the content last changed.
+//
+// Parameters:
+//   - team: one entry per person, e.g. "Jane Doe <jane@example.com>"; the
+//     TEAM section is left out when empty
+//   - lastUpdate: when posts last changed; zero leaves out the date
+func Humans(team []string, lastUpdate time.Time) string {
+	var b strings.Builder
+	if len(team) > 0 {
+		b.WriteString("/* TEAM */\n")
+		for _, member := range team {
+			b.WriteString(member + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("/* SITE */\n")
+	if !lastUpdate.IsZero() {
+		b.WriteString("Last update: " + lastUpdate.UTC().Format("2006/01/02") + "\n")
+	}
+	b.WriteString("Software: " + HUMANS_SOFTWARE + "\n")
+	return b.String()
+}
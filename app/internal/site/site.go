@@ -0,0 +1,276 @@
+// Package site renders the blog as static files: a page per post, an index,
+// a page per tag, and Atom feeds, so snapshots can be hosted on object
+// storage without the API. Pages use html/template, like the embeddable
+// comments widget.
+//
+// Only public content is published. Private, passphrase-protected, and
+// scheduled posts are never rendered; unlisted and archived posts get their page but are
+// left out of the index, tag pages, and feeds, as in the API listings.
+package site
+
+import (
+	"embed"
+	"encoding/xml"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+)
+
+// FEED_SIZE is the number of most recent posts in each feed.
+const FEED_SIZE = 20
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// pages holds the index, tag, and post templates and their shared parts.
+var pages = template.Must(template.New("site").
+	Funcs(template.FuncMap{"slug": Slug}).
+	ParseFS(templateFiles, "templates/*.html"))
+
+// Site describes the blog being generated.
+type Site struct {
+	Title       string    // Blog name shown in headers and feeds
+	BaseURL     string    // Absolute URL the output is hosted at, used in feeds
+	GeneratedAt time.Time // Snapshot time shown in page footers
+}
+
+// Report summarizes a generation run.
+type Report struct {
+	Posts   int `json:"posts"`   // Post pages written
+	Listed  int `json:"listed"`  // Posts on the index and in feeds
+	Tags    int `json:"tags"`    // Tag pages written
+	Skipped int `json:"skipped"` // Private or protected posts not rendered
+}
+
+// page is the data passed to every template.
+type page struct {
+	Site      Site
+	PageTitle string
+	Root      string // Relative path from the page to the output root
+	Posts     []models.BlogPost
+	Tag       string
+	Post      models.BlogPost
+	Comments  []models.Comment
+}
+
+// Generate renders posts and their comments into dir, which is created if
+// needed. Files already in dir are overwritten but not removed.
+//
+// Parameters:
+//   - site: blog title, base URL, and snapshot time
+//   - posts: every post, in any order
+//   - comments: comments keyed by post ID, in any order
+//   - dir: output directory
+//
+// Returns what was written.
+func Generate(site Site, posts []models.BlogPost, comments map[models.ID][]models.Comment, dir string) (Report, error) {
+	var report Report
+
+	// Newest first, like the API listing
+	posts = append([]models.BlogPost(nil), posts...)
+	sort.Slice(posts, func(i, j int) bool { return posts[i].CreatedAt.After(posts[j].CreatedAt) })
+
+	var listed []models.BlogPost
+	byTag := make(map[string][]models.BlogPost)
+	tagNames := make(map[string]string)
+	for _, post := range posts {
+		if !visibility.PAGES.Allows(post) {
+			report.Skipped++
+			continue
+		}
+
+		err := render(filepath.Join(dir, "posts", post.ID.String(), "index.html"), "post", page{
+			Site:      site,
+			PageTitle: post.Title + " - " + site.Title,
+			Root:      "../../",
+			Post:      post,
+			Comments:  threaded(comments[post.ID]),
+		})
+		if err != nil {
+			return report, err
+		}
+		report.Posts++
+
+		if !visibility.PUBLISHED.Allows(post) {
+			continue
+		}
+		listed = append(listed, post)
+		for _, tag := range post.Tags {
+			slug := Slug(tag)
+			if slug == "" {
+				continue
+			}
+			byTag[slug] = append(byTag[slug], post)
+			tagNames[slug] = tag
+		}
+	}
+	report.Listed = len(listed)
+
+	err := render(filepath.Join(dir, "index.html"), "index", page{
+		Site: site, PageTitle: site.Title, Root: "", Posts: listed,
+	})
+	if err != nil {
+		return report, err
+	}
+	if err := writeFeed(filepath.Join(dir, "feed.xml"), site, site.Title, listed); err != nil {
+		return report, err
+	}
+
+	for slug, tagged := range byTag {
+		tagDir := filepath.Join(dir, "tags", slug)
+		err := render(filepath.Join(tagDir, "index.html"), "tag", page{
+			Site:      site,
+			PageTitle: "#" + tagNames[slug] + " - " + site.Title,
+			Root:      "../../",
+			Posts:     tagged,
+			Tag:       tagNames[slug],
+		})
+		if err != nil {
+			return report, err
+		}
+		if err := writeFeed(filepath.Join(tagDir, "feed.xml"), site, site.Title+" #"+tagNames[slug], tagged); err != nil {
+			return report, err
+		}
+		report.Tags++
+	}
+
+	return report, nil
+}
+
+// Slug turns a tag into a path segment: lowercase letters and digits, with
+// runs of anything else collapsed to a single "-".
+func Slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// threaded orders comments so each reply follows its parent, oldest first
+// at every level. Replies whose parent is missing are shown top-level.
+func threaded(comments []models.Comment) []models.Comment {
+	present := make(map[models.ID]bool, len(comments))
+	for _, comment := range comments {
+		present[comment.ID] = true
+	}
+
+	sorted := append([]models.Comment(nil), comments...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].CreatedAt.Before(sorted[j].CreatedAt) })
+
+	var roots []models.Comment
+	replies := make(map[models.ID][]models.Comment)
+	for _, comment := range sorted {
+		if comment.ParentID.IsZero() || !present[comment.ParentID] {
+			comment.ParentID = ""
+			roots = append(roots, comment)
+		} else {
+			replies[comment.ParentID] = append(replies[comment.ParentID], comment)
+		}
+	}
+
+	ordered := make([]models.Comment, 0, len(comments))
+	var walk func(models.Comment)
+	walk = func(comment models.Comment) {
+		ordered = append(ordered, comment)
+		for _, reply := range replies[comment.ID] {
+			walk(reply)
+		}
+	}
+	for _, root := range roots {
+		walk(root)
+	}
+	return ordered
+}
+
+// render executes the named template into path, creating parent directories.
+func render(path, name string, data page) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pages.ExecuteTemplate(file, name, data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// atomFeed is the XML shape of an Atom feed.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomLink is an Atom link element.
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+// atomEntry is one post in an Atom feed.
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary,omitempty"`
+	Content string   `xml:"content"`
+}
+
+// writeFeed writes an Atom feed of the FEED_SIZE newest posts to path.
+func writeFeed(path string, site Site, title string, posts []models.BlogPost) error {
+	base := strings.TrimRight(site.BaseURL, "/") + "/"
+	if len(posts) > FEED_SIZE {
+		posts = posts[:FEED_SIZE]
+	}
+
+	feed := atomFeed{
+		Title:   title,
+		ID:      base,
+		Updated: site.GeneratedAt.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: base},
+	}
+	for _, post := range posts {
+		link := base + "posts/" + post.ID.String() + "/index.html"
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   post.Title,
+			ID:      link,
+			Updated: post.CreatedAt.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: link},
+			Summary: post.Excerpt,
+			Content: post.Content,
+		})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), out...), 0o644)
+}
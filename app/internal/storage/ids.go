@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Supported ID_FORMAT values.
+const (
+	ID_FORMAT_OBJECTID = "objectid" // 24-character hex MongoDB ObjectIDs
+	ID_FORMAT_UUIDV7   = "uuidv7"   // Time-ordered RFC 9562 UUIDs
+)
+
+// ErrInvalidID is returned when a raw ID does not match the active format.
+var ErrInvalidID = errors.New("invalid id")
+
+// IDCodec generates new primary keys and validates IDs received from
+// clients, so handlers and external systems never depend on one ID format.
+type IDCodec interface {
+	// New returns a fresh, unique ID.
+	New() models.ID
+	// Parse validates raw and returns it in canonical form, or ErrInvalidID.
+	Parse(raw string) (models.ID, error)
+}
+
+// NewIDCodec returns the codec for an ID_FORMAT value.
+//
+// Parameters:
+//   - format: ID_FORMAT_OBJECTID or ID_FORMAT_UUIDV7 (case-insensitive)
+//
+// Returns an error for unknown formats.
+func NewIDCodec(format string) (IDCodec, error) {
+	switch strings.ToLower(format) {
+	case ID_FORMAT_OBJECTID, "":
+		return ObjectIDCodec{}, nil
+	case ID_FORMAT_UUIDV7:
+		return UUIDv7Codec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown id format %q", format)
+	}
+}
+
+// ObjectIDCodec issues MongoDB ObjectIDs. It is the default format.
+type ObjectIDCodec struct{}
+
+// New returns a new ObjectID in hex form.
+func (ObjectIDCodec) New() models.ID {
+	return models.ID(primitive.NewObjectID().Hex())
+}
+
+// Parse accepts 24-character hex ObjectIDs.
+func (ObjectIDCodec) Parse(raw string) (models.ID, error) {
+	oid, err := primitive.ObjectIDFromHex(raw)
+	if err != nil {
+		return "", ErrInvalidID
+	}
+	return models.ID(oid.Hex()), nil
+}
+
+// UUIDv7Codec issues version 7 UUIDs: a 48-bit millisecond timestamp
+// followed by random bits, so IDs sort by creation time like ObjectIDs do
+// and fit native UUID columns in other databases.
+type UUIDv7Codec struct{}
+
+// New returns a new UUIDv7 in lowercase hyphenated form.
+func (UUIDv7Codec) New() models.ID {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		panic("storage: failed to read random bytes: " + err.Error())
+	}
+
+	// 48-bit big-endian Unix milliseconds, then version and variant bits
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(uuid[0:6], ms[2:8])
+	uuid[6] = uuid[6]&0x0f | 0x70
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	return models.ID(formatUUID(uuid))
+}
+
+// Parse accepts hyphenated version 7 UUIDs in either case.
+func (UUIDv7Codec) Parse(raw string) (models.ID, error) {
+	if len(raw) != 36 || raw[8] != '-' || raw[13] != '-' || raw[18] != '-' || raw[23] != '-' {
+		return "", ErrInvalidID
+	}
+
+	var uuid [16]byte
+	digits := strings.ReplaceAll(raw, "-", "")
+	if _, err := hex.Decode(uuid[:], []byte(digits)); err != nil {
+		return "", ErrInvalidID
+	}
+	if uuid[6]>>4 != 7 || uuid[8]>>6 != 0b10 {
+		return "", ErrInvalidID
+	}
+	return models.ID(formatUUID(uuid)), nil
+}
+
+// formatUUID renders 16 bytes as a lowercase 8-4-4-4-12 UUID string.
+func formatUUID(uuid [16]byte) string {
+	encoded := hex.EncodeToString(uuid[:])
+	return encoded[0:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:32]
+}
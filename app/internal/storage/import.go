@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ImportComments stores historical comments on a post, keeping their
+// original authors and timestamps, and updates the post's comment count.
+// Comments whose ImportID was already imported into the post are skipped.
+// Replies are linked to their parent through ParentImportID, which may
+// refer to a comment in the same batch or one imported earlier; a parent
+// that cannot be found leaves the reply top-level.
+//
+// Parameters:
+//   - ctx: context for the database operations
+//   - postID: ID of the post the comments belong to
+//   - comments: comments to import, validated by the caller
+//
+// Returns the number of comments imported and skipped.
+func (db *Storage) ImportComments(ctx context.Context, postID models.ID, comments []models.ImportedComment) (int, int, error) {
+	existing, err := db.importedCommentIDs(ctx, postID, comments)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Assign IDs up front so replies can point to parents later in the batch
+	ids := make(map[string]models.ID, len(existing)+len(comments))
+	for importID, id := range existing {
+		ids[importID] = id
+	}
+	skipped := 0
+	fresh := make([]models.ImportedComment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.ImportID != "" {
+			if _, ok := ids[comment.ImportID]; ok {
+				skipped++
+				continue
+			}
+			ids[comment.ImportID] = db.IDs.New()
+		}
+		fresh = append(fresh, comment)
+	}
+	if len(fresh) == 0 {
+		return 0, skipped, nil
+	}
+
+	docs := make([]any, 0, len(fresh))
+	for _, comment := range fresh {
+		id, ok := ids[comment.ImportID]
+		if !ok {
+			id = db.IDs.New()
+		}
+		docs = append(docs, models.Comment{
+			ID:        id,
+			PostID:    postID,
+			ParentID:  ids[comment.ParentImportID],
+			Author:    comment.Author,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+			ImportID:  comment.ImportID,
+		})
+	}
+
+	// Unordered, so a comment imported concurrently doesn't stop the rest
+	_, err = db.Comments.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	duplicates := 0
+	if err != nil {
+		if duplicates, err = duplicateWrites(err); err != nil {
+			return 0, skipped, err
+		}
+	}
+
+	imported := len(docs) - duplicates
+	if imported > 0 {
+		if err := db.RecordCommentChange(ctx, postID, int64(imported)); err != nil {
+			return imported, skipped + duplicates, err
+		}
+	}
+	return imported, skipped + duplicates, nil
+}
+
+// importedCommentIDs returns the IDs of comments on the post already
+// imported under an import ID used (as ImportID or ParentImportID) by
+// comments, keyed by import ID.
+func (db *Storage) importedCommentIDs(ctx context.Context, postID models.ID, comments []models.ImportedComment) (map[string]models.ID, error) {
+	importIDs := make([]string, 0, 2*len(comments))
+	for _, comment := range comments {
+		if comment.ImportID != "" {
+			importIDs = append(importIDs, comment.ImportID)
+		}
+		if comment.ParentImportID != "" {
+			importIDs = append(importIDs, comment.ParentImportID)
+		}
+	}
+	ids := make(map[string]models.ID)
+	if len(importIDs) == 0 {
+		return ids, nil
+	}
+
+	cursor, err := db.Comments.Find(ctx,
+		bson.M{"post_id": postID, "import_id": bson.M{"$in": importIDs}},
+		options.Find().SetProjection(bson.M{"_id": 1, "import_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.Comment
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, comment := range found {
+		ids[comment.ImportID] = comment.ID
+	}
+	return ids, nil
+}
+
+// duplicateWrites inspects an InsertMany error. When every failed write is
+// a duplicate key, it returns their number and a nil error; otherwise it
+// returns the original error.
+func duplicateWrites(err error) (int, error) {
+	bulkErr, ok := err.(mongo.BulkWriteException)
+	if !ok || bulkErr.WriteConcernError != nil {
+		return 0, err
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return 0, err
+		}
+	}
+	return len(bulkErr.WriteErrors), nil
+}
@@ -0,0 +1,395 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/content"
+	"github.com/pedrobertao/challenge-prosi/app/internal/i18n"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CSP_REPORT_RETENTION is how long CSP violation reports are kept.
+const CSP_REPORT_RETENTION = 30 * 24 * time.Hour
+
+// SEARCH_ALERT_RETENTION is how long saved search alerts are kept.
+const SEARCH_ALERT_RETENTION = 90 * 24 * time.Hour
+
+// POSTS_TEXT_INDEX is the text index behind GET /api/posts/search. Title
+// matches weigh ten times content matches. The index stems English words;
+// the posts' own language field holds free-form tags the index would
+// reject, so the language override points at an unused field.
+const POSTS_TEXT_INDEX = "posts_text"
+
+// collectionIndexes lists the indexes of one collection.
+type collectionIndexes struct {
+	coll    *mongo.Collection
+	indexes []mongo.IndexModel
+}
+
+// indexes returns the indexes required by the API's query patterns.
+//
+// Indexes required:
+//   - comments.(post_id, created_at, _id) - comment counts and date-ordered pages per post
+//   - comments.(post_id, import_id) - unique among imported comments
+//   - comments.import_id          - federated replies looked up by Note IRI
+//   - posts.(created_at, _id) (desc) - newest-first paginated listings
+//   - posts.(comment_count, _id) (desc) - engagement filters and most-discussed sorting
+//   - posts.(like_count, _id) (desc) - top-liked sorting
+//   - posts.(clap_count, _id) (desc) - most-clapped sorting and all-time top-clapped listing
+//   - posts.(title, _id)          - listings sorted by title
+//   - posts.view_count (desc)     - engagement filters on view count
+//   - posts.tags                  - tag filters (multikey)
+//   - posts.linked_posts          - backlinks of a post (multikey)
+//   - posts.(language, created_at, _id) (desc) - newest-first listings of one language
+//   - posts.category_id (sparse)  - category filters and in-use checks on category deletion
+//   - posts.publish_at (sparse)   - scheduled posts going live (see MongoPostRepository.LastModified)
+//   - posts text (title, content) - full-text search, see POSTS_TEXT_INDEX
+//   - likes.(post_id, user_key)   - unique, one like per requester and post
+//   - claps.(post_id, user_key)   - unique, one clap counter per requester and post
+//   - clap_counts.(day, post_id)  - unique, one counter per post and day; windowed top-clapped listing
+//   - reactions.(comment_id, user_key) - unique, one reaction per requester and comment
+//   - reactions.post_id           - reactions trashed with their post
+//   - translations.(post_id, lang) - unique, one translation per language
+//   - csp_reports.received_at     - TTL, reports expire after CSP_REPORT_RETENTION
+//   - users.username              - unique, one account per username
+//   - post_views.(post_id, day, referrer) - unique, one rollup counter per key
+//   - api_keys.key_hash           - unique, X-API-Key lookups
+//   - categories.slug             - unique, one category per slugified name
+//   - saved_searches.user_id      - a user's saved searches
+//   - search_alerts.(user_id, created_at, _id) - a user's alerts, newest first
+//   - search_alerts.created_at    - TTL, alerts expire after SEARCH_ALERT_RETENTION
+//   - trash.(deleted_at, _id) (desc) - trash listings, newest deleted first, and purges
+//   - trash.(kind, deleted_at, _id) (desc) - trash listings of one kind
+//   - trash_documents.trash_id    - documents moved with a trash item, on restore
+//   - trash_documents.deleted_at  - purges
+//   - audit_log.(created_at, _id) (desc) - audit log listings, newest first
+//   - audit_log.(action, created_at, _id) (desc) - audit log listings of one action
+//   - autosaves.(post_id, created_at, _id) (desc) - a post's draft snapshots, newest first
+func (db *Storage) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{db.APIKeys, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{db.Categories, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{db.SavedSearches, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		}},
+		{db.SearchAlerts, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+			{
+				Keys:    bson.D{{Key: "created_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(int32(SEARCH_ALERT_RETENTION.Seconds())),
+			},
+		}},
+		{db.Trash, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "kind", Value: 1}, {Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}}},
+		}},
+		{db.TrashRows, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "trash_id", Value: 1}}},
+			{Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+		}},
+		{db.AuditLog, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		}},
+		{db.Autosaves, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		}},
+		{db.PostViews, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "post_id", Value: 1}, {Key: "day", Value: 1}, {Key: "referrer", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{db.Users, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{db.CSPReports, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "received_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(CSP_REPORT_RETENTION.Seconds())),
+		}}},
+		{db.Translations, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "post_id", Value: 1}, {Key: "lang", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{db.Likes, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "post_id", Value: 1}, {Key: "user_key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{db.Claps, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "post_id", Value: 1}, {Key: "user_key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{db.ClapCounts, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "day", Value: 1}, {Key: "post_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{db.Reactions, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "comment_id", Value: 1}, {Key: "user_key", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "post_id", Value: 1}}},
+		}},
+		{db.Comments, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+			{
+				Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "import_id", Value: 1}},
+				Options: options.Index().
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"import_id": bson.M{"$exists": true}}),
+			},
+			{
+				Keys:    bson.D{{Key: "import_id", Value: 1}},
+				Options: options.Index().SetSparse(true),
+			},
+		}},
+		{db.Posts, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "comment_count", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "like_count", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "clap_count", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "view_count", Value: -1}}},
+			{Keys: bson.D{{Key: "tags", Value: 1}}},
+			{Keys: bson.D{{Key: "linked_posts", Value: 1}}},
+			{Keys: bson.D{{Key: "language", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+			{
+				Keys:    bson.D{{Key: "category_id", Value: 1}},
+				Options: options.Index().SetSparse(true),
+			},
+			{
+				Keys:    bson.D{{Key: "publish_at", Value: -1}},
+				Options: options.Index().SetSparse(true),
+			},
+			{
+				Keys: bson.D{{Key: "title", Value: "text"}, {Key: "content", Value: "text"}},
+				Options: options.Index().
+					SetName(POSTS_TEXT_INDEX).
+					SetWeights(bson.M{"title": 10, "content": 1}).
+					SetLanguageOverride("search_language"),
+			},
+		}},
+	}
+}
+
+// ensureIndexes creates the indexes returned by indexes. CreateMany is
+// idempotent, so this is safe to run on every startup.
+func (db *Storage) ensureIndexes(ctx context.Context) error {
+	for _, required := range db.indexes() {
+		if _, err := required.coll.Indexes().CreateMany(ctx, required.indexes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MissingIndexes lists the required indexes (see indexes) the database
+// lacks, as "collection.index_name", without creating them. Indexes are
+// matched by name, so an index rebuilt with other options still counts as
+// present.
+func (db *Storage) MissingIndexes(ctx context.Context) ([]string, error) {
+	var missing []string
+	for _, required := range db.indexes() {
+		specs, err := required.coll.Indexes().ListSpecifications(ctx)
+		if err != nil {
+			return nil, err
+		}
+		present := make(map[string]bool, len(specs))
+		for _, spec := range specs {
+			present[spec.Name] = true
+		}
+		for _, index := range required.indexes {
+			if name := indexName(index); !present[name] {
+				missing = append(missing, required.coll.Name()+"."+name)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// indexName returns the name MongoDB gives index: its explicit name, or
+// the keys and their directions joined by underscores, e.g.
+// "created_at_-1__id_-1".
+func indexName(index mongo.IndexModel) string {
+	if index.Options != nil && index.Options.Name != nil {
+		return *index.Options.Name
+	}
+	var parts []string
+	for _, key := range index.Keys.(bson.D) {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// Posts still to be visited by the startup backfills, which fill in fields
+// added after the posts were created.
+var (
+	missingCommentCount = bson.M{"comment_count": bson.M{"$exists": false}}
+	missingContentStats = bson.M{"stats": bson.M{"$exists": false}}
+	missingLinkedPosts  = bson.M{"linked_posts": bson.M{"$exists": false}}
+	missingLanguage     = bson.M{"language": bson.M{"$exists": false}}
+)
+
+// PendingBackfills counts the posts each startup backfill has yet to
+// update, by the field it fills in: "comment_count", "stats",
+// "linked_posts", and "language". All are 0 once Connect and
+// BackfillLinkedPosts have completed them.
+func (db *Storage) PendingBackfills(ctx context.Context) (map[string]int64, error) {
+	pending := make(map[string]int64, 4)
+	for field, filter := range map[string]bson.M{
+		"comment_count": missingCommentCount,
+		"stats":         missingContentStats,
+		"linked_posts":  missingLinkedPosts,
+		"language":      missingLanguage,
+	} {
+		count, err := db.Posts.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		pending[field] = count
+	}
+	return pending, nil
+}
+
+// backfillCommentCounts initializes the denormalized comment_count of posts
+// created before the counter existed. Only posts missing the field are
+// visited, so after the first run this is a single empty query.
+func (db *Storage) backfillCommentCounts(ctx context.Context) error {
+	cursor, err := db.Posts.Find(ctx,
+		missingCommentCount,
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post struct {
+			ID any `bson:"_id"`
+		}
+		if err := cursor.Decode(&post); err != nil {
+			return err
+		}
+		count, err := db.Comments.CountDocuments(ctx, VisibleComments(bson.M{"post_id": post.ID}))
+		if err != nil {
+			return err
+		}
+		if _, err := db.Posts.UpdateOne(ctx,
+			bson.M{"_id": post.ID},
+			bson.M{"$set": bson.M{"comment_count": count}},
+		); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// backfillContentStats computes the content statistics of posts created
+// before they were stored on save. Like backfillCommentCounts, only posts
+// missing the field are visited.
+func (db *Storage) backfillContentStats(ctx context.Context) error {
+	cursor, err := db.Posts.Find(ctx,
+		missingContentStats,
+		options.Find().SetProjection(bson.M{"_id": 1, "content": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post struct {
+			ID      any    `bson:"_id"`
+			Content string `bson:"content"`
+		}
+		if err := cursor.Decode(&post); err != nil {
+			return err
+		}
+		if _, err := db.Posts.UpdateOne(ctx,
+			bson.M{"_id": post.ID},
+			bson.M{"$set": bson.M{"stats": content.Analyze(post.Content)}},
+		); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// backfillLanguages detects the language of posts saved before it was
+// detected on save. Posts whose language cannot be told get an empty one,
+// so like the other backfills only posts missing the field are visited.
+func (db *Storage) backfillLanguages(ctx context.Context) error {
+	cursor, err := db.Posts.Find(ctx,
+		missingLanguage,
+		options.Find().SetProjection(bson.M{"_id": 1, "content": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post struct {
+			ID      any    `bson:"_id"`
+			Content string `bson:"content"`
+		}
+		if err := cursor.Decode(&post); err != nil {
+			return err
+		}
+		if _, err := db.Posts.UpdateOne(ctx,
+			bson.M{"_id": post.ID},
+			bson.M{"$set": bson.M{"language": i18n.Detect(content.PlainText(post.Content))}},
+		); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// BackfillLinkedPosts finds the post links of posts saved before they were
+// stored on save. Unlike the other backfills it runs after Connect, since
+// recognizing post links takes the public post URL from configuration.
+// Like backfillCommentCounts, only posts missing the field are visited.
+//
+// Parameters:
+//   - ctx: context for the database operations
+//   - linked: returns the posts the content of the post postID links to
+func (db *Storage) BackfillLinkedPosts(ctx context.Context, linked func(postID models.ID, text string) []models.ID) error {
+	cursor, err := db.Posts.Find(ctx,
+		missingLinkedPosts,
+		options.Find().SetProjection(bson.M{"_id": 1, "content": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post struct {
+			ID      models.ID `bson:"_id"`
+			Content string    `bson:"content"`
+		}
+		if err := cursor.Decode(&post); err != nil {
+			return err
+		}
+		if _, err := db.Posts.UpdateOne(ctx,
+			bson.M{"_id": post.ID},
+			bson.M{"$set": bson.M{"linked_posts": linked(post.ID, post.Content)}},
+		); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// POSTS_META_KEY identifies the meta document tracking when the post
+// listing last changed (posts created or deleted, comment counts changed).
+const POSTS_META_KEY = "posts"
+
+// metaDocument is the shape of a bookkeeping document in the meta collection.
+type metaDocument struct {
+	Key          string    `bson:"_id"`
+	LastModified time.Time `bson:"last_modified"`
+}
+
+// Touch records the current time as the last modification of the resource
+// identified by key. Uses $max so concurrent writers never move it backwards.
+//
+// Parameters:
+//   - ctx: context for the database operation
+//   - key: meta document identifier (e.g., POSTS_META_KEY)
+//
+// Returns error if the upsert fails.
+func (db *Storage) Touch(ctx context.Context, key string) error {
+	_, err := db.Meta.UpdateOne(ctx,
+		bson.M{"_id": key},
+		bson.M{"$max": bson.M{"last_modified": time.Now().UTC()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// LastModified returns the last modification time recorded for key.
+// A zero time (and no error) is returned when nothing was recorded yet.
+//
+// Parameters:
+//   - ctx: context for the database operation
+//   - key: meta document identifier (e.g., POSTS_META_KEY)
+func (db *Storage) LastModified(ctx context.Context, key string) (time.Time, error) {
+	var doc metaDocument
+	err := db.Meta.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	return doc.LastModified, err
+}
+
+// TouchPost bumps the last-modified time of a single post and of the post
+// listing as a whole. Call it after any write that changes what GET
+// /api/posts/:id or GET /api/posts would return for that post.
+//
+// Parameters:
+//   - ctx: context for the database operation
+//   - postID: ID of the post that changed
+func (db *Storage) TouchPost(ctx context.Context, postID models.ID) error {
+	if _, err := db.Posts.UpdateOne(ctx,
+		bson.M{"_id": postID},
+		bson.M{"$max": bson.M{"last_modified": time.Now().UTC()}},
+	); err != nil {
+		return err
+	}
+	return db.Touch(ctx, POSTS_META_KEY)
+}
+
+// RecordCommentChange applies delta to the post's denormalized comment_count
+// and bumps its last-modified time in a single update, then touches the
+// listing. Call it after a comment is created (+1) or deleted (-1).
+//
+// Parameters:
+//   - ctx: context for the database operation
+//   - postID: ID of the post the comment belongs to
+//   - delta: change in comment count
+func (db *Storage) RecordCommentChange(ctx context.Context, postID models.ID, delta int64) error {
+	return db.recordCounterChange(ctx, postID, "comment_count", delta)
+}
+
+// RecordLikeChange applies delta to the post's denormalized like_count and
+// bumps its last-modified time, then touches the listing. Call it after a
+// like is added (+1) or removed (-1).
+//
+// Parameters:
+//   - ctx: context for the database operation
+//   - postID: ID of the liked post
+//   - delta: change in like count
+func (db *Storage) RecordLikeChange(ctx context.Context, postID models.ID, delta int64) error {
+	return db.recordCounterChange(ctx, postID, "like_count", delta)
+}
+
+// RecordClaps adds claps to the post's denormalized clap_count and to its
+// counter for the current UTC day in clap_counts, then bumps the post's
+// last-modified time and touches the listing. The daily counters let
+// windowed top-clapped listings sum a few small documents per post instead
+// of scanning every requester's claps.
+//
+// Parameters:
+//   - ctx: context for the database operation
+//   - postID: ID of the applauded post
+//   - claps: number of claps added
+func (db *Storage) RecordClaps(ctx context.Context, postID models.ID, claps int64) error {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	filter := bson.M{"day": day, "post_id": postID}
+	update := bson.M{"$inc": bson.M{"claps": claps}}
+	_, err := db.ClapCounts.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent first clap of the day won the insert; add to it instead
+		_, err = db.ClapCounts.UpdateOne(ctx, filter, update)
+	}
+	if err != nil {
+		return err
+	}
+	return db.recordCounterChange(ctx, postID, "clap_count", claps)
+}
+
+// RecordReactionChange moves one reaction of the comment's denormalized
+// reactions counters from the type from to the type to, either of which is
+// empty when a reaction is added or removed, then bumps the last-modified
+// time of the comment's post.
+//
+// Parameters:
+//   - ctx: context for the database operation
+//   - comment: the comment reacted to; only ID and PostID are used
+//   - from: previous reaction type of the requester, or ""
+//   - to: new reaction type of the requester, or ""
+func (db *Storage) RecordReactionChange(ctx context.Context, comment models.Comment, from, to string) error {
+	if from == to {
+		return nil
+	}
+	inc := bson.M{}
+	if from != "" {
+		inc["reactions."+from] = -1
+	}
+	if to != "" {
+		inc["reactions."+to] = 1
+	}
+	if _, err := db.Comments.UpdateOne(ctx, bson.M{"_id": comment.ID}, bson.M{"$inc": inc}); err != nil {
+		return err
+	}
+	return db.TouchPost(ctx, comment.PostID)
+}
+
+// recordCounterChange increments a denormalized counter field on a post,
+// bumps the post's last-modified time, and touches the post listing.
+func (db *Storage) recordCounterChange(ctx context.Context, postID models.ID, field string, delta int64) error {
+	if _, err := db.Posts.UpdateOne(ctx,
+		bson.M{"_id": postID},
+		bson.M{
+			"$inc": bson.M{field: delta},
+			"$max": bson.M{"last_modified": time.Now().UTC()},
+		},
+	); err != nil {
+		return err
+	}
+	return db.Touch(ctx, POSTS_META_KEY)
+}
+
+// RecordView increments the denormalized view_count of a post.
+// Views do not bump last-modified times, so conditional GETs stay cacheable.
+//
+// Parameters:
+//   - ctx: context for the database operation
+//   - postID: ID of the post that was read
+func (db *Storage) RecordView(ctx context.Context, postID models.ID) error {
+	_, err := db.Posts.UpdateOne(ctx,
+		bson.M{"_id": postID},
+		bson.M{"$inc": bson.M{"view_count": 1}},
+	)
+	return err
+}
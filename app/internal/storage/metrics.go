@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// OperationStats reports the commands run against one collection with one
+// operation type since startup.
+type OperationStats struct {
+	Collection string  `json:"collection"` // Collection name, e.g. "posts"
+	Operation  string  `json:"operation"`  // Command name, e.g. "find", "insert", "aggregate"
+	Calls      int64   `json:"calls"`      // Commands completed, failed ones included
+	Errors     int64   `json:"errors"`     // Commands the server or network failed
+	ErrorRate  float64 `json:"error_rate"` // Errors divided by calls
+	TotalMs    float64 `json:"total_ms"`   // Summed round-trip time in milliseconds
+	AvgMs      float64 `json:"avg_ms"`     // Mean round-trip time in milliseconds
+	MaxMs      float64 `json:"max_ms"`     // Slowest round trip in milliseconds
+}
+
+// operationKey labels the commands counted together.
+type operationKey struct {
+	collection string
+	operation  string
+}
+
+// operationCounters accumulates the outcomes of one operationKey.
+type operationCounters struct {
+	calls  int64
+	errors int64
+	total  time.Duration
+	max    time.Duration
+}
+
+// OperationMetrics counts the commands the driver sends, labeled by the
+// collection they target and their operation type, so database hotspots
+// can be traced to the features issuing them. Commands that target no
+// collection, such as ping or the handshake, are not counted.
+type OperationMetrics struct {
+	mu       sync.Mutex
+	counters map[operationKey]*operationCounters
+	pending  sync.Map // connection and request ID -> operationKey of commands in flight
+}
+
+// NewOperationMetrics creates metrics with no commands counted.
+func NewOperationMetrics() *OperationMetrics {
+	return &OperationMetrics{counters: make(map[operationKey]*operationCounters)}
+}
+
+// Monitor returns the command monitor feeding m, for the client options.
+func (m *OperationMetrics) Monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			collection := commandCollection(e.CommandName, e.Command)
+			if collection == "" {
+				return
+			}
+			m.pending.Store(pendingKey(e.ConnectionID, e.RequestID), operationKey{collection: collection, operation: e.CommandName})
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			m.finish(e.CommandFinishedEvent, false)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			m.finish(e.CommandFinishedEvent, true)
+		},
+	}
+}
+
+// finish counts a completed command that was seen starting.
+func (m *OperationMetrics) finish(e event.CommandFinishedEvent, failed bool) {
+	value, ok := m.pending.LoadAndDelete(pendingKey(e.ConnectionID, e.RequestID))
+	if !ok {
+		return
+	}
+	key := value.(operationKey)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counters := m.counters[key]
+	if counters == nil {
+		counters = &operationCounters{}
+		m.counters[key] = counters
+	}
+	counters.calls++
+	if failed {
+		counters.errors++
+	}
+	counters.total += e.Duration
+	counters.max = max(counters.max, e.Duration)
+}
+
+// Snapshot returns the counters of every collection and operation seen,
+// sorted by collection, then operation. A nil m has none.
+func (m *OperationMetrics) Snapshot() []OperationStats {
+	if m == nil {
+		return []OperationStats{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]OperationStats, 0, len(m.counters))
+	for key, counters := range m.counters {
+		entry := OperationStats{
+			Collection: key.collection,
+			Operation:  key.operation,
+			Calls:      counters.calls,
+			Errors:     counters.errors,
+			TotalMs:    milliseconds(counters.total),
+			MaxMs:      milliseconds(counters.max),
+		}
+		if counters.calls > 0 {
+			entry.ErrorRate = float64(counters.errors) / float64(counters.calls)
+			entry.AvgMs = entry.TotalMs / float64(counters.calls)
+		}
+		stats = append(stats, entry)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Collection != stats[j].Collection {
+			return stats[i].Collection < stats[j].Collection
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
+
+// commandCollection returns the collection a command targets: the value
+// of its first element, e.g. {find: "posts"}, or of the collection field
+// for getMore. Returns "" for commands without one.
+func commandCollection(name string, command bson.Raw) string {
+	field := name
+	if name == "getMore" {
+		field = "collection"
+	}
+	collection, ok := command.Lookup(field).StringValueOK()
+	if !ok {
+		return ""
+	}
+	return collection
+}
+
+// pendingKey identifies a command in flight; request IDs are only unique
+// per connection.
+func pendingKey(connectionID string, requestID int64) string {
+	return connectionID + "/" + strconv.FormatInt(requestID, 10)
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -18,32 +18,107 @@ type Storage struct {
 	Client   *mongo.Client     // MongoDB client for database operations
 	Posts    *mongo.Collection // Collection for blog posts
 	Comments *mongo.Collection // Collection for post comments
+	Meta     *mongo.Collection // Collection for bookkeeping such as last-modified times
+	Likes    *mongo.Collection // Collection for per-requester post likes
+	Claps    *mongo.Collection // Collection for per-requester post claps
+
+	ClapCounts *mongo.Collection // Collection for daily per-post clap counters
+
+	Reactions *mongo.Collection // Collection for per-requester comment reactions
+
+	Duplicates   *mongo.Collection // Collection for near-duplicate post matches
+	Translations *mongo.Collection // Collection for localized post title/content
+	Locks        *mongo.Collection // Collection for background job leases
+	Followers    *mongo.Collection // Collection for ActivityPub followers
+	Integrations *mongo.Collection // Collection for chat integrations
+	CSPReports   *mongo.Collection // Collection for sampled CSP violation reports
+	Users        *mongo.Collection // Collection for registered user accounts
+	PostViews    *mongo.Collection // Collection for daily per-referrer view rollups
+	APIKeys      *mongo.Collection // Collection for service-to-service API keys
+	BrokenLinks  *mongo.Collection // Collection for the latest link check results
+	PostCards    *mongo.Collection // Collection for rendered social-card images
+	Categories   *mongo.Collection // Collection for post categories
+
+	SavedSearches *mongo.Collection // Collection for users' saved searches
+	SearchAlerts  *mongo.Collection // Collection for posts matching saved searches
+	Trash         *mongo.Collection // Collection for deleted posts and comments awaiting purge
+	TrashRows     *mongo.Collection // Collection for the documents moved out with each trash item
+	AuditLog      *mongo.Collection // Collection for administrative events such as content freezes
+	Autosaves     *mongo.Collection // Collection for draft snapshots saved by editors
+
+	IDs     IDCodec           // Generates and validates primary keys
+	Metrics *OperationMetrics // Per-collection command counts and durations
 }
 
 // Connect establishes a connection to MongoDB and initializes the Storage struct.
-// It creates a new MongoDB client, tests the connection with a ping, and sets up
-// collection references for posts and comments.
+// It opens the connection (see Open), then makes sure the indexes and
+// counters the handlers rely on are in place.
 //
 // Connection process:
-//  1. Creates MongoDB client with provided URI
+//  1. Creates MongoDB client with provided URI and read routing
 //  2. Tests connection with ping operation
 //  3. Initializes database and collection references
-//  4. Returns configured Storage instance
+//  4. Creates required indexes, backfills missing counters, content stats,
+//     and languages, and splits legacy trash entries
+//  5. Returns configured Storage instance
 //
 // Parameters:
 //   - uri: MongoDB connection string (e.g., "mongodb://localhost:27017")
 //   - dbName: name of the database to use (e.g., "blog")
+//   - ids: codec used for new primary keys and ID validation (see NewIDCodec)
+//   - routing: which replica set members serve reads (see ReadRouting)
 //
 // Returns:
 //   - *Storage: configured storage instance with active connections
 //   - error: connection error if any step fails
-func Connect(uri, dbName string) (*Storage, error) {
+func Connect(uri, dbName string, ids IDCodec, routing ReadRouting) (*Storage, error) {
+	storage, err := Open(uri, dbName, ids, routing)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Make sure the indexes and counters the handlers rely on are in place
+	if err := storage.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+	if err := storage.backfillCommentCounts(ctx); err != nil {
+		return nil, err
+	}
+	if err := storage.backfillContentStats(ctx); err != nil {
+		return nil, err
+	}
+	if err := storage.backfillLanguages(ctx); err != nil {
+		return nil, err
+	}
+	if err := storage.splitTrashEntries(ctx); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+// Open connects to MongoDB like Connect, steps 1 to 3, without writing to
+// the database: no indexes are created and nothing is backfilled, so the
+// --check self-check reports what Connect would change.
+//
+// Parameters: as for Connect.
+func Open(uri, dbName string, ids IDCodec, routing ReadRouting) (*Storage, error) {
 	// Create context with timeout to prevent hanging connections
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Settings applied after the URI take precedence over its options
+	opts := options.Client().ApplyURI(uri)
+	if err := routing.apply(opts); err != nil {
+		return nil, err
+	}
+	metrics := NewOperationMetrics()
+	opts.SetMonitor(combineMonitors(metrics.Monitor(), (&CommandTracer{}).Monitor()))
+
 	// Establish connection to MongoDB server
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -55,15 +130,70 @@ func Connect(uri, dbName string) (*Storage, error) {
 
 	// Get database reference and collection handles
 	db := client.Database(dbName)
-	postsCol := db.Collection("posts")       // Collection for blog posts
-	commentsCol := db.Collection("comments") // Collection for post comments
+	postsCol := db.Collection("posts")                  // Collection for blog posts
+	commentsCol := db.Collection("comments")            // Collection for post comments
+	metaCol := db.Collection("meta")                    // Collection for bookkeeping documents
+	likesCol := db.Collection("likes")                  // Collection for post likes
+	clapsCol := db.Collection("claps")                  // Collection for post claps
+	clapCountsCol := db.Collection("clap_counts")       // Collection for clap counters
+	duplicatesCol := db.Collection("duplicates")        // Collection for near-duplicate matches
+	translationsCol := db.Collection("translations")    // Collection for post translations
+	locksCol := db.Collection("locks")                  // Collection for job leases
+	followersCol := db.Collection("followers")          // Collection for ActivityPub followers
+	integrationsCol := db.Collection("integrations")    // Collection for chat integrations
+	cspReportsCol := db.Collection("csp_reports")       // Collection for CSP violation reports
+	usersCol := db.Collection("users")                  // Collection for user accounts
+	postViewsCol := db.Collection("post_views")         // Collection for view rollups
+	apiKeysCol := db.Collection("api_keys")             // Collection for API keys
+	brokenLinksCol := db.Collection("broken_links")     // Collection for broken links
+	postCardsCol := db.Collection("post_cards")         // Collection for social cards
+	categoriesCol := db.Collection("categories")        // Collection for post categories
+	savedSearchesCol := db.Collection("saved_searches") // Collection for saved searches
+	searchAlertsCol := db.Collection("search_alerts")   // Collection for saved search matches
+	trashCol := db.Collection("trash")                  // Collection for deleted posts and comments
+	trashRowsCol := db.Collection("trash_documents")    // Collection for documents trashed with them
+	reactionsCol := db.Collection("reactions")          // Collection for comment reactions
+	auditLogCol := db.Collection("audit_log")           // Collection for audit entries
+	autosavesCol := db.Collection("autosaves")          // Collection for draft snapshots
 
-	// Return configured Storage instance with all references
-	return &Storage{
+	storage := &Storage{
 		Client:   client,
 		Posts:    postsCol,
 		Comments: commentsCol,
-	}, nil
+		Meta:     metaCol,
+		Likes:    likesCol,
+		Claps:    clapsCol,
+
+		ClapCounts: clapCountsCol,
+
+		Reactions: reactionsCol,
+
+		Duplicates:   duplicatesCol,
+		Translations: translationsCol,
+		Locks:        locksCol,
+		Followers:    followersCol,
+		Integrations: integrationsCol,
+		CSPReports:   cspReportsCol,
+		Users:        usersCol,
+		PostViews:    postViewsCol,
+		APIKeys:      apiKeysCol,
+		BrokenLinks:  brokenLinksCol,
+		PostCards:    postCardsCol,
+		Categories:   categoriesCol,
+
+		SavedSearches: savedSearchesCol,
+		SearchAlerts:  searchAlertsCol,
+		Trash:         trashCol,
+		TrashRows:     trashRowsCol,
+		AuditLog:      auditLogCol,
+		Autosaves:     autosavesCol,
+
+		IDs:     ids,
+		Metrics: metrics,
+	}
+
+	// Return configured Storage instance with all references
+	return storage, nil
 }
 
 // Close gracefully shuts down the MongoDB connection.
@@ -78,3 +208,44 @@ func (db *Storage) Close(ctx context.Context) error {
 	// Disconnect the MongoDB client and clean up resources
 	return db.Client.Disconnect(ctx)
 }
+
+// Transaction runs fn in a multi-document transaction, so its writes are
+// committed together or not at all. The driver runs fn again on transient
+// errors, so it must not keep state from an earlier run. Transactions need
+// a replica set or sharded cluster; a standalone server fails them.
+// Called with the context of a running transaction, fn joins it instead
+// of starting a second one, so repositories can be transactional on their
+// own and still take part in a caller's transaction.
+//
+// Parameters:
+//   - ctx: context for the transaction, bounding every retry
+//   - fn: the operations to run; they must use the context passed to fn
+//
+// Returns the error of fn, or of committing the transaction.
+func (db *Storage) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := db.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// Collections returns every collection the application uses, in field order.
+func (db *Storage) Collections() []*mongo.Collection {
+	return []*mongo.Collection{
+		db.Posts, db.Comments, db.Meta, db.Likes, db.Reactions,
+		db.Duplicates, db.Translations, db.Locks, db.Followers, db.Integrations,
+		db.CSPReports, db.Users, db.PostViews, db.APIKeys, db.BrokenLinks,
+		db.PostCards, db.Categories, db.SavedSearches, db.SearchAlerts,
+		db.Trash, db.TrashRows, db.AuditLog, db.Autosaves, db.Claps, db.ClapCounts,
+	}
+}
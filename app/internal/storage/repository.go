@@ -0,0 +1,744 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// PostRepository is the persistence of blog posts behind the core post and
+// comment handlers, so they can be tested against mocks instead of a
+// database. Missing posts are reported as mongo.ErrNoDocuments.
+type PostRepository interface {
+	// Count returns the number of posts matching filter.
+	Count(ctx context.Context, filter bson.M) (int64, error)
+	// List returns the headers of the posts matching filter in sort order,
+	// after skipping skip of them and returning at most limit.
+	List(ctx context.Context, filter bson.M, sort bson.D, skip, limit int64) ([]models.BlogPostHeader, error)
+	// Stream opens a cursor over the posts matching filter, for listings
+	// and exports streamed as NDJSON; the caller closes it.
+	Stream(ctx context.Context, filter bson.M, opts *options.FindOptions) (*mongo.Cursor, error)
+	// Get returns a post with one page of its comments.
+	Get(ctx context.Context, id models.ID, comments CommentPage) (models.BlogPost, error)
+	// Exists reports whether a post with id exists.
+	Exists(ctx context.Context, id models.ID) (bool, error)
+	// Published reports whether a post with id exists and is served by the
+	// public read paths: neither private nor scheduled for later.
+	Published(ctx context.Context, id models.ID) (bool, error)
+	// CommentsLocked reports whether a post's comments are closed.
+	CommentsLocked(ctx context.Context, id models.ID) (bool, error)
+	// Insert stores a new post.
+	Insert(ctx context.Context, post models.BlogPost) error
+	// Update applies a MongoDB update document and returns the updated post.
+	Update(ctx context.Context, id models.ID, update bson.M) (models.BlogPost, error)
+	// Delete moves a post with its comments, likes, reactions, and
+	// translations to the trash.
+	Delete(ctx context.Context, id models.ID) error
+	// Restore moves a trashed post with its comments, likes, reactions,
+	// and translations back; missing trash items are mongo.ErrNoDocuments.
+	Restore(ctx context.Context, id models.ID) error
+	// Liked returns which of postIDs the requester userKey has liked.
+	Liked(ctx context.Context, userKey string, postIDs []models.ID) (map[models.ID]bool, error)
+	// Likes returns one page of a post's likes, newest first, and the
+	// total number of likes of the post.
+	Likes(ctx context.Context, id models.ID, skip, limit int64) ([]models.Like, int64, error)
+
+	// LastModified returns when the post listing last changed.
+	LastModified(ctx context.Context) (time.Time, error)
+	// Touch records that the post listing changed.
+	Touch(ctx context.Context) error
+	// TouchPost records that a post changed, which also changes the listing.
+	TouchPost(ctx context.Context, id models.ID) error
+	// RecordCommentChange applies delta to the post's comment counter.
+	RecordCommentChange(ctx context.Context, id models.ID, delta int64) error
+	// RecordView counts a read of the post.
+	RecordView(ctx context.Context, id models.ID) error
+	// RecordTitleImpressions counts one impression of the title variant
+	// shown, per post.
+	RecordTitleImpressions(ctx context.Context, shown map[models.ID]int) error
+	// RecordTitleClick counts a click on a post's title variant.
+	RecordTitleClick(ctx context.Context, id models.ID, variant int) error
+}
+
+// CommentRepository is the persistence of comments behind the core comment
+// handlers. Missing comments are reported as mongo.ErrNoDocuments.
+type CommentRepository interface {
+	// CountByPost returns the number of comments on a post.
+	CountByPost(ctx context.Context, postID models.ID) (int64, error)
+	// List returns one page of the comments on a post.
+	List(ctx context.Context, postID models.ID, page CommentPage) ([]models.Comment, error)
+	// Summarize returns the statistics of the discussion under a post.
+	Summarize(ctx context.Context, postID models.ID) (models.CommentThreadSummary, error)
+	// Get returns a comment.
+	Get(ctx context.Context, id models.ID) (models.Comment, error)
+	// Insert stores a new comment.
+	Insert(ctx context.Context, comment models.Comment) error
+	// UpdateContent replaces a comment's content, records editedAt, and
+	// returns the updated comment.
+	UpdateContent(ctx context.Context, id models.ID, content string, editedAt time.Time) (models.Comment, error)
+	// Delete moves a comment with every reply below it to the trash and
+	// returns it with the number of visible comments removed, replies
+	// included.
+	Delete(ctx context.Context, id models.ID) (models.Comment, int64, error)
+	// Restore moves a trashed comment back with the replies trashed along
+	// with it and returns it with the number of visible comments restored,
+	// or ErrPostMissing or ErrParentMissing if its post or parent is gone.
+	Restore(ctx context.Context, id models.ID) (models.Comment, int64, error)
+	// Moderate sets a comment's moderation status, recording who set it
+	// and when, and returns the comment as it was before.
+	Moderate(ctx context.Context, id models.ID, status, moderatedBy string, at time.Time) (models.Comment, error)
+}
+
+// VisibleComments restricts a comments filter to the comments shown to
+// readers, leaving out those with a hidden moderation status (see
+// models.HiddenCommentStatuses), and returns it.
+//
+// Parameters:
+//   - filter: comments filter, modified in place
+func VisibleComments(filter bson.M) bson.M {
+	filter["status"] = bson.M{"$nin": models.HiddenCommentStatuses}
+	return filter
+}
+
+// CommentPage selects a page of a post's comments: at most Limit comments
+// after skipping Skip, by creation time.
+type CommentPage struct {
+	Skip        int64 // Comments skipped from the start of the ordering
+	Limit       int64 // Maximum comments returned
+	NewestFirst bool  // Order newest first instead of oldest first
+}
+
+// Sort returns the MongoDB sort spec of the page's ordering. _id breaks
+// creation time ties so consecutive pages never overlap.
+func (p CommentPage) Sort() bson.D {
+	direction := 1
+	if p.NewestFirst {
+		direction = -1
+	}
+	return bson.D{{Key: "created_at", Value: direction}, {Key: "_id", Value: direction}}
+}
+
+// PostHeaderProjection restricts list queries to the fields decoded into
+// models.BlogPostHeader, leaving post content on the server.
+var PostHeaderProjection = bson.M{"title": 1, "created_at": 1, "comment_count": 1, "like_count": 1, "clap_count": 1, "language": 1, "title_variants": 1}
+
+// MongoPostRepository implements PostRepository on the posts collection.
+type MongoPostRepository struct {
+	DB *Storage // Database storage instance for MongoDB operations
+}
+
+// NewPostRepository creates the MongoDB post repository.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+func NewPostRepository(db *Storage) *MongoPostRepository {
+	return &MongoPostRepository{DB: db}
+}
+
+// Count returns the number of posts matching filter.
+func (r *MongoPostRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	return r.DB.Posts.CountDocuments(ctx, filter)
+}
+
+// List returns one page of post headers matching filter in sort order.
+// sort should end with _id so posts with equal keys never appear on two
+// consecutive pages.
+func (r *MongoPostRepository) List(ctx context.Context, filter bson.M, sort bson.D, skip, limit int64) ([]models.BlogPostHeader, error) {
+	opts := options.Find().
+		SetProjection(PostHeaderProjection).
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit)
+	cursor, err := r.DB.Posts.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	headers := []models.BlogPostHeader{}
+	for cursor.Next(ctx) {
+		var header models.BlogPostHeader
+		if err := cursor.Decode(&header); err != nil {
+			logger.Warn("malformed post", zap.Error(err))
+			// Skip malformed posts and continue reading
+			continue
+		}
+		headers = append(headers, header)
+	}
+	return headers, cursor.Err()
+}
+
+// postWithComments is the decoding target for the Get aggregation.
+// BlogPost.Comments is excluded from BSON, so the joined comments are
+// decoded into a sibling field and copied over afterwards.
+type postWithComments struct {
+	models.BlogPost `bson:",inline"`
+	Comments        []models.Comment `bson:"comments"`
+}
+
+// Get matches the post and joins one page of its comments in one
+// aggregation.
+func (r *MongoPostRepository) Get(ctx context.Context, id models.ID, comments CommentPage) (models.BlogPost, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": id}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": r.DB.Comments.Name(),
+			"let":  bson.M{"postId": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": VisibleComments(bson.M{"$expr": bson.M{"$eq": bson.A{"$post_id", "$$postId"}}})},
+				bson.M{"$sort": comments.Sort()},
+				bson.M{"$skip": comments.Skip},
+				bson.M{"$limit": comments.Limit},
+			},
+			"as": "comments",
+		}}},
+	}
+
+	cursor, err := r.DB.Posts.Aggregate(ctx, pipeline)
+	if err != nil {
+		return models.BlogPost{}, err
+	}
+	defer cursor.Close(ctx)
+
+	// The pipeline yields at most one document; no document means no post
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			return models.BlogPost{}, err
+		}
+		return models.BlogPost{}, mongo.ErrNoDocuments
+	}
+
+	var result postWithComments
+	if err := cursor.Decode(&result); err != nil {
+		return models.BlogPost{}, err
+	}
+	post := result.BlogPost
+	post.Comments = result.Comments
+	return post, nil
+}
+
+// Stream opens a cursor over the posts matching filter with opts. The
+// cursor is read after the request handler returns, so ctx must outlive it.
+func (r *MongoPostRepository) Stream(ctx context.Context, filter bson.M, opts *options.FindOptions) (*mongo.Cursor, error) {
+	return r.DB.Posts.Find(ctx, filter, opts)
+}
+
+// Exists reports whether a post with id exists.
+func (r *MongoPostRepository) Exists(ctx context.Context, id models.ID) (bool, error) {
+	count, err := r.DB.Posts.CountDocuments(ctx, bson.M{"_id": id}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// Published reports whether a post with id exists and is neither private
+// nor scheduled for later (see visibility.Unpublished).
+func (r *MongoPostRepository) Published(ctx context.Context, id models.ID) (bool, error) {
+	filter := bson.M{"_id": id, "$nor": bson.A{visibility.Unpublished(time.Now())}}
+	count, err := r.DB.Posts.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// CommentsLocked reads the post's comment lock flag.
+func (r *MongoPostRepository) CommentsLocked(ctx context.Context, id models.ID) (bool, error) {
+	var post struct {
+		Locked bool `bson:"comments_locked"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"comments_locked": 1})
+	err := r.DB.Posts.FindOne(ctx, bson.M{"_id": id}, opts).Decode(&post)
+	return post.Locked, err
+}
+
+// Insert stores a new post.
+func (r *MongoPostRepository) Insert(ctx context.Context, post models.BlogPost) error {
+	_, err := r.DB.Posts.InsertOne(ctx, post)
+	return err
+}
+
+// Update applies update to the post and returns the post after the update.
+func (r *MongoPostRepository) Update(ctx context.Context, id models.ID, update bson.M) (models.BlogPost, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var post models.BlogPost
+	err := r.DB.Posts.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&post)
+	return post, err
+}
+
+// Delete moves the post to the trash with everything attached to it, one
+// trash row per document, so no comments, likes, claps, reactions,
+// translations, or autosaves are left orphaned and a busy post never has to
+// fit in one document. Everything moves in one transaction; the social card
+// is dropped and rendered again on restore.
+// Daily clap counters stay behind: the post's clap_count travels with it,
+// and top-clapped listings skip counters of posts that are gone.
+func (r *MongoPostRepository) Delete(ctx context.Context, id models.ID) error {
+	return r.DB.Transaction(ctx, func(tx context.Context) error {
+		raw, err := r.DB.Posts.FindOne(tx, bson.M{"_id": id}).Raw()
+		if err != nil {
+			return err
+		}
+		var post models.BlogPost
+		if err := bson.Unmarshal(raw, &post); err != nil {
+			return err
+		}
+
+		item := models.TrashItem{ID: id, Kind: models.TRASH_POST, Title: post.Title, DeletedAt: time.Now()}
+		if err := r.DB.putInTrash(tx, trashEntry{TrashItem: item, Document: raw}); err != nil {
+			return err
+		}
+		for _, coll := range []*mongo.Collection{r.DB.Comments, r.DB.Likes, r.DB.Claps, r.DB.Reactions, r.DB.Translations, r.DB.Autosaves} {
+			if err := r.DB.moveToTrash(tx, item, coll, bson.M{"post_id": id}); err != nil {
+				return err
+			}
+		}
+		if _, err := r.DB.PostCards.DeleteOne(tx, bson.M{"_id": id}); err != nil {
+			return err
+		}
+		_, err = r.DB.Posts.DeleteOne(tx, bson.M{"_id": id})
+		return err
+	})
+}
+
+// Restore moves the trashed post back with everything trashed with it, in
+// one transaction.
+func (r *MongoPostRepository) Restore(ctx context.Context, id models.ID) error {
+	return r.DB.Transaction(ctx, func(tx context.Context) error {
+		entry, err := r.DB.takeFromTrash(tx, id, models.TRASH_POST)
+		if err != nil {
+			return err
+		}
+		if _, err := r.DB.Posts.InsertOne(tx, entry.Document); err != nil {
+			return err
+		}
+		_, err = r.DB.restoreRows(tx, id)
+		return err
+	})
+}
+
+// Liked looks up the requester's likes of postIDs in one query over the
+// unique likes index.
+func (r *MongoPostRepository) Liked(ctx context.Context, userKey string, postIDs []models.ID) (map[models.ID]bool, error) {
+	filter := bson.M{"user_key": userKey, "post_id": bson.M{"$in": postIDs}}
+	opts := options.Find().SetProjection(bson.M{"post_id": 1})
+	cursor, err := r.DB.Likes.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var likes []models.Like
+	if err := cursor.All(ctx, &likes); err != nil {
+		return nil, err
+	}
+	liked := make(map[models.ID]bool, len(likes))
+	for _, like := range likes {
+		liked[like.PostID] = true
+	}
+	return liked, nil
+}
+
+// Likes reads one page of the post's likes, newest first, and counts them
+// all for the page metadata.
+func (r *MongoPostRepository) Likes(ctx context.Context, id models.ID, skip, limit int64) ([]models.Like, int64, error) {
+	filter := bson.M{"post_id": id}
+	total, err := r.DB.Likes.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	cursor, err := r.DB.Likes.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	likes := []models.Like{}
+	if err := cursor.All(ctx, &likes); err != nil {
+		return nil, 0, err
+	}
+	return likes, total, nil
+}
+
+// LastModified returns when the post listing last changed.
+//
+// Scheduled posts change the listing when they go live without any write,
+// so the latest publish_at already passed counts as a change too.
+func (r *MongoPostRepository) LastModified(ctx context.Context) (time.Time, error) {
+	lastModified, err := r.DB.LastModified(ctx, POSTS_META_KEY)
+	if err != nil {
+		return lastModified, err
+	}
+
+	var published struct {
+		PublishAt time.Time `bson:"publish_at"`
+	}
+	opts := options.FindOne().
+		SetSort(bson.M{"publish_at": -1}).
+		SetProjection(bson.M{"publish_at": 1})
+	err = r.DB.Posts.FindOne(ctx, bson.M{"publish_at": bson.M{"$lte": time.Now()}}, opts).Decode(&published)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return lastModified, err
+	}
+	if published.PublishAt.After(lastModified) {
+		lastModified = published.PublishAt
+	}
+	return lastModified, nil
+}
+
+// Touch records that the post listing changed.
+func (r *MongoPostRepository) Touch(ctx context.Context) error {
+	return r.DB.Touch(ctx, POSTS_META_KEY)
+}
+
+// TouchPost records that a post changed.
+func (r *MongoPostRepository) TouchPost(ctx context.Context, id models.ID) error {
+	return r.DB.TouchPost(ctx, id)
+}
+
+// RecordCommentChange applies delta to the post's comment counter.
+func (r *MongoPostRepository) RecordCommentChange(ctx context.Context, id models.ID, delta int64) error {
+	return r.DB.RecordCommentChange(ctx, id, delta)
+}
+
+// RecordView counts a read of the post.
+func (r *MongoPostRepository) RecordView(ctx context.Context, id models.ID) error {
+	return r.DB.RecordView(ctx, id)
+}
+
+// RecordTitleImpressions increments the impression counters of every shown
+// variant in one unordered bulk write.
+func (r *MongoPostRepository) RecordTitleImpressions(ctx context.Context, shown map[models.ID]int) error {
+	if len(shown) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, 0, len(shown))
+	for id, variant := range shown {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$inc": bson.M{titleTestField(variant, "impressions"): 1}}))
+	}
+	_, err := r.DB.Posts.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// RecordTitleClick increments the click counter of a post's variant.
+func (r *MongoPostRepository) RecordTitleClick(ctx context.Context, id models.ID, variant int) error {
+	_, err := r.DB.Posts.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{titleTestField(variant, "clicks"): 1}},
+	)
+	return err
+}
+
+// titleTestField is the path of one counter of a variant in title_test.
+func titleTestField(variant int, counter string) string {
+	return "title_test." + strconv.Itoa(variant) + "." + counter
+}
+
+// MongoCommentRepository implements CommentRepository on the comments
+// collection.
+type MongoCommentRepository struct {
+	DB *Storage // Database storage instance for MongoDB operations
+}
+
+// NewCommentRepository creates the MongoDB comment repository.
+//
+// Parameters:
+//   - db: pointer to a Storage instance for database operations
+func NewCommentRepository(db *Storage) *MongoCommentRepository {
+	return &MongoCommentRepository{DB: db}
+}
+
+// CountByPost returns the number of visible comments on a post.
+func (r *MongoCommentRepository) CountByPost(ctx context.Context, postID models.ID) (int64, error) {
+	return r.DB.Comments.CountDocuments(ctx, VisibleComments(bson.M{"post_id": postID}))
+}
+
+// Summarize computes the statistics of a post's visible comments in one
+// aggregation: each facet runs over the comments matched once through the
+// (post_id, created_at, _id) index. Participants are told apart by author
+// name, the only identity comments carry, and hours are UTC clock hours.
+// Ties go to the earliest hour and the oldest comment.
+func (r *MongoCommentRepository) Summarize(ctx context.Context, postID models.ID) (models.CommentThreadSummary, error) {
+	summary := models.CommentThreadSummary{PostID: postID}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: VisibleComments(bson.M{"post_id": postID})}},
+		{{Key: "$facet", Value: bson.M{
+			"totals": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":      nil,
+					"comments": bson.M{"$sum": 1},
+					"replies":  bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$ifNull": bson.A{"$parent_id", false}}, 1, 0}}},
+					"first":    bson.M{"$min": "$created_at"},
+					"last":     bson.M{"$max": "$created_at"},
+				}},
+			},
+			"participants": bson.A{
+				bson.M{"$group": bson.M{"_id": "$author"}},
+				bson.M{"$count": "count"},
+			},
+			"hours": bson.A{
+				bson.M{"$group": bson.M{
+					"_id": bson.M{"$dateFromParts": bson.M{
+						"year":  bson.M{"$year": "$created_at"},
+						"month": bson.M{"$month": "$created_at"},
+						"day":   bson.M{"$dayOfMonth": "$created_at"},
+						"hour":  bson.M{"$hour": "$created_at"},
+					}},
+					"comments": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.D{{Key: "comments", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": 1},
+			},
+			"most_reacted": bson.A{
+				bson.M{"$addFields": bson.M{"reaction_count": bson.M{"$sum": bson.M{"$map": bson.M{
+					"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$reactions", bson.M{}}}},
+					"in":    "$$this.v",
+				}}}}},
+				bson.M{"$match": bson.M{"reaction_count": bson.M{"$gt": 0}}},
+				bson.M{"$sort": bson.D{{Key: "reaction_count", Value: -1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": 1},
+			},
+		}}},
+	}
+	cursor, err := r.DB.Comments.Aggregate(ctx, pipeline)
+	if err != nil {
+		return summary, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Totals []struct {
+			Comments int64     `bson:"comments"`
+			Replies  int64     `bson:"replies"`
+			First    time.Time `bson:"first"`
+			Last     time.Time `bson:"last"`
+		} `bson:"totals"`
+		Participants []struct {
+			Count int64 `bson:"count"`
+		} `bson:"participants"`
+		Hours       []models.CommentHour    `bson:"hours"`
+		MostReacted []models.ReactedComment `bson:"most_reacted"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return summary, err
+	}
+	if len(facets) != 1 || len(facets[0].Totals) == 0 {
+		return summary, nil
+	}
+
+	totals := facets[0].Totals[0]
+	summary.Comments, summary.Replies = totals.Comments, totals.Replies
+	summary.TopLevel = totals.Comments - totals.Replies
+	summary.FirstCommentAt, summary.LastCommentAt = &totals.First, &totals.Last
+	if len(facets[0].Participants) > 0 {
+		summary.Participants = facets[0].Participants[0].Count
+	}
+	if len(facets[0].Hours) > 0 {
+		summary.BusiestHour = &facets[0].Hours[0]
+	}
+	if len(facets[0].MostReacted) > 0 {
+		summary.MostReacted = &facets[0].MostReacted[0]
+	}
+	return summary, nil
+}
+
+// List returns one page of the visible comments on a post.
+func (r *MongoCommentRepository) List(ctx context.Context, postID models.ID, page CommentPage) ([]models.Comment, error) {
+	opts := options.Find().
+		SetSort(page.Sort()).
+		SetSkip(page.Skip).
+		SetLimit(page.Limit)
+	cursor, err := r.DB.Comments.Find(ctx, VisibleComments(bson.M{"post_id": postID}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []models.Comment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// Get returns the comment with id.
+func (r *MongoCommentRepository) Get(ctx context.Context, id models.ID) (models.Comment, error) {
+	var comment models.Comment
+	err := r.DB.Comments.FindOne(ctx, bson.M{"_id": id}).Decode(&comment)
+	return comment, err
+}
+
+// Insert stores a new comment.
+func (r *MongoCommentRepository) Insert(ctx context.Context, comment models.Comment) error {
+	_, err := r.DB.Comments.InsertOne(ctx, comment)
+	return err
+}
+
+// UpdateContent replaces the comment's content and returns it updated.
+func (r *MongoCommentRepository) UpdateContent(ctx context.Context, id models.ID, content string, editedAt time.Time) (models.Comment, error) {
+	update := bson.M{"$set": bson.M{"content": content, "edited_at": editedAt}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var comment models.Comment
+	err := r.DB.Comments.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&comment)
+	return comment, err
+}
+
+// Delete moves the comment and every reply below it, with their
+// reactions, to the trash in one transaction, returning the comment so
+// callers know its post and the number of visible comments removed for its
+// comment count. Replies and reactions are trash rows of the comment.
+func (r *MongoCommentRepository) Delete(ctx context.Context, id models.ID) (models.Comment, int64, error) {
+	var deleted models.Comment
+	var visible int64
+	err := r.DB.Transaction(ctx, func(tx context.Context) error {
+		// A retried transaction starts over, results included
+		deleted, visible = models.Comment{}, 0
+		raw, err := r.DB.Comments.FindOne(tx, bson.M{"_id": id}).Raw()
+		if err != nil {
+			return err
+		}
+		if err := bson.Unmarshal(raw, &deleted); err != nil {
+			return err
+		}
+		replies, replyIDs, err := r.replies(tx, deleted)
+		if err != nil {
+			return err
+		}
+		ids := append([]models.ID{id}, replyIDs...)
+
+		item := models.TrashItem{
+			ID:        id,
+			Kind:      models.TRASH_COMMENT,
+			PostID:    deleted.PostID,
+			Title:     commentTitle(deleted),
+			DeletedAt: time.Now(),
+		}
+		if err := r.DB.putInTrash(tx, trashEntry{TrashItem: item, Document: raw}); err != nil {
+			return err
+		}
+		if err := r.DB.trashRaw(tx, item, r.DB.Comments, replies); err != nil {
+			return err
+		}
+		if err := r.DB.moveToTrash(tx, item, r.DB.Reactions, bson.M{"comment_id": bson.M{"$in": ids}}); err != nil {
+			return err
+		}
+		if _, err := r.DB.Comments.DeleteMany(tx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return err
+		}
+		visible, err = countVisible(append([]bson.Raw{raw}, replies...))
+		return err
+	})
+	return deleted, visible, err
+}
+
+// Moderate sets the comment's status and returns it as before the update.
+func (r *MongoCommentRepository) Moderate(ctx context.Context, id models.ID, status, moderatedBy string, at time.Time) (models.Comment, error) {
+	update := bson.M{"$set": bson.M{"status": status, "moderated_by": moderatedBy, "moderated_at": at}}
+	var before models.Comment
+	err := r.DB.Comments.FindOneAndUpdate(ctx, bson.M{"_id": id}, update).Decode(&before)
+	return before, err
+}
+
+// countVisible counts the raw comments whose moderation status does not
+// hide them.
+func countVisible(docs []bson.Raw) (int64, error) {
+	var visible int64
+	for _, doc := range docs {
+		var comment models.Comment
+		if err := bson.Unmarshal(doc, &comment); err != nil {
+			return 0, err
+		}
+		if !comment.Hidden() {
+			visible++
+		}
+	}
+	return visible, nil
+}
+
+// replies returns the raw replies below comment at every depth and their
+// IDs, walking the thread one level per query.
+func (r *MongoCommentRepository) replies(ctx context.Context, comment models.Comment) ([]bson.Raw, []models.ID, error) {
+	var docs []bson.Raw
+	var ids []models.ID
+	parents := []models.ID{comment.ID}
+	for len(parents) > 0 {
+		level, err := findRaw(ctx, r.DB.Comments, bson.M{"post_id": comment.PostID, "parent_id": bson.M{"$in": parents}})
+		if err != nil {
+			return nil, nil, err
+		}
+		parents = nil
+		for _, reply := range level {
+			var id models.ID
+			if err := reply.Lookup("_id").Unmarshal(&id); err != nil {
+				return nil, nil, err
+			}
+			parents = append(parents, id)
+		}
+		docs = append(docs, level...)
+		ids = append(ids, parents...)
+	}
+	return docs, ids, nil
+}
+
+// Restore moves the trashed comment back onto its post with the replies
+// trashed along with it, in one transaction. A reply is only restored while
+// the comment it answers exists.
+func (r *MongoCommentRepository) Restore(ctx context.Context, id models.ID) (models.Comment, int64, error) {
+	var restored models.Comment
+	var visible int64
+	err := r.DB.Transaction(ctx, func(tx context.Context) error {
+		// A retried transaction starts over, results included
+		restored, visible = models.Comment{}, 0
+		entry, err := r.DB.takeFromTrash(tx, id, models.TRASH_COMMENT)
+		if err != nil {
+			return err
+		}
+		if err := bson.Unmarshal(entry.Document, &restored); err != nil {
+			return err
+		}
+
+		exists, err := r.DB.Posts.CountDocuments(tx, bson.M{"_id": restored.PostID}, options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+		if exists == 0 {
+			return ErrPostMissing
+		}
+		if !restored.ParentID.IsZero() {
+			exists, err := r.DB.Comments.CountDocuments(tx, bson.M{"_id": restored.ParentID}, options.Count().SetLimit(1))
+			if err != nil {
+				return err
+			}
+			if exists == 0 {
+				return ErrParentMissing
+			}
+		}
+
+		if _, err := r.DB.Comments.InsertOne(tx, entry.Document); err != nil {
+			return err
+		}
+		replies, err := r.DB.restoreRows(tx, id)
+		if err != nil {
+			return err
+		}
+		visible, err = countVisible(append([]bson.Raw{entry.Document}, replies...))
+		return err
+	})
+	return restored, visible, err
+}
+
+// Compile-time checks that the MongoDB repositories satisfy the interfaces.
+var (
+	_ PostRepository    = (*MongoPostRepository)(nil)
+	_ CommentRepository = (*MongoCommentRepository)(nil)
+)
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/description"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/tag"
+	"go.uber.org/zap"
+)
+
+// ReadRouting selects which members of a replica set, possibly spread over
+// several regions, serve reads. The hosts themselves come from the
+// connection URI, which may list members of every region; writes always go
+// to the primary. The zero value keeps the URI's read preference.
+type ReadRouting struct {
+	// Preference is a read preference mode: primary, primaryPreferred,
+	// secondary, secondaryPreferred, or nearest (case-insensitive).
+	Preference string
+	// TagSets picks members by their replica set tags, in order of
+	// preference: sets are separated by ";" and hold "key:value" pairs
+	// separated by ",", e.g. "region:eu-west,zone:a;region:eu-west;". A
+	// trailing empty set falls back to any member.
+	TagSets string
+	// Hedged sends sharded reads to two members at once and keeps the
+	// fastest answer. Honored by mongos on MongoDB 4.4 to 7.x only.
+	Hedged bool
+	// MaxStaleness excludes secondaries lagging the primary by more than
+	// this; at least 90 seconds when set (0 disables the check).
+	MaxStaleness time.Duration
+	// LocalThreshold is the latency window above the fastest member within
+	// which members are picked at random (0 keeps the driver's 15ms).
+	LocalThreshold time.Duration
+}
+
+// ReadPref builds the read preference of r.
+//
+// Returns nil when r sets no preference, or an error for an unknown mode,
+// malformed tag sets, or options the mode does not accept.
+func (r ReadRouting) ReadPref() (*readpref.ReadPref, error) {
+	if r.Preference == "" {
+		if r.TagSets != "" || r.Hedged || r.MaxStaleness > 0 {
+			return nil, errors.New("read tags, hedged reads, and max staleness need a read preference")
+		}
+		return nil, nil
+	}
+	mode, err := readpref.ModeFromString(r.Preference)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []readpref.Option
+	if r.TagSets != "" {
+		sets, err := parseTagSets(r.TagSets)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, readpref.WithTagSets(sets...))
+	}
+	if r.Hedged {
+		opts = append(opts, readpref.WithHedgeEnabled(true))
+	}
+	if r.MaxStaleness > 0 {
+		opts = append(opts, readpref.WithMaxStaleness(r.MaxStaleness))
+	}
+	return readpref.New(mode, opts...)
+}
+
+// parseTagSets parses the TagSets format of ReadRouting.
+func parseTagSets(raw string) ([]tag.Set, error) {
+	var sets []tag.Set
+	for _, rawSet := range strings.Split(raw, ";") {
+		set := tag.Set{}
+		for _, pair := range strings.Split(rawSet, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			name, value, ok := strings.Cut(pair, ":")
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			if !ok || name == "" || value == "" {
+				return nil, fmt.Errorf("invalid read tag %q, expected key:value", pair)
+			}
+			set = append(set, tag.Tag{Name: name, Value: value})
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// apply sets the read preference, latency window, and failover logging of
+// r on opts.
+func (r ReadRouting) apply(opts *options.ClientOptions) error {
+	pref, err := r.ReadPref()
+	if err != nil {
+		return err
+	}
+	if pref != nil {
+		opts.SetReadPreference(pref)
+	}
+	if r.LocalThreshold > 0 {
+		opts.SetLocalThreshold(r.LocalThreshold)
+	}
+	opts.SetServerMonitor(failoverMonitor())
+	return nil
+}
+
+// failoverMonitor logs the health changes of the deployment: members
+// becoming unreachable or reachable again, and the primary moving or being
+// lost, so failovers across regions show up in the logs.
+func failoverMonitor() *event.ServerMonitor {
+	return &event.ServerMonitor{
+		ServerDescriptionChanged: func(e *event.ServerDescriptionChangedEvent) {
+			previous, current := e.PreviousDescription, e.NewDescription
+			switch {
+			case previous.Kind != description.Unknown && current.Kind == description.Unknown:
+				logger.Warn("mongo server unreachable",
+					zap.String("address", e.Address.String()), zap.Error(current.LastError))
+			case previous.Kind == description.Unknown && previous.LastError != nil && current.Kind != description.Unknown:
+				logger.Info("mongo server reachable again",
+					zap.String("address", e.Address.String()), zap.String("kind", current.Kind.String()))
+			}
+		},
+		TopologyDescriptionChanged: func(e *event.TopologyDescriptionChangedEvent) {
+			previous, current := primaryOf(e.PreviousDescription), primaryOf(e.NewDescription)
+			switch {
+			case previous == current:
+			case current == "":
+				logger.Error("mongo primary lost", zap.String("previous", previous))
+			case previous == "":
+				logger.Info("mongo primary available", zap.String("primary", current))
+			default:
+				logger.Warn("mongo primary changed", zap.String("previous", previous), zap.String("primary", current))
+			}
+		},
+	}
+}
+
+// primaryOf returns the address of the replica set primary in topology, or
+// "" if it has none (or is not a replica set).
+func primaryOf(topology description.Topology) string {
+	for _, server := range topology.Servers {
+		if server.Kind == description.RSPrimary {
+			return server.Addr.String()
+		}
+	}
+	return ""
+}
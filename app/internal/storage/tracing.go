@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// CommandTracer records a client span for every command the driver sends
+// (see lib/tracing), named after the operation and collection, e.g. "find
+// posts". Spans are children of the span in the command's context, so the
+// commands of a request appear under its server span. Commands run outside
+// a trace, such as those of background jobs, are not traced, so they do not
+// flood the backend with one-span traces.
+type CommandTracer struct {
+	pending sync.Map // connection and request ID -> *tracing.Span of commands in flight
+}
+
+// Monitor returns the command monitor feeding t, for the client options.
+func (t *CommandTracer) Monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			parent := tracing.FromContext(ctx).Context()
+			if !parent.Valid() {
+				return
+			}
+			name := e.CommandName
+			collection := commandCollection(e.CommandName, e.Command)
+			if collection != "" {
+				name += " " + collection
+			}
+			span := tracing.StartSpan(parent, name, tracing.KIND_CLIENT)
+			span.SetAttributes(
+				tracing.Attr("db.system", "mongodb"),
+				tracing.Attr("db.namespace", e.DatabaseName),
+				tracing.Attr("db.operation.name", e.CommandName),
+				tracing.Attr("db.collection.name", collection),
+			)
+			t.pending.Store(pendingKey(e.ConnectionID, e.RequestID), span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			t.finish(e.CommandFinishedEvent, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			t.finish(e.CommandFinishedEvent, e.Failure)
+		},
+	}
+}
+
+// finish ends the span of a completed command, failed when failure is set.
+func (t *CommandTracer) finish(e event.CommandFinishedEvent, failure string) {
+	value, ok := t.pending.LoadAndDelete(pendingKey(e.ConnectionID, e.RequestID))
+	if !ok {
+		return
+	}
+	span := value.(*tracing.Span)
+	if failure != "" {
+		span.SetError(failure)
+	}
+	span.End()
+}
+
+// combineMonitors returns a command monitor calling every monitor in
+// order, since the client options take only one.
+func combineMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, monitor := range monitors {
+				monitor.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, monitor := range monitors {
+				monitor.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, monitor := range monitors {
+				monitor.Failed(ctx, e)
+			}
+		},
+	}
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TRASH_TITLE_LENGTH caps the characters of a comment quoted as the title
+// of its trash item.
+const TRASH_TITLE_LENGTH = 80
+
+// ErrPostMissing and ErrParentMissing are returned when restoring a
+// comment whose post or parent comment is gone, trashed or purged; a
+// trashed post or parent has to be restored first.
+var (
+	ErrPostMissing   = errors.New("post of the comment no longer exists")
+	ErrParentMissing = errors.New("parent of the comment no longer exists")
+)
+
+// trashEntry is the stored form of a models.TrashItem: the item's listing
+// fields plus the raw deleted post or comment, kept byte for byte so a
+// restore brings back every field, including ones the models do not
+// declare. The documents moved out with it are trashRows, so a busy post
+// never has to fit in one document.
+type trashEntry struct {
+	models.TrashItem `bson:",inline"`
+	Document         bson.Raw `bson:"document"` // The deleted post or comment
+}
+
+// trashRow is one document moved to the trash along with a trash item:
+// a comment, like, clap, reaction, translation, or autosave.
+type trashRow struct {
+	TrashID    models.ID `bson:"trash_id"`   // ID of the trash item the row belongs to
+	Collection string    `bson:"collection"` // Name of the collection the document came from
+	Document   bson.Raw  `bson:"document"`   // The document, byte for byte
+	DeletedAt  time.Time `bson:"deleted_at"` // Deletion time of the trash item, for purges
+}
+
+// findRaw returns the raw documents of coll matching filter.
+func findRaw(ctx context.Context, coll *mongo.Collection, filter bson.M) ([]bson.Raw, error) {
+	cursor, err := coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.Raw
+	for cursor.Next(ctx) {
+		docs = append(docs, append(bson.Raw(nil), cursor.Current...))
+	}
+	return docs, cursor.Err()
+}
+
+// putInTrash stores entry. Callers remove the original document in the same
+// transaction, and move what goes with it with moveToTrash or trashRaw.
+func (db *Storage) putInTrash(ctx context.Context, entry trashEntry) error {
+	_, err := db.Trash.InsertOne(ctx, entry)
+	return err
+}
+
+// trashRaw stores docs of coll as trash rows of item.
+func (db *Storage) trashRaw(ctx context.Context, item models.TrashItem, coll *mongo.Collection, docs []bson.Raw) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	rows := make([]any, len(docs))
+	for i, doc := range docs {
+		rows[i] = trashRow{TrashID: item.ID, Collection: coll.Name(), Document: doc, DeletedAt: item.DeletedAt}
+	}
+	_, err := db.TrashRows.InsertMany(ctx, rows)
+	return err
+}
+
+// moveToTrash moves the documents of coll matching filter to the trash as
+// rows of item.
+func (db *Storage) moveToTrash(ctx context.Context, item models.TrashItem, coll *mongo.Collection, filter bson.M) error {
+	docs, err := findRaw(ctx, coll, filter)
+	if err != nil || len(docs) == 0 {
+		return err
+	}
+	if err := db.trashRaw(ctx, item, coll, docs); err != nil {
+		return err
+	}
+	_, err = coll.DeleteMany(ctx, filter)
+	return err
+}
+
+// takeFromTrash loads the trash entry of id.
+//
+// Returns mongo.ErrNoDocuments if id is not in the trash as kind.
+func (db *Storage) takeFromTrash(ctx context.Context, id models.ID, kind string) (trashEntry, error) {
+	var entry trashEntry
+	err := db.Trash.FindOne(ctx, bson.M{"_id": id, "kind": kind}).Decode(&entry)
+	return entry, err
+}
+
+// restoreRows moves the trash rows of id back into their collections and
+// removes the trash entry. Callers run it in a transaction, after putting
+// the entry's own document back.
+//
+// Returns the restored comments, for their post's comment count.
+func (db *Storage) restoreRows(ctx context.Context, id models.ID) ([]bson.Raw, error) {
+	collections := make(map[string]*mongo.Collection)
+	for _, coll := range db.Collections() {
+		collections[coll.Name()] = coll
+	}
+
+	cursor, err := db.TrashRows.Find(ctx, bson.M{"trash_id": id})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var comments []bson.Raw
+	for cursor.Next(ctx) {
+		var row trashRow
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		coll, ok := collections[row.Collection]
+		if !ok {
+			return nil, fmt.Errorf("trash row of %s from unknown collection %q", id, row.Collection)
+		}
+		if _, err := coll.InsertOne(ctx, row.Document); err != nil {
+			return nil, err
+		}
+		if coll == db.Comments {
+			comments = append(comments, append(bson.Raw(nil), row.Document...))
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.TrashRows.DeleteMany(ctx, bson.M{"trash_id": id}); err != nil {
+		return nil, err
+	}
+	_, err = db.Trash.DeleteOne(ctx, bson.M{"_id": id})
+	return comments, err
+}
+
+// legacyTrashFields are the arrays trash entries used to keep the documents
+// moved out with them in, by the collection the documents came from.
+func (db *Storage) legacyTrashFields() map[string]*mongo.Collection {
+	return map[string]*mongo.Collection{
+		"comments":     db.Comments,
+		"likes":        db.Likes,
+		"claps":        db.Claps,
+		"reactions":    db.Reactions,
+		"translations": db.Translations,
+		"autosaves":    db.Autosaves,
+	}
+}
+
+// splitTrashEntries moves the documents kept inside trash entries written
+// before trash rows existed out into rows, so they restore like new ones.
+// Rows of an entry are replaced before its arrays are dropped, so an
+// interrupted run is simply repeated; entries already split are not
+// visited.
+func (db *Storage) splitTrashEntries(ctx context.Context) error {
+	fields := db.legacyTrashFields()
+	legacy := make([]bson.M, 0, len(fields))
+	for field := range fields {
+		legacy = append(legacy, bson.M{field: bson.M{"$exists": true}})
+	}
+	cursor, err := db.Trash.Find(ctx, bson.M{"$or": legacy})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var item models.TrashItem
+		if err := cursor.Decode(&item); err != nil {
+			return err
+		}
+		if _, err := db.TrashRows.DeleteMany(ctx, bson.M{"trash_id": item.ID}); err != nil {
+			return err
+		}
+		unset := bson.M{}
+		for field, coll := range fields {
+			value, err := cursor.Current.LookupErr(field)
+			if err != nil {
+				continue
+			}
+			var docs []bson.Raw
+			if err := value.Unmarshal(&docs); err != nil {
+				return err
+			}
+			if err := db.trashRaw(ctx, item, coll, docs); err != nil {
+				return err
+			}
+			unset[field] = ""
+		}
+		if _, err := db.Trash.UpdateOne(ctx, bson.M{"_id": item.ID}, bson.M{"$unset": unset}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// commentTitle quotes the start of a comment for its trash listing.
+func commentTitle(comment models.Comment) string {
+	runes := []rune(comment.Content)
+	if len(runes) <= TRASH_TITLE_LENGTH {
+		return comment.Content
+	}
+	return string(runes[:TRASH_TITLE_LENGTH]) + "…"
+}
+
+// PurgeTrash permanently removes the trash items deleted before cutoff.
+//
+// Returns the number of items removed.
+func (db *Storage) PurgeTrash(ctx context.Context, cutoff time.Time) (int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": cutoff}}
+	result, err := db.Trash.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	// Rows share their item's deletion time, so they go with it
+	if _, err := db.TrashRows.DeleteMany(ctx, filter); err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
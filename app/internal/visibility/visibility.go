@@ -0,0 +1,96 @@
+// Package visibility decides which posts a read path may show. Posts can be
+// hidden five ways: archived, unlisted, private, passphrase-protected, or
+// scheduled for later.
+// Each read path (listings, search, federation, the static site) picks a
+// Filter instead of building its own query, so the MongoDB filter and
+// the in-memory check always agree.
+package visibility
+
+import (
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Filter lists the kinds of hidden posts a read path includes. The zero
+// Filter includes none of them: only public, unarchived, unprotected,
+// published posts.
+type Filter struct {
+	Archived  bool // Include archived posts
+	Unlisted  bool // Include unlisted posts
+	Private   bool // Include private posts
+	Protected bool // Include passphrase-protected posts
+	Scheduled bool // Include posts whose publish_at is still ahead
+}
+
+// Filters of the read paths.
+var (
+	// LISTING is GET /api/posts: protected posts are listed, their content
+	// stays locked behind the passphrase.
+	LISTING = Filter{Protected: true}
+	// PUBLISHED is content pushed or exposed in full without a passphrase:
+	// search, the ActivityPub outbox, search engine pings, and the static
+	// site's index, tag pages, and feeds.
+	PUBLISHED = Filter{}
+	// PAGES is every post that gets a static site page, listed or not.
+	PAGES = Filter{Archived: true, Unlisted: true}
+	// HIDDEN includes everything, for include_hidden=true requests of
+	// administrators.
+	HIDDEN = Filter{Archived: true, Unlisted: true, Private: true, Protected: true, Scheduled: true}
+)
+
+// BSON returns the MongoDB filter matching the posts f includes at the
+// current time, to be merged with a read path's own conditions. Posts
+// stored before visibility existed have no visibility field and count as
+// public; posts without publish_at are published.
+func (f Filter) BSON() bson.M {
+	filter := bson.M{}
+	var excluded bson.A
+	if !f.Unlisted {
+		excluded = append(excluded, models.VISIBILITY_UNLISTED)
+	}
+	if !f.Private {
+		excluded = append(excluded, models.VISIBILITY_PRIVATE)
+	}
+	if len(excluded) > 0 {
+		filter["visibility"] = bson.M{"$nin": excluded}
+	}
+	if !f.Archived {
+		filter["archived"] = bson.M{"$ne": true}
+	}
+	if !f.Protected {
+		filter["protected"] = bson.M{"$ne": true}
+	}
+	if !f.Scheduled {
+		filter["publish_at"] = bson.M{"$not": bson.M{"$gt": time.Now()}}
+	}
+	return filter
+}
+
+// Allows reports whether f includes post, the in-memory equivalent of BSON.
+func (f Filter) Allows(post models.BlogPost) bool {
+	switch {
+	case post.Visibility == models.VISIBILITY_UNLISTED && !f.Unlisted:
+		return false
+	case post.Visibility == models.VISIBILITY_PRIVATE && !f.Private:
+		return false
+	case post.Archived && !f.Archived:
+		return false
+	case post.Protected && !f.Protected:
+		return false
+	case post.Scheduled(time.Now()) && !f.Scheduled:
+		return false
+	}
+	return true
+}
+
+// Unpublished matches the posts no public read path serves even by ID:
+// private posts and posts scheduled after now. Preview links still reach
+// them.
+func Unpublished(now time.Time) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"visibility": models.VISIBILITY_PRIVATE},
+		bson.M{"publish_at": bson.M{"$gt": now}},
+	}}
+}
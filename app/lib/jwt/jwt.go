@@ -0,0 +1,125 @@
+// Package jwt issues and verifies JSON Web Tokens (RFC 7519) signed with
+// HMAC-SHA256 (HS256). Only HS256 is accepted, so tokens declaring another
+// algorithm, including "none", are always rejected.
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/lib/token"
+)
+
+// ErrInvalid is returned for tokens that are malformed, use another
+// algorithm, or carry a bad signature.
+var ErrInvalid = errors.New("invalid token")
+
+// ErrExpired is returned for correctly signed tokens past their expiry.
+var ErrExpired = errors.New("token expired")
+
+// ALGORITHM is the only accepted signing algorithm.
+const ALGORITHM = "HS256"
+
+// encoding is the unpadded base64url encoding JWT segments use.
+var encoding = base64.RawURLEncoding
+
+// header is the JOSE header of issued tokens.
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// Claims are the registered claims the API uses plus the user's name.
+type Claims struct {
+	Subject   string `json:"sub"`            // User ID
+	Name      string `json:"name,omitempty"` // Username, for display
+	IssuedAt  int64  `json:"iat"`            // Issue time, Unix seconds
+	ExpiresAt int64  `json:"exp"`            // Expiry time, Unix seconds
+}
+
+// Signer creates and verifies tokens with a shared secret.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a Signer using secret as the HMAC key.
+// If secret is empty a random key is generated (see token.Key), which means
+// tokens stop verifying after a restart and are not shared between
+// instances; callers should configure a secret in production.
+//
+// Parameters:
+//   - secret: HMAC-SHA256 key
+//
+// Returns a pointer to a new Signer.
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: token.Key(secret)}
+}
+
+// Sign encodes and signs claims.
+//
+// Parameters:
+//   - claims: token claims; ExpiresAt should be set
+//
+// Returns the compact serialization "<header>.<claims>.<signature>".
+func (s *Signer) Sign(claims Claims) (string, error) {
+	head, err := json.Marshal(header{Algorithm: ALGORITHM, Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encoding.EncodeToString(head) + "." + encoding.EncodeToString(body)
+	return signingInput + "." + encoding.EncodeToString(s.mac(signingInput)), nil
+}
+
+// Verify checks a token's algorithm, signature, and expiry.
+//
+// Parameters:
+//   - token: compact serialization from Sign
+//
+// Returns the claims, or ErrInvalid / ErrExpired.
+func (s *Signer) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalid
+	}
+
+	// Check the algorithm before trusting the signature
+	var head header
+	if raw, err := encoding.DecodeString(parts[0]); err != nil || json.Unmarshal(raw, &head) != nil {
+		return Claims{}, ErrInvalid
+	}
+	if head.Algorithm != ALGORITHM {
+		return Claims{}, ErrInvalid
+	}
+	given, err := encoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(given, s.mac(parts[0]+"."+parts[1])) {
+		return Claims{}, ErrInvalid
+	}
+
+	var claims Claims
+	if raw, err := encoding.DecodeString(parts[1]); err != nil || json.Unmarshal(raw, &claims) != nil {
+		return Claims{}, ErrInvalid
+	}
+	if claims.Subject == "" {
+		return Claims{}, ErrInvalid
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return Claims{}, ErrExpired
+	}
+	return claims, nil
+}
+
+// mac computes the HMAC-SHA256 of the signing input.
+func (s *Signer) mac(signingInput string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(signingInput))
+	return h.Sum(nil)
+}
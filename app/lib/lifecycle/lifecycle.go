@@ -0,0 +1,169 @@
+// Package lifecycle starts and stops the subsystems of the application in
+// order. Each subsystem registers a Hook; Start runs the start functions in
+// registration order and Stop runs the stop functions in reverse, so a
+// subsystem is always stopped before the ones it depends on, and the
+// database goes last. Every stop function gets its own timeout, so one
+// stuck subsystem cannot use up the shutdown of the others.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// Hook is the startup and shutdown of one subsystem. Either function may
+// be nil.
+type Hook struct {
+	Name string // Subsystem name, for logs and errors
+
+	// Start prepares the subsystem. It should return once the subsystem is
+	// ready; long-running work belongs in a goroutine (see Workers). ctx is
+	// cancelled when startup is aborted.
+	Start func(ctx context.Context) error
+
+	// Stop releases the subsystem. ctx is done when the hook's timeout
+	// expires; Stop should give up then, and the shutdown moves on even if
+	// it does not.
+	Stop func(ctx context.Context) error
+
+	// Timeout bounds Stop; zero uses the Manager's StopTimeout.
+	Timeout time.Duration
+}
+
+// Manager runs the hooks registered with Append. It is not safe for
+// concurrent use.
+type Manager struct {
+	StopTimeout time.Duration // Default bound of each Stop function
+
+	hooks   []Hook
+	started int // Number of hooks whose Start succeeded, stopped by Stop
+}
+
+// New creates a Manager.
+//
+// Parameters:
+//   - stopTimeout: default time each hook's Stop function may take
+//
+// Returns a pointer to an empty Manager.
+func New(stopTimeout time.Duration) *Manager {
+	return &Manager{StopTimeout: stopTimeout}
+}
+
+// Append registers hooks, started after those registered before and
+// stopped before them.
+func (m *Manager) Append(hooks ...Hook) {
+	m.hooks = append(m.hooks, hooks...)
+}
+
+// Start runs the start functions of the hooks not started yet, in
+// registration order. When one fails, the hooks already started are
+// stopped again, in reverse order, and its error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	for m.started < len(m.hooks) {
+		hook := m.hooks[m.started]
+		if hook.Start != nil {
+			if err := hook.Start(ctx); err != nil {
+				err = fmt.Errorf("start %s: %w", hook.Name, err)
+				if stopErr := m.Stop(); stopErr != nil {
+					logger.Warn("failed to stop after aborted startup", zap.Error(stopErr))
+				}
+				return err
+			}
+		}
+		m.started++
+	}
+	return nil
+}
+
+// Stop runs the stop functions of the started hooks in reverse order,
+// each bounded by its timeout. Failures and timeouts are logged and do not
+// prevent the remaining hooks from stopping.
+//
+// Returns the errors of every failed or timed out hook, joined.
+func (m *Manager) Stop() error {
+	var errs []error
+	for ; m.started > 0; m.started-- {
+		hook := m.hooks[m.started-1]
+		if hook.Stop == nil {
+			continue
+		}
+		timeout := hook.Timeout
+		if timeout <= 0 {
+			timeout = m.StopTimeout
+		}
+		if err := stopHook(hook, timeout); err != nil {
+			logger.Warn("failed to stop", zap.String("hook", hook.Name), zap.Duration("timeout", timeout), zap.Error(err))
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopHook runs hook.Stop and waits for it at most timeout, returning
+// context.DeadlineExceeded when it does not return in time.
+func stopHook(hook Hook, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.Stop(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Workers returns a hook running each function in its own goroutine from
+// startup until shutdown, for background jobs and schedulers. Their
+// context is cancelled when the hook stops, which then waits for all of
+// them to return, e.g. after a final flush.
+//
+// Parameters:
+//   - name: name of the hook
+//   - runs: long-running functions that return once their context is done
+func Workers(name string, runs ...func(ctx context.Context)) Hook {
+	var (
+		wg     sync.WaitGroup
+		cancel context.CancelFunc
+	)
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			// Workers outlive startup, so their context is not derived from it
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			for _, run := range runs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					run(ctx)
+				}()
+			}
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
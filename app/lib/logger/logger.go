@@ -1,11 +1,15 @@
 package logger
 
 import (
+	"context"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var log *zap.Logger
+// log is a no-op logger until Setup runs, so packages that log during
+// tests or early initialization never hit a nil logger.
+var log = zap.NewNop()
 
 // Init initializes the logger with the specified level
 func Setup(level string) error {
@@ -65,3 +69,28 @@ func Error(msg string, fields ...zap.Field) {
 func Fatal(msg string, fields ...zap.Field) {
 	log.Fatal(msg, fields...)
 }
+
+// CONTEXT_KEY is the context key holding a request-scoped logger. It is a
+// string so values stored with fiber.Ctx.Locals are also visible through
+// fiber.Ctx.Context() and contexts derived from it.
+const CONTEXT_KEY = "logger"
+
+// With returns a logger that adds fields to every entry, e.g. the ID of
+// the request being served.
+func With(fields ...zap.Field) *zap.Logger {
+	return log.With(fields...)
+}
+
+// Ctx returns the request-scoped logger stored in ctx under CONTEXT_KEY,
+// or the global logger when there is none.
+func Ctx(ctx context.Context) *zap.Logger {
+	if scoped, ok := ctx.Value(CONTEXT_KEY).(*zap.Logger); ok {
+		return scoped
+	}
+	return log
+}
+
+// Sync flushes buffered log entries; call it before the process exits.
+func Sync() error {
+	return log.Sync()
+}
@@ -0,0 +1,276 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/lib/logger"
+	"go.uber.org/zap"
+)
+
+// OTLP_TRACES_PATH is where OTLP/HTTP receivers accept spans.
+const OTLP_TRACES_PATH = "/v1/traces"
+
+// SCOPE_NAME names the instrumentation producing the spans.
+const SCOPE_NAME = "github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+
+// EXPORT_BATCH_SIZE is the most spans sent in one export request.
+const EXPORT_BATCH_SIZE = 512
+
+// EXPORT_INTERVAL is how long ended spans wait for a batch to fill.
+const EXPORT_INTERVAL = 5 * time.Second
+
+// EXPORT_QUEUE_SIZE bounds the spans waiting for export; spans ended while
+// it is full are dropped rather than slowing requests down.
+const EXPORT_QUEUE_SIZE = 4096
+
+// EXPORT_TIMEOUT bounds one export request.
+const EXPORT_TIMEOUT = 10 * time.Second
+
+// ParseHeaders parses "name=value" entries of OTLP export headers, e.g.
+// "Authorization=Bearer secret". Invalid entries are skipped and reported
+// in the returned error; the valid ones are still returned.
+//
+// Parameters:
+//   - entries: header entries from configuration
+func ParseHeaders(entries []string) (map[string]string, error) {
+	headers := make(map[string]string, len(entries))
+	var invalid []string
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			invalid = append(invalid, entry)
+			continue
+		}
+		headers[name] = value
+	}
+	if len(invalid) > 0 {
+		return headers, fmt.Errorf("invalid OTLP headers: %v", invalid)
+	}
+	return headers, nil
+}
+
+// exporter batches ended spans and posts them to an OTLP/HTTP receiver as
+// JSON.
+type exporter struct {
+	url     string
+	headers map[string]string
+	service string
+	client  *http.Client
+	queue   chan *Span
+	flush   chan chan struct{}
+}
+
+// newExporter creates an exporter posting to endpoint's OTLP_TRACES_PATH;
+// an endpoint already ending with it is used as is. It sends nothing until
+// run is started.
+func newExporter(endpoint string, headers map[string]string, service string) *exporter {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, OTLP_TRACES_PATH) {
+		url += OTLP_TRACES_PATH
+	}
+	return &exporter{
+		url:     url,
+		headers: headers,
+		service: service,
+		client:  &http.Client{Timeout: EXPORT_TIMEOUT},
+		queue:   make(chan *Span, EXPORT_QUEUE_SIZE),
+		flush:   make(chan chan struct{}),
+	}
+}
+
+// enqueue queues an ended span, dropping it when the queue is full.
+func (e *exporter) enqueue(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+	}
+}
+
+// run exports queued spans in batches until stop is called.
+func (e *exporter) run() {
+	ticker := time.NewTicker(EXPORT_INTERVAL)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, EXPORT_BATCH_SIZE)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil {
+			logger.Warn("failed to export spans", zap.Int("spans", len(batch)), zap.Error(err))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) == EXPORT_BATCH_SIZE {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case flushed := <-e.flush:
+			for drained := false; !drained; {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+					if len(batch) == EXPORT_BATCH_SIZE {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(flushed)
+			return
+		}
+	}
+}
+
+// stop exports the queued spans and ends run, waiting at most until ctx
+// is done.
+func (e *exporter) stop(ctx context.Context) error {
+	flushed := make(chan struct{})
+	select {
+	case e.flush <- flushed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// export posts one batch of spans.
+func (e *exporter) export(spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("collector answered %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP JSON messages, a subset of ExportTraceServiceRequest. IDs are hex
+// encoded and 64-bit integers are strings, as the OTLP JSON mapping asks.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              SpanKind        `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            *otlpStatus     `json:"status,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"` // 2 is STATUS_CODE_ERROR
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// request encodes spans as one OTLP export request.
+func (e *exporter) request(spans []*Span) otlpRequest {
+	encoded := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		span.mu.Lock()
+		encoded[i] = otlpSpan{
+			TraceID:           span.context.TraceID.String(),
+			SpanID:            span.context.SpanID.String(),
+			Name:              span.name,
+			Kind:              span.kind,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.attributes),
+		}
+		if span.parent != (SpanID{}) {
+			encoded[i].ParentSpanID = span.parent.String()
+		}
+		if span.err != "" {
+			encoded[i].Status = &otlpStatus{Code: 2, Message: span.err}
+		}
+		span.mu.Unlock()
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes([]Attribute{Attr("service.name", e.service)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: SCOPE_NAME}, Spans: encoded}},
+	}}}
+}
+
+// otlpAttributes encodes attributes as OTLP key-value pairs; values of
+// other types are sent as their text.
+func otlpAttributes(attributes []Attribute) []otlpAttribute {
+	encoded := make([]otlpAttribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		var value map[string]any
+		switch v := attribute.Value.(type) {
+		case string:
+			value = map[string]any{"stringValue": v}
+		case bool:
+			value = map[string]any{"boolValue": v}
+		case int:
+			value = map[string]any{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": v}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, otlpAttribute{Key: attribute.Key, Value: value})
+	}
+	return encoded
+}
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+)
+
+// TRACEPARENT_HEADER carries the trace context of a request across
+// services, as defined by W3C Trace Context.
+const TRACEPARENT_HEADER = "traceparent"
+
+// TraceID identifies a trace: every span of one request shares it.
+type TraceID [16]byte
+
+// SpanID identifies a span within its trace.
+type SpanID [8]byte
+
+// String returns the ID hex encoded, as in traceparent headers.
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// String returns the ID hex encoded, as in traceparent headers.
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext is the part of a span propagated to other services: its
+// trace, its own ID, which becomes the parent of their spans, and whether
+// the trace is recorded.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// Valid reports whether sc identifies a span; the zero SpanContext does
+// not.
+func (sc SpanContext) Valid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent formats sc as a version 00 traceparent header value, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses a traceparent header value. Returns false for
+// malformed values and all-zero IDs, which callers treat as no parent.
+// Versions above 00 are read as 00, as the specification asks.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	var sc SpanContext
+	if len(value) < 55 || value[2] != '-' || value[35] != '-' || value[52] != '-' ||
+		(len(value) > 55 && (value[:2] == "00" || value[55] != '-')) || value[:2] == "ff" {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(value[3:35])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(value[36:52])); err != nil {
+		return sc, false
+	}
+	flags, err := strconv.ParseUint(value[53:55], 16, 8)
+	if err != nil {
+		return sc, false
+	}
+	sc.Sampled = flags&1 == 1
+	return sc, sc.Valid()
+}
+
+// Transport records a client span for every request sent through base,
+// or http.DefaultTransport when base is nil, and forwards the trace
+// context in a traceparent header. The span is a child of the span in the
+// request's context, so outgoing calls nest under the request causing them.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return tracingTransport{base: base}
+}
+
+// tracingTransport is the http.RoundTripper returned by Transport.
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends req with a traceparent header inside a client span.
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	span := StartSpan(FromContext(req.Context()).Context(), req.Method, KIND_CLIENT)
+	defer span.End()
+	span.SetAttributes(
+		Attr("http.request.method", req.Method),
+		Attr("server.address", req.URL.Hostname()),
+		Attr("url.full", req.URL.Redacted()),
+	)
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set(TRACEPARENT_HEADER, span.Context().Traceparent())
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.SetError(err.Error())
+		return nil, err
+	}
+	span.SetAttributes(Attr("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetError(resp.Status)
+	}
+	return resp, nil
+}
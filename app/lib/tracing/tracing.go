@@ -0,0 +1,231 @@
+// Package tracing records OpenTelemetry spans and exports them over
+// OTLP/HTTP, so a request can be followed end to end in Jaeger, Tempo, or
+// any other OTLP backend. It implements just enough of the OpenTelemetry
+// data model, the W3C Trace Context headers, and the OTLP JSON encoding to
+// need no SDK.
+//
+// Like the logger, tracing is process-wide: Setup installs the exporter and
+// Start records spans with it. Until Setup runs, or when no endpoint is
+// configured, spans still carry IDs so trace context is propagated, but
+// nothing is exported.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// CONTEXT_KEY is the context key holding the current *Span. It is a string
+// so spans stored with fiber.Ctx.Locals are also visible through
+// fiber.Ctx.Context() and contexts derived from it, as for the logger.
+const CONTEXT_KEY = "span"
+
+// SpanKind tells backends which side of a call a span describes; the
+// values are those of the OTLP protocol.
+type SpanKind int
+
+const (
+	KIND_INTERNAL SpanKind = 1 // Work inside the service
+	KIND_SERVER   SpanKind = 2 // Handling of an incoming request
+	KIND_CLIENT   SpanKind = 3 // Outgoing request, e.g. a database command
+)
+
+// active is the exporter installed by Setup, nil when spans are not
+// exported, and ratio the fraction of new traces sampled.
+var (
+	mu     sync.RWMutex
+	active *exporter
+	ratio  float64
+)
+
+// Setup starts exporting sampled spans to an OTLP/HTTP endpoint, e.g.
+// "http://localhost:4318". An empty endpoint disables export, leaving only
+// trace context propagation.
+//
+// Parameters:
+//   - endpoint: OTLP/HTTP base URL; spans are posted to its /v1/traces
+//   - headers: extra request headers, e.g. for backend authentication
+//   - service: service.name resource attribute identifying this service
+//   - sampleRatio: fraction (0-1) of new traces recorded; requests carrying
+//     a trace context follow its sampling decision instead
+func Setup(endpoint string, headers map[string]string, service string, sampleRatio float64) {
+	mu.Lock()
+	defer mu.Unlock()
+	if active != nil {
+		active.stop(context.Background())
+	}
+	active, ratio = nil, sampleRatio
+	if endpoint != "" {
+		active = newExporter(endpoint, headers, service)
+		go active.run()
+	}
+}
+
+// Shutdown exports the spans still queued and stops exporting, waiting at
+// most until ctx is done.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	exporter := active
+	active = nil
+	mu.Unlock()
+	if exporter == nil {
+		return nil
+	}
+	return exporter.stop(ctx)
+}
+
+// Attribute is a key-value pair describing a span, e.g. the route of a
+// request. Values are strings, integers, floats, or booleans.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr returns the attribute key with value.
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is one timed operation of a trace. Its methods are safe to call on
+// a nil *Span, so callers need not check whether tracing is enabled.
+type Span struct {
+	mu         sync.Mutex
+	name       string
+	kind       SpanKind
+	context    SpanContext
+	parent     SpanID
+	start      time.Time
+	end        time.Time
+	attributes []Attribute
+	err        string
+	ended      bool
+}
+
+// Start begins a span named name as a child of the span in ctx, or as the
+// root of a new trace when ctx has none, and returns ctx carrying it. End
+// must be called once the operation is over.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := StartSpan(FromContext(ctx).Context(), name, kind)
+	return context.WithValue(ctx, CONTEXT_KEY, span), span
+}
+
+// StartSpan begins a span named name as a child of parent, e.g. the trace
+// context of an incoming request (see ParseTraceparent). An invalid parent
+// starts a new trace, sampled at the configured ratio.
+func StartSpan(parent SpanContext, name string, kind SpanKind) *Span {
+	span := &Span{name: name, kind: kind, start: time.Now()}
+	span.context.SpanID = newSpanID()
+	if parent.Valid() {
+		span.context.TraceID, span.context.Sampled = parent.TraceID, parent.Sampled
+		span.parent = parent.SpanID
+	} else {
+		span.context.TraceID = newTraceID()
+		span.context.Sampled = sampled(span.context.TraceID)
+	}
+	return span
+}
+
+// FromContext returns the span in ctx, or nil when there is none.
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(CONTEXT_KEY).(*Span)
+	return span
+}
+
+// Context returns the IDs and sampling decision of s, to propagate it.
+// A nil span has an invalid context.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetName renames s, e.g. once the route a request matched is known.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttributes adds attributes to s; later values of a key win.
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, attributes...)
+}
+
+// SetError marks s as failed with message.
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = message
+}
+
+// End completes s and queues it for export when it is sampled. Calls after
+// the first are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended, s.end = true, time.Now()
+	s.mu.Unlock()
+
+	if !s.context.Sampled {
+		return
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	if active != nil {
+		active.enqueue(s)
+	}
+}
+
+// sampled decides whether a new trace is recorded, from its ID so the
+// decision is stable for a trace.
+func sampled(id TraceID) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	switch {
+	case ratio >= 1:
+		return true
+	case ratio <= 0:
+		return false
+	}
+	// The low 8 bytes of a W3C trace ID are random
+	return float64(binary.BigEndian.Uint64(id[8:])>>11)/(1<<53) < ratio
+}
+
+// newTraceID returns a random, valid trace ID.
+func newTraceID() TraceID {
+	var id TraceID
+	for id == (TraceID{}) {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// newSpanID returns a random, valid span ID.
+func newSpanID() SpanID {
+	var id SpanID
+	for id == (SpanID{}) {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,24 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReferrerHost verifies that referrers are reduced to the referring
+// site, so all its pages add up in the rollups.
+func TestReferrerHost(t *testing.T) {
+	cases := map[string]string{
+		"":                                    "",
+		"https://www.News.example.com/a?b=c":  "news.example.com",
+		"http://blog.example.org:8080/post":   "blog.example.org",
+		"android-app://com.google.android.gm": "com.google.android.gm",
+		"not a url":                           "",
+		"/relative/path":                      "",
+	}
+	for referrer, want := range cases {
+		assert.Equal(t, want, jobs.ReferrerHost(referrer), referrer)
+	}
+}
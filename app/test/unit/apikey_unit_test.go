@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/lib/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAPIKeyScopes verifies that read keys are limited to safe methods,
+// unknown keys are rejected, requests without a key pass through, and write
+// keys stand in for a login JWT.
+func TestAPIKeyScopes(t *testing.T) {
+	keys := map[string]*models.APIKey{
+		"bk_read":  {Name: "reader", Scope: models.API_KEY_SCOPE_READ},
+		"bk_write": {Name: "writer", Scope: models.API_KEY_SCOPE_WRITE},
+	}
+	lookup := func(ctx context.Context, raw string) (*models.APIKey, error) {
+		return keys[raw], nil
+	}
+
+	app := fiber.New()
+	api := app.Group("/api", middleware.APIKey(lookup))
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(200) }
+	api.Get("/posts", ok)
+	api.Post("/posts", middleware.RequireAuth(jwt.NewSigner("test-secret")), ok)
+
+	cases := []struct {
+		method, key string
+		status      int
+	}{
+		{"GET", "", 200},
+		{"GET", "bk_read", 200},
+		{"GET", "bk_unknown", 401},
+		{"POST", "bk_read", 403},
+		{"POST", "bk_write", 200},
+		{"POST", "", 401},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/api/posts", nil)
+		if tc.key != "" {
+			req.Header.Set(middleware.API_KEY_HEADER, tc.key)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, tc.status, resp.StatusCode, "%s with key %q", tc.method, tc.key)
+	}
+}
+
+// TestRequireAdmin verifies that admin routes answer 401 without a login
+// JWT, even with a write key, 403 for users not listed as administrators,
+// and let listed users through regardless of case.
+func TestRequireAdmin(t *testing.T) {
+	signer := jwt.NewSigner("test-secret")
+	lookup := func(ctx context.Context, raw string) (*models.APIKey, error) {
+		return &models.APIKey{Name: "writer", Scope: models.API_KEY_SCOPE_WRITE}, nil
+	}
+	app := fiber.New()
+	api := app.Group("/api", middleware.APIKey(lookup))
+	api.Post("/admin/api-keys", middleware.RequireAdmin(signer, []string{"Ana"}), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	login := func(name string) string {
+		signed, err := signer.Sign(jwt.Claims{Subject: "686c3a82361beb165141b490", Name: name, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		require.NoError(t, err)
+		return "Bearer " + signed
+	}
+	cases := []struct {
+		authorization, key string
+		status             int
+	}{
+		{"", "", 401},
+		{"", "bk_write", 401},
+		{login("bob"), "", 403},
+		{login("ana"), "", 200},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("POST", "/api/admin/api-keys", nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		if tc.key != "" {
+			req.Header.Set(middleware.API_KEY_HEADER, tc.key)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, tc.status, resp.StatusCode, "%q with key %q", tc.authorization, tc.key)
+	}
+}
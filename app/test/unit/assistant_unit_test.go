@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/assistant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOpenAIDriverParsesSuggestion runs the OpenAI-compatible driver
+// against a fake completions server and checks the request it sends and
+// the cleaned suggestion it returns.
+func TestOpenAIDriverParsesSuggestion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "test-model", body["model"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant",` +
+			`"content":"{\"summary\":\" A short summary. \",\"tags\":[\"Go\",\"go\",\" MongoDB \",\"\"]}"}}]}`))
+	}))
+	defer server.Close()
+
+	driver := assistant.NewOpenAI(server.URL+"/v1/", "test-key", "test-model")
+	suggestion, err := driver.Suggest(context.Background(), "Title", "Content")
+	require.NoError(t, err)
+
+	assert.Equal(t, "A short summary.", suggestion.Summary)
+	assert.Equal(t, []string{"go", "mongodb"}, suggestion.Tags)
+}
+
+// TestOpenAIDriverRejectsProviderErrors checks that non-200 replies surface as errors.
+func TestOpenAIDriverRejectsProviderErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	driver := assistant.NewOpenAI(server.URL, "test-key", "test-model")
+	_, err := driver.Suggest(context.Background(), "Title", "Content")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCountsCacheHitAndInvalidate verifies that cached counts are served
+// until invalidated, which is what comment create/delete rely on.
+func TestCountsCacheHitAndInvalidate(t *testing.T) {
+	counts := cache.NewCounts(time.Minute)
+
+	// A fresh cache misses
+	_, ok := counts.Get("post")
+	assert.False(t, ok)
+
+	// A stored count is returned
+	counts.Set("post", 3)
+	value, ok := counts.Get("post")
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), value)
+
+	// Invalidation forces the next lookup to miss
+	counts.Invalidate("post")
+	_, ok = counts.Get("post")
+	assert.False(t, ok)
+}
+
+// TestCountsCacheExpiryAndDisabled verifies TTL expiry and that a
+// non-positive TTL turns the cache into a no-op.
+func TestCountsCacheExpiryAndDisabled(t *testing.T) {
+	counts := cache.NewCounts(10 * time.Millisecond)
+	counts.Set("post", 1)
+	time.Sleep(20 * time.Millisecond)
+	_, ok := counts.Get("post")
+	assert.False(t, ok)
+
+	disabled := cache.NewCounts(0)
+	disabled.Set("post", 1)
+	_, ok = disabled.Get("post")
+	assert.False(t, ok)
+}
+
+// TestCountsCacheClear verifies that Clear drops every entry.
+func TestCountsCacheClear(t *testing.T) {
+	counts := cache.NewCounts(time.Minute)
+	counts.Set("a", 1)
+	counts.Set("b", 2)
+
+	counts.Clear()
+
+	_, ok := counts.Get("a")
+	assert.False(t, ok)
+	_, ok = counts.Get("b")
+	assert.False(t, ok)
+}
+
+// TestFlightSharesConcurrentCalls verifies that concurrent reads of the same
+// key run the underlying query once and all receive its result.
+func TestFlightSharesConcurrentCalls(t *testing.T) {
+	flight := cache.NewFlight()
+	release := make(chan struct{})
+	var executions atomic.Int32
+
+	const callers = 5
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _, err := flight.Do("post:1", func() (any, error) {
+				executions.Add(1)
+				<-release
+				return "post", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "post", value)
+		}()
+	}
+
+	// Wait until every caller has joined before letting the read finish
+	assert.Eventually(t, func() bool { return flight.Stats().Calls == callers }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), executions.Load())
+	assert.Equal(t, cache.FlightStats{Calls: callers, Shared: callers}, flight.Stats())
+}
@@ -0,0 +1,31 @@
+package unit
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRenderCard verifies cards are PNGs of the social-card size, whatever
+// the title length.
+func TestRenderCard(t *testing.T) {
+	for _, title := range []string{
+		"Hello",
+		strings.Repeat("A very long headline that needs wrapping ", 10),
+		strings.Repeat("x", 300),
+		"",
+	} {
+		data, err := card.Render(title, "Jane Doe")
+		require.NoError(t, err)
+
+		img, err := png.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, card.WIDTH, img.Bounds().Dx())
+		assert.Equal(t, card.HEIGHT, img.Bounds().Dy())
+	}
+}
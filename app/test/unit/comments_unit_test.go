@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCommentsBatch verifies GET /api/comments validates the requested
+// post IDs before any query, and answers 502 when the visibility lookup
+// fails.
+func TestGetCommentsBatch(t *testing.T) {
+	tooMany := strings.TrimSuffix(strings.Repeat("686c3a82361beb165141b490,", handlers.MAX_BATCH_POST_IDS+1), ",")
+	for _, tc := range []struct {
+		name, query, message string
+		status               int
+	}{
+		{"missing post_ids", "", "post_ids required", 400},
+		{"only separators", "?post_ids=,%20,", "post_ids required", 400},
+		{"invalid post ID", "?post_ids=686c3a82361beb165141b490,nope", "Invalid post ID", 400},
+		{"too many post IDs", "?post_ids=" + tooMany, "Too many post IDs", 400},
+		{"invalid truncate", "?post_ids=686c3a82361beb165141b490&truncate=-1", "Invalid truncate", 400},
+		{"lookup failure", "?post_ids=686c3a82361beb165141b490,%20686c3a82361beb165141b491", "Failed to fetch comments", 502},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, _, _ := newMockedHandler(t)
+			h.DB.Posts = offlineCollection(t, "posts")
+			app := fiber.New()
+			app.Get("/api/comments", h.GetCommentsBatch)
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/comments"+tc.query, nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, tc.message, decodeResponse(t, resp.Body).Error)
+		})
+	}
+}
+
+// TestCreateCommentLength verifies new comments are held to the configured
+// length limits, counted in characters rather than bytes.
+func TestCreateCommentLength(t *testing.T) {
+	for content, message := range map[string]string{
+		"Hi":                    "Comment must be at least 3 characters",
+		strings.Repeat("é", 11): "Comment must be at most 10 characters",
+	} {
+		h, posts, comments := newMockedHandler(t)
+		h.Config.CommentMinLength, h.Config.CommentMaxLength = 3, 10
+		app := fiber.New()
+		app.Post("/api/posts/:id/comments", h.CreateComment)
+		req := httptest.NewRequest("POST", "/api/posts/686c3a82361beb165141b490/comments", strings.NewReader(`{"author":"ana","content":"`+content+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, 422, resp.StatusCode, content)
+		response := decodeResponse(t, resp.Body)
+		assert.Equal(t, handlers.VALIDATION_FAILED, response.Error)
+		assert.Contains(t, response.Data, map[string]interface{}{"field": "content", "message": message})
+		posts.AssertNotCalled(t, "CommentsLocked", mock.Anything, mock.Anything)
+		comments.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	}
+
+	// Exactly at the limit in characters, though longer in bytes
+	h, posts, _ := newMockedHandler(t)
+	h.Config.CommentMaxLength = 10
+	posts.On("CommentsLocked", mock.Anything, mock.Anything).Return(true, nil)
+	app := fiber.New()
+	app.Post("/api/posts/:id/comments", h.CreateComment)
+	req := httptest.NewRequest("POST", "/api/posts/686c3a82361beb165141b490/comments", strings.NewReader(`{"author":"ana","content":"`+strings.Repeat("é", 10)+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 403, resp.StatusCode, "validation passed, the closed thread refused it")
+}
+
+// TestGetPostTruncatesComments verifies truncate= shortens joined comments
+// and their replies at a word boundary, flags them has_more, leaves short
+// ones alone, and does not modify the comments shared between requests.
+func TestGetPostTruncatesComments(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	h.Config.CommentThreadDepth = 3
+	id := models.ID("686c3a82361beb165141b490")
+	parentID := models.ID("686c3a82361beb165141b4a0")
+	joined := []models.Comment{
+		{ID: parentID, PostID: id, Author: "ana", Content: "Hello wonderful world"},
+		{ID: "686c3a82361beb165141b4a1", PostID: id, ParentID: parentID, Author: "bob", Content: "Thanks a lot for writing this"},
+		{ID: "686c3a82361beb165141b4a2", PostID: id, Author: "eve", Content: "Short"},
+	}
+	posts.On("Get", mock.Anything, id, storage.CommentPage{Limit: handlers.DEFAULT_POST_COMMENTS_LIMIT}).
+		Return(models.BlogPost{ID: id, Title: "First Post", Comments: joined}, nil)
+	posts.On("RecordView", mock.Anything, id).Return(nil)
+
+	app := fiber.New()
+	app.Get("/api/posts/:id", h.GetPost)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts/"+id.String()+"?truncate=17", nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+
+	thread := decodeResponse(t, resp.Body).Data.(map[string]interface{})["comments"].([]interface{})
+	require.Len(t, thread, 2)
+	first := thread[0].(map[string]interface{})
+	assert.Equal(t, "Hello wonderful", first["content"])
+	assert.Equal(t, true, first["has_more"])
+	reply := first["replies"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Thanks a lot for", reply["content"])
+	assert.Equal(t, true, reply["has_more"])
+	short := thread[1].(map[string]interface{})
+	assert.Equal(t, "Short", short["content"])
+	assert.Nil(t, short["has_more"])
+	assert.Equal(t, "Hello wonderful world", joined[0].Content)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/posts/"+id.String()+"?truncate=-1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, "Invalid truncate", decodeResponse(t, resp.Body).Error)
+}
+
+// TestGetComment verifies the full comment endpoint rejects invalid IDs and
+// answers 502 when the comment cannot be read.
+func TestGetComment(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	h.DB.Comments = offlineCollection(t, "comments")
+	app := fiber.New()
+	app.Get("/api/comments/:id", h.GetComment)
+
+	for path, want := range map[string]struct {
+		status  int
+		message string
+	}{
+		"/api/comments/nope":                     {400, "Invalid comment ID"},
+		"/api/comments/686c3a82361beb165141b4a0": {502, "Failed to fetch comment"},
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, want.status, resp.StatusCode, path)
+		assert.Equal(t, want.message, decodeResponse(t, resp.Body).Error, path)
+	}
+}
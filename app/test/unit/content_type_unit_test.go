@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newContentTypeApp echoes the parsed comment author after JSONBody ran.
+func newContentTypeApp(allowForm bool) *fiber.App {
+	app := fiber.New()
+	app.Use(middleware.JSONBody(allowForm))
+	app.Post("/comments", func(c *fiber.Ctx) error {
+		if len(c.Body()) == 0 {
+			return c.SendString("")
+		}
+		var req models.CreateCommentRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+		}
+		return c.SendString(req.Author)
+	})
+	return app
+}
+
+// TestJSONBodyContentTypes covers accepted and rejected body encodings.
+func TestJSONBodyContentTypes(t *testing.T) {
+	cases := []struct {
+		name        string
+		allowForm   bool
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"plain json", false, "application/json", `{"author":"ana"}`, 200, "ana"},
+		{"utf-8 charset", false, "application/json; charset=UTF-8", `{"author":"ana"}`, 200, "ana"},
+		{"utf-8 bom is stripped", false, "application/json", "\xEF\xBB\xBF" + `{"author":"ana"}`, 200, "ana"},
+		{"vendor json", false, "application/vnd.blog+json", `{"author":"ana"}`, 200, "ana"},
+		{"csp report", false, "application/csp-report", `{"author":"ana"}`, 200, "ana"},
+		{"other charset", false, "application/json; charset=latin1", `{"author":"ana"}`, 415, ""},
+		{"text body", false, "text/plain", "ana", 415, ""},
+		{"missing content type", false, "", `{"author":"ana"}`, 415, ""},
+		{"form without opt-in", false, "application/x-www-form-urlencoded", "author=ana", 415, ""},
+		{"form with opt-in", true, "application/x-www-form-urlencoded;charset=UTF-8", "author=ana&content=hi", 200, "ana"},
+		{"empty body passes", false, "", "", 200, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/comments", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			resp, err := newContentTypeApp(tc.allowForm).Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			if tc.wantStatus == 200 {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tc.wantBody, string(body))
+			}
+		})
+	}
+}
+
+// TestJSONBodyIgnoresReads verifies that bodiless methods are never checked.
+func TestJSONBodyIgnoresReads(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.JSONBody(false))
+	app.Get("/posts", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	req := httptest.NewRequest("GET", "/posts", strings.NewReader("ignored"))
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
@@ -0,0 +1,98 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/content"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/sanitize"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAnalyzeContent verifies Markdown and HTML structure counts, including
+// that fenced code blocks are not mistaken for headings.
+func TestAnalyzeContent(t *testing.T) {
+	text := `# Getting started
+
+Read the [docs](https://example.com/docs) or the <a href="/faq">FAQ</a>.
+
+![diagram](/img/diagram.png)
+
+## Install
+
+` + "```sh" + `
+# not a heading
+go install ./...
+` + "```" + `
+
+<h3>Notes</h3>
+<img src="/img/notes.png">`
+
+	assert.Equal(t, models.ContentStats{
+		WordCount:    15,
+		HeadingCount: 3,
+		LinkCount:    2,
+		ImageCount:   2,
+	}, content.Analyze(text))
+}
+
+// TestAnalyzeEmptyContent verifies that empty bodies produce zero stats.
+func TestAnalyzeEmptyContent(t *testing.T) {
+	assert.Equal(t, models.ContentStats{}, content.Analyze(""))
+}
+
+// TestContentLinks verifies link extraction from Markdown and HTML, skipping
+// relative links, bare URLs, code blocks, and repeats.
+func TestContentLinks(t *testing.T) {
+	text := `See the [docs](https://example.com/docs "Docs") and <https://example.org>.
+Bare https://example.net/text is not a link, [faq](/faq) is relative.
+<a href="https://example.com/docs">again</a> <img src='http://cdn.example.com/a.png'>
+
+` + "```" + `
+[sample](https://example.com/in-code)
+` + "```"
+
+	assert.Equal(t, []string{
+		"https://example.com/docs",
+		"https://example.org",
+		"http://cdn.example.com/a.png",
+	}, content.Links(text))
+	assert.Empty(t, content.Links("no links here"))
+}
+
+// TestContentLinkedPosts verifies post links are recognized by public link,
+// relative path, and API path, skipping other links and code blocks.
+func TestContentLinkedPosts(t *testing.T) {
+	text := `Follow-up to [part one](https://blog.example.com/posts/abc?ref=feed#intro),
+see also [part two](/posts/def) and <a href="http://api.example.net/api/posts/ghi">three</a>.
+[Again](/posts/abc), [elsewhere](https://other.example.com/posts/xyz), [nested](/posts/a/b).
+
+` + "```" + `
+[sample](/posts/in-code)
+` + "```"
+
+	assert.Equal(t, []string{"abc", "def", "ghi"}, content.LinkedPosts(text, "https://blog.example.com/posts/{id}"))
+	assert.Equal(t, []string{"ghi"}, content.LinkedPosts(text, ""))
+	assert.Empty(t, content.LinkedPosts("no links here", "https://blog.example.com/posts/{id}"))
+}
+
+// TestRenderContent verifies Markdown rendering of common blocks and
+// inlines, and that unsafe inline HTML does not survive sanitizing the
+// rendered HTML.
+func TestRenderContent(t *testing.T) {
+	text := "# Title\n\nSome *em*, **strong**, and `a<b`.\n\n- one\n- two\n\n" +
+		"```go\nx := 1\n```\n\n[docs](https://example.com \"Docs\") <script>alert(1)</script> [bad](javascript:alert(1))"
+
+	rendered := content.Render(text)
+	assert.Equal(t, "<h1>Title</h1>\n"+
+		"<p>Some <em>em</em>, <strong>strong</strong>, and <code>a&lt;b</code>.</p>\n"+
+		"<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n"+
+		"<pre><code class=\"language-go\">x := 1\n</code></pre>\n"+
+		"<p><a href=\"https://example.com\" title=\"Docs\">docs</a> <script>alert(1)</script> <a href=\"javascript:alert(1)\">bad</a></p>\n",
+		rendered)
+
+	var policy *sanitize.Policy
+	assert.Equal(t, "<p><a href=\"https://example.com\" title=\"Docs\">docs</a>  <a>bad</a></p>\n",
+		policy.SanitizeHTML(strings.Split(rendered, "</pre>\n")[1]))
+}
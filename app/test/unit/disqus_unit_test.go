@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/disqus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// disqusExport is a trimmed Disqus export with one thread, a reply, and a
+// deleted comment.
+const disqusExport = `<?xml version="1.0" encoding="utf-8"?>
+<disqus xmlns="http://disqus.com" xmlns:dsq="http://disqus.com/disqus-internals">
+  <thread dsq:id="100">
+    <id>507f1f77bcf86cd799439011</id>
+    <link>https://blog.example.com/posts/my-first-post/</link>
+    <title>My First Blog Post</title>
+  </thread>
+  <post dsq:id="1">
+    <message><![CDATA[<p>Great post!</p>]]></message>
+    <createdAt>2019-05-02T18:24:00Z</createdAt>
+    <isDeleted>false</isDeleted>
+    <isSpam>false</isSpam>
+    <author><name>Jane Smith</name></author>
+    <thread dsq:id="100"/>
+  </post>
+  <post dsq:id="2">
+    <message>Thanks!</message>
+    <createdAt>2019-05-03T08:00:00Z</createdAt>
+    <isDeleted>false</isDeleted>
+    <isSpam>false</isSpam>
+    <author><name></name><username>author42</username></author>
+    <thread dsq:id="100"/>
+    <parent dsq:id="1"/>
+  </post>
+  <post dsq:id="3">
+    <message>removed</message>
+    <createdAt>2019-05-04T08:00:00Z</createdAt>
+    <isDeleted>true</isDeleted>
+    <isSpam>false</isSpam>
+    <author><name>Someone</name></author>
+    <thread dsq:id="100"/>
+  </post>
+</disqus>`
+
+// TestDisqusParse verifies threads and comments are read with their
+// thread and parent references.
+func TestDisqusParse(t *testing.T) {
+	export, err := disqus.Parse(strings.NewReader(disqusExport))
+	require.NoError(t, err)
+
+	require.Len(t, export.Threads, 1)
+	thread := export.Threads[0]
+	assert.Equal(t, "100", thread.DsqID)
+	assert.Equal(t, "507f1f77bcf86cd799439011", thread.Identifier)
+	assert.Equal(t, "my-first-post", thread.Slug())
+
+	require.Len(t, export.Comments, 3)
+	assert.Equal(t, "<p>Great post!</p>", export.Comments[0].Message)
+	assert.Equal(t, time.Date(2019, 5, 2, 18, 24, 0, 0, time.UTC), export.Comments[0].CreatedAt)
+	assert.Equal(t, "author42", export.Comments[1].Author)
+	assert.Equal(t, "1", export.Comments[1].ParentID)
+	assert.True(t, export.Comments[2].Deleted)
+}
+
+// TestDisqusThreadComments verifies deleted comments are dropped and
+// replies keep their parent as an import ID.
+func TestDisqusThreadComments(t *testing.T) {
+	export, err := disqus.Parse(strings.NewReader(disqusExport))
+	require.NoError(t, err)
+
+	comments := export.ThreadComments()["100"]
+	require.Len(t, comments, 2)
+	assert.Equal(t, "disqus:1", comments[0].ImportID)
+	assert.Empty(t, comments[0].ParentImportID)
+	assert.Equal(t, "disqus:1", comments[1].ParentImportID)
+}
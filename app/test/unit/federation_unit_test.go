@@ -0,0 +1,73 @@
+package unit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/federation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFederationSignature verifies that signed requests verify with the
+// signer's key and fail when the body or key does not match.
+func TestFederationSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	body := []byte(`{"type":"Follow"}`)
+	req, err := http.NewRequest(http.MethodPost, "https://blog.example.com/ap/inbox", strings.NewReader(string(body)))
+	require.NoError(t, err)
+	require.NoError(t, federation.Sign(req, body, "https://remote.example/users/ann#main-key", key))
+
+	sig, err := federation.ParseSignature(req.Header.Get("Signature"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote.example/users/ann#main-key", sig.KeyID)
+
+	get := func(name string) string { return req.Header.Get(name) }
+	assert.NoError(t, federation.Verify(sig, req.Method, "/ap/inbox", get, body, &key.PublicKey))
+	assert.ErrorIs(t, federation.Verify(sig, req.Method, "/ap/inbox", get, []byte(`{}`), &key.PublicKey), federation.ErrBadSignature)
+	assert.ErrorIs(t, federation.Verify(sig, req.Method, "/ap/inbox", get, body, &other.PublicKey), federation.ErrBadSignature)
+	assert.ErrorIs(t, federation.Verify(sig, req.Method, "/ap/outbox", get, body, &key.PublicKey), federation.ErrBadSignature)
+
+	_, err = federation.ParseSignature(`keyId="x"`)
+	assert.ErrorIs(t, err, federation.ErrBadSignature)
+}
+
+// TestFederationPlainText verifies Note HTML is reduced to comment text.
+func TestFederationPlainText(t *testing.T) {
+	html := `<p><span class="h-card"><a href="https://blog.example.com">@blog</a></span> Nice post!</p><p>Line one<br>line &amp; two</p>`
+	assert.Equal(t, "@blog Nice post!\n\nLine one\nline & two", federation.PlainText(html))
+}
+
+// TestFetchActorRefusesInternalHosts verifies actor IRIs taken from inbox
+// signatures are refused, before any request is made, unless they are https
+// URLs of public hosts.
+func TestFetchActorRefusesInternalHosts(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+	service := federation.New(nil, "https://blog.example.com", "blog", "Blog")
+
+	for _, iri := range []string{
+		server.URL + "/users/ann#main-key",
+		strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/users/ann",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/users/ann",
+		"http://remote.example/users/ann",
+	} {
+		_, err := service.FetchActor(context.Background(), iri)
+		assert.ErrorIs(t, err, federation.ErrForbiddenURL, iri)
+	}
+	assert.Zero(t, hits.Load(), "no request may reach the server")
+}
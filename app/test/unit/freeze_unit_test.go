@@ -0,0 +1,55 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContentFreeze verifies that an active freeze rejects guarded
+// requests with 403 and audits them, that requests the guard does not
+// apply to pass, and that a failed lookup rejects rather than lets through.
+func TestContentFreeze(t *testing.T) {
+	now := time.Now()
+	window := &models.ContentFreeze{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Reason: "launch"}
+	assert.True(t, window.ActiveAt(now))
+	assert.False(t, window.ActiveAt(window.EndsAt))
+
+	var freeze *models.ContentFreeze
+	var lookupErr error
+	lookup := func(context.Context) (*models.ContentFreeze, error) { return freeze, lookupErr }
+	var audited []string
+	audit := func(c *fiber.Ctx, _ *models.ContentFreeze, operation string) { audited = append(audited, operation) }
+	isPublic := func(c *fiber.Ctx) bool { return c.Query("visibility") != models.VISIBILITY_PRIVATE }
+
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(200) }
+	app.Post("/posts", middleware.ContentFreeze(lookup, audit, models.FREEZE_PUBLISH, isPublic), ok)
+	app.Delete("/posts/1", middleware.ContentFreeze(lookup, audit, models.FREEZE_DELETE, nil), ok)
+
+	status := func(method, target string) int {
+		resp, err := app.Test(httptest.NewRequest(method, target, nil))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, status("POST", "/posts"))
+	assert.Equal(t, 200, status("DELETE", "/posts/1"))
+
+	freeze = window
+	assert.Equal(t, 403, status("POST", "/posts"))
+	assert.Equal(t, 403, status("DELETE", "/posts/1"))
+	assert.Equal(t, 200, status("POST", "/posts?visibility=private"))
+	assert.Equal(t, []string{models.FREEZE_PUBLISH, models.FREEZE_DELETE}, audited)
+
+	freeze, lookupErr = nil, errors.New("database down")
+	assert.Equal(t, 502, status("DELETE", "/posts/1"))
+}
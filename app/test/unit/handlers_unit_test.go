@@ -3,250 +3,1574 @@ package unit
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/config"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
 	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/routes"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/pedrobertao/challenge-prosi/app/lib/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// MockCursor implements the necessary mongo.Cursor methods for testing.
-// This struct simulates MongoDB cursor behavior by maintaining an internal
-// slice of posts and an index to track iteration position.
-type MockCursor struct {
-	posts []models.BlogPost // Collection of blog posts to iterate through
-	index int               // Current position in the posts slice
+// MockPostRepository implements storage.PostRepository with testify/mock,
+// so handler tests control what the database returns.
+type MockPostRepository struct {
+	mock.Mock // Embedding mock.Mock provides expectation and assertion capabilities
 }
 
-// Next simulates MongoDB cursor's Next() method.
-// Returns true if there are more documents to process, false otherwise.
-// This method is called by the handler to check if more posts are available.
-func (m *MockCursor) Next(ctx context.Context) bool {
-	return m.index < len(m.posts)
+func (m *MockPostRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
 }
 
-// Decode simulates MongoDB cursor's Decode() method.
-// Copies the current post data into the provided interface.
-// Increments the index to move to the next post after successful decode.
-//
-// Parameters:
-//   - val: pointer to the struct where decoded data should be stored
-//
-// Returns error if no more documents are available.
-func (m *MockCursor) Decode(val interface{}) error {
-	// Check if we've reached the end of available posts
-	if m.index >= len(m.posts) {
-		return mongo.ErrNoDocuments
-	}
+func (m *MockPostRepository) List(ctx context.Context, filter bson.M, sort bson.D, skip, limit int64) ([]models.BlogPostHeader, error) {
+	args := m.Called(ctx, filter, sort, skip, limit)
+	return args.Get(0).([]models.BlogPostHeader), args.Error(1)
+}
 
-	// Cast the interface to BlogPost pointer and copy current post data
-	post := val.(*models.BlogPost)
-	*post = m.posts[m.index]
+func (m *MockPostRepository) Stream(ctx context.Context, filter bson.M, opts *options.FindOptions) (*mongo.Cursor, error) {
+	args := m.Called(ctx, filter, opts)
+	cursor, _ := args.Get(0).(*mongo.Cursor)
+	return cursor, args.Error(1)
+}
 
-	// Move to next post for subsequent calls
-	m.index++
-	return nil
+func (m *MockPostRepository) Get(ctx context.Context, id models.ID, comments storage.CommentPage) (models.BlogPost, error) {
+	args := m.Called(ctx, id, comments)
+	return args.Get(0).(models.BlogPost), args.Error(1)
 }
 
-// Close simulates MongoDB cursor's Close() method.
-// In real MongoDB operations, this releases cursor resources.
-// For testing purposes, this is a no-op that always succeeds.
-func (m *MockCursor) Close(ctx context.Context) error {
-	return nil
+func (m *MockPostRepository) Exists(ctx context.Context, id models.ID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
 }
 
-// MockCollection simulates MongoDB collection operations for testing.
-// Uses testify/mock to track method calls and return configured responses.
-type MockCollection struct {
-	mock.Mock // Embedding mock.Mock provides expectation and assertion capabilities
+func (m *MockPostRepository) Published(ctx context.Context, id models.ID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
 }
 
-// MockHandler simulates the actual handler structure for testing.
-// Contains references to mock collections to control database behavior during tests.
-type MockHandler struct {
-	mock.Mock                 // Provides mock capabilities for handler methods
-	Posts     *MockCollection // Mock posts collection for database operations
-	Comments  *MockCollection // Mock comments collection for counting operations
+func (m *MockPostRepository) CommentsLocked(ctx context.Context, id models.ID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
 }
 
-// Find simulates MongoDB collection's Find() method.
-// Returns a cursor and potential error based on configured mock expectations.
-//
-// Parameters:
-//   - ctx: context for the database operation
-//   - filter: BSON filter criteria (typically bson.M{} for all documents)
-//
-// Returns configured mock cursor and error from test expectations.
-func (m *MockCollection) Find(ctx context.Context, filter interface{}) (*mongo.Cursor, error) {
-	args := m.Called(ctx, filter)
-	return args.Get(0).(*mongo.Cursor), args.Error(1)
-}
-
-// CountDocuments simulates MongoDB collection's CountDocuments() method.
-// Returns the number of documents matching the filter criteria.
-//
-// Parameters:
-//   - ctx: context for the database operation
-//   - filter: BSON filter criteria (typically contains post_id for comment counting)
-//
-// Returns configured mock count and error from test expectations.
-func (m *MockCollection) CountDocuments(ctx context.Context, filter interface{}) (int64, error) {
-	args := m.Called(ctx, filter)
+func (m *MockPostRepository) Insert(ctx context.Context, post models.BlogPost) error {
+	return m.Called(ctx, post).Error(0)
+}
+
+func (m *MockPostRepository) Update(ctx context.Context, id models.ID, update bson.M) (models.BlogPost, error) {
+	args := m.Called(ctx, id, update)
+	return args.Get(0).(models.BlogPost), args.Error(1)
+}
+
+func (m *MockPostRepository) Delete(ctx context.Context, id models.ID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockPostRepository) Restore(ctx context.Context, id models.ID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockPostRepository) Liked(ctx context.Context, userKey string, postIDs []models.ID) (map[models.ID]bool, error) {
+	args := m.Called(ctx, userKey, postIDs)
+	return args.Get(0).(map[models.ID]bool), args.Error(1)
+}
+
+func (m *MockPostRepository) Likes(ctx context.Context, id models.ID, skip, limit int64) ([]models.Like, int64, error) {
+	args := m.Called(ctx, id, skip, limit)
+	return args.Get(0).([]models.Like), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockPostRepository) LastModified(ctx context.Context) (time.Time, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockPostRepository) Touch(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockPostRepository) TouchPost(ctx context.Context, id models.ID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockPostRepository) RecordCommentChange(ctx context.Context, id models.ID, delta int64) error {
+	return m.Called(ctx, id, delta).Error(0)
+}
+
+func (m *MockPostRepository) RecordView(ctx context.Context, id models.ID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockPostRepository) RecordTitleImpressions(ctx context.Context, shown map[models.ID]int) error {
+	return m.Called(ctx, shown).Error(0)
+}
+
+func (m *MockPostRepository) RecordTitleClick(ctx context.Context, id models.ID, variant int) error {
+	return m.Called(ctx, id, variant).Error(0)
+}
+
+// MockCommentRepository implements storage.CommentRepository with
+// testify/mock.
+type MockCommentRepository struct {
+	mock.Mock // Embedding mock.Mock provides expectation and assertion capabilities
+}
+
+func (m *MockCommentRepository) CountByPost(ctx context.Context, postID models.ID) (int64, error) {
+	args := m.Called(ctx, postID)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-// GetPosts simulates the actual GetPosts handler method for testing.
-// Returns a hardcoded JSON response that matches the expected API format.
-// This allows testing the HTTP layer without involving real database operations.
-//
-// Parameters:
-//   - c: Fiber context containing request/response information
-//
-// Returns error if response sending fails (typically nil in tests).
-func (m *MockHandler) GetPosts(c *fiber.Ctx) error {
-	// Return a predefined JSON response that matches the expected API format
-	// This response contains two blog posts with different comment counts
-	return c.Status(200).Send([]byte(`{
-		"success": true,
-		"error": "",
-		"data": [
-			{
-				"id": "686c3a82361beb165141b490",
-				"title": "First Post",
-				"comment_count": 3,
-				"created_at": "2025-07-07T16:00:00Z"
-			},
-			{
-				"id": "686c3a82361beb165141b491",
-				"title": "Second Post",
-				"comment_count": 1,
-				"created_at": "2025-07-07T17:00:00Z"
-			}
-		]
-	}`))
+func (m *MockCommentRepository) List(ctx context.Context, postID models.ID, page storage.CommentPage) ([]models.Comment, error) {
+	args := m.Called(ctx, postID, page)
+	return args.Get(0).([]models.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) Summarize(ctx context.Context, postID models.ID) (models.CommentThreadSummary, error) {
+	args := m.Called(ctx, postID)
+	return args.Get(0).(models.CommentThreadSummary), args.Error(1)
+}
+
+func (m *MockCommentRepository) Insert(ctx context.Context, comment models.Comment) error {
+	return m.Called(ctx, comment).Error(0)
+}
+
+func (m *MockCommentRepository) UpdateContent(ctx context.Context, id models.ID, content string, editedAt time.Time) (models.Comment, error) {
+	args := m.Called(ctx, id, content, editedAt)
+	return args.Get(0).(models.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) Get(ctx context.Context, id models.ID) (models.Comment, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) Delete(ctx context.Context, id models.ID) (models.Comment, int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Comment), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCommentRepository) Restore(ctx context.Context, id models.ID) (models.Comment, int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Comment), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCommentRepository) Moderate(ctx context.Context, id models.ID, status, moderatedBy string, at time.Time) (models.Comment, error) {
+	args := m.Called(ctx, id, status, moderatedBy, at)
+	return args.Get(0).(models.Comment), args.Error(1)
+}
+
+// newMockedHandler builds a real Handler whose post and comment
+// repositories are mocks. The storage carries only the ID codec, so any
+// handler reaching for a collection directly fails the test loudly.
+func newMockedHandler(t *testing.T) (*handlers.Handler, *MockPostRepository, *MockCommentRepository) {
+	ids, err := storage.NewIDCodec(storage.ID_FORMAT_OBJECTID)
+	require.NoError(t, err)
+
+	h := handlers.New(&storage.Storage{IDs: ids}, &config.Config{
+		CommentMinLength:     1,
+		CommentMaxLength:     5000,
+		CommentCountCacheTTL: time.Minute,
+		DBReadTimeout:        10 * time.Second,
+		DBWriteTimeout:       10 * time.Second,
+		DBAggregateTimeout:   30 * time.Second,
+		DBTransactionTimeout: 30 * time.Second,
+		DBMaxTimeout:         time.Minute,
+	})
+	posts, comments := &MockPostRepository{}, &MockCommentRepository{}
+	h.Posts, h.Comments = posts, comments
+	return h, posts, comments
+}
+
+// decodeResponse reads an APIResponse from a test response body.
+func decodeResponse(t *testing.T, body io.Reader) models.APIResponse {
+	var response models.APIResponse
+	require.NoError(t, json.NewDecoder(body).Decode(&response))
+	return response
 }
 
 // TestGetPostsSuccess tests the successful retrieval of blog posts.
 // This test verifies that the GetPosts handler correctly:
-// 1. Fetches posts from the database
+// 1. Lists one page of posts from the repository
 // 2. Counts comments for each post
-// 3. Returns properly formatted API response
-// 4. Handles the complete request-response cycle
+// 3. Flags the posts the requester liked
+// 4. Returns a properly formatted API response with page metadata
 func TestGetPostsSuccess(t *testing.T) {
 	// === SETUP PHASE ===
-	// Create specific ObjectIDs that match the expected response
-	// These IDs are used to ensure consistency between test data and expected output
-	id1, _ := primitive.ObjectIDFromHex("686c3a82361beb165141b490")
-	id2, _ := primitive.ObjectIDFromHex("686c3a82361beb165141b491")
-
-	// Create test blog posts that simulate real database records
-	// Each post has realistic content and timestamps offset from current time
-	testPosts := []models.BlogPost{
-		{
-			ID:        id1,
-			Title:     "First Post",
-			Content:   "This is the first post content", // Content won't appear in summary
-			CreatedAt: time.Now().Add(-2 * time.Hour),   // Posted 2 hours ago
-		},
-		{
-			ID:        id2,
-			Title:     "Second Post",
-			Content:   "This is the second post content", // Content won't appear in summary
-			CreatedAt: time.Now().Add(-1 * time.Hour),    // Posted 1 hour ago
+	h, posts, comments := newMockedHandler(t)
+	id1 := models.ID("686c3a82361beb165141b490")
+	id2 := models.ID("686c3a82361beb165141b491")
+	headers := []models.BlogPostHeader{
+		{ID: id1, Title: "First Post", CreatedAt: time.Now().Add(-2 * time.Hour)},
+		{ID: id2, Title: "Second Post", CreatedAt: time.Now().Add(-1 * time.Hour)},
+	}
+
+	// === MOCK EXPECTATIONS SETUP ===
+	// The second page of two posts per page skips the first two matches
+	posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+	posts.On("Count", mock.Anything, mock.Anything).Return(int64(4), nil)
+	newestFirst := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	posts.On("List", mock.Anything, mock.Anything, newestFirst, int64(2), int64(2)).Return(headers, nil)
+	posts.On("Liked", mock.Anything, mock.Anything, []models.ID{id1, id2}).Return(map[models.ID]bool{id1: true}, nil)
+	comments.On("CountByPost", mock.Anything, id1).Return(int64(3), nil)
+	comments.On("CountByPost", mock.Anything, id2).Return(int64(1), nil)
+
+	// === EXECUTION PHASE ===
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts?page=2&limit=2", nil))
+	require.NoError(t, err)
+
+	// === ASSERTION PHASE ===
+	assert.Equal(t, 200, resp.StatusCode)
+	response := decodeResponse(t, resp.Body)
+	assert.True(t, response.Success)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, &models.Pagination{Page: 2, Limit: 2, Total: 4, TotalPages: 2}, response.Pagination)
+
+	summaries, ok := response.Data.([]interface{})
+	require.True(t, ok)
+	require.Len(t, summaries, 2)
+
+	// JSON numbers are unmarshaled as float64
+	first := summaries[0].(map[string]interface{})
+	assert.Equal(t, "First Post", first["title"])
+	assert.Equal(t, float64(3), first["comment_count"])
+	assert.Equal(t, true, first["liked"])
+
+	second := summaries[1].(map[string]interface{})
+	assert.Equal(t, "Second Post", second["title"])
+	assert.Equal(t, float64(1), second["comment_count"])
+	assert.Nil(t, second["liked"])
+
+	posts.AssertExpectations(t)
+	comments.AssertExpectations(t)
+}
+
+// TestGetPostsPaginationBounds verifies GET /api/posts pages default to
+// DEFAULT_POSTS_PAGE_SIZE, cap the limit at MAX_POSTS_PAGE_SIZE, skip the
+// earlier pages, and refuse page numbers and limits that are not positive.
+func TestGetPostsPaginationBounds(t *testing.T) {
+	newestFirst := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	for _, tc := range []struct {
+		query       string
+		skip, limit int64
+		pagination  models.Pagination
+	}{
+		{"", 0, handlers.DEFAULT_POSTS_PAGE_SIZE, models.Pagination{Page: 1, Limit: handlers.DEFAULT_POSTS_PAGE_SIZE, Total: 250, TotalPages: 13}},
+		{"?limit=500", 0, handlers.MAX_POSTS_PAGE_SIZE, models.Pagination{Page: 1, Limit: handlers.MAX_POSTS_PAGE_SIZE, Total: 250, TotalPages: 3}},
+		{"?page=3&limit=10", 20, 10, models.Pagination{Page: 3, Limit: 10, Total: 250, TotalPages: 25}},
+		{"?page=99&limit=100", 9800, 100, models.Pagination{Page: 99, Limit: 100, Total: 250, TotalPages: 3}},
+	} {
+		h, posts, _ := newMockedHandler(t)
+		posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+		posts.On("Count", mock.Anything, mock.Anything).Return(int64(250), nil)
+		posts.On("List", mock.Anything, mock.Anything, newestFirst, tc.skip, tc.limit).Return([]models.BlogPostHeader{}, nil)
+
+		app := fiber.New()
+		app.Get("/api/posts", h.GetPosts)
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts"+tc.query, nil))
+		require.NoError(t, err)
+
+		require.Equal(t, 200, resp.StatusCode, tc.query)
+		assert.Equal(t, &tc.pagination, decodeResponse(t, resp.Body).Pagination, tc.query)
+		posts.AssertExpectations(t)
+	}
+
+	for _, query := range []string{"page=0", "page=-1", "page=two", "limit=0", "limit=-5", "limit=ten", "page=1.5"} {
+		h, posts, _ := newMockedHandler(t)
+		posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+		app := fiber.New()
+		app.Get("/api/posts", h.GetPosts)
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts?"+query, nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, 400, resp.StatusCode, query)
+		assert.Equal(t, "Invalid pagination", decodeResponse(t, resp.Body).Error, query)
+		posts.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+// TestGetPostJoinsComments verifies a post page is served from the one
+// aggregation joining its comments: none are queried separately, and
+// replies are nested under the comment they answer.
+func TestGetPostJoinsComments(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	h.Config.CommentThreadDepth = 3
+	id := models.ID("686c3a82361beb165141b490")
+	parentID := models.ID("686c3a82361beb165141b4a0")
+	posts.On("Get", mock.Anything, id, storage.CommentPage{Limit: handlers.DEFAULT_POST_COMMENTS_LIMIT}).Return(models.BlogPost{
+		ID:           id,
+		Title:        "First Post",
+		CommentCount: 2,
+		Comments: []models.Comment{
+			{ID: parentID, PostID: id, Author: "ana", Content: "First"},
+			{ID: "686c3a82361beb165141b4a1", PostID: id, ParentID: parentID, Author: "bob", Content: "Reply"},
 		},
+	}, nil)
+	posts.On("RecordView", mock.Anything, id).Return(nil)
+
+	app := fiber.New()
+	app.Get("/api/posts/:id", h.GetPost)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts/"+id.String(), nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+
+	post := decodeResponse(t, resp.Body).Data.(map[string]interface{})
+	assert.Equal(t, float64(2), post["comment_count"])
+	thread := post["comments"].([]interface{})
+	require.Len(t, thread, 1)
+	replies := thread[0].(map[string]interface{})["replies"].([]interface{})
+	require.Len(t, replies, 1)
+	assert.Equal(t, "Reply", replies[0].(map[string]interface{})["content"])
+
+	posts.AssertNumberOfCalls(t, "Get", 1)
+	assert.Empty(t, comments.Calls, "comments fetched outside the aggregation")
+}
+
+// TestGetPostNotFound verifies a missing post answers 404.
+func TestGetPostNotFound(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	posts.On("Get", mock.Anything, id, storage.CommentPage{Limit: handlers.DEFAULT_POST_COMMENTS_LIMIT}).Return(models.BlogPost{}, mongo.ErrNoDocuments)
+
+	app := fiber.New()
+	app.Get("/api/posts/:id", h.GetPost)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts/"+id.String(), nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.Equal(t, "Post not found", decodeResponse(t, resp.Body).Error)
+	posts.AssertExpectations(t)
+}
+
+// TestGetPostETag verifies a post's ETag changes with its view count, so a
+// 304 never serves a stale count, and that 304s vary on Accept-Language.
+func TestGetPostETag(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	lastModified := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	page := storage.CommentPage{Limit: handlers.DEFAULT_POST_COMMENTS_LIMIT}
+	posts.On("Get", mock.Anything, id, page).
+		Return(models.BlogPost{ID: id, Title: "First Post", LastModified: lastModified, ViewCount: 1}, nil).Once()
+	posts.On("Get", mock.Anything, id, page).
+		Return(models.BlogPost{ID: id, Title: "First Post", LastModified: lastModified, ViewCount: 2}, nil)
+	posts.On("RecordView", mock.Anything, id).Return(nil)
+
+	app := fiber.New()
+	app.Get("/api/posts/:id", h.GetPost)
+	get := func(headers map[string]string) *http.Response {
+		req := httptest.NewRequest("GET", "/api/posts/"+id.String(), nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
 	}
 
-	// === MOCK SETUP PHASE ===
-	// Create mock cursor that will iterate through our test posts
-	// The cursor starts at index 0 and will return each post in sequence
-	mockCursor := &MockCursor{
-		posts: testPosts,
-		index: 0, // Start at beginning of posts array
+	resp := get(nil)
+	require.Equal(t, 200, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+
+	resp = get(map[string]string{
+		"If-None-Match":     etag,
+		"If-Modified-Since": lastModified.Format(http.TimeFormat),
+	})
+	require.Equal(t, 200, resp.StatusCode, "view count changed")
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+
+	resp = get(map[string]string{"If-None-Match": resp.Header.Get("ETag")})
+	assert.Equal(t, 304, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Vary"), "Accept-Language")
+
+	resp = get(map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)})
+	assert.Equal(t, 200, resp.StatusCode, "If-Modified-Since ignores counters")
+}
+
+// TestGetHiddenPost verifies private and scheduled posts answer 404 to
+// anonymous readers and other users but are served to administrators. The
+// admin requests send If-None-Match: * so they stop at the 304, before the
+// translation lookups that need a database.
+func TestGetHiddenPost(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	h.Config.AdminUsers = []string{"ana"}
+	page := storage.CommentPage{Limit: handlers.DEFAULT_POST_COMMENTS_LIMIT}
+	publishAt := time.Now().Add(time.Hour)
+	hidden := map[string]models.BlogPost{
+		"private":   {ID: "686c3a82361beb165141b490", Visibility: models.VISIBILITY_PRIVATE},
+		"scheduled": {ID: "686c3a82361beb165141b491", PublishAt: &publishAt},
 	}
 
-	// Create mock collections to simulate database operations
-	mockPosts := &MockCollection{}    // Will handle Posts.Find() operations
-	mockComments := &MockCollection{} // Will handle Comments.CountDocuments() operations
+	app := fiber.New()
+	app.Get("/api/posts/:id", h.GetPost)
+	for name, post := range hidden {
+		t.Run(name, func(t *testing.T) {
+			posts.On("Get", mock.Anything, post.ID, page).Return(post, nil)
+			posts.On("RecordView", mock.Anything, post.ID).Return(nil)
 
-	// === MOCK EXPECTATIONS SETUP ===
-	// Configure the posts collection to return our mock cursor when Find() is called
-	// The bson.M{} parameter represents an empty filter (get all posts)
-	mockPosts.On("Find", mock.Anything, bson.M{}).Return((mockCursor), nil)
+			for _, tc := range []struct {
+				user string
+				want int
+			}{{"", 404}, {"bob", 404}, {"ana", 304}} {
+				req := httptest.NewRequest("GET", "/api/posts/"+post.ID.String(), nil)
+				req.Header.Set("If-None-Match", "*")
+				if tc.user != "" {
+					signed, err := h.Auth.Sign(jwt.Claims{Subject: "686c3a82361beb165141b4a0", Name: tc.user, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+					require.NoError(t, err)
+					req.Header.Set("Authorization", "Bearer "+signed)
+				}
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, resp.StatusCode, "user %q", tc.user)
+				assert.Contains(t, resp.Header.Get("Vary"), "Authorization")
+			}
+		})
+	}
+	posts.AssertNumberOfCalls(t, "RecordView", 2)
+}
 
-	// Configure comment counts for each post
-	// First post should have 3 comments
-	mockComments.On("CountDocuments", mock.Anything, bson.M{"post_id": testPosts[0].ID}).Return(int64(3), nil)
-	// Second post should have 1 comment
-	mockComments.On("CountDocuments", mock.Anything, bson.M{"post_id": testPosts[1].ID}).Return(int64(1), nil)
+// TestDBTimeouts verifies database operations are bounded by the timeout
+// of their class, unless the request's context has a sooner deadline.
+func TestDBTimeouts(t *testing.T) {
+	h, _, comments := newMockedHandler(t)
+	commentID := models.ID("686c3a82361beb165141b4a0")
+	var deadlines []time.Time
+	comments.On("Delete", mock.Anything, commentID).Run(func(args mock.Arguments) {
+		deadline, ok := args.Get(0).(context.Context).Deadline()
+		require.True(t, ok)
+		deadlines = append(deadlines, deadline)
+	}).Return(models.Comment{}, int64(0), mongo.ErrNoDocuments)
 
-	// Create handler instance with mock collections
-	handler := &MockHandler{
-		Posts:    mockPosts,    // Mock posts collection
-		Comments: mockComments, // Mock comments collection
+	app := fiber.New()
+	app.Delete("/api/comments/:id", h.DeleteComment)
+	app.Delete("/hurried/comments/:id", func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		c.SetUserContext(ctx)
+		return h.DeleteComment(c)
+	})
+	start := time.Now()
+	for _, path := range []string{"/api/comments/", "/hurried/comments/"} {
+		_, err := app.Test(httptest.NewRequest("DELETE", path+commentID.String(), nil))
+		require.NoError(t, err)
 	}
 
-	// === HTTP TEST SETUP ===
-	// Create Fiber application instance for testing HTTP endpoints
+	require.Len(t, deadlines, 2)
+	assert.WithinDuration(t, start.Add(h.Config.DBTransactionTimeout), deadlines[0], 5*time.Second)
+	assert.True(t, deadlines[1].Before(start.Add(2*time.Second)), "client deadline ignored")
+}
+
+// TestCreateCommentMissingPost verifies no comment is stored for a post
+// that does not exist.
+func TestCreateCommentMissingPost(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	posts.On("CommentsLocked", mock.Anything, id).Return(false, mongo.ErrNoDocuments)
+
 	app := fiber.New()
-	// Register the GetPosts handler for the /api/posts route
-	app.Get("/api/posts", handler.GetPosts)
+	app.Post("/api/posts/:id/comments", h.CreateComment)
+	req := httptest.NewRequest("POST", "/api/posts/"+id.String()+"/comments", strings.NewReader(`{"author":"ana","content":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
 
-	// Create HTTP GET request to test the endpoint
-	req := httptest.NewRequest("GET", "/api/posts", nil)
+	assert.Equal(t, 404, resp.StatusCode)
+	comments.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	posts.AssertExpectations(t)
+}
 
-	// === EXECUTION PHASE ===
-	// Execute the HTTP request against our test application
-	// This triggers the entire request-response cycle
+// TestGetPostCommentPage verifies the comments_* parameters select the page
+// of comments joined into the post.
+func TestGetPostCommentPage(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	page := storage.CommentPage{Skip: 10, Limit: 10, NewestFirst: true}
+	posts.On("Get", mock.Anything, id, page).Return(models.BlogPost{}, mongo.ErrNoDocuments)
+
+	app := fiber.New()
+	app.Get("/api/posts/:id", h.GetPost)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts/"+id.String()+"?comments_page=2&comments_limit=10&comments_sort=newest", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/posts/"+id.String()+"?comments_sort=popular", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	posts.AssertExpectations(t)
+}
+
+// TestGetPostUnknownView verifies unknown response views are rejected
+// before the post is loaded.
+func TestGetPostUnknownView(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+
+	app := fiber.New()
+	app.Get("/api/posts/:id", h.GetPost)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts/686c3a82361beb165141b490?view=tablet", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	posts.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCreateCommentClosedThread verifies comments on a post whose thread
+// was closed are rejected.
+func TestCreateCommentClosedThread(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	posts.On("CommentsLocked", mock.Anything, id).Return(true, nil)
+
+	app := fiber.New()
+	app.Post("/api/posts/:id/comments", h.CreateComment)
+	req := httptest.NewRequest("POST", "/api/posts/"+id.String()+"/comments", strings.NewReader(`{"author":"ana","content":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
 	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 403, resp.StatusCode)
+	assert.Equal(t, "Comments are closed", decodeResponse(t, resp.Body).Error)
+	comments.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+}
+
+// TestEmbedComments verifies the widget page renders for any site to frame,
+// and that its JSON endpoints, unlike the rest of the API, answer any
+// origin.
+func TestEmbedComments(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	h.Config.AllowedOrigins = []string{"https://blog.example.com"}
+	id := models.ID("686c3a82361beb165141b490")
+	app := routes.Setup(h)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/embed/comments/"+id.String(), nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "frame-ancestors *", resp.Header.Get("Content-Security-Policy"))
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `data-post-id="`+id.String()+`"`)
+	assert.Contains(t, string(page), `data-api-base="`+handlers.EMBED_API_BASE+`"`)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/embed/comments/nope", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, "Invalid post ID", decodeResponse(t, resp.Body).Error)
+
+	request := func(method, path string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(fiber.HeaderOrigin, "https://static.example.net")
+		req.Header.Set(fiber.HeaderAccessControlRequestMethod, "POST")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+	resp = request("OPTIONS", handlers.EMBED_API_BASE+"/posts/"+id.String()+"/comments")
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, "*", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "GET,POST,OPTIONS", resp.Header.Get(fiber.HeaderAccessControlAllowMethods))
+	resp = request("GET", handlers.EMBED_API_BASE+"/posts/nope/comments")
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, "*", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+
+	resp = request("OPTIONS", "/api/posts/"+id.String()+"/comments")
+	assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+}
+
+// TestEmbedCommentToken verifies the embed widget's comment endpoint only
+// takes comments carrying the embed token served in the widget page of the
+// same post.
+func TestEmbedCommentToken(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	posts.On("CommentsLocked", mock.Anything, id).Return(true, nil)
+	app := routes.Setup(h)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/embed/comments/"+id.String(), nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	match := regexp.MustCompile(`data-token="([^"]+)"`).FindSubmatch(page)
+	require.NotNil(t, match, "page carries no embed token")
+	served := string(match[1])
+
+	post := func(token string) *http.Response {
+		req := httptest.NewRequest("POST", "/embed/api/posts/"+id.String()+"/comments?token="+url.QueryEscape(token), strings.NewReader("author=ana&content=hi"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+	for name, token := range map[string]string{
+		"missing":       "",
+		"forged":        "bm9wZQ.bm9wZQ",
+		"other post":    h.Tokens.Sign(handlers.EMBED_TOKEN_PURPOSE, "686c3a82361beb165141b491", time.Now().Add(time.Hour)),
+		"expired":       h.Tokens.Sign(handlers.EMBED_TOKEN_PURPOSE, id.String(), time.Now().Add(-time.Minute)),
+		"other purpose": h.Tokens.Sign(handlers.PREVIEW_TOKEN_PURPOSE, id.String(), time.Now().Add(time.Hour)),
+	} {
+		resp := post(token)
+		assert.Equal(t, 401, resp.StatusCode, name)
+		assert.Equal(t, "Invalid or expired embed token, reload the page", decodeResponse(t, resp.Body).Error, name)
+	}
+	posts.AssertNotCalled(t, "CommentsLocked", mock.Anything, mock.Anything)
+
+	// The served token reaches the handler, which finds the thread closed
+	resp = post(served)
+	assert.Equal(t, 403, resp.StatusCode)
+	posts.AssertExpectations(t)
+	comments.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+}
+
+// TestCreateCommentParentOnOtherPost verifies a reply is rejected when the
+// comment it answers belongs to another post.
+func TestCreateCommentParentOnOtherPost(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	parentID := models.ID("686c3a82361beb165141b4a0")
+	posts.On("CommentsLocked", mock.Anything, id).Return(false, nil)
+	comments.On("Get", mock.Anything, parentID).Return(models.Comment{ID: parentID, PostID: "686c3a82361beb165141b491"}, nil)
+
+	app := fiber.New()
+	app.Post("/api/posts/:id/comments", h.CreateComment)
+	body := `{"author":"ana","content":"hi","parent_comment_id":"` + parentID.String() + `"}`
+	req := httptest.NewRequest("POST", "/api/posts/"+id.String()+"/comments", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, "Parent comment belongs to another post", decodeResponse(t, resp.Body).Error)
+	comments.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+}
+
+// TestDeleteCommentUpdatesCount verifies deleting a comment decrements the
+// counter of the post it belonged to by the comments removed, replies
+// included.
+func TestDeleteCommentUpdatesCount(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	commentID := models.ID("686c3a82361beb165141b4a0")
+	postID := models.ID("686c3a82361beb165141b490")
+	comments.On("Delete", mock.Anything, commentID).Return(models.Comment{ID: commentID, PostID: postID}, int64(3), nil)
+	posts.On("RecordCommentChange", mock.Anything, postID, int64(-3)).Return(nil)
+
+	app := fiber.New()
+	app.Delete("/api/comments/:id", h.DeleteComment)
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/comments/"+commentID.String(), nil))
+	require.NoError(t, err)
 
-	// === ASSERTION PHASE ===
-	// Verify that the request executed without errors
-	assert.NoError(t, err)
-	// Verify that the response has HTTP 200 OK status
 	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, decodeResponse(t, resp.Body).Success)
+	posts.AssertExpectations(t)
+	comments.AssertExpectations(t)
+}
 
-	// === RESPONSE PARSING ===
-	// Parse the JSON response body into our API response model
-	var response models.APIResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	assert.NoError(t, err)
+// TestDeleteCommentNotFound verifies a missing comment answers 400 without
+// touching any post.
+func TestDeleteCommentNotFound(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	commentID := models.ID("686c3a82361beb165141b4a0")
+	comments.On("Delete", mock.Anything, commentID).Return(models.Comment{}, int64(0), mongo.ErrNoDocuments)
 
-	// === RESPONSE STRUCTURE VERIFICATION ===
-	// Verify that the API response indicates success
-	assert.True(t, response.Success)
-	// Verify that no error message is present
-	assert.Empty(t, response.Error)
-	// Verify that response contains data
-	assert.NotNil(t, response.Data)
-
-	// === DATA CONTENT VERIFICATION ===
-	// Convert the generic data interface to a slice of interfaces
-	// JSON unmarshaling creates []interface{} for arrays
-	summariesData, ok := response.Data.([]interface{})
-	assert.True(t, ok)
-	// Verify that exactly 2 posts are returned
-	assert.Len(t, summariesData, 2)
-
-	// === FIRST POST VERIFICATION ===
-	// Extract first post summary and verify its content
-	firstSummary := summariesData[0].(map[string]interface{})
-	// Verify the title matches our test data
-	assert.Equal(t, testPosts[0].Title, firstSummary["title"])
-	// Verify comment count (JSON numbers are unmarshaled as float64)
-	assert.Equal(t, float64(3), firstSummary["comment_count"])
-
-	// === SECOND POST VERIFICATION ===
-	// Extract second post summary and verify its content
-	secondSummary := summariesData[1].(map[string]interface{})
-	// Verify the title matches our test data
-	assert.Equal(t, testPosts[1].Title, secondSummary["title"])
-	// Verify comment count matches expected value
-	assert.Equal(t, float64(1), secondSummary["comment_count"])
+	app := fiber.New()
+	app.Delete("/api/comments/:id", h.DeleteComment)
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/comments/"+commentID.String(), nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, "No comment found to delete", decodeResponse(t, resp.Body).Error)
+	posts.AssertNotCalled(t, "RecordCommentChange", mock.Anything, mock.Anything, mock.Anything)
+}
 
+// TestGetPostsTitleTest verifies that a post running a title test is listed
+// under one of its headlines, the same one on every request, and that the
+// impression is counted for that headline.
+func TestGetPostsTitleTest(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	headlines := []string{"Original", "Variant A", "Variant B"}
+	headers := []models.BlogPostHeader{{ID: id, Title: headlines[0], TitleVariants: headlines[1:]}}
+
+	posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+	posts.On("Count", mock.Anything, mock.Anything).Return(int64(1), nil)
+	posts.On("List", mock.Anything, mock.Anything, mock.Anything, int64(0), mock.Anything).Return(headers, nil)
+	posts.On("Liked", mock.Anything, mock.Anything, mock.Anything).Return(map[models.ID]bool{}, nil)
+	posts.On("RecordTitleImpressions", mock.Anything, mock.Anything).Return(nil)
+	comments.On("CountByPost", mock.Anything, id).Return(int64(0), nil)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+
+	// Mock assertions read the recorded request contexts, which Fiber
+	// recycles, so they run after the last request
+	var shown []string
+	var variants []int
+	for i := 0; i < 2; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts", nil))
+		require.NoError(t, err)
+		summaries := decodeResponse(t, resp.Body).Data.([]interface{})
+		require.Len(t, summaries, 1)
+		summary := summaries[0].(map[string]interface{})
+
+		variant := 0
+		if raw, ok := summary["title_variant"]; ok {
+			variant = int(raw.(float64))
+		}
+		assert.Equal(t, headlines[variant], summary["title"])
+		shown = append(shown, summary["title"].(string))
+		variants = append(variants, variant)
+	}
+	assert.Equal(t, shown[0], shown[1], "a visitor keeps seeing the same headline")
+	posts.AssertCalled(t, "RecordTitleImpressions", mock.Anything, map[models.ID]int{id: variants[0]})
+}
+
+// TestGetPostsSort verifies sort and order become a MongoDB sort spec with
+// an _id tiebreaker, and that unknown values are rejected.
+func TestGetPostsSort(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	mostDiscussed := bson.D{{Key: "comment_count", Value: -1}, {Key: "_id", Value: -1}}
+	posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+	posts.On("Count", mock.Anything, mock.Anything).Return(int64(0), nil)
+	posts.On("List", mock.Anything, mock.Anything, mostDiscussed, int64(0), mock.Anything).Return([]models.BlogPostHeader{}, nil)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	for query, status := range map[string]int{
+		"sort=comment_count":            200,
+		"sort=comment_count&order=desc": 200,
+		"sort=views":                    400,
+		"sort=title&order=up":           400,
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts?"+query, nil))
+		require.NoError(t, err)
+		assert.Equal(t, status, resp.StatusCode, query)
+	}
+	posts.AssertNumberOfCalls(t, "List", 2)
+}
+
+// TestPostTags verifies listings filter on the normalized tag and that
+// edits with too many tags are rejected before anything is stored.
+func TestPostTags(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	tagged := mock.MatchedBy(func(filter bson.M) bool { return filter["tags"] == "machine learning" })
+	posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+	posts.On("Count", mock.Anything, tagged).Return(int64(0), nil)
+	posts.On("List", mock.Anything, tagged, mock.Anything, int64(0), mock.Anything).Return([]models.BlogPostHeader{}, nil)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	app.Put("/api/posts/:id", h.UpdatePost)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts?tag=Machine%20%20Learning", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	posts.AssertNumberOfCalls(t, "List", 1)
+
+	body := `{"tags": ["a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"]}`
+	req := httptest.NewRequest("PUT", "/api/posts/686c3a82361beb165141b490", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	posts.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCategoryValidation verifies malformed categories and category
+// references are rejected before reaching the database.
+func TestCategoryValidation(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	app.Put("/api/posts/:id", h.UpdatePost)
+	app.Post("/api/categories", h.CreateCategory)
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 400, send("GET", "/api/posts?category_id=nope", ""))
+	assert.Equal(t, 400, send("PUT", "/api/posts/686c3a82361beb165141b490", `{"category_id": "nope"}`))
+	assert.Equal(t, 400, send("POST", "/api/categories", `{"name": " & "}`))
+	assert.Equal(t, 400, send("POST", "/api/categories", `{"name": "`+strings.Repeat("x", handlers.MAX_CATEGORY_NAME_LENGTH+1)+`"}`))
+	posts.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	posts.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestHealthz verifies the liveness probe answers without a database.
+func TestHealthz(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+
+	app := fiber.New()
+	app.Get("/healthz", h.Healthz)
+	resp, err := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	data := decodeResponse(t, resp.Body).Data.(map[string]any)
+	assert.Equal(t, handlers.HEALTH_OK, data["status"])
+	assert.NotEmpty(t, data["version"])
+	assert.NotContains(t, data, "collections", "liveness does not check collections")
+}
+
+// TestUpdateSettingsValidation verifies settings need a login JWT and are
+// validated before anything is stored.
+func TestUpdateSettingsValidation(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+
+	app := fiber.New()
+	app.Put("/settings", middleware.RequireAuth(h.Auth), h.UpdateSettings)
+	put := func(token, body string) *http.Response {
+		req := httptest.NewRequest("PUT", "/settings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	assert.Equal(t, 401, put("", `{}`).StatusCode)
+
+	signed, err := h.Auth.Sign(jwt.Claims{Subject: "686c3a82361beb165141b490", Name: "ana", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	for _, body := range []string{
+		`{"default_visibility":"secret"}`,
+		`{"notification_channels":["pager"]}`,
+		`{"locale":"not a locale"}`,
+		`{"timezone":"Mars/Olympus_Mons"}`,
+	} {
+		resp := put(signed, body)
+		assert.Equal(t, 400, resp.StatusCode, body)
+		assert.False(t, decodeResponse(t, resp.Body).Success)
+	}
+}
+
+// TestCreateSavedSearchValidation verifies saved searches need a login JWT
+// and a query or tags before anything is stored.
+func TestCreateSavedSearchValidation(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+
+	app := fiber.New()
+	app.Post("/searches", middleware.RequireAuth(h.Auth), h.CreateSavedSearch)
+	post := func(token, body string) int {
+		req := httptest.NewRequest("POST", "/searches", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 401, post("", `{"query":"mongodb"}`))
+
+	signed, err := h.Auth.Sign(jwt.Claims{Subject: "686c3a82361beb165141b490", Name: "ana", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	for _, body := range []string{
+		`{}`,
+		`{"query":"   ","tags":[" "]}`,
+		`{"query":"` + strings.Repeat("x", handlers.MAX_SEARCH_QUERY_LENGTH+1) + `"}`,
+		`{"tags":["` + strings.Repeat("x", handlers.MAX_TAG_LENGTH+1) + `"]}`,
+	} {
+		assert.Equal(t, 400, post(signed, body), body)
+	}
+}
+
+// TestRestoreComment verifies a restored comment counts on its post again,
+// and that one whose post is gone answers 409 without touching any post.
+func TestRestoreComment(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	commentID := models.ID("686c3a82361beb165141b4a0")
+	orphanID := models.ID("686c3a82361beb165141b4a1")
+	postID := models.ID("686c3a82361beb165141b490")
+	comments.On("Restore", mock.Anything, commentID).Return(models.Comment{ID: commentID, PostID: postID}, int64(1), nil)
+	comments.On("Restore", mock.Anything, orphanID).Return(models.Comment{}, int64(0), storage.ErrPostMissing)
+	posts.On("RecordCommentChange", mock.Anything, postID, int64(1)).Return(nil).Once()
+
+	app := fiber.New()
+	app.Post("/api/comments/:id/restore", h.RestoreComment)
+	restore := func(id models.ID) int {
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/comments/"+id.String()+"/restore", nil))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, restore(commentID))
+	assert.Equal(t, 409, restore(orphanID))
+	posts.AssertExpectations(t)
+	comments.AssertExpectations(t)
+}
+
+// TestReadRouting verifies read preferences are built from the
+// MONGODB_READ_* settings and that invalid combinations fail at startup.
+func TestReadRouting(t *testing.T) {
+	pref, err := storage.ReadRouting{}.ReadPref()
+	require.NoError(t, err)
+	assert.Nil(t, pref)
+
+	pref, err = storage.ReadRouting{Preference: "Nearest", TagSets: "region:eu-west, zone:a;region:eu-west;", Hedged: true}.ReadPref()
+	require.NoError(t, err)
+	assert.Equal(t, "nearest", pref.Mode().String())
+	require.Len(t, pref.TagSets(), 3)
+	assert.Len(t, pref.TagSets()[0], 2)
+	assert.Empty(t, pref.TagSets()[2])
+	require.NotNil(t, pref.HedgeEnabled())
+
+	for _, routing := range []storage.ReadRouting{
+		{Preference: "closest"},
+		{Preference: "nearest", TagSets: "region"},
+		{Preference: "primary", Hedged: true},
+		{TagSets: "region:eu-west"},
+	} {
+		_, err := routing.ReadPref()
+		assert.Error(t, err, routing)
+	}
+}
+
+// TestBatch verifies sub-requests run in order through the app's routes,
+// each with its own status, and that batches cannot be nested.
+func TestBatch(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+
+	app := fiber.New()
+	app.Post("/api/batch", h.Batch)
+	app.Get("/api/schema/:type", h.GetSchema)
+	batch := func(body string) (int, []models.BatchResponse) {
+		req := httptest.NewRequest("POST", "/api/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var decoded struct {
+			Data []models.BatchResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+		return resp.StatusCode, decoded.Data
+	}
+
+	status, responses := batch(`[{"method":"get","path":"/api/schema/comment"},{"method":"GET","path":"/api/schema/unknown"}]`)
+	assert.Equal(t, 200, status)
+	require.Len(t, responses, 2)
+	assert.Equal(t, 200, responses[0].Status)
+	assert.Contains(t, string(responses[0].Body), "parent_comment_id")
+	assert.Equal(t, 404, responses[1].Status)
+
+	for _, nested := range []string{"/api/batch", "/api/BATCH", "/api//Batch/", "/api/posts/../batch"} {
+		status, _ = batch(`[{"method":"POST","path":"` + nested + `","body":[]}]`)
+		assert.Equal(t, 400, status, nested)
+	}
+
+	// A sub-request reaching the batch route anyway is refused there
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+	ctx.Locals(handlers.BATCH_LOCALS_KEY, true)
+	ctx.Request().SetBody([]byte(`[{"method":"GET","path":"/api/schema/comment"}]`))
+	require.NoError(t, h.Batch(ctx))
+	assert.Equal(t, 400, ctx.Response().StatusCode())
+}
+
+// TestReactToCommentInvalidReaction verifies an unknown reaction type is
+// rejected before the comment is looked up.
+func TestReactToCommentInvalidReaction(t *testing.T) {
+	h, _, comments := newMockedHandler(t)
+	commentID := models.ID("686c3a82361beb165141b4a0")
+
+	app := fiber.New()
+	app.Post("/api/comments/:id/react", h.ReactToComment)
+	req := httptest.NewRequest("POST", "/api/comments/"+commentID.String()+"/react", strings.NewReader(`{"reaction":"angry"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Contains(t, decodeResponse(t, resp.Body).Error, "Invalid reaction")
+	comments.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+// TestPutAutosaveValidation verifies autosaves need a login, a valid post
+// ID, a body within the schema, and an existing post before anything is
+// stored.
+func TestPutAutosaveValidation(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	posts.On("Exists", mock.Anything, id).Return(false, nil)
+
+	app := fiber.New()
+	app.Put("/api/posts/:id/autosave", middleware.RequireAuth(h.Auth), h.PutAutosave)
+	put := func(token, postID, body string) int {
+		req := httptest.NewRequest("PUT", "/api/posts/"+postID+"/autosave", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 401, put("", id.String(), `{"content":"draft"}`))
+
+	signed, err := h.Auth.Sign(jwt.Claims{Subject: "686c3a82361beb165141b491", Name: "ana", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	assert.Equal(t, 400, put(signed, "not-an-id", `{"content":"draft"}`))
+	assert.Equal(t, 422, put(signed, id.String(), `{"title":"`+strings.Repeat("x", 201)+`"}`))
+	assert.Equal(t, 404, put(signed, id.String(), `{"content":"draft"}`))
+	posts.AssertExpectations(t)
+}
+
+// TestBulkModerateCommentsValidation verifies that bulk moderation rejects
+// malformed bodies and unknown actions before touching any comment.
+func TestBulkModerateCommentsValidation(t *testing.T) {
+	h, _, comments := newMockedHandler(t)
+
+	app := fiber.New()
+	app.Post("/api/admin/comments/bulk", h.BulkModerateComments)
+	post := func(body string) (int, models.APIResponse) {
+		req := httptest.NewRequest("POST", "/api/admin/comments/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode, decodeResponse(t, resp.Body)
+	}
+
+	status, _ := post(`{"ids":`)
+	assert.Equal(t, 400, status)
+	status, response := post(`{"ids":["686c3a82361beb165141b490"],"action":"archive"}`)
+	assert.Equal(t, 422, status)
+	assert.Equal(t, handlers.VALIDATION_FAILED, response.Error)
+	status, _ = post(`{"action":"approve"}`)
+	assert.Equal(t, 422, status, "ids are required")
+	comments.AssertNotCalled(t, "Moderate")
+	comments.AssertNotCalled(t, "Delete")
+}
+
+// TestClaps verifies clap counts outside 1 to MAX_CLAPS_PER_REQUESTER are
+// rejected before the post is looked up, and that the all-time top-clapped
+// listing ranks by the denormalized clap counter.
+func TestClaps(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	mostClapped := bson.D{{Key: "clap_count", Value: -1}, {Key: "_id", Value: -1}}
+	posts.On("List", mock.Anything, mock.Anything, mostClapped, int64(0), int64(2)).Return([]models.BlogPostHeader{
+		{ID: "686c3a82361beb165141b490", Title: "Loved", ClapCount: 120},
+		{ID: "686c3a82361beb165141b491", Title: "Liked", ClapCount: 7},
+	}, nil)
+	posts.On("Liked", mock.Anything, mock.Anything, mock.Anything).Return(map[models.ID]bool{}, nil)
+	comments.On("CountByPost", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+	app := fiber.New()
+	app.Get("/api/posts/top-clapped", h.GetTopClapped)
+	app.Post("/api/posts/:id/clap", h.ClapPost)
+	for _, body := range []string{`{"count":51}`, `{"count":-1}`} {
+		req := httptest.NewRequest("POST", "/api/posts/686c3a82361beb165141b490/clap", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 422, resp.StatusCode, body)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts/top-clapped?days=0", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/posts/top-clapped?limit=2", nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	var top []models.TopClappedPost
+	raw, err := json.Marshal(decodeResponse(t, resp.Body).Data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &top))
+	require.Len(t, top, 2)
+	assert.Equal(t, "Loved", top[0].Title)
+	assert.Equal(t, int64(120), top[0].Claps)
+	assert.Equal(t, int64(120), top[0].ClapCount)
+	posts.AssertExpectations(t)
+}
+
+// TestGetCommentSummaryValidation verifies malformed post IDs and truncate
+// values are rejected before the discussion is aggregated.
+func TestGetCommentSummaryValidation(t *testing.T) {
+	h, _, comments := newMockedHandler(t)
+
+	app := fiber.New()
+	app.Get("/api/posts/:id/comments/summary", h.GetCommentSummary)
+	for path, message := range map[string]string{
+		"/api/posts/not-an-id/comments/summary":                           "Invalid post ID",
+		"/api/posts/686c3a82361beb165141b490/comments/summary?truncate=x": "Invalid truncate",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode, path)
+		assert.Equal(t, message, decodeResponse(t, resp.Body).Error)
+	}
+	comments.AssertNotCalled(t, "Summarize", mock.Anything, mock.Anything)
+}
+
+// TestGetPostsCursor verifies cursor pagination continues below the after
+// ID in _id order without counting, and returns next_cursor only while
+// more posts follow.
+func TestGetPostsCursor(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id1 := models.ID("686c3a82361beb165141b493")
+	id2 := models.ID("686c3a82361beb165141b492")
+	id3 := models.ID("686c3a82361beb165141b491")
+	newestID := bson.D{{Key: "_id", Value: -1}}
+	belowID2 := mock.MatchedBy(func(filter bson.M) bool {
+		return assert.ObjectsAreEqual(bson.M{"$lt": id2}, filter["_id"])
+	})
+	firstPage := mock.MatchedBy(func(filter bson.M) bool { return filter["_id"] == nil })
+	posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+	posts.On("List", mock.Anything, firstPage, newestID, int64(0), int64(3)).
+		Return([]models.BlogPostHeader{{ID: id1}, {ID: id2}, {ID: id3}}, nil)
+	posts.On("List", mock.Anything, belowID2, newestID, int64(0), int64(3)).
+		Return([]models.BlogPostHeader{{ID: id3}}, nil)
+	posts.On("Liked", mock.Anything, mock.Anything, mock.Anything).Return(map[models.ID]bool{}, nil)
+	comments.On("CountByPost", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	get := func(query string) (int, models.APIResponse) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts?"+query, nil))
+		require.NoError(t, err)
+		return resp.StatusCode, decodeResponse(t, resp.Body)
+	}
+
+	status, response := get("after=&limit=2")
+	require.Equal(t, 200, status)
+	assert.Len(t, response.Data, 2)
+	assert.Nil(t, response.Pagination)
+	assert.Equal(t, &models.Cursor{Limit: 2, NextCursor: id2}, response.Cursor)
+
+	status, response = get("after=" + id2.String() + "&limit=2")
+	require.Equal(t, 200, status)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, &models.Cursor{Limit: 2}, response.Cursor, "last page has no next_cursor")
+
+	for _, query := range []string{"after=not-an-id", "after=&page=2", "after=&sort=title"} {
+		status, response = get(query)
+		assert.Equal(t, 400, status, query)
+		assert.Equal(t, "Invalid cursor", response.Error)
+	}
+	posts.AssertNotCalled(t, "Count", mock.Anything, mock.Anything)
+	posts.AssertExpectations(t)
+}
+
+// TestGetPostsIncludeHidden verifies include_hidden=true is refused to
+// anonymous callers and to users not listed in ADMIN_USERS, and lists
+// hidden posts for administrators.
+func TestGetPostsIncludeHidden(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	h.Config.AdminUsers = []string{"ana"}
+	everything := mock.MatchedBy(func(filter bson.M) bool { return len(filter) == 0 })
+	posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+	posts.On("Count", mock.Anything, everything).Return(int64(0), nil)
+	posts.On("List", mock.Anything, everything, mock.Anything, mock.Anything, mock.Anything).Return([]models.BlogPostHeader{}, nil)
+	posts.On("Liked", mock.Anything, mock.Anything, mock.Anything).Return(map[models.ID]bool{}, nil)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	get := func(name string) int {
+		req := httptest.NewRequest("GET", "/api/posts?include_hidden=true", nil)
+		if name != "" {
+			signed, err := h.Auth.Sign(jwt.Claims{Subject: "686c3a82361beb165141b490", Name: name, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+signed)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 401, get(""))
+	assert.Equal(t, 403, get("bob"))
+	assert.Equal(t, 200, get("ana"))
+}
+
+// TestGetPostsEngagementFilters verifies has_comments, min_comments, and
+// min_views filter on the denormalized counters, combine into one
+// comment_count condition, and are refused with 400 when malformed.
+func TestGetPostsEngagementFilters(t *testing.T) {
+	for _, tc := range []struct {
+		query                   string
+		commentCount, viewCount any
+	}{
+		{"", nil, nil},
+		{"has_comments=true", bson.M{"$gt": 0}, nil},
+		{"has_comments=false", bson.M{"$not": bson.M{"$gt": 0}}, nil},
+		{"min_comments=3", bson.M{"$gte": int64(3)}, nil},
+		{"has_comments=true&min_comments=3", bson.M{"$gt": 0, "$gte": int64(3)}, nil},
+		{"min_views=100", nil, bson.M{"$gte": int64(100)}},
+	} {
+		h, posts, _ := newMockedHandler(t)
+		var filter bson.M
+		posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+		posts.On("Count", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			filter = args.Get(1).(bson.M)
+		}).Return(int64(0), nil)
+		posts.On("List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.BlogPostHeader{}, nil)
+
+		app := fiber.New()
+		app.Get("/api/posts", h.GetPosts)
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts?"+tc.query, nil))
+		require.NoError(t, err)
+
+		require.Equal(t, 200, resp.StatusCode, tc.query)
+		assert.Equal(t, tc.commentCount, filter["comment_count"], tc.query)
+		assert.Equal(t, tc.viewCount, filter["view_count"], tc.query)
+	}
+
+	for _, query := range []string{"has_comments=maybe", "min_comments=-1", "min_comments=many", "min_views=-5", "min_views=1.5"} {
+		h, _, _ := newMockedHandler(t)
+		app := fiber.New()
+		app.Get("/api/posts", h.GetPosts)
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts?"+query, nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, 400, resp.StatusCode, query)
+		assert.Equal(t, "Invalid filter", decodeResponse(t, resp.Body).Error, query)
+	}
+}
+
+// TestGetPostsArchived verifies archived posts are left out of the listing
+// unless include_archived=true is sent.
+func TestGetPostsArchived(t *testing.T) {
+	for query, want := range map[string]any{
+		"":                        bson.M{"$ne": true},
+		"?include_archived=false": bson.M{"$ne": true},
+		"?include_archived=true":  nil,
+	} {
+		h, posts, _ := newMockedHandler(t)
+		var filter bson.M
+		posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+		posts.On("Count", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			filter = args.Get(1).(bson.M)
+		}).Return(int64(0), nil)
+		posts.On("List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.BlogPostHeader{}, nil)
+
+		app := fiber.New()
+		app.Get("/api/posts", h.GetPosts)
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts"+query, nil))
+		require.NoError(t, err)
+
+		require.Equal(t, 200, resp.StatusCode, query)
+		assert.Equal(t, want, filter["archived"], query)
+	}
+
+	h, _, _ := newMockedHandler(t)
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts?include_archived=soon", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+// TestArchivePost verifies archiving and unarchiving require a login, reject
+// invalid IDs, and answer 502 when the update fails.
+func TestArchivePost(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	h.DB.Posts = offlineCollection(t, "posts")
+	routed := routes.Setup(h)
+	app := fiber.New()
+	app.Post("/api/posts/:id/archive", h.ArchivePost)
+	app.Delete("/api/posts/:id/archive", h.UnarchivePost)
+
+	for _, method := range []string{"POST", "DELETE"} {
+		send := func(app *fiber.App, id string) (int, string) {
+			resp, err := app.Test(httptest.NewRequest(method, "/api/posts/"+id+"/archive", nil))
+			require.NoError(t, err)
+			return resp.StatusCode, decodeResponse(t, resp.Body).Error
+		}
+
+		status, _ := send(routed, "686c3a82361beb165141b490")
+		assert.Equal(t, 401, status, method)
+		status, message := send(app, "nope")
+		assert.Equal(t, 400, status, method)
+		assert.Equal(t, "Invalid post ID", message, method)
+		status, message = send(app, "686c3a82361beb165141b490")
+		assert.Equal(t, 502, status, method)
+		assert.Equal(t, "Failed to update post", message, method)
+	}
+}
+
+// TestGetPostsETag verifies the listing carries a weak ETag, answers 304
+// when If-None-Match lists it, and lets a stale If-None-Match win over a
+// fresh If-Modified-Since.
+func TestGetPostsETag(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	lastModified := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	posts.On("LastModified", mock.Anything).Return(lastModified, nil)
+	posts.On("Count", mock.Anything, mock.Anything).Return(int64(1), nil)
+	posts.On("List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]models.BlogPostHeader{{ID: id, Title: "First Post"}}, nil)
+	posts.On("Liked", mock.Anything, mock.Anything, mock.Anything).Return(map[models.ID]bool{}, nil)
+	comments.On("CountByPost", mock.Anything, id).Return(int64(2), nil)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	get := func(headers map[string]string) *http.Response {
+		req := httptest.NewRequest("GET", "/api/posts", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := get(nil)
+	require.Equal(t, 200, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.Regexp(t, `^W/"[0-9a-f]{16}"$`, etag)
+
+	resp = get(map[string]string{"If-None-Match": `"other", ` + etag})
+	assert.Equal(t, 304, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, body)
+
+	resp = get(map[string]string{
+		"If-None-Match":     `W/"0000000000000000"`,
+		"If-Modified-Since": lastModified.Format(http.TimeFormat),
+	})
+	assert.Equal(t, 200, resp.StatusCode, "If-None-Match takes precedence")
+}
+
+// TestGetPostsIfModifiedSince verifies the listing sends Last-Modified and
+// answers 304 without querying posts while the client copy is current, at
+// the one-second precision of HTTP dates, and that view filters, which
+// last-modified does not track, opt out.
+func TestGetPostsIfModifiedSince(t *testing.T) {
+	lastModified := time.Date(2025, 7, 1, 12, 0, 0, 500_000_000, time.UTC)
+	for _, tc := range []struct {
+		query, since string
+		want         int
+	}{
+		{"", "Tue, 01 Jul 2025 12:00:00 GMT", 304},
+		{"", "Tue, 01 Jul 2025 13:00:00 GMT", 304},
+		{"", "Tue, 01 Jul 2025 11:59:59 GMT", 200},
+		{"", "yesterday", 200},
+		{"", "", 200},
+		{"?min_views=1", "Tue, 01 Jul 2025 13:00:00 GMT", 200},
+	} {
+		h, posts, _ := newMockedHandler(t)
+		posts.On("LastModified", mock.Anything).Return(lastModified, nil)
+		posts.On("Count", mock.Anything, mock.Anything).Return(int64(0), nil)
+		posts.On("List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.BlogPostHeader{}, nil)
+
+		app := fiber.New()
+		app.Get("/api/posts", h.GetPosts)
+		req := httptest.NewRequest("GET", "/api/posts"+tc.query, nil)
+		if tc.since != "" {
+			req.Header.Set("If-Modified-Since", tc.since)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		name := tc.query + " " + tc.since
+		assert.Equal(t, tc.want, resp.StatusCode, name)
+		if tc.query == "" {
+			assert.Equal(t, "Tue, 01 Jul 2025 12:00:00 GMT", resp.Header.Get("Last-Modified"), name)
+		} else {
+			posts.AssertNotCalled(t, "LastModified", mock.Anything)
+		}
+		if tc.want == 304 {
+			posts.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	}
+}
+
+// TestRequesterKey verifies anonymous requesters are keyed on their IP
+// alone, so changing User-Agent does not reset the clap cap or allow a
+// second like or reaction, and logged-in requesters on their user ID.
+func TestRequesterKey(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	var keys []string
+	posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+	posts.On("Count", mock.Anything, mock.Anything).Return(int64(1), nil)
+	posts.On("List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]models.BlogPostHeader{{ID: id, Title: "First Post"}}, nil)
+	posts.On("Liked", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		keys = append(keys, args.String(1))
+	}).Return(map[models.ID]bool{}, nil)
+	comments.On("CountByPost", mock.Anything, id).Return(int64(0), nil)
+	signed, err := h.Auth.Sign(jwt.Claims{Subject: "686c3a82361beb165141b4a0", Name: "ana", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	for _, headers := range []map[string]string{
+		{"User-Agent": "curl/8.0"},
+		{"User-Agent": "Mozilla/5.0"},
+		{"User-Agent": "curl/8.0", "Authorization": "Bearer " + signed},
+	} {
+		req := httptest.NewRequest("GET", "/api/posts", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+	}
+
+	require.Len(t, keys, 3)
+	assert.Equal(t, keys[0], keys[1], "User-Agent must not change the requester")
+	assert.True(t, strings.HasPrefix(keys[0], handlers.ANON_KEY_PREFIX))
+	assert.Equal(t, handlers.USER_KEY_PREFIX+"686c3a82361beb165141b4a0", keys[2])
+}
+
+// TestGetPostLikes verifies the likes of a post are reserved for
+// administrators and paged.
+func TestGetPostLikes(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	h.Config.AdminUsers = []string{"ana"}
+	id := models.ID("686c3a82361beb165141b490")
+	likes := []models.Like{{ID: "686c3a82361beb165141b4b0", PostID: id, UserKey: "anon:1"}}
+	posts.On("Likes", mock.Anything, id, int64(10), int64(10)).Return(likes, int64(11), nil)
+	app := routes.Setup(h)
+
+	get := func(query, user string) *http.Response {
+		req := httptest.NewRequest("GET", "/api/posts/"+id.String()+"/likes"+query, nil)
+		if user != "" {
+			signed, err := h.Auth.Sign(jwt.Claims{Subject: "686c3a82361beb165141b4a0", Name: user, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+signed)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	assert.Equal(t, 401, get("", "").StatusCode)
+	assert.Equal(t, 403, get("", "bob").StatusCode)
+	assert.Equal(t, 400, get("?page=0", "ana").StatusCode)
+
+	resp := get("?page=2&limit=10", "ana")
+	require.Equal(t, 200, resp.StatusCode)
+	response := decodeResponse(t, resp.Body)
+	assert.Equal(t, &models.Pagination{Page: 2, Limit: 10, Total: 11, TotalPages: 2}, response.Pagination)
+	assert.Len(t, response.Data, 1)
+	posts.AssertExpectations(t)
+}
+
+// TestLikeHiddenPost verifies private and scheduled posts cannot be liked or
+// unliked, and answer like missing posts.
+func TestLikeHiddenPost(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	posts.On("Published", mock.Anything, id).Return(false, nil)
+
+	app := fiber.New()
+	app.Post("/api/posts/:id/like", h.LikePost)
+	app.Delete("/api/posts/:id/like", h.UnlikePost)
+	for _, method := range []string{"POST", "DELETE"} {
+		resp, err := app.Test(httptest.NewRequest(method, "/api/posts/"+id.String()+"/like", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 404, resp.StatusCode, method)
+		assert.Equal(t, "Post not found", decodeResponse(t, resp.Body).Error)
+	}
+	posts.AssertExpectations(t)
+}
+
+// TestGetPostsLiked verifies the listing flags the posts the requester
+// liked, looked up together in one query, and leaves the flags unset when
+// the lookup fails.
+func TestGetPostsLiked(t *testing.T) {
+	id1 := models.ID("686c3a82361beb165141b490")
+	id2 := models.ID("686c3a82361beb165141b491")
+	for _, tc := range []struct {
+		name  string
+		liked map[models.ID]bool
+		err   error
+		want  []bool
+	}{
+		{"liked", map[models.ID]bool{id2: true}, nil, []bool{false, true}},
+		{"lookup failure", nil, mongo.ErrClientDisconnected, []bool{false, false}},
+	} {
+		h, posts, comments := newMockedHandler(t)
+		posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+		posts.On("Count", mock.Anything, mock.Anything).Return(int64(2), nil)
+		posts.On("List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]models.BlogPostHeader{{ID: id1, Title: "First Post"}, {ID: id2, Title: "Second Post"}}, nil)
+		posts.On("Liked", mock.Anything, mock.Anything, []models.ID{id1, id2}).Return(tc.liked, tc.err).Once()
+		comments.On("CountByPost", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+		app := fiber.New()
+		app.Get("/api/posts", h.GetPosts)
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/posts", nil))
+		require.NoError(t, err)
+
+		require.Equal(t, 200, resp.StatusCode, tc.name)
+		summaries := decodeResponse(t, resp.Body).Data.([]interface{})
+		require.Len(t, summaries, 2, tc.name)
+		for i, want := range tc.want {
+			liked, _ := summaries[i].(map[string]interface{})["liked"].(bool)
+			assert.Equal(t, want, liked, tc.name)
+		}
+		posts.AssertExpectations(t)
+	}
+}
+
+// TestLikePostFailure verifies a like or unlike that cannot be written
+// answers 502 rather than reporting the new state.
+func TestLikePostFailure(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	h.DB.Likes = offlineCollection(t, "likes")
+	id := models.ID("686c3a82361beb165141b490")
+	posts.On("Published", mock.Anything, id).Return(true, nil)
+
+	app := fiber.New()
+	app.Post("/api/posts/:id/like", h.LikePost)
+	app.Delete("/api/posts/:id/like", h.UnlikePost)
+	for method, message := range map[string]string{"POST": "Failed to like post", "DELETE": "Failed to unlike post"} {
+		resp, err := app.Test(httptest.NewRequest(method, "/api/posts/"+id.String()+"/like", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 502, resp.StatusCode, method)
+		assert.Equal(t, message, decodeResponse(t, resp.Body).Error)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/posts/nope/like", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, "Invalid post ID", decodeResponse(t, resp.Body).Error)
+	posts.AssertExpectations(t)
+}
+
+// TestClapHiddenPost verifies private and scheduled posts cannot be clapped
+// for, and answer like missing posts.
+func TestClapHiddenPost(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	posts.On("Published", mock.Anything, id).Return(false, nil)
+
+	app := fiber.New()
+	app.Post("/api/posts/:id/clap", h.ClapPost)
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/posts/"+id.String()+"/clap", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.Equal(t, "Post not found", decodeResponse(t, resp.Body).Error)
+	posts.AssertExpectations(t)
 }
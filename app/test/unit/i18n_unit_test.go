@@ -0,0 +1,46 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseAcceptLanguageOrdering verifies q-value ordering, implicit
+// primary-language fallbacks, and that invalid or refused entries are dropped.
+func TestParseAcceptLanguageOrdering(t *testing.T) {
+	tags := i18n.ParseAcceptLanguage("en;q=0.5, pt-BR, fr;q=0, *;q=0.1, es;q=0.8")
+	assert.Equal(t, []string{"pt-br", "pt", "es", "en"}, tags)
+
+	assert.Empty(t, i18n.ParseAcceptLanguage(""))
+}
+
+// TestBestLanguage verifies negotiation picks the most preferred available tag.
+func TestBestLanguage(t *testing.T) {
+	preferred := i18n.ParseAcceptLanguage("pt-BR,en;q=0.5")
+
+	assert.Equal(t, "pt", i18n.Best(preferred, []string{"en", "pt"}))
+	assert.Equal(t, "en", i18n.Best(preferred, []string{"en", "de"}))
+	assert.Equal(t, "", i18n.Best(preferred, []string{"de"}))
+}
+
+// TestDetectLanguage verifies Latin-script languages are told apart by
+// their stopwords, other scripts by their letters, and that short or
+// ambiguous texts are left undetected.
+func TestDetectLanguage(t *testing.T) {
+	cases := map[string]string{
+		"This is a post about the new release and what it means for you.":                  "en",
+		"Este é um post sobre a nova versão e o que ela significa para você.":              "pt",
+		"Este es un artículo sobre la nueva versión y lo que significa para los usuarios.": "es",
+		"Ceci est un article sur la nouvelle version et ce qu'elle signifie pour vous.":    "fr",
+		"Это статья о новой версии и о том, что она значит для вас.":                       "ru",
+		"これは新しいリリースについての記事です。":                                                             "ja",
+		"这是一篇关于新版本的文章，以及它对你意味着什么。":                                                         "zh",
+		"Hello world":       "",
+		"Kubernetes Docker": "",
+	}
+	for text, want := range cases {
+		assert.Equal(t, want, i18n.Detect(text), text)
+	}
+}
@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// TestIDCodecsRoundTrip verifies that every codec accepts the IDs it
+// generates and rejects IDs of the other format.
+func TestIDCodecsRoundTrip(t *testing.T) {
+	objectIDs, err := storage.NewIDCodec(storage.ID_FORMAT_OBJECTID)
+	require.NoError(t, err)
+	uuids, err := storage.NewIDCodec(storage.ID_FORMAT_UUIDV7)
+	require.NoError(t, err)
+
+	oid := objectIDs.New()
+	uuid := uuids.New()
+
+	parsed, err := objectIDs.Parse(oid.String())
+	assert.NoError(t, err)
+	assert.Equal(t, oid, parsed)
+	parsed, err = uuids.Parse(uuid.String())
+	assert.NoError(t, err)
+	assert.Equal(t, uuid, parsed)
+
+	_, err = objectIDs.Parse(uuid.String())
+	assert.ErrorIs(t, err, storage.ErrInvalidID)
+	_, err = uuids.Parse(oid.String())
+	assert.ErrorIs(t, err, storage.ErrInvalidID)
+
+	// Version 4 UUIDs are not accepted by the v7 codec
+	_, err = uuids.Parse("0b9f8f4e-2d1c-4c8a-9f3e-6a1b2c3d4e5f")
+	assert.ErrorIs(t, err, storage.ErrInvalidID)
+
+	_, err = storage.NewIDCodec("serial")
+	assert.Error(t, err)
+}
+
+// TestUUIDv7IsTimeOrdered verifies that later UUIDv7s sort after earlier
+// ones, which keeps creation-order index scans cheap.
+func TestUUIDv7IsTimeOrdered(t *testing.T) {
+	codec := storage.UUIDv7Codec{}
+	first := codec.New()
+	for i := 0; i < 1000; i++ {
+		if next := codec.New(); next.String()[:13] < first.String()[:13] {
+			t.Fatalf("uuid %s sorts before %s", next, first)
+		}
+	}
+}
+
+// TestIDBSONRepresentation verifies that ObjectID-formatted IDs keep their
+// native BSON type while UUIDs are stored as strings, and both decode back.
+func TestIDBSONRepresentation(t *testing.T) {
+	cases := map[models.ID]bsontype.Type{
+		storage.ObjectIDCodec{}.New(): bsontype.ObjectID,
+		storage.UUIDv7Codec{}.New():   bsontype.String,
+	}
+	for id, want := range cases {
+		raw, err := bson.Marshal(models.Comment{ID: id, PostID: id})
+		require.NoError(t, err)
+		assert.Equal(t, want, bson.Raw(raw).Lookup("_id").Type)
+		assert.Equal(t, want, bson.Raw(raw).Lookup("post_id").Type)
+
+		var decoded models.Comment
+		require.NoError(t, bson.Unmarshal(raw, &decoded))
+		assert.Equal(t, id, decoded.ID)
+		assert.Equal(t, id, decoded.PostID)
+	}
+
+	// Unset IDs are omitted so the database never stores an empty key
+	raw, err := bson.Marshal(models.Comment{})
+	require.NoError(t, err)
+	_, err = bson.Raw(raw).LookupErr("_id")
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/routes"
+	"github.com/pedrobertao/challenge-prosi/app/lib/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importBody is a valid import batch of one comment.
+const importBody = `{"comments":[{"import_id":"d1","author":"ana","content":"First!","created_at":"2015-03-01T10:00:00Z"}]}`
+
+// TestImportCommentsAuth verifies the comment import is reserved for site
+// administrators: anonymous requests get 401, other users 403, and only
+// administrators reach the post lookup, whose failure answers 502.
+func TestImportCommentsAuth(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	h.Config.AdminUsers = []string{"ana"}
+	h.DB.Posts = offlineCollection(t, "posts")
+	app := routes.Setup(h)
+
+	post := func(user string) (int, string) {
+		req := httptest.NewRequest("POST", "/api/posts/686c3a82361beb165141b490/comments/import", strings.NewReader(importBody))
+		req.Header.Set("Content-Type", "application/json")
+		if user != "" {
+			signed, err := h.Auth.Sign(jwt.Claims{Subject: "686c3a82361beb165141b4a0", Name: user, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+signed)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode, decodeResponse(t, resp.Body).Error
+	}
+
+	status, message := post("")
+	assert.Equal(t, 401, status)
+	assert.Equal(t, "Authentication required", message)
+
+	status, message = post("bob")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "Admin access required", message)
+
+	// The offline posts collection fails the lookup, past the auth checks
+	status, message = post("ana")
+	assert.Equal(t, 502, status)
+	assert.Equal(t, "Failed to import comments", message)
+}
+
+// TestImportCommentsRejected verifies invalid batches are refused as a
+// whole before the database is used.
+func TestImportCommentsRejected(t *testing.T) {
+	tooMany := strings.Repeat(`{"author":"ana","content":"hi","created_at":"2015-03-01T10:00:00Z"},`, handlers.MAX_IMPORT_COMMENTS+1)
+	for _, tc := range []struct {
+		name, path, body, message string
+	}{
+		{"invalid post ID", "/api/posts/nope/comments/import", importBody, "Invalid post ID"},
+		{"invalid JSON", "/api/posts/686c3a82361beb165141b490/comments/import", `{"comments":`, "Invalid JSON"},
+		{"empty batch", "/api/posts/686c3a82361beb165141b490/comments/import", `{"comments":[]}`,
+			fmt.Sprintf("Import 1 to %d comments per request", handlers.MAX_IMPORT_COMMENTS)},
+		{"oversized batch", "/api/posts/686c3a82361beb165141b490/comments/import", `{"comments":[` + strings.TrimSuffix(tooMany, ",") + `]}`,
+			fmt.Sprintf("Import 1 to %d comments per request", handlers.MAX_IMPORT_COMMENTS)},
+		{"missing author", "/api/posts/686c3a82361beb165141b490/comments/import",
+			`{"comments":[{"author":"ana","content":"ok","created_at":"2015-03-01T10:00:00Z"},{"content":"hi","created_at":"2015-03-01T10:00:00Z"}]}`,
+			"Comment 1: author, content, and created_at required"},
+		{"script-only content", "/api/posts/686c3a82361beb165141b490/comments/import",
+			`{"comments":[{"author":"ana","content":"<script>alert(1)</script>","created_at":"2015-03-01T10:00:00Z"}]}`,
+			"Comment 0: author, content, and created_at required"},
+		{"missing created_at", "/api/posts/686c3a82361beb165141b490/comments/import",
+			`{"comments":[{"author":"ana","content":"hi"}]}`,
+			"Comment 0: author, content, and created_at required"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, _, _ := newMockedHandler(t)
+			app := fiber.New()
+			app.Post("/api/posts/:id/comments/import", h.ImportComments)
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, 400, resp.StatusCode)
+			assert.Equal(t, tc.message, decodeResponse(t, resp.Body).Error)
+		})
+	}
+}
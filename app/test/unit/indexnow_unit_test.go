@@ -0,0 +1,37 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/indexnow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIndexNowSubmit verifies the submission body and status handling.
+func TestIndexNowSubmit(t *testing.T) {
+	assert.Nil(t, indexnow.New("https://api.indexnow.org/indexnow", "", "", "https://blog.example.com/posts/{id}"))
+
+	var received map[string]any
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := indexnow.New(server.URL, "abc123", "", "https://blog.example.com/posts/{id}")
+	require.NoError(t, client.Submit(context.Background(), client.URL("p1")))
+	assert.Equal(t, map[string]any{
+		"host":    "blog.example.com",
+		"key":     "abc123",
+		"urlList": []any{"https://blog.example.com/posts/p1"},
+	}, received)
+
+	status = http.StatusForbidden
+	assert.Error(t, client.Submit(context.Background(), client.URL("p1")))
+}
@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/integrations"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIntegrationsValidate verifies that drivers only accept their own
+// service's webhook URLs.
+func TestIntegrationsValidate(t *testing.T) {
+	assert.NoError(t, integrations.Validate(models.INTEGRATION_SLACK, "https://hooks.slack.com/services/T0/B0/x"))
+	assert.NoError(t, integrations.Validate(models.INTEGRATION_DISCORD, "https://discord.com/api/webhooks/1/abc"))
+
+	assert.Error(t, integrations.Validate(models.INTEGRATION_SLACK, "http://hooks.slack.com/services/T0/B0/x"))
+	assert.Error(t, integrations.Validate(models.INTEGRATION_SLACK, "https://discord.com/api/webhooks/1/abc"))
+	assert.Error(t, integrations.Validate(models.INTEGRATION_DISCORD, "https://discord.com/channels/1"))
+	assert.Error(t, integrations.Validate(models.INTEGRATION_DISCORD, "https://127.0.0.1/api/webhooks/1"))
+	assert.Error(t, integrations.Validate("teams", "https://example.com"))
+}
+
+// TestIntegrationsPayload verifies message formatting per driver.
+func TestIntegrationsPayload(t *testing.T) {
+	dispatcher := integrations.NewDispatcher(nil, "https://blog.example.com/posts/{id}")
+	event := dispatcher.PostEvent(models.BlogPost{ID: "p1", Title: "Go <3", Content: "Body"})
+	assert.Equal(t, "https://blog.example.com/posts/p1", event.URL)
+
+	slack := integrations.Drivers[models.INTEGRATION_SLACK].Payload(event)
+	assert.Equal(t, map[string]any{"text": "*<https://blog.example.com/posts/p1|Go &lt;3>*\nBody"}, slack)
+
+	discord := integrations.Drivers[models.INTEGRATION_DISCORD].Payload(event).(map[string]any)
+	embed := discord["embeds"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Go <3", embed["title"])
+	assert.Equal(t, "Body", embed["description"])
+}
+
+// TestIntegrationsTelegram verifies the Telegram notifier is only enabled
+// with both settings and escapes HTML in messages.
+func TestIntegrationsTelegram(t *testing.T) {
+	assert.Nil(t, integrations.NewTelegram("token", ""))
+
+	telegram := integrations.NewTelegram("123:abc", "@blog")
+	assert.Equal(t, "https://api.telegram.org/bot123:abc/sendMessage", telegram.URL())
+	payload := telegram.Payload(integrations.Event{Title: "Go <3", Summary: "a & b"})
+	assert.Equal(t, map[string]any{
+		"chat_id":    "@blog",
+		"text":       "<b>Go &lt;3</b>\na &amp; b",
+		"parse_mode": "HTML",
+	}, payload)
+}
@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/jobs"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewLocker verifies lease durations default when not positive and that
+// every locker gets its own owner name, even within one process.
+func TestNewLocker(t *testing.T) {
+	db := &storage.Storage{}
+	assert.Equal(t, jobs.DEFAULT_LOCK_TTL, jobs.NewLocker(db, 0).TTL)
+	assert.Equal(t, jobs.DEFAULT_LOCK_TTL, jobs.NewLocker(db, -time.Second).TTL)
+	assert.Equal(t, 5*time.Minute, jobs.NewLocker(db, 5*time.Minute).TTL)
+
+	first, second := jobs.NewLocker(db, 0), jobs.NewLocker(db, 0)
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first.Owner, host+"-"), first.Owner)
+	assert.NotEqual(t, first.Owner, second.Owner)
+}
+
+// TestLockerUnavailable verifies a job is not run when its lease cannot be
+// acquired, and that the failure is reported rather than counted as
+// contention.
+func TestLockerUnavailable(t *testing.T) {
+	locker := jobs.NewLocker(&storage.Storage{Locks: offlineCollection(t, "locks")}, time.Minute)
+	called := false
+	ran, err := locker.Do(context.Background(), jobs.DUPLICATE_SCAN_LOCK, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.False(t, ran)
+	assert.False(t, called)
+	assert.Equal(t, jobs.LockStats{}, locker.Stats())
+}
+
+// TestLockEndpointsUnavailable verifies the lock listing and the manual
+// duplicate scan answer 502 when the locks collection cannot be reached.
+func TestLockEndpointsUnavailable(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	h.DB.Locks = offlineCollection(t, "locks")
+	app := fiber.New()
+	app.Get("/api/admin/locks", h.GetLocks)
+	app.Post("/api/admin/duplicates/scan", h.ScanDuplicates)
+
+	for _, tc := range []struct{ method, path, message string }{
+		{"GET", "/api/admin/locks", "Failed to fetch locks"},
+		{"POST", "/api/admin/duplicates/scan", "Failed to scan for duplicates"},
+	} {
+		resp, err := app.Test(httptest.NewRequest(tc.method, tc.path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, 502, resp.StatusCode, tc.path)
+		assert.Equal(t, tc.message, decodeResponse(t, resp.Body).Error, tc.path)
+	}
+}
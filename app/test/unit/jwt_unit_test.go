@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/routes"
+	"github.com/pedrobertao/challenge-prosi/app/lib/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJWTRoundTripAndRejections verifies that tokens carry their claims and
+// are rejected when signed by another key, expired, or downgraded to the
+// "none" algorithm.
+func TestJWTRoundTripAndRejections(t *testing.T) {
+	signer := jwt.NewSigner("test-secret")
+	claims := jwt.Claims{Subject: "686c3a82361beb165141b490", Name: "ana", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	signed, err := signer.Sign(claims)
+	require.NoError(t, err)
+	verified, err := signer.Verify(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, claims, verified)
+
+	// Signed by another key
+	_, err = jwt.NewSigner("other-secret").Verify(signed)
+	assert.Equal(t, jwt.ErrInvalid, err)
+
+	// Unsigned token claiming alg "none"
+	parts := strings.Split(signed, ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	_, err = signer.Verify(none + "." + parts[1] + ".")
+	assert.Equal(t, jwt.ErrInvalid, err)
+
+	// Past its expiry
+	claims.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	expired, err := signer.Sign(claims)
+	require.NoError(t, err)
+	_, err = signer.Verify(expired)
+	assert.Equal(t, jwt.ErrExpired, err)
+}
+
+// TestRequireAuth verifies that protected routes answer 401 without a valid
+// Bearer token, expose the claims with one, and are reported as JWT routes.
+func TestRequireAuth(t *testing.T) {
+	signer := jwt.NewSigner("test-secret")
+	app := fiber.New()
+	app.Post("/protected", middleware.RequireAuth(signer), func(c *fiber.Ctx) error {
+		claims, ok := middleware.CurrentUser(c)
+		require.True(t, ok)
+		return c.SendString(claims.Name)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/protected", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Bearer")
+
+	signed, err := signer.Sign(jwt.Claims{Subject: "686c3a82361beb165141b490", Name: "ana", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	infos := routes.Describe(app)
+	require.Len(t, infos, 1)
+	assert.Equal(t, routes.AUTH_JWT, infos[0].Auth)
+}
+
+// TestRegisterReservesAdminNames verifies registration refuses the names
+// listed in ADMIN_USERS, whatever their case, before touching the database.
+func TestRegisterReservesAdminNames(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	h.Config.AdminUsers = []string{"Ana"}
+	app := fiber.New()
+	app.Post("/api/auth/register", h.Register)
+
+	for _, username := range []string{"ana", "ANA", " Ana "} {
+		req := httptest.NewRequest("POST", "/api/auth/register", strings.NewReader(`{"username":"`+username+`","password":"long-enough"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode, username)
+		assert.Equal(t, "Username is reserved", decodeResponse(t, resp.Body).Error)
+	}
+}
@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/lib/lifecycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLifecycle verifies hooks start in registration order and stop in
+// reverse, that a stuck hook times out without holding up the others, and
+// that a failed start stops the hooks already started.
+func TestLifecycle(t *testing.T) {
+	var calls []string
+	hook := func(name string) lifecycle.Hook {
+		return lifecycle.Hook{
+			Name:  name,
+			Start: func(context.Context) error { calls = append(calls, "start "+name); return nil },
+			Stop:  func(context.Context) error { calls = append(calls, "stop "+name); return nil },
+		}
+	}
+
+	manager := lifecycle.New(time.Second)
+	stuck := hook("stuck")
+	stuck.Timeout = 10 * time.Millisecond
+	stuck.Stop = func(context.Context) error { select {} }
+	jobStopped := make(chan struct{})
+	manager.Append(hook("database"), stuck, lifecycle.Workers("jobs", func(ctx context.Context) {
+		<-ctx.Done()
+		close(jobStopped)
+	}), hook("server"))
+
+	require.NoError(t, manager.Start(context.Background()))
+	err := manager.Stop()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "stop stuck")
+	assert.Equal(t, []string{"start database", "start stuck", "start server", "stop server", "stop database"}, calls)
+	<-jobStopped
+
+	calls = nil
+	failing := hook("federation")
+	failing.Start = func(context.Context) error { return errors.New("no key") }
+	manager = lifecycle.New(time.Second)
+	manager.Append(hook("database"), failing, hook("server"))
+	assert.EqualError(t, manager.Start(context.Background()), "start federation: no key")
+	assert.Equal(t, []string{"start database", "stop database"}, calls)
+	assert.NoError(t, manager.Stop(), "stopped hooks are not stopped twice")
+}
+
+// TestGracefulShutdown verifies the server hook of cmd/main.go drains
+// in-flight requests before it returns, refuses new connections after, and
+// gives up on requests outlasting SHUTDOWN_TIMEOUT.
+func TestGracefulShutdown(t *testing.T) {
+	cfg := checkedConfig()
+	cfg.ShutdownTimeout = 0
+	assert.ErrorContains(t, cfg.Validate(), "SHUTDOWN_TIMEOUT: must be positive")
+
+	serve := func(timeout time.Duration) (*lifecycle.Manager, string, chan struct{}, chan struct{}) {
+		started, release := make(chan struct{}), make(chan struct{})
+		app := fiber.New(fiber.Config{DisableStartupMessage: true})
+		app.Get("/slow", func(c *fiber.Ctx) error {
+			close(started)
+			<-release
+			return c.SendString("done")
+		})
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		manager := lifecycle.New(timeout)
+		manager.Append(lifecycle.Hook{
+			Name:  "server",
+			Start: func(context.Context) error { go app.Listener(listener); return nil },
+			Stop:  app.ShutdownWithContext,
+		})
+		require.NoError(t, manager.Start(context.Background()))
+		return manager, "http://" + listener.Addr().String(), started, release
+	}
+
+	manager, base, started, release := serve(5 * time.Second)
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{string(body), err}
+	}()
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- manager.Stop() }()
+	select {
+	case <-stopped:
+		t.Fatal("shutdown returned while a request was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	response := <-responses
+	require.NoError(t, response.err)
+	assert.Equal(t, "done", response.body)
+	assert.NoError(t, <-stopped)
+	_, err := http.Get(base + "/slow")
+	assert.Error(t, err, "connections accepted after shutdown")
+
+	manager, base, started, release = serve(20 * time.Millisecond)
+	defer close(release)
+	go func() {
+		if resp, err := http.Get(base + "/slow"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	err = manager.Stop()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "stop server")
+}
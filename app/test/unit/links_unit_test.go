@@ -0,0 +1,53 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckLink verifies which answers make a link broken, including the
+// GET retry for servers that reject HEAD.
+func TestCheckLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/flaky":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	checker := jobs.NewLinkChecker(nil, nil, server.Client(), 0)
+	for path, wantOK := range map[string]bool{
+		"/ok":       true,
+		"/get-only": true,
+		"/flaky":    true,
+		"/missing":  false,
+		"/gone":     false,
+	} {
+		result, ok := checker.CheckLink(context.Background(), server.URL+path)
+		assert.Equal(t, wantOK, ok, path)
+		if !wantOK {
+			assert.NotZero(t, result.Status, path)
+		}
+	}
+
+	// A server that does not answer is broken, with the error recorded
+	server.Close()
+	result, ok := checker.CheckLink(context.Background(), server.URL+"/ok")
+	assert.False(t, ok)
+	assert.Zero(t, result.Status)
+	assert.NotEmpty(t, result.Error)
+}
@@ -0,0 +1,24 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseLogSampling verifies valid rules are kept when others are
+// rejected.
+func TestParseLogSampling(t *testing.T) {
+	rules, err := middleware.ParseLogSampling([]string{"/healthz=0", "/api/posts=0.25", "api=0.5", "/x=2", "/y"})
+
+	assert.Error(t, err)
+	assert.Equal(t, []middleware.LogSampling{
+		{Prefix: "/healthz", Rate: 0},
+		{Prefix: "/api/posts", Rate: 0.25},
+	}, rules)
+
+	rules, err = middleware.ParseLogSampling(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/routes"
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// postAccessToken signs an access token the way UnlockPost does: bound to
+// the post and to a fingerprint of its passphrase hash.
+func postAccessToken(h *handlers.Handler, postID models.ID, passphraseHash string, expiresAt time.Time) string {
+	sum := sha256.Sum256([]byte(passphraseHash))
+	return h.Tokens.Sign(handlers.POST_ACCESS_TOKEN_PURPOSE, postID.String()+":"+hex.EncodeToString(sum[:8]), expiresAt)
+}
+
+// TestGetProtectedPost verifies a protected post answers 401 with an unlock
+// challenge until the request carries a valid access token for the post's
+// current passphrase.
+func TestGetProtectedPost(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	hash := "$2a$10$currentcurrentcurrentcu"
+	posts.On("Get", mock.Anything, id, storage.CommentPage{Limit: handlers.DEFAULT_POST_COMMENTS_LIMIT}).
+		Return(models.BlogPost{ID: id, Title: "Secret", Content: "Hidden content", Protected: true, PassphraseHash: hash}, nil)
+	posts.On("RecordView", mock.Anything, id).Return(nil)
+
+	app := fiber.New()
+	app.Get("/api/posts/:id", h.GetPost)
+	get := func(token string) (int, models.APIResponse, string) {
+		req := httptest.NewRequest("GET", "/api/posts/"+id.String(), nil)
+		if token != "" {
+			req.Header.Set(handlers.POST_ACCESS_HEADER, token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode, decodeResponse(t, resp.Body), resp.Header.Get("WWW-Authenticate")
+	}
+
+	status, response, challenge := get("")
+	assert.Equal(t, 401, status)
+	assert.Equal(t, "Passphrase required", response.Error)
+	assert.Equal(t, `Passphrase realm="post", unlock="/api/posts/`+id.String()+`/unlock"`, challenge)
+
+	for name, token := range map[string]string{
+		"forged":          "bm9wZQ.bm9wZQ",
+		"expired":         postAccessToken(h, id, hash, time.Now().Add(-time.Minute)),
+		"old passphrase":  postAccessToken(h, id, "$2a$10$previouspreviousprevio", time.Now().Add(time.Hour)),
+		"other post":      postAccessToken(h, "686c3a82361beb165141b491", hash, time.Now().Add(time.Hour)),
+		"preview purpose": h.Tokens.Sign(handlers.PREVIEW_TOKEN_PURPOSE, id.String(), time.Now().Add(time.Hour)),
+	} {
+		status, response, _ := get(token)
+		assert.Equal(t, 401, status, name)
+		assert.Equal(t, "Invalid or expired access token", response.Error, name)
+	}
+	posts.AssertNotCalled(t, "RecordView", mock.Anything, mock.Anything)
+
+	status, response, _ = get(postAccessToken(h, id, hash, time.Now().Add(time.Hour)))
+	require.Equal(t, 200, status)
+	post := response.Data.(map[string]interface{})
+	assert.Equal(t, "Hidden content", post["content"])
+	assert.NotContains(t, post, "passphrase_hash")
+	posts.AssertExpectations(t)
+}
+
+// TestPassphraseRejected verifies passphrase changes need a login and a
+// passphrase bcrypt can hash, and that unlocking rejects malformed requests
+// and reports lookup failures.
+func TestPassphraseRejected(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	h.DB.Posts = offlineCollection(t, "posts")
+	routed := routes.Setup(h)
+	app := fiber.New()
+	app.Put("/api/posts/:id/passphrase", h.SetPassphrase)
+	app.Delete("/api/posts/:id/passphrase", h.RemovePassphrase)
+	app.Post("/api/posts/:id/unlock", h.UnlockPost)
+
+	for _, method := range []string{"PUT", "DELETE"} {
+		resp, err := routed.Test(httptest.NewRequest(method, "/api/posts/686c3a82361beb165141b490/passphrase", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 401, resp.StatusCode, method)
+	}
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+		message            string
+	}{
+		{"PUT", "/api/posts/686c3a82361beb165141b490/passphrase", `{"passphrase":`, 400, "Invalid JSON"},
+		{"PUT", "/api/posts/686c3a82361beb165141b490/passphrase", `{"passphrase":""}`, 400, "Passphrase must be 1 to 72 bytes"},
+		{"PUT", "/api/posts/686c3a82361beb165141b490/passphrase", `{"passphrase":"` + strings.Repeat("é", 37) + `"}`, 400, "Passphrase must be 1 to 72 bytes"},
+		{"DELETE", "/api/posts/nope/passphrase", "", 400, "Invalid post ID"},
+		{"POST", "/api/posts/nope/unlock", `{"passphrase":"open sesame"}`, 400, "Invalid post ID"},
+		{"POST", "/api/posts/686c3a82361beb165141b490/unlock", `{"passphrase":`, 400, "Invalid JSON"},
+		{"POST", "/api/posts/686c3a82361beb165141b490/unlock", `{"passphrase":"open sesame"}`, 500, "Failed to fetch post"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		name := tc.method + " " + tc.path + " " + tc.body
+		assert.Equal(t, tc.status, resp.StatusCode, name)
+		assert.Equal(t, tc.message, decodeResponse(t, resp.Body).Error, name)
+	}
+}
@@ -0,0 +1,69 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/plugins"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingPlugin implements every hook and records the order it ran in.
+type recordingPlugin struct {
+	name   string
+	calls  *[]string
+	reject bool
+}
+
+func (p *recordingPlugin) Name() string { return p.name }
+
+func (p *recordingPlugin) PreValidate(c *fiber.Ctx, resource string, request any) error {
+	*p.calls = append(*p.calls, p.name+":pre-validate")
+	if p.reject {
+		return errors.New("rejected by " + p.name)
+	}
+	return nil
+}
+
+func (p *recordingPlugin) PostCreate(ctx context.Context, resource string, created any) {
+	*p.calls = append(*p.calls, p.name+":post-create")
+	panic("plugins must not break writes")
+}
+
+func (p *recordingPlugin) PreResponse(c *fiber.Ctx, resource string, data any) any {
+	*p.calls = append(*p.calls, p.name+":pre-response")
+	return data.(string) + "+" + p.name
+}
+
+// TestPluginChainOrderAndHooks verifies plugins run in configured order,
+// pre-validate stops at the first rejection, post-create panics are
+// contained, and pre-response threads data through each plugin.
+func TestPluginChainOrderAndHooks(t *testing.T) {
+	var calls []string
+	plugins.Register(&recordingPlugin{name: "test-first", calls: &calls})
+	plugins.Register(&recordingPlugin{name: "test-second", calls: &calls, reject: true})
+
+	chain, err := plugins.Enable([]string{"test-second", "test-first", "test-missing"})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"test-second", "test-first"}, chain.Names())
+
+	// The second plugin runs first and rejects, so the first never runs
+	err = chain.PreValidate(nil, plugins.RESOURCE_POST, nil)
+	assert.EqualError(t, err, "rejected by test-second")
+	assert.Equal(t, []string{"test-second:pre-validate"}, calls)
+
+	// Both post-create hooks run despite panicking
+	calls = nil
+	chain.PostCreate(context.Background(), plugins.RESOURCE_POST, nil)
+	assert.Equal(t, []string{"test-second:post-create", "test-first:post-create"}, calls)
+
+	// Data is threaded through responders in order
+	assert.Equal(t, "data+test-second+test-first", chain.PreResponse(nil, plugins.RESOURCE_POST, "data"))
+
+	// A nil chain is a no-op
+	var empty *plugins.Chain
+	assert.NoError(t, empty.PreValidate(nil, plugins.RESOURCE_POST, nil))
+	assert.Equal(t, "data", empty.PreResponse(nil, plugins.RESOURCE_POST, "data"))
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// benchmarkPostDocument builds the raw BSON of a post as MongoDB would send it.
+// When projected is true the content field is left out, mirroring what the
+// server returns once handlers.PostHeaderProjection is applied to the query.
+func benchmarkPostDocument(b *testing.B, projected bool) []byte {
+	doc := bson.M{
+		"_id":        primitive.NewObjectID(),
+		"title":      "A reasonably sized blog post title",
+		"created_at": time.Now(),
+	}
+	if !projected {
+		// Typical long-form article body (~20KB)
+		doc["content"] = strings.Repeat("Lorem ipsum dolor sit amet. ", 730)
+	}
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return raw
+}
+
+// BenchmarkDecodeFullPost measures the listing path before projection:
+// the whole document is transferred and decoded into models.BlogPost.
+func BenchmarkDecodeFullPost(b *testing.B) {
+	raw := benchmarkPostDocument(b, false)
+	b.SetBytes(int64(len(raw)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var post models.BlogPost
+		if err := bson.Unmarshal(raw, &post); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeProjectedPost measures the listing path with projection:
+// only title and created_at are transferred and decoded into models.BlogPostHeader.
+func BenchmarkDecodeProjectedPost(b *testing.B) {
+	raw := benchmarkPostDocument(b, true)
+	b.SetBytes(int64(len(raw)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var post models.BlogPostHeader
+		if err := bson.Unmarshal(raw, &post); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
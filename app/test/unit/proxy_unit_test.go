@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientIPBehindTrustedProxies verifies that forwarding headers are only
+// honored from trusted peers and that spoofed left-most hops are ignored.
+func TestClientIPBehindTrustedProxies(t *testing.T) {
+	resolver, err := proxy.NewResolver([]string{"10.0.0.0/8", "192.168.1.10"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name, peer, forwardedFor, realIP, want string
+	}{
+		{"untrusted peer ignores headers", "203.0.113.7", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"single trusted hop", "10.0.0.5", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed hop left of client", "10.0.0.5", "1.1.1.1, 198.51.100.1, 10.0.0.9", "", "198.51.100.1"},
+		{"all hops trusted", "192.168.1.10", "10.1.1.1, 10.2.2.2", "", "10.1.1.1"},
+		{"malformed hop stops the walk", "10.0.0.5", "198.51.100.1, garbage", "", "10.0.0.5"},
+		{"real ip fallback", "10.0.0.5", "", "198.51.100.3", "198.51.100.3"},
+		{"no headers", "10.0.0.5", "", "", "10.0.0.5"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, resolver.ClientIP(tc.peer, tc.forwardedFor, tc.realIP))
+		})
+	}
+}
+
+// TestNewResolverReportsInvalidEntries verifies that invalid entries are
+// reported while valid ones stay trusted.
+func TestNewResolverReportsInvalidEntries(t *testing.T) {
+	resolver, err := proxy.NewResolver([]string{"10.0.0.0/8", "not-an-ip", "300.0.0.0/8"})
+	assert.Error(t, err)
+	assert.Equal(t, "198.51.100.1", resolver.ClientIP("10.0.0.1", "198.51.100.1", ""))
+
+	var none *proxy.Resolver
+	assert.Equal(t, "10.0.0.1", none.ClientIP("10.0.0.1", "198.51.100.1", ""))
+}
@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/proxy"
+	"github.com/pedrobertao/challenge-prosi/app/internal/ratelimit"
+	"github.com/pedrobertao/challenge-prosi/app/internal/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRateLimit verifies that requests over a policy's limit get 429 with
+// Retry-After, that methods the policy does not count pass through, and
+// that each policy counts apart from the others.
+func TestRateLimit(t *testing.T) {
+	proxies, err := proxy.NewResolver(nil)
+	require.NoError(t, err)
+	store := ratelimit.NewMemory()
+	reads := ratelimit.Policy{Name: "reads", Limit: 2, Window: time.Minute, Methods: []string{fiber.MethodGet}}
+	posts := ratelimit.Policy{Name: "posts", Limit: 1, Window: time.Minute}
+
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(200) }
+	api := app.Group("/api", middleware.RateLimit(proxies, store, reads))
+	api.Get("/posts", ok)
+	api.Post("/posts", middleware.RateLimit(proxies, store, posts), ok)
+
+	cases := []struct {
+		method    string
+		status    int
+		remaining string
+	}{
+		{"GET", 200, "1"},
+		{"GET", 200, "0"},
+		{"GET", 429, "0"},
+		{"POST", 200, "0"},
+		{"POST", 429, "0"},
+	}
+	for i, tc := range cases {
+		resp, err := app.Test(httptest.NewRequest(tc.method, "/api/posts", nil))
+		require.NoError(t, err)
+		assert.Equal(t, tc.status, resp.StatusCode, "request %d", i)
+		assert.Equal(t, tc.remaining, resp.Header.Get(middleware.RATE_LIMIT_REMAINING_HEADER), "request %d", i)
+		if tc.status == 429 {
+			assert.Equal(t, "60", resp.Header.Get(fiber.HeaderRetryAfter), "request %d", i)
+			response := decodeResponse(t, resp.Body)
+			assert.False(t, response.Success)
+			assert.NotEmpty(t, response.Error)
+		}
+	}
+}
+
+// TestEmbedCommentRateLimit verifies the embed comment endpoint shares the
+// comments limit of the API endpoint, so it cannot be used to get around it.
+func TestEmbedCommentRateLimit(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	h.Config.RateLimitComments = 1
+	h.Config.RateLimitWindow = time.Minute
+	app := routes.Setup(h)
+
+	post := func(path string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(`{"author":"ana","content":"hi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 401, post("/api/posts/686c3a82361beb165141b490/comments"), "counted, then refused without a login")
+	assert.Equal(t, 429, post("/embed/api/posts/686c3a82361beb165141b490/comments"))
+}
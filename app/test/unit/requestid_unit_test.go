@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestID verifies client IDs are reused when well formed, and that
+// error bodies, but not successful ones, carry the ID.
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.RequestID())
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.JSON(models.APIResponse{Success: true, Data: middleware.CurrentRequestID(c)})
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusBadGateway).JSON(models.APIResponse{Success: false, Error: "Failed"})
+	})
+
+	req := httptest.NewRequest("GET", "/fail", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "trace-123")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "trace-123", resp.Header.Get(fiber.HeaderXRequestID))
+	body := decodeResponse(t, resp.Body)
+	assert.Equal(t, "Failed", body.Error)
+	assert.Equal(t, "trace-123", body.RequestID)
+
+	// Malformed client IDs are replaced with a generated one
+	req = httptest.NewRequest("GET", "/ok", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "bad id\twith spaces")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	id := resp.Header.Get(fiber.HeaderXRequestID)
+	assert.Len(t, id, 32)
+	body = decodeResponse(t, resp.Body)
+	assert.Equal(t, id, body.Data)
+	assert.Empty(t, body.RequestID, "successful responses are left unchanged")
+}
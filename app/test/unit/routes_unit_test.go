@@ -0,0 +1,165 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/internal/routes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeRoutes(t *testing.T) {
+	app := fiber.New()
+	noop := func(c *fiber.Ctx) error { return nil }
+	mw := func(c *fiber.Ctx) error { return c.Next() }
+
+	app.Get("/public", noop)
+	group := app.Group("/guarded", mw)
+	group.Get("/item", noop)
+	group.Post("/item", noop)
+
+	infos := routes.Describe(app)
+	require.Len(t, infos, 3, "HEAD duplicates and Use routes must be omitted")
+
+	for _, info := range infos {
+		assert.Equal(t, routes.AUTH_PUBLIC, info.Auth)
+		if info.Path == "/public" {
+			assert.Empty(t, info.Middleware)
+		} else {
+			assert.Equal(t, "/guarded/item", info.Path)
+			require.Len(t, info.Middleware, 1, "group middleware is attributed to its routes")
+		}
+	}
+}
+
+// TestOpenAPI verifies that the OpenAPI document describes the registered
+// routes with path parameters, request bodies, and security, and that the
+// Swagger UI page loads it.
+func TestOpenAPI(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	app := routes.Setup(h)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/openapi.json", nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			OperationID string           `json:"operationId"`
+			Tags        []string         `json:"tags"`
+			Parameters  []map[string]any `json:"parameters"`
+			RequestBody map[string]any   `json:"requestBody"`
+			Security    []map[string]any `json:"security"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+	assert.Equal(t, routes.OPENAPI_VERSION, doc.OpenAPI)
+
+	post := doc.Paths["/api/posts/{id}"]
+	require.Contains(t, post, "get")
+	require.Contains(t, post, "put")
+	assert.Equal(t, "GetPost", post["get"].OperationID)
+	assert.Equal(t, []string{"posts"}, post["get"].Tags)
+	assert.Equal(t, "id", post["get"].Parameters[0]["name"])
+	assert.Empty(t, post["get"].Security, "reads are public")
+	assert.NotEmpty(t, post["put"].Security)
+	assert.Contains(t, fmt.Sprint(post["put"].RequestBody), "#/components/schemas/UpdatePostRequest")
+	assert.NotEmpty(t, doc.Paths["/api/me/settings"]["get"].Security)
+	assert.Equal(t, "GetPostComments_embed", doc.Paths["/embed/api/posts/{id}/comments"]["get"].OperationID)
+
+	comment := doc.Components.Schemas["CreateCommentRequest"]["properties"].(map[string]any)["content"].(map[string]any)
+	assert.EqualValues(t, 5000, comment["maxLength"], "configured limits are filled in")
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/docs", nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), handlers.OPENAPI_PATH)
+}
+
+// TestCORS verifies preflights from allowed origins are answered with the
+// configured policy, other origins get no CORS headers, and invalid
+// ALLOWED_ORIGINS entries are skipped.
+func TestCORS(t *testing.T) {
+	origins, err := middleware.ParseOrigins([]string{"https://blog.example.com", "https://*.example.org", "*", "ftp://files.example.com", "https://example.com/path"})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"https://blog.example.com", "https://*.example.org"}, origins)
+	origins, err = middleware.ParseOrigins([]string{"*"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{middleware.ANY_ORIGIN}, origins)
+
+	h, _, _ := newMockedHandler(t)
+	h.Config.AllowedOrigins = []string{"https://blog.example.com", "https://*.example.org"}
+	h.Config.CORSAllowedMethods = []string{"GET", "POST"}
+	h.Config.CORSAllowedHeaders = []string{"Authorization", "Content-Type"}
+	h.Config.CORSMaxAge = 10 * time.Minute
+	app := routes.Setup(h)
+
+	preflight := func(origin string) *http.Response {
+		req := httptest.NewRequest("OPTIONS", "/api/posts", nil)
+		req.Header.Set(fiber.HeaderOrigin, origin)
+		req.Header.Set(fiber.HeaderAccessControlRequestMethod, "POST")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := preflight("https://blog.example.com")
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, "https://blog.example.com", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "GET,POST", resp.Header.Get(fiber.HeaderAccessControlAllowMethods))
+	assert.Equal(t, "Authorization,Content-Type", resp.Header.Get(fiber.HeaderAccessControlAllowHeaders))
+	assert.Equal(t, "600", resp.Header.Get(fiber.HeaderAccessControlMaxAge))
+
+	resp = preflight("https://docs.example.org")
+	assert.Equal(t, "https://docs.example.org", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+
+	resp = preflight("https://evil.example.net")
+	assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+}
+
+// TestRouteAuth verifies the authentication requirement of routes exposing
+// hidden content or changing it, as reported by Describe.
+func TestRouteAuth(t *testing.T) {
+	h, _, _ := newMockedHandler(t)
+	auth := make(map[string]string)
+	for _, info := range routes.Describe(routes.Setup(h)) {
+		auth[info.Method+" "+info.Path] = info.Auth
+	}
+
+	for route, want := range map[string]string{
+		"GET /api/posts":                         routes.AUTH_PUBLIC,
+		"GET /api/posts/export":                  routes.AUTH_ADMIN,
+		"POST /api/posts/:id/comments/import":    routes.AUTH_ADMIN,
+		"GET /api/posts/:id/likes":               routes.AUTH_ADMIN,
+		"POST /api/admin/api-keys":               routes.AUTH_ADMIN,
+		"GET /api/admin/locks":                   routes.AUTH_ADMIN,
+		"POST /api/admin/duplicates/scan":        routes.AUTH_ADMIN,
+		"PUT /api/posts/:id/passphrase":          routes.AUTH_JWT,
+		"DELETE /api/posts/:id/passphrase":       routes.AUTH_JWT,
+		"POST /api/posts/:id/unlock":             routes.AUTH_PUBLIC,
+		"PUT /api/posts/:id/visibility":          routes.AUTH_JWT,
+		"POST /api/posts/:id/archive":            routes.AUTH_JWT,
+		"DELETE /api/posts/:id/archive":          routes.AUTH_JWT,
+		"POST /api/posts/:id/translations/:lang": routes.AUTH_JWT,
+		"POST /api/posts/:id/preview-token":      routes.AUTH_JWT,
+		"POST /api/posts/:id/assist":             routes.AUTH_JWT,
+		"POST /api/posts/:id/assist/accept":      routes.AUTH_JWT,
+		"POST /embed/api/posts/:id/comments":     routes.AUTH_EMBED,
+	} {
+		assert.Equal(t, want, auth[route], route)
+	}
+}
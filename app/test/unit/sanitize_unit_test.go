@@ -0,0 +1,37 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/sanitize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSanitize verifies that both modes keep allowed formatting, drop
+// unsafe attributes and URL schemes, and differ only in whether other
+// markup is removed or escaped.
+func TestSanitize(t *testing.T) {
+	strip, err := sanitize.New(sanitize.MODE_STRIP)
+	require.NoError(t, err)
+	escape, err := sanitize.New(sanitize.MODE_ESCAPE)
+	require.NoError(t, err)
+	_, err = sanitize.New("off")
+	assert.Error(t, err)
+
+	input := `<p onclick="steal()">Hi</p><script>alert(1)</script>[me](javascript:alert(1))`
+	assert.Equal(t, `<p>Hi</p>[me](#)`, strip.Sanitize(input))
+	assert.Equal(t, `<p>Hi</p>&lt;script&gt;alert(1)&lt;/script&gt;[me](#)`, escape.Sanitize(input))
+
+	cases := []struct{ in, want string }{
+		{"# Title\n\n> quote & a < b", "# Title\n\n> quote & a < b"},
+		{`<a href="java&#x09;script:x" title='a>b'>x</a>`, `<a title="a&gt;b">x</a>`},
+		{`<a HREF=/posts/1>ok</a> <img src=https://x.test/a.png onerror=alert(1)/>`, `<a href="/posts/1">ok</a> <img src="https://x.test/a.png">`},
+		{`<code class="language-go">x</code><code class="evil">y</code>`, `<code class="language-go">x</code><code>y</code>`},
+		{"before<STYLE>body{}</style >after<!-- note -->", "beforeafter"},
+		{"&lt;script&gt;", "&lt;script&gt;"},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, strip.Sanitize(tc.in), tc.in)
+	}
+}
@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSchemaFromRequestDTO verifies that JSON names, types, and schema tag
+// rules are reflected in the generated document.
+func TestSchemaFromRequestDTO(t *testing.T) {
+	doc := schema.For(models.CreatePostRequest{})
+
+	assert.Equal(t, schema.DRAFT, doc["$schema"])
+	assert.Equal(t, "CreatePostRequest", doc["title"])
+	assert.Equal(t, "object", doc["type"])
+	assert.Equal(t, []string{"title", "content"}, doc["required"])
+	assert.Equal(t, schema.Schema{"type": "string", "minLength": 1, "maxLength": 200}, doc["properties"].(schema.Schema)["title"])
+}
+
+// TestSchemaOptionalArrays verifies that optional fields are not required
+// and slices map to typed arrays.
+func TestSchemaOptionalArrays(t *testing.T) {
+	doc := schema.For(models.AcceptAssistRequest{})
+
+	assert.NotContains(t, doc, "required")
+	assert.Equal(t,
+		schema.Schema{"type": "array", "items": schema.Schema{"type": "string"}},
+		doc["properties"].(schema.Schema)["tags"],
+	)
+}
+
+// TestSchemaEnum verifies that enum rules list the allowed values.
+func TestSchemaEnum(t *testing.T) {
+	doc := schema.For(models.SetVisibilityRequest{})
+
+	assert.Equal(t, []string{"visibility"}, doc["required"])
+	assert.Equal(t,
+		schema.Schema{"type": "string", "enum": []string{"public", "unlisted", "private"}},
+		doc["properties"].(schema.Schema)["visibility"],
+	)
+}
+
+// TestSchemaValidate verifies that requests are trimmed, then checked
+// against the same tag rules, with one error per broken rule in field order.
+func TestSchemaValidate(t *testing.T) {
+	req := models.CreatePostRequest{
+		Title:      "  Hello  ",
+		Content:    " \n ",
+		Visibility: "secret",
+		Tags:       []string{"go", "\xff"},
+	}
+
+	assert.Equal(t, []schema.FieldError{
+		{Field: "content", Message: "is required"},
+		{Field: "visibility", Message: "must be one of public, unlisted, private"},
+		{Field: "tags[1]", Message: "must be valid UTF-8"},
+	}, schema.Validate(&req))
+	assert.Equal(t, "Hello", req.Title)
+
+	empty := ""
+	assert.Equal(t, []schema.FieldError{{Field: "title", Message: "must not be empty"}},
+		schema.Validate(&models.UpdatePostRequest{Title: &empty}))
+	assert.Nil(t, schema.Validate(&models.CreatePostRequest{Title: "Hello", Content: "World"}))
+}
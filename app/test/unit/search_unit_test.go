@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/search"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSearchTerms verifies phrases are split and negated terms dropped.
+func TestSearchTerms(t *testing.T) {
+	assert.Equal(t, []string{"go", "generics", "type", "params"},
+		search.Terms(`Go generics -java "type params" go`))
+	assert.Empty(t, search.Terms("-only -negated"))
+}
+
+// TestSearchHighlight verifies matched words are marked and the rest escaped.
+func TestSearchHighlight(t *testing.T) {
+	got := search.Highlight("Posting <b>posts</b> & more", []string{"post"})
+	assert.Equal(t, "<mark>Posting</mark> &lt;b&gt;<mark>posts</mark>&lt;/b&gt; &amp; more", got)
+	assert.Equal(t, "no match", search.Highlight("no match", []string{"post"}))
+}
+
+// TestSearchSnippets verifies excerpts are cut around matches and merged
+// when their context overlaps.
+func TestSearchSnippets(t *testing.T) {
+	filler := strings.Repeat("lorem ipsum ", 20)
+	text := filler + "first needle here " + filler + "second needle and another needle " + filler
+
+	snippets := search.Snippets(text, []string{"needle"}, 5)
+	assert.Len(t, snippets, 2)
+	for _, snippet := range snippets {
+		assert.True(t, strings.HasPrefix(snippet, "…"))
+		assert.True(t, strings.HasSuffix(snippet, "…"))
+		assert.NotContains(t, snippet, "  ")
+	}
+	assert.Equal(t, 1, strings.Count(snippets[0], "<mark>needle</mark>"))
+	assert.Equal(t, 2, strings.Count(snippets[1], "<mark>needle</mark>"))
+
+	assert.Len(t, search.Snippets(text, []string{"needle"}, 1), 1)
+	assert.Nil(t, search.Snippets(text, []string{"absent"}, 5))
+	assert.Equal(t, []string{"<mark>short</mark> text"}, search.Snippets("short\n text", []string{"short"}, 5))
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/config"
+	"github.com/pedrobertao/challenge-prosi/app/internal/selfcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkedConfig returns a valid configuration for self-check tests.
+func checkedConfig() *config.Config {
+	return &config.Config{
+		Port:                 "8080",
+		MongoURI:             "mongodb://127.0.0.1:27017",
+		DBName:               "blog",
+		IDFormat:             "objectid",
+		CommentMinLength:     1,
+		CommentMaxLength:     5000,
+		ShutdownTimeout:      time.Second,
+		JobLockTTL:           time.Minute,
+		PreviewTokenTTL:      time.Hour,
+		PostAccessTokenTTL:   time.Hour,
+		JWTTTL:               time.Hour,
+		RateLimitWindow:      time.Minute,
+		DBReadTimeout:        10 * time.Second,
+		DBWriteTimeout:       10 * time.Second,
+		DBAggregateTimeout:   30 * time.Second,
+		DBTransactionTimeout: 30 * time.Second,
+		DBMaxTimeout:         time.Minute,
+		SanitizeMode:         "strip",
+		AutosaveKeep:         20,
+		TokenSecret:          "token-secret",
+		JWTSecret:            "jwt-secret",
+	}
+}
+
+// TestSelfCheck verifies the report of an instance whose database is down:
+// the database checks fail, skipped ones included, configuration problems
+// are listed, and reachable integrations pass.
+func TestSelfCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := checkedConfig()
+	require.NoError(t, cfg.Validate())
+	cfg.AssistantAPIKey = "key"
+	cfg.AssistantAPIURL = server.URL
+	cfg.CSPReportSampleRate = 2
+
+	report := selfcheck.New(cfg, nil, errors.New("connection refused")).Run(context.Background())
+	statuses := map[string]string{}
+	for _, result := range report.Results {
+		statuses[result.Name] = result.Status
+	}
+	assert.Equal(t, map[string]string{
+		"config":                selfcheck.STATUS_FAIL,
+		"database":              selfcheck.STATUS_FAIL,
+		"indexes":               selfcheck.STATUS_FAIL,
+		"backfills":             selfcheck.STATUS_FAIL,
+		"integration:assistant": selfcheck.STATUS_OK,
+	}, statuses)
+	assert.Contains(t, report.Results[0].Detail, "CSP_REPORT_SAMPLE_RATE")
+	assert.True(t, report.Failed())
+}
+
+// TestCheckSecrets verifies production refuses sample, short, and shared
+// signing secrets, while other environments and unset secrets pass.
+func TestCheckSecrets(t *testing.T) {
+	strong := strings.Repeat("a", config.MIN_SECRET_BYTES)
+	other := strings.Repeat("b", config.MIN_SECRET_BYTES)
+	cases := []struct {
+		name, env, token, jwt, problem string
+	}{
+		{"distinct strong secrets", "prod", strong, other, ""},
+		{"unset secrets", "production", "", "", ""},
+		{"sample value", "PROD", "change-me", other, "TOKEN_SECRET: \"change-me\" is a sample value"},
+		{"short secret", "prod", strong, "jwt-secret", "JWT_SECRET: must be at least"},
+		{"shared secret", "prod", strong, strong, "TOKEN_SECRET: must differ from JWT_SECRET"},
+		{"development", "dev", "change-me", "change-me", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := checkedConfig()
+			cfg.ENV, cfg.TokenSecret, cfg.JWTSecret = tc.env, tc.token, tc.jwt
+			err := cfg.CheckSecrets()
+			if tc.problem == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.problem)
+			assert.ErrorContains(t, cfg.Validate(), tc.problem)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/similarity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numberedText builds a long text of distinct words (prefix0 prefix1 ...)
+// so that every shingle is unique, like real prose.
+func numberedText(prefix string, words int) string {
+	parts := make([]string, words)
+	for i := range parts {
+		parts[i] = prefix + strconv.Itoa(i)
+	}
+	return strings.Join(parts, " ")
+}
+
+// TestFindDuplicatesFlagsNearCopies verifies that a lightly edited copy of a
+// post is flagged while an unrelated post is not.
+func TestFindDuplicatesFlagsNearCopies(t *testing.T) {
+	original := numberedText("mongo", 300)
+	nearCopy := original + " one extra closing sentence was added by the importer"
+	unrelated := numberedText("fiber", 300)
+
+	hasher := similarity.NewMinHasher(similarity.DEFAULT_NUM_HASHES)
+	sign := func(text string) similarity.Signature {
+		return hasher.Sign(similarity.Shingles(text, similarity.DEFAULT_SHINGLE_SIZE))
+	}
+	docs := []similarity.Document{
+		{ID: "original", Signature: sign(original)},
+		{ID: "copy", Signature: sign(nearCopy)},
+		{ID: "unrelated", Signature: sign(unrelated)},
+	}
+
+	matches := similarity.FindDuplicates(docs, similarity.DEFAULT_BANDS, 0.8)
+
+	// Only the original/copy pair should be reported
+	require.Len(t, matches, 1)
+	assert.Equal(t, "original", matches[0].A)
+	assert.Equal(t, "copy", matches[0].B)
+	assert.GreaterOrEqual(t, matches[0].Similarity, 0.8)
+}
+
+// TestSimilarityOfIdenticalAndEmptyTexts checks the boundaries of the
+// similarity estimate.
+func TestSimilarityOfIdenticalAndEmptyTexts(t *testing.T) {
+	hasher := similarity.NewMinHasher(similarity.DEFAULT_NUM_HASHES)
+	text := "the quick brown fox jumps over the lazy dog again and again"
+
+	a := hasher.Sign(similarity.Shingles(text, similarity.DEFAULT_SHINGLE_SIZE))
+	b := hasher.Sign(similarity.Shingles(strings.ToUpper(text), similarity.DEFAULT_SHINGLE_SIZE))
+	assert.Equal(t, 1.0, similarity.Similarity(a, b))
+
+	empty := hasher.Sign(similarity.Shingles("", similarity.DEFAULT_SHINGLE_SIZE))
+	assert.Equal(t, 0.0, similarity.Similarity(empty, empty))
+}
@@ -0,0 +1,69 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/site"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSiteGenerate verifies which posts are rendered and listed, and that
+// tag pages, feeds, and threaded comments are written.
+func TestSiteGenerate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC)
+	posts := []models.BlogPost{
+		{ID: "public", Title: "Hello <World>", Content: "Body", CreatedAt: now, Tags: []string{"Go Lang"}},
+		{ID: "unlisted", Title: "Unlisted", CreatedAt: now, Visibility: models.VISIBILITY_UNLISTED},
+		{ID: "private", Title: "Private", CreatedAt: now, Visibility: models.VISIBILITY_PRIVATE},
+		{ID: "protected", Title: "Protected", CreatedAt: now, Protected: true},
+	}
+	comments := map[models.ID][]models.Comment{
+		"public": {
+			{ID: "c2", ParentID: "c1", Author: "Bob", Content: "Reply", CreatedAt: now.Add(time.Minute)},
+			{ID: "c1", Author: "Ann", Content: "First", CreatedAt: now},
+		},
+	}
+
+	report, err := site.Generate(site.Site{Title: "Blog", BaseURL: "https://blog.example.com", GeneratedAt: now}, posts, comments, dir)
+	require.NoError(t, err)
+	assert.Equal(t, site.Report{Posts: 2, Listed: 1, Tags: 1, Skipped: 2}, report)
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "Hello &lt;World&gt;")
+	assert.NotContains(t, string(index), "Unlisted")
+
+	page, err := os.ReadFile(filepath.Join(dir, "posts", "public", "index.html"))
+	require.NoError(t, err)
+	assert.Regexp(t, `(?s)First.*class="comment reply".*Reply`, string(page))
+
+	assert.FileExists(t, filepath.Join(dir, "posts", "unlisted", "index.html"))
+	assert.NoFileExists(t, filepath.Join(dir, "posts", "private", "index.html"))
+	assert.NoFileExists(t, filepath.Join(dir, "posts", "protected", "index.html"))
+	assert.FileExists(t, filepath.Join(dir, "tags", "go-lang", "index.html"))
+
+	feed, err := os.ReadFile(filepath.Join(dir, "feed.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(feed), "https://blog.example.com/posts/public/index.html")
+}
+
+// TestSiteRobots verifies the disallowed prefixes and sitemap line.
+func TestSiteRobots(t *testing.T) {
+	assert.Equal(t, "User-agent: *\nDisallow: /api/\nDisallow: /ap/\n\nSitemap: https://blog.example.com/sitemap.xml\n",
+		site.Robots([]string{"/api/", "/ap/"}, "https://blog.example.com/sitemap.xml"))
+	assert.Equal(t, "User-agent: *\nDisallow:\n", site.Robots(nil, ""))
+}
+
+// TestSiteHumans verifies the team and site sections.
+func TestSiteHumans(t *testing.T) {
+	updated := time.Date(2024, 1, 15, 23, 0, 0, 0, time.FixedZone("BRT", -3*3600))
+	assert.Equal(t, "/* TEAM */\nJane Doe <jane@example.com>\n\n/* SITE */\nLast update: 2024/01/16\nSoftware: "+site.HUMANS_SOFTWARE+"\n",
+		site.Humans([]string{"Jane Doe <jane@example.com>"}, updated))
+	assert.Equal(t, "/* SITE */\nSoftware: "+site.HUMANS_SOFTWARE+"\n", site.Humans(nil, time.Time{}))
+}
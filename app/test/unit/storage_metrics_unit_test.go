@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// TestOperationMetrics verifies that driver commands are counted per
+// collection and operation, with failures and durations, and that
+// commands without a collection are ignored.
+func TestOperationMetrics(t *testing.T) {
+	metrics := storage.NewOperationMetrics()
+	monitor := metrics.Monitor()
+	ctx := context.Background()
+
+	run := func(requestID int64, name string, command bson.D, duration time.Duration, failed bool) {
+		raw, err := bson.Marshal(command)
+		require.NoError(t, err)
+		monitor.Started(ctx, &event.CommandStartedEvent{Command: raw, CommandName: name, RequestID: requestID, ConnectionID: "conn-1"})
+		finished := event.CommandFinishedEvent{CommandName: name, RequestID: requestID, ConnectionID: "conn-1", Duration: duration}
+		if failed {
+			monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: "timeout"})
+		} else {
+			monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+		}
+	}
+	run(1, "find", bson.D{{Key: "find", Value: "posts"}}, 2*time.Millisecond, false)
+	run(2, "find", bson.D{{Key: "find", Value: "posts"}}, 6*time.Millisecond, true)
+	run(3, "getMore", bson.D{{Key: "getMore", Value: int64(42)}, {Key: "collection", Value: "posts"}}, time.Millisecond, false)
+	run(4, "insert", bson.D{{Key: "insert", Value: "comments"}}, 3*time.Millisecond, false)
+	run(5, "ping", bson.D{{Key: "ping", Value: 1}}, time.Millisecond, false)
+
+	stats := metrics.Snapshot()
+	require.Len(t, stats, 3)
+	assert.Equal(t, storage.OperationStats{
+		Collection: "comments", Operation: "insert", Calls: 1, TotalMs: 3, AvgMs: 3, MaxMs: 3,
+	}, stats[0])
+	assert.Equal(t, storage.OperationStats{
+		Collection: "posts", Operation: "find", Calls: 2, Errors: 1, ErrorRate: 0.5, TotalMs: 8, AvgMs: 4, MaxMs: 6,
+	}, stats[1])
+	assert.Equal(t, "getMore", stats[2].Operation)
+
+	var unset *storage.OperationMetrics
+	assert.Empty(t, unset.Snapshot())
+}
@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/handlers"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ndjsonLines decodes every line of an NDJSON body into a map.
+func ndjsonLines(t *testing.T, body io.Reader) []map[string]any {
+	var lines []map[string]any
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+// TestGetPostsNDJSON verifies GET /api/posts streams one summary per line,
+// with the filter and sort of the buffered listing, when the client accepts
+// NDJSON, and keeps the JSON envelope otherwise.
+func TestGetPostsNDJSON(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	id1 := models.ID("686c3a82361beb165141b490")
+	id2 := models.ID("686c3a82361beb165141b491")
+	cursor, err := mongo.NewCursorFromDocuments([]any{
+		models.BlogPostHeader{ID: id1, Title: "First Post", CreatedAt: time.Now()},
+		models.BlogPostHeader{ID: id2, Title: "Second Post", CreatedAt: time.Now()},
+	}, nil, nil)
+	require.NoError(t, err)
+	newestFirst := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	posts.On("LastModified", mock.Anything).Return(time.Time{}, nil)
+	posts.On("Stream", mock.Anything, mock.Anything, mock.MatchedBy(func(opts *options.FindOptions) bool {
+		return assert.ObjectsAreEqual(newestFirst, opts.Sort) && opts.Limit == nil
+	})).Return(cursor, nil)
+	comments.On("CountByPost", mock.Anything, id1).Return(int64(3), nil)
+	comments.On("CountByPost", mock.Anything, id2).Return(int64(0), nil)
+
+	app := fiber.New()
+	app.Get("/api/posts", h.GetPosts)
+	req := httptest.NewRequest("GET", "/api/posts", nil)
+	req.Header.Set("Accept", handlers.NDJSON_CONTENT_TYPE)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, handlers.NDJSON_CONTENT_TYPE, resp.Header.Get("Content-Type"))
+	lines := ndjsonLines(t, resp.Body)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "First Post", lines[0]["title"])
+	assert.Equal(t, float64(3), lines[0]["comment_count"])
+	assert.Equal(t, "Second Post", lines[1]["title"])
+	posts.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// JSON stays the default when both are acceptable
+	posts.On("Count", mock.Anything, mock.Anything).Return(int64(0), nil)
+	posts.On("List", mock.Anything, mock.Anything, newestFirst, int64(0), int64(handlers.DEFAULT_POSTS_PAGE_SIZE)).Return([]models.BlogPostHeader{}, nil)
+	req = httptest.NewRequest("GET", "/api/posts", nil)
+	req.Header.Set("Accept", "application/json, "+handlers.NDJSON_CONTENT_TYPE)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+	posts.AssertNumberOfCalls(t, "Stream", 1)
+	posts.AssertExpectations(t)
+}
+
+// TestExportPosts verifies the export streams every post in full, and
+// answers 502 when the cursor cannot be opened.
+func TestExportPosts(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	cursor, err := mongo.NewCursorFromDocuments([]any{
+		models.BlogPost{ID: "686c3a82361beb165141b490", Title: "Public", Content: "Full content", Visibility: models.VISIBILITY_PUBLIC},
+		models.BlogPost{ID: "686c3a82361beb165141b491", Title: "Private", Content: "Hidden content", Visibility: models.VISIBILITY_PRIVATE},
+	}, nil, nil)
+	require.NoError(t, err)
+	posts.On("Stream", mock.Anything, bson.M{}, mock.Anything).Return(cursor, nil).Once()
+	posts.On("Stream", mock.Anything, bson.M{}, mock.Anything).Return(nil, mongo.ErrClientDisconnected)
+
+	app := fiber.New()
+	app.Get("/api/posts/export", h.ExportPosts)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/posts/export", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, handlers.NDJSON_CONTENT_TYPE, resp.Header.Get("Content-Type"))
+	lines := ndjsonLines(t, resp.Body)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Full content", lines[0]["content"])
+	assert.Equal(t, "Hidden content", lines[1]["content"])
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/posts/export", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 502, resp.StatusCode)
+	assert.Equal(t, "Failed to export posts", decodeResponse(t, resp.Body).Error)
+}
@@ -0,0 +1,33 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/lib/token"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenRoundTripAndScope verifies that tokens carry their subject and
+// are rejected when used for another purpose, tampered with, or expired.
+func TestTokenRoundTripAndScope(t *testing.T) {
+	signer := token.NewSigner("test-secret")
+
+	signed := signer.Sign("preview", "686c3a82361beb165141b490", time.Now().Add(time.Hour))
+	subject, _, err := signer.Verify("preview", signed)
+	assert.NoError(t, err)
+	assert.Equal(t, "686c3a82361beb165141b490", subject)
+
+	// Same token, different feature scope
+	_, _, err = signer.Verify("access", signed)
+	assert.Equal(t, token.ErrInvalid, err)
+
+	// Signed by another key
+	_, _, err = token.NewSigner("other-secret").Verify("preview", signed)
+	assert.Equal(t, token.ErrInvalid, err)
+
+	// Past its expiry
+	expired := signer.Sign("preview", "686c3a82361beb165141b490", time.Now().Add(-time.Minute))
+	_, _, err = signer.Verify("preview", expired)
+	assert.Equal(t, token.ErrExpired, err)
+}
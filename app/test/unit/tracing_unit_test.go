@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/middleware"
+	"github.com/pedrobertao/challenge-prosi/app/lib/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTraceparent verifies well-formed traceparent headers round-trip
+// and malformed ones are rejected.
+func TestParseTraceparent(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := tracing.ParseTraceparent(header)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, header, sc.Traceparent())
+
+	for _, invalid := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01", // All-zero trace ID
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", // All-zero span ID
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+	} {
+		_, ok := tracing.ParseTraceparent(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+// TestTracing verifies a request continues the caller's trace: the server
+// span is a child of the incoming traceparent, outgoing requests carry the
+// trace on, and both spans are exported to the OTLP receiver.
+func TestTracing(t *testing.T) {
+	exported := make(chan map[string]any, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		exported <- body
+	}))
+	defer collector.Close()
+	var forwarded string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Get(tracing.TRACEPARENT_HEADER)
+	}))
+	defer downstream.Close()
+
+	tracing.Setup(collector.URL, map[string]string{"Authorization": "Bearer secret"}, "blog-api", 1)
+	defer tracing.Setup("", nil, "", 0)
+
+	client := &http.Client{Transport: tracing.Transport(nil)}
+	app := fiber.New()
+	app.Use(middleware.RequestID(), middleware.Tracing())
+	app.Get("/api/posts/:id", func(c *fiber.Ctx) error {
+		req, err := http.NewRequestWithContext(c.Context(), http.MethodGet, downstream.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/api/posts/1", nil)
+	req.Header.Set(tracing.TRACEPARENT_HEADER, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	server, ok := tracing.ParseTraceparent(resp.Header.Get(tracing.TRACEPARENT_HEADER))
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.TraceID.String())
+	outgoing, ok := tracing.ParseTraceparent(forwarded)
+	require.True(t, ok)
+	assert.Equal(t, server.TraceID, outgoing.TraceID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, tracing.Shutdown(ctx))
+	body := <-exported
+
+	// One resource, one scope, holding the client span, then the server's
+	resource := body["resourceSpans"].([]any)[0].(map[string]any)
+	spans := resource["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	require.Len(t, spans, 2)
+	clientSpan, serverSpan := spans[0].(map[string]any), spans[1].(map[string]any)
+	assert.Equal(t, "GET /api/posts/:id", serverSpan["name"])
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan["parentSpanId"])
+	assert.Equal(t, server.SpanID.String(), serverSpan["spanId"])
+	assert.Equal(t, server.SpanID.String(), clientSpan["parentSpanId"])
+	assert.Equal(t, outgoing.SpanID.String(), clientSpan["spanId"])
+}
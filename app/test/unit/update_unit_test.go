@@ -0,0 +1,251 @@
+package unit
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// offlineCollection returns a collection of a disconnected client, for
+// handlers writing in the background: every operation on it fails at once
+// with mongo.ErrClientDisconnected instead of panicking on a nil collection.
+func offlineCollection(t *testing.T, name string) *mongo.Collection {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	require.NoError(t, err)
+	require.NoError(t, client.Disconnect(context.Background()))
+	return client.Database("blog").Collection(name)
+}
+
+// TestUpdatePostFields verifies UpdatePost patches only the fields sent:
+// non-empty values are set, emptied ones unset, and updated_at always moves.
+func TestUpdatePostFields(t *testing.T) {
+	id := models.ID("686c3a82361beb165141b490")
+	cases := []struct {
+		name  string
+		body  string
+		set   bson.M
+		unset bson.M
+	}{
+		{"title", `{"title":"New title"}`, bson.M{"title": "New title"}, nil},
+		{"excerpt", `{"excerpt":"  Short  "}`, bson.M{"excerpt": "Short"}, nil},
+		{"cleared excerpt", `{"excerpt":" "}`, bson.M{}, bson.M{"excerpt": ""}},
+		{"tags", `{"tags":["Go","go","  Web   Dev "]}`, bson.M{"tags": []string{"go", "web dev"}}, nil},
+		{"cleared tags", `{"tags":[]}`, bson.M{}, bson.M{"tags": ""}},
+		{"cleared category", `{"category_id":""}`, bson.M{}, bson.M{"category_id": ""}},
+		{"title variants", `{"title":"A","title_variants":["A","B"]}`,
+			bson.M{"title": "A", "title_variants": []string{"B"}, "title_test": make([]models.TitleVariantStats, 2)}, nil},
+		{"ended title test", `{"title_variants":[]}`, bson.M{}, bson.M{"title_variants": "", "title_test": ""}},
+		{"comment window", `{"comments_close_after_days":7}`, bson.M{"comments_close_after_days": 7},
+			bson.M{"comments_locked": "", "comments_locked_at": ""}},
+		{"default comment window", `{"comments_close_after_days":-1}`, bson.M{},
+			bson.M{"comments_close_after_days": "", "comments_locked": "", "comments_locked_at": ""}},
+		{"publish at", `{"publish_at":"2030-03-01T09:00","timezone":"Europe/Paris"}`,
+			bson.M{"publish_at": time.Date(2030, 3, 1, 8, 0, 0, 0, time.UTC), "timezone": "Europe/Paris"}, nil},
+		{"published now", `{"publish_at":"","timezone":"UTC"}`, bson.M{"timezone": "UTC"}, bson.M{"publish_at": ""}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, posts, _ := newMockedHandler(t)
+			h.DB.PostCards = offlineCollection(t, "post_cards")
+			var update bson.M
+			posts.On("Update", mock.Anything, id, mock.Anything).Run(func(args mock.Arguments) {
+				update = args.Get(2).(bson.M)
+			}).Return(models.BlogPost{ID: id, Title: "New title"}, nil)
+			posts.On("TouchPost", mock.Anything, id).Return(nil)
+
+			app := fiber.New()
+			app.Put("/api/posts/:id", h.UpdatePost)
+			req := httptest.NewRequest("PUT", "/api/posts/"+id.String(), strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.StatusCode)
+
+			set := update["$set"].(bson.M)
+			assert.WithinDuration(t, time.Now(), set["updated_at"].(time.Time), time.Minute)
+			delete(set, "updated_at")
+			assert.Equal(t, tc.set, set)
+			if tc.unset == nil {
+				assert.NotContains(t, update, "$unset")
+			} else {
+				assert.Equal(t, tc.unset, update["$unset"])
+			}
+			posts.AssertExpectations(t)
+		})
+	}
+}
+
+// TestUpdatePostContent verifies a content edit is sanitized and refreshes
+// the values derived from the content.
+func TestUpdatePostContent(t *testing.T) {
+	h, posts, _ := newMockedHandler(t)
+	id := models.ID("686c3a82361beb165141b490")
+	var update bson.M
+	posts.On("Update", mock.Anything, id, mock.Anything).Run(func(args mock.Arguments) {
+		update = args.Get(2).(bson.M)
+	}).Return(models.BlogPost{ID: id}, nil)
+	posts.On("TouchPost", mock.Anything, id).Return(nil)
+
+	app := fiber.New()
+	app.Put("/api/posts/:id", h.UpdatePost)
+	req := httptest.NewRequest("PUT", "/api/posts/"+id.String(),
+		strings.NewReader(`{"content":"Hello there, this is an edited post.<script>alert(1)</script>"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+
+	set := update["$set"].(bson.M)
+	assert.NotContains(t, set["content"], "<script>")
+	assert.Contains(t, set, "stats")
+	assert.Contains(t, set, "linked_posts")
+	assert.Contains(t, set, "language")
+	assert.NotContains(t, set, "title")
+}
+
+// TestUpdatePostRejected verifies invalid edits are refused before any
+// write, and that a missing post answers 404.
+func TestUpdatePostRejected(t *testing.T) {
+	id := models.ID("686c3a82361beb165141b490")
+	cases := []struct {
+		name, path, body string
+		status           int
+		message          string
+	}{
+		{"invalid ID", "/api/posts/nope", `{"title":"A"}`, 400, "Invalid post ID"},
+		{"invalid JSON", "/api/posts/" + id.String(), `{"title":`, 400, "Invalid JSON"},
+		{"no fields", "/api/posts/" + id.String(), `{}`, 400, "No fields to update"},
+		{"empty title", "/api/posts/" + id.String(), `{"title":""}`, 400, "Title cannot be empty"},
+		{"empty content", "/api/posts/" + id.String(), `{"content":""}`, 400, "Content cannot be empty"},
+		{"script-only content", "/api/posts/" + id.String(), `{"content":"<script>alert(1)</script>"}`, 400, "Content cannot be empty"},
+		{"too many tags", "/api/posts/" + id.String(), `{"tags":["a","b","c","d","e","f","g","h","i","j","k"]}`, 400, "At most 10 tags of at most 32 characters"},
+		{"too long tag", "/api/posts/" + id.String(), `{"tags":["` + strings.Repeat("t", 33) + `"]}`, 400, "At most 10 tags of at most 32 characters"},
+		{"too many title variants", "/api/posts/" + id.String(), `{"title_variants":["a","b","c","d","e"]}`, 400, "At most 4 title variants"},
+		{"unknown timezone", "/api/posts/" + id.String(), `{"timezone":"Mars/Olympus"}`, 400, "Unknown timezone"},
+		{"invalid publish_at", "/api/posts/" + id.String(), `{"publish_at":"tomorrow","timezone":"UTC"}`, 400, "Invalid publish_at"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, posts, _ := newMockedHandler(t)
+			app := fiber.New()
+			app.Put("/api/posts/:id", h.UpdatePost)
+			req := httptest.NewRequest("PUT", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, tc.message, decodeResponse(t, resp.Body).Error)
+			posts.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("missing post", func(t *testing.T) {
+		h, posts, _ := newMockedHandler(t)
+		posts.On("Update", mock.Anything, id, mock.Anything).Return(models.BlogPost{}, mongo.ErrNoDocuments)
+		app := fiber.New()
+		app.Put("/api/posts/:id", h.UpdatePost)
+		req := httptest.NewRequest("PUT", "/api/posts/"+id.String(), strings.NewReader(`{"excerpt":"x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, 404, resp.StatusCode)
+		assert.Equal(t, "Post not found", decodeResponse(t, resp.Body).Error)
+		posts.AssertNotCalled(t, "TouchPost", mock.Anything, mock.Anything)
+	})
+}
+
+// TestUpdateComment verifies a comment edit stores the sanitized content
+// with its edit time and moves the last-modified time of the comment's post.
+func TestUpdateComment(t *testing.T) {
+	h, posts, comments := newMockedHandler(t)
+	commentID := models.ID("686c3a82361beb165141b4a0")
+	postID := models.ID("686c3a82361beb165141b490")
+	var editedAt time.Time
+	comments.On("UpdateContent", mock.Anything, commentID, "Edited comment", mock.Anything).Run(func(args mock.Arguments) {
+		editedAt = args.Get(3).(time.Time)
+	}).Return(models.Comment{ID: commentID, PostID: postID, Content: "Edited comment"}, nil)
+	posts.On("TouchPost", mock.Anything, postID).Return(nil)
+
+	app := fiber.New()
+	app.Put("/api/comments/:id", h.UpdateComment)
+	req := httptest.NewRequest("PUT", "/api/comments/"+commentID.String(),
+		strings.NewReader(`{"content":"Edited comment<script>alert(1)</script>"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.WithinDuration(t, time.Now(), editedAt, time.Minute)
+	comments.AssertExpectations(t)
+	posts.AssertExpectations(t)
+}
+
+// TestUpdateCommentRejected verifies invalid comment edits are refused
+// before any write, and that missing comments and failed writes are told
+// apart.
+func TestUpdateCommentRejected(t *testing.T) {
+	commentID := models.ID("686c3a82361beb165141b4a0")
+	cases := []struct {
+		name, path, body string
+		status           int
+		message          string
+	}{
+		{"invalid ID", "/api/comments/nope", `{"content":"Hello"}`, 400, "Invalid comment ID"},
+		{"invalid JSON", "/api/comments/" + commentID.String(), `{"content":`, 400, "Invalid JSON"},
+		{"missing content", "/api/comments/" + commentID.String(), `{}`, 400, "Content required"},
+		{"script-only content", "/api/comments/" + commentID.String(), `{"content":"<script>alert(1)</script>"}`, 400, "Content required"},
+		{"too short", "/api/comments/" + commentID.String(), `{"content":"Hi"}`, 400, "Comment must be at least 3 characters"},
+		{"too long", "/api/comments/" + commentID.String(), `{"content":"` + strings.Repeat("é", 11) + `"}`, 400, "Comment must be at most 10 characters"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, _, comments := newMockedHandler(t)
+			h.Config.CommentMinLength, h.Config.CommentMaxLength = 3, 10
+			app := fiber.New()
+			app.Put("/api/comments/:id", h.UpdateComment)
+			req := httptest.NewRequest("PUT", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, tc.message, decodeResponse(t, resp.Body).Error)
+			comments.AssertNotCalled(t, "UpdateContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	for _, tc := range []struct {
+		err     error
+		status  int
+		message string
+	}{
+		{mongo.ErrNoDocuments, 404, "Comment not found"},
+		{mongo.ErrClientDisconnected, 502, "Failed to update comment"},
+	} {
+		h, posts, comments := newMockedHandler(t)
+		comments.On("UpdateContent", mock.Anything, commentID, "Hello", mock.Anything).Return(models.Comment{}, tc.err)
+		app := fiber.New()
+		app.Put("/api/comments/:id", h.UpdateComment)
+		req := httptest.NewRequest("PUT", "/api/comments/"+commentID.String(), strings.NewReader(`{"content":"Hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, tc.status, resp.StatusCode)
+		assert.Equal(t, tc.message, decodeResponse(t, resp.Body).Error)
+		posts.AssertNotCalled(t, "TouchPost", mock.Anything, mock.Anything)
+	}
+}
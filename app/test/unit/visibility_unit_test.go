@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pedrobertao/challenge-prosi/app/internal/models"
+	"github.com/pedrobertao/challenge-prosi/app/internal/visibility"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestVisibilityFilters verifies each read path's filter, in both its
+// MongoDB and in-memory forms.
+func TestVisibilityFilters(t *testing.T) {
+	published := visibility.PUBLISHED.BSON()
+	assert.Contains(t, published, "publish_at")
+	assert.Equal(t, bson.M{
+		"visibility": bson.M{"$nin": bson.A{models.VISIBILITY_UNLISTED, models.VISIBILITY_PRIVATE}},
+		"archived":   bson.M{"$ne": true},
+		"protected":  bson.M{"$ne": true},
+		"publish_at": published["publish_at"],
+	}, published)
+	assert.Equal(t, bson.M{
+		"visibility": bson.M{"$nin": bson.A{models.VISIBILITY_PRIVATE}},
+		"protected":  bson.M{"$ne": true},
+	}, withoutPublishAt(visibility.PAGES.BSON()))
+	assert.Empty(t, visibility.HIDDEN.BSON())
+
+	public := models.BlogPost{}
+	archived := models.BlogPost{Archived: true}
+	unlisted := models.BlogPost{Visibility: models.VISIBILITY_UNLISTED}
+	private := models.BlogPost{Visibility: models.VISIBILITY_PRIVATE}
+	protected := models.BlogPost{Visibility: models.VISIBILITY_PUBLIC, Protected: true}
+	later, earlier := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+	scheduled := models.BlogPost{PublishAt: &later}
+	live := models.BlogPost{PublishAt: &earlier}
+
+	assert.True(t, visibility.PUBLISHED.Allows(public))
+	assert.False(t, visibility.PUBLISHED.Allows(archived))
+	assert.False(t, visibility.PUBLISHED.Allows(protected))
+	assert.True(t, visibility.LISTING.Allows(protected))
+	assert.False(t, visibility.LISTING.Allows(unlisted))
+	assert.True(t, visibility.PAGES.Allows(unlisted))
+	assert.False(t, visibility.PAGES.Allows(private))
+	assert.False(t, visibility.PUBLISHED.Allows(scheduled))
+	assert.False(t, visibility.PAGES.Allows(scheduled))
+	assert.True(t, visibility.PUBLISHED.Allows(live))
+	for _, post := range []models.BlogPost{public, archived, unlisted, private, protected, scheduled} {
+		assert.True(t, visibility.HIDDEN.Allows(post))
+	}
+}
+
+// withoutPublishAt drops the time-dependent publish_at condition of a
+// visibility filter so the rest compares exactly.
+func withoutPublishAt(filter bson.M) bson.M {
+	delete(filter, "publish_at")
+	return filter
+}